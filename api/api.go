@@ -0,0 +1,11 @@
+package api
+
+import _ "embed"
+
+//go:generate go run gen.go
+
+// OpenAPI - OpenAPI 3.1 description of the HTTP API in YAML generated from swagger.yaml, the DTOs are generated
+// from swagger.yaml as well
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"homework/internal/openapigen"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI_GeneratedFromSwagger(t *testing.T) {
+	swagger, err := os.ReadFile("swagger.yaml")
+	require.NoError(t, err)
+	expected, err := openapigen.Convert(swagger)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(OpenAPI), "openapi.yaml is out of date, run go generate ./api")
+}
@@ -0,0 +1,24 @@
+//go:build ignore
+
+// gen - writes openapi.yaml converted from swagger.yaml, run by go generate in the api directory
+package main
+
+import (
+	"homework/internal/openapigen"
+	"log"
+	"os"
+)
+
+func main() {
+	swagger, err := os.ReadFile("swagger.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	openapi, err := openapigen.Convert(swagger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("openapi.yaml", openapi, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
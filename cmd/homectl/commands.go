@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"homework/pkg/client"
+)
+
+// keyValues - repeatable flag of key=value pairs
+type keyValues map[string]string
+
+func (kv keyValues) String() string {
+	pairs := make([]string, 0, len(kv))
+	for k, v := range kv {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (kv keyValues) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("%q is not key=value", value)
+	}
+	kv[k] = v
+	return nil
+}
+
+// requireFlags - reports the required flags that were not set
+func requireFlags(fs *flag.FlagSet, names ...string) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, name := range names {
+		if !set[name] {
+			fmt.Fprintf(fs.Output(), "flag -%s is required\n", name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+func (a *app) sensorsList(ctx context.Context, c *client.Client, args []string) error {
+	fs := a.commandFlags("sensors list")
+	if ok, err := parseCommandFlags(fs, args); !ok {
+		return err
+	}
+
+	sensors, err := c.Sensors(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(sensors, func(i, j int) bool { return sensors[i].ID < sensors[j].ID })
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSERIAL\tTYPE\tACTIVE\tVALUE\tLAST ACTIVITY\tDESCRIPTION")
+	for _, s := range sensors {
+		lastActivity := "-"
+		if !s.LastActivity.IsZero() && s.LastActivity.Year() > 1 {
+			lastActivity = s.LastActivity.Local().Format(time.DateTime)
+		}
+		value := strconv.FormatFloat(s.CurrentValue, 'f', -1, 64)
+		if s.Unit != "" {
+			value += " " + s.Unit
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\t%s\t%s\n",
+			s.ID, s.SerialNumber, s.Type, s.IsActive, value, lastActivity, s.Description)
+	}
+	return w.Flush()
+}
+
+func (a *app) sensorsRegister(ctx context.Context, c *client.Client, args []string) error {
+	fs := a.commandFlags("sensors register")
+	serial := fs.String("serial", "", "serial number, 10 digits (required)")
+	sensorType := fs.String("type", "", "sensor type: cc, adc, temperature, humidity, motion, power_meter (required)")
+	description := fs.String("description", "", "description")
+	active := fs.Bool("active", true, "whether the sensor is active")
+	labels := keyValues{}
+	fs.Var(labels, "label", "label key=value, repeatable")
+	if ok, err := parseCommandFlags(fs, args); !ok {
+		return err
+	}
+	if err := requireFlags(fs, "serial", "type"); err != nil {
+		return err
+	}
+
+	sensor, err := c.RegisterSensor(ctx, client.SensorToCreate{
+		SerialNumber: *serial,
+		Type:         *sensorType,
+		Description:  *description,
+		IsActive:     *active,
+		Labels:       labels,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "sensor %d registered (%s, %s)\n", sensor.ID, sensor.SerialNumber, sensor.Type)
+	return nil
+}
+
+func (a *app) eventsPush(ctx context.Context, c *client.Client, args []string) error {
+	fs := a.commandFlags("events push")
+	serial := fs.String("serial", "", "serial number of the sensor (required)")
+	payload := fs.Int64("payload", 0, "payload of a sensor without channels")
+	values := keyValues{}
+	fs.Var(values, "value", "channel=value of a multichannel sensor, repeatable")
+	id := fs.String("id", "", "event id, the retried pushes with the same id are stored once")
+	if ok, err := parseCommandFlags(fs, args); !ok {
+		return err
+	}
+	if err := requireFlags(fs, "serial"); err != nil {
+		return err
+	}
+
+	event := client.EventToPush{SensorSerialNumber: *serial, EventID: *id}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "payload" {
+			event.Payload = payload
+		}
+	})
+	if len(values) > 0 {
+		event.Values = make(map[string]int64, len(values))
+		for channel, raw := range values {
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value of channel %s: %w", channel, err)
+			}
+			event.Values[channel] = v
+		}
+	}
+
+	created, err := c.PushEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Fprintln(a.stdout, "event stored")
+	} else {
+		fmt.Fprintln(a.stdout, "event already stored")
+	}
+	return nil
+}
+
+func (a *app) eventsTail(ctx context.Context, c *client.Client, args []string) error {
+	fs := a.commandFlags("events tail")
+	sensorID := fs.Int64("sensor", 0, "sensor id (required)")
+	if ok, err := parseCommandFlags(fs, args); !ok {
+		return err
+	}
+	if err := requireFlags(fs, "sensor"); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(a.stdout)
+	err := c.SubscribeSensor(ctx, *sensorID, func(e client.Event) error {
+		return enc.Encode(e)
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil // interrupted
+	}
+	return err
+}
+
+func (a *app) historyExport(ctx context.Context, c *client.Client, args []string) error {
+	fs := a.commandFlags("history export")
+	sensorID := fs.Int64("sensor", 0, "sensor id (required)")
+	from := fs.String("from", "24h", "start of the range, RFC 3339 time or duration back from now")
+	to := fs.String("to", "0s", "end of the range, RFC 3339 time or duration back from now")
+	channel := fs.String("channel", "", "channel of a multichannel sensor, the first one by default")
+	format := fs.String("format", "csv", "output format: csv or json")
+	output := fs.String("o", "", "output file, stdout by default")
+	if ok, err := parseCommandFlags(fs, args); !ok {
+		return err
+	}
+	if err := requireFlags(fs, "sensor"); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q, expected csv or json", *format)
+	}
+
+	now := time.Now()
+	start, err := parseTime(*from, now)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	end, err := parseTime(*to, now)
+	if err != nil {
+		return fmt.Errorf("invalid -to: %w", err)
+	}
+
+	history, err := c.SensorHistory(ctx, *sensorID, start, end, *channel)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return writeHistory(a.stdout, *format, history)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("can't create output file: %w", err)
+	}
+	if err := writeHistory(f, *format, history); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeHistory(w io.Writer, format string, history []client.HistoryPoint) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(history)
+	}
+	return writeHistoryCSV(w, history)
+}
+
+func writeHistoryCSV(w io.Writer, history []client.HistoryPoint) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"timestamp", "payload", "raw_payload", "value"})
+	for _, p := range history {
+		_ = cw.Write([]string{
+			p.Timestamp.UTC().Format(time.RFC3339Nano),
+			strconv.FormatInt(p.Payload, 10),
+			strconv.FormatInt(p.RawPayload, 10),
+			strconv.FormatFloat(p.Value, 'f', -1, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
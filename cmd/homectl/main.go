@@ -0,0 +1,153 @@
+// homectl - command line client of the home controller API
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"homework/pkg/client"
+)
+
+const (
+	serverEnv     = "HOMECTL_SERVER"
+	apiKeyEnv     = "HOMECTL_API_KEY"
+	defaultServer = "http://localhost:8080"
+)
+
+// errUsage - the usage was already printed, so the error itself is not
+var errUsage = errors.New("invalid usage")
+
+const usage = `Usage: homectl [flags] <command> [command flags]
+
+Commands:
+  sensors list                       list the sensors
+  sensors register -serial -type ... register a sensor or update the one with the serial number
+  events push -serial -payload ...   push an event of a sensor
+  events tail -sensor ID             print the events of a sensor as JSON lines until interrupted,
+                                     reconnecting if the connection drops
+  history export -sensor ID ...      export the history of a sensor channel as CSV or JSON
+
+Run "homectl <command> -h" for the command flags.
+
+Flags:
+`
+
+// app - the CLI with its environment, the tests replace the streams and the client options
+type app struct {
+	stdout        io.Writer
+	stderr        io.Writer
+	lookupEnv     func(string) (string, bool)
+	clientOptions []func(*client.Client)
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	a := &app{stdout: os.Stdout, stderr: os.Stderr, lookupEnv: os.LookupEnv}
+	if err := a.run(ctx, os.Args[1:]); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "homectl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run - parses the global flags and runs the command
+func (a *app) run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("homectl", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprint(a.stderr, usage)
+		fs.PrintDefaults()
+	}
+	server := fs.String("server", a.env(serverEnv, defaultServer), "base URL of the controller (env "+serverEnv+")")
+	apiKey := fs.String("api-key", a.env(apiKeyEnv, ""), "API key sent in X-API-Key (env "+apiKeyEnv+")")
+	timeout := fs.Duration("timeout", client.DefaultTimeout, "timeout of a request")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	options := append([]func(*client.Client){
+		client.WithHTTPClient(&http.Client{Timeout: *timeout}),
+		client.WithAPIKey(*apiKey),
+		client.WithUserAgent("homectl"),
+	}, a.clientOptions...)
+	c, err := client.New(*server, options...)
+	if err != nil {
+		return err
+	}
+
+	command, commandArgs := fs.Arg(0)+" "+fs.Arg(1), fs.Args()[2:]
+	switch command {
+	case "sensors list":
+		return a.sensorsList(ctx, c, commandArgs)
+	case "sensors register":
+		return a.sensorsRegister(ctx, c, commandArgs)
+	case "events push":
+		return a.eventsPush(ctx, c, commandArgs)
+	case "events tail":
+		return a.eventsTail(ctx, c, commandArgs)
+	case "history export":
+		return a.historyExport(ctx, c, commandArgs)
+	}
+	fmt.Fprintf(a.stderr, "unknown command %q\n\n", command)
+	fs.Usage()
+	return errUsage
+}
+
+func (a *app) env(name, fallback string) string {
+	if value, ok := a.lookupEnv(name); ok {
+		return value
+	}
+	return fallback
+}
+
+// commandFlags - flag set of the command, its errors are reported by the set itself
+func (a *app) commandFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("homectl "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// parseCommandFlags - parses the command flags, the help is not an error
+func parseCommandFlags(fs *flag.FlagSet, args []string) (bool, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, nil
+		}
+		return false, errUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments %q\n", fs.Args())
+		fs.Usage()
+		return false, errUsage
+	}
+	return true, nil
+}
+
+// parseTime - RFC 3339 time or a duration back from now, e.g. 24h
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC 3339 time nor duration", value)
+	}
+	return t, nil
+}
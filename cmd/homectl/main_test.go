@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"homework/internal/testserver"
+	"homework/pkg/client"
+)
+
+// syncBuffer - stdout of the commands running concurrently with the test
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestApp(t *testing.T, srv *testserver.TestServer, stdout *syncBuffer) *app {
+	return &app{
+		stdout: stdout,
+		stderr: &syncBuffer{},
+		lookupEnv: func(name string) (string, bool) {
+			if name == serverEnv {
+				return srv.URL, true
+			}
+			return "", false
+		},
+		clientOptions: []func(*client.Client){
+			client.WithHTTPClient(&http.Client{Transport: testserver.SpecTransport(t, nil), Timeout: 5 * time.Second}),
+			client.WithReconnectDelay(50*time.Millisecond, time.Second),
+		},
+	}
+}
+
+func TestHomectl(t *testing.T) {
+	srv := testserver.SetupTestServer()
+	defer srv.Close()
+	ctx := context.Background()
+	homectl := func(args ...string) (string, error) {
+		stdout := &syncBuffer{}
+		err := newTestApp(t, srv, stdout).run(ctx, args)
+		return stdout.String(), err
+	}
+
+	out, err := homectl("sensors", "register", "-serial", "0000000001", "-type", "adc",
+		"-description", "water level", "-label", "kind=level", "-label", "floor=0")
+	require.NoError(t, err)
+	assert.Equal(t, "sensor 1 registered (0000000001, adc)\n", out)
+
+	sensor, err := srv.UseCases.Sensor.GetSensorByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"kind": "level", "floor": "0"}, sensor.Labels)
+
+	t.Run("ok, tail", func(t *testing.T) {
+		tailCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stdout := &syncBuffer{}
+		done := make(chan error)
+		go func() {
+			done <- newTestApp(t, srv, stdout).run(tailCtx, []string{"events", "tail", "-sensor", "1"})
+		}()
+		time.Sleep(100 * time.Millisecond) // subscribed
+
+		out, err := homectl("events", "push", "-serial", "0000000001", "-payload", "42", "-id", "e-1")
+		require.NoError(t, err)
+		assert.Equal(t, "event stored\n", out)
+		out, err = homectl("events", "push", "-serial", "0000000001", "-payload", "42", "-id", "e-1")
+		require.NoError(t, err)
+		assert.Equal(t, "event already stored\n", out)
+
+		require.Eventually(t, func() bool { return stdout.String() != "" }, 3*time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		require.Len(t, lines, 1)
+		var e client.Event
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+		assert.Equal(t, int64(42), e.Payload)
+		assert.Equal(t, "0000000001", e.SensorSerialNumber)
+	})
+
+	t.Run("ok, list", func(t *testing.T) {
+		out, err := homectl("sensors", "list")
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, []string{"ID", "SERIAL", "TYPE", "ACTIVE", "VALUE", "LAST", "ACTIVITY", "DESCRIPTION"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"1", "0000000001", "adc", "true", "42"}, strings.Fields(lines[1])[:5])
+		assert.True(t, strings.HasSuffix(lines[1], "water level"))
+	})
+
+	t.Run("ok, export", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.csv")
+		_, err := homectl("history", "export", "-sensor", "1", "-from", "1h", "-o", path)
+		require.NoError(t, err)
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		rows, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, []string{"timestamp", "payload", "raw_payload", "value"}, rows[0])
+		assert.Equal(t, []string{"42", "42", "42"}, rows[1][1:])
+
+		out, err := homectl("history", "export", "-sensor", "1", "-format", "json")
+		require.NoError(t, err)
+		var history []client.HistoryPoint
+		require.NoError(t, json.Unmarshal([]byte(out), &history))
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("fail, server errors", func(t *testing.T) {
+		_, err := homectl("events", "tail", "-sensor", "42")
+		assert.True(t, client.IsProblem(err, "sensor_not_found"), err)
+
+		_, err = homectl("sensors", "register", "-serial", "1", "-type", "cc")
+		assert.True(t, client.IsProblem(err, "validation_failed"), err)
+
+		_, err = homectl("history", "export", "-sensor", "1", "-from", "yesterday")
+		assert.ErrorContains(t, err, "invalid -from")
+	})
+
+	t.Run("fail, usage", func(t *testing.T) {
+		for _, args := range [][]string{
+			{},
+			{"sensors"},
+			{"sensors", "remove"},
+			{"sensors", "register", "-serial", "0000000002"},
+			{"events", "push", "-serial", "0000000001", "-value", "temperature"},
+			{"sensors", "list", "extra"},
+		} {
+			_, err := homectl(args...)
+			assert.ErrorIs(t, err, errUsage, args)
+		}
+
+		_, err := homectl("-server", "localhost", "sensors", "list")
+		assert.ErrorIs(t, err, client.ErrInvalidBaseURL)
+	})
+}
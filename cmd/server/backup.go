@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/backup"
+	"homework/internal/config"
+	"homework/internal/logging"
+	"homework/internal/repository/pgoptions"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	eventPostgres "homework/internal/repository/event/postgres"
+	locationPostgres "homework/internal/repository/location/postgres"
+	sensorPostgres "homework/internal/repository/sensor/postgres"
+	userPostgres "homework/internal/repository/user/postgres"
+)
+
+const backupUsage = `Usage: %s backup <command> FILE [config flags]
+
+Commands:
+  export FILE|-  write the locations, users, sensors, owner bindings, location access and event history
+                 to the compressed archive, - for stdout
+  import FILE    verify the archive and restore it, the records already present are skipped,
+                 so an interrupted import is resumed by running it again
+  verify FILE|-  check the format version, structure and checksum of the archive, - for stdin
+
+Export and import work with the %q storage, an in-memory server is backed up with the admin API.
+`
+
+var errBackupUsage = errors.New("invalid usage")
+
+// runBackup - the backup subcommand, args follow "backup"
+func runBackup(name string, args []string, stdin io.Reader, stdout, stderr io.Writer, lookupEnv func(string) (string, bool)) error {
+	usageErr := func() error {
+		fmt.Fprintf(stderr, backupUsage, name, config.StorageBackendPostgres)
+		return errBackupUsage
+	}
+	if len(args) < 2 || strings.HasPrefix(args[0], "-") || (strings.HasPrefix(args[1], "-") && args[1] != "-") {
+		return usageErr()
+	}
+	command, path, args := args[0], args[1], args[2:]
+	switch {
+	case command == "verify":
+		return verifyBackup(path, stdin, stdout)
+	case command == "import" && path == "-":
+		// the archive is read twice: verified before anything is written
+		return usageErr()
+	case command != "export" && command != "import":
+		return usageErr()
+	}
+
+	cfg, err := config.Load(name+" backup "+command, args, lookupEnv)
+	if err != nil {
+		return err
+	}
+	if cfg.Storage.Backend != config.StorageBackendPostgres {
+		return fmt.Errorf("backup works with the %q storage only, use the admin API of the server", config.StorageBackendPostgres)
+	}
+	logger, err := logging.New(stderr, logging.Options{Level: cfg.Log.SlogLevel(), Format: cfg.Log.Format})
+	if err != nil {
+		return fmt.Errorf("can't create logger: %w", err)
+	}
+	if err := migrateOnStart(logger, cfg.Storage); err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	pool, err := newPool(ctx, cfg.Storage)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	pgOptions := pgoptions.WithLogger(logger)
+	archiver := backup.New(backup.Repositories{
+		Users:        userPostgres.NewUserRepository(pool, pgOptions),
+		SensorOwners: userPostgres.NewSensorOwnerRepository(pool, pgOptions),
+		Sensors:      sensorPostgres.NewSensorRepository(pool, pgOptions),
+		Events:       eventPostgres.NewEventRepository(pool, pgOptions),
+		Locations:    locationPostgres.NewLocationRepository(pool, pgOptions),
+	}, backup.WithLogger(logger))
+
+	if command == "export" {
+		return exportBackup(ctx, archiver, path, stdout)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	summary, err := archiver.Import(ctx, f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "imported %+v\nskipped  %+v\nremapped %+v\n", summary.Imported, summary.Skipped, summary.Remapped)
+	return nil
+}
+
+// exportBackup - writes the archive to a temporary file renamed to path once complete,
+// so a failed export doesn't leave a partial archive behind
+func exportBackup(ctx context.Context, archiver *backup.Archiver, path string, stdout io.Writer) error {
+	if path == "-" {
+		_, err := archiver.Export(ctx, stdout)
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	manifest, err := archiver.Export(ctx, f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%+v\nsha256 %s\n", manifest.Counts, manifest.SHA256)
+	return nil
+}
+
+func verifyBackup(path string, stdin io.Reader, stdout io.Writer) error {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	manifest, err := backup.Verify(r)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "version %d, created %s\n%+v\nsha256 %s\n",
+		manifest.Version, manifest.CreatedAt.Format(time.RFC3339), manifest.Counts, manifest.SHA256)
+	return nil
+}
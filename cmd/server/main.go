@@ -2,75 +2,318 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"homework/internal/backup"
+	"homework/internal/config"
 	"homework/internal/domain"
+	"homework/internal/health"
+	"homework/internal/logging"
+	"homework/internal/ratelimit"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
 	"homework/internal/usecase"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	httpGateway "homework/internal/gateways/http"
+	"homework/internal/gateways/webhook"
 	metrics "homework/internal/metrics"
-	eventRepository "homework/internal/repository/event/postgres"
-	sensorRepository "homework/internal/repository/sensor/postgres"
+	actuatorInMemory "homework/internal/repository/actuator/inmemory"
+	actuatorPostgres "homework/internal/repository/actuator/postgres"
+	automationInMemory "homework/internal/repository/automation/inmemory"
+	automationPostgres "homework/internal/repository/automation/postgres"
+	eventInMemory "homework/internal/repository/event/inmemory"
+	eventPostgres "homework/internal/repository/event/postgres"
+	jobInMemory "homework/internal/repository/job/inmemory"
+	jobPostgres "homework/internal/repository/job/postgres"
+	locationInMemory "homework/internal/repository/location/inmemory"
+	locationPostgres "homework/internal/repository/location/postgres"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+	sensorPostgres "homework/internal/repository/sensor/postgres"
 	subscriptionRepository "homework/internal/repository/subscription/inmemory"
-	userRepository "homework/internal/repository/user/postgres"
+	userInMemory "homework/internal/repository/user/inmemory"
+	userPostgres "homework/internal/repository/user/postgres"
 )
 
-func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer cancel()
+type repositories struct {
+	event       usecase.EventRepository
+	sensor      usecase.SensorRepository
+	user        usecase.UserRepository
+	sensorOwner usecase.SensorOwnerRepository
+	location    usecase.LocationRepository
+	actuator    usecase.ActuatorRepository
+	command     usecase.CommandRepository
+	automation  usecase.AutomationRepository
+	job         usecase.JobRepository
+	jobLocker   usecase.JobLocker
+	pending     usecase.PendingSensorRepository
+}
 
-	config, err := pgxpool.ParseConfig(os.Getenv("DATABASE_URL"))
+func newPool(ctx context.Context, cfg config.StorageConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("can't parse pgxpool config")
+		return nil, fmt.Errorf("can't parse pgxpool config: %w", err)
 	}
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.ConnConfig.ConnectTimeout = time.Duration(cfg.ConnectTimeout)
+	poolConfig.ConnConfig.Tracer = tracing.NewPgxTracer()
 
-	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		log.Fatalf("can't create new pool")
+		return nil, fmt.Errorf("can't create new pool: %w", err)
 	}
-	defer pool.Close()
+	return pool, nil
+}
 
-	er := eventRepository.NewEventRepository(pool)
-	sr := sensorRepository.NewSensorRepository(pool)
-	ur := userRepository.NewUserRepository(pool)
-	sor := userRepository.NewSensorOwnerRepository(pool)
-	esr := subscriptionRepository.NewSubscriptionRepository[domain.Event]()
+// heartbeatPeriods - how many periods a background loop may miss before it is reported stuck,
+// minHeartbeatAge keeps the short periods from failing the probes on a brief stall
+const (
+	heartbeatPeriods = 3
+	minHeartbeatAge  = 30 * time.Second
+)
 
-	useCases := httpGateway.UseCases{
-		Event:             usecase.NewEvent(er, sr, esr),
-		Sensor:            usecase.NewSensor(sr),
-		User:              usecase.NewUser(ur, sor, sr),
-		EventSubscription: usecase.NewSubscription[domain.Event](esr, sr),
+// newHeartbeat - registers the readiness check of the background loop running every period
+func newHeartbeat(registry *health.Registry, name string, period time.Duration) *health.Heartbeat {
+	hb := health.NewHeartbeat(name, max(heartbeatPeriods*period, minHeartbeatAge))
+	registry.Register(name, hb.Check)
+	return hb
+}
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[0], os.Args[2:], os.Stdout, os.Stderr, os.LookupEnv); err != nil {
+			if !errors.Is(err, errMigrateUsage) {
+				fmt.Fprintln(os.Stderr, "migrate:", err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		if err := runBackup(os.Args[0], os.Args[2:], os.Stdin, os.Stdout, os.Stderr, os.LookupEnv); err != nil {
+			if !errors.Is(err, errBackupUsage) {
+				fmt.Fprintln(os.Stderr, "backup:", err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := config.LoadFromOS()
+	if err != nil {
+		log.Fatalf("can't load config: %v", err)
 	}
 
-	r := httpGateway.NewServer(useCases)
-	if host, ok := os.LookupEnv("HTTP_HOST"); ok {
-		httpGateway.WithHost(host)(r)
+	logger, err := logging.New(os.Stderr, logging.Options{Level: cfg.Log.SlogLevel(), Format: cfg.Log.Format})
+	if err != nil {
+		log.Fatalf("can't create logger: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		ServiceName:  cfg.Tracing.ServiceName,
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal(logger, "can't setup tracing", err)
 	}
-	if port, ok := os.LookupEnv("HTTP_PORT"); ok {
-		iPort, err := strconv.ParseUint(port, 10, 16)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.HTTP.ShutdownTimeout))
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Error("can't flush traces", "error", err)
+		}
+	}()
+
+	healthRegistry := health.NewRegistry(health.WithCheckTimeout(time.Duration(cfg.Health.CheckTimeout)))
+
+	var repos repositories
+	switch cfg.Storage.Backend {
+	case config.StorageBackendPostgres:
+		if err := migrateOnStart(logger, cfg.Storage); err != nil {
+			fatal(logger, "can't start on the database schema", err)
+		}
+		pool, err := newPool(context.Background(), cfg.Storage)
 		if err != nil {
-			log.Fatalf("invalid HTTP_PORT is set: %v", err)
+			fatal(logger, "can't connect to the database", err)
+		}
+		defer pool.Close()
+		healthRegistry.Register("database", pool.Ping)
+		if err := metrics.RegisterPool(pool); err != nil {
+			fatal(logger, "can't register pool metrics", err)
+		}
+
+		pgOptions := pgoptions.WithLogger(logger)
+		repos = repositories{
+			event:       eventPostgres.NewEventRepository(pool, pgOptions),
+			sensor:      sensorPostgres.NewSensorRepository(pool, pgOptions),
+			user:        userPostgres.NewUserRepository(pool, pgOptions),
+			sensorOwner: userPostgres.NewSensorOwnerRepository(pool, pgOptions),
+			location:    locationPostgres.NewLocationRepository(pool, pgOptions),
+			actuator:    actuatorPostgres.NewActuatorRepository(pool, pgOptions),
+			command:     actuatorPostgres.NewCommandRepository(pool, pgOptions),
+			automation:  automationPostgres.NewAutomationRepository(pool, pgOptions),
+			job:         jobPostgres.NewJobRepository(pool, pgOptions),
+			jobLocker:   jobPostgres.NewJobLocker(pool, pgOptions),
+			pending:     sensorPostgres.NewPendingSensorRepository(pool, pgOptions),
+		}
+	case config.StorageBackendInMemory:
+		repos = repositories{
+			event:       eventInMemory.NewEventRepository(),
+			sensor:      sensorInMemory.NewSensorRepository(),
+			user:        userInMemory.NewUserRepository(),
+			sensorOwner: userInMemory.NewSensorOwnerRepository(),
+			location:    locationInMemory.NewLocationRepository(),
+			actuator:    actuatorInMemory.NewActuatorRepository(),
+			command:     actuatorInMemory.NewCommandRepository(),
+			automation:  automationInMemory.NewAutomationRepository(),
+			job:         jobInMemory.NewJobRepository(),
+			jobLocker:   jobInMemory.NewJobLocker(),
+			pending:     sensorInMemory.NewPendingSensorRepository(),
 		}
-		httpGateway.WithPort(uint16(iPort))(r)
 	}
+	esr := subscriptionRepository.NewSubscriptionRepository[domain.Event]()
+	csr := subscriptionRepository.NewSubscriptionRepository[domain.Command]()
+	healthRegistry.Register("subscriptions", esr.Ping)
+	healthRegistry.Register("metrics_server", metrics.ServerCheck)
 
-	metricsPort := 8008
-	if port, ok := os.LookupEnv("METRICS_PORT"); ok {
-		metricsPort, err = strconv.Atoi(port)
-		if err != nil {
-			log.Fatalf("invalid METRICS_PORT is set: %v", err)
+	ucLogger := usecase.WithLogger(logger)
+	dedupWindow := usecase.WithDedupWindow(time.Duration(cfg.Events.DedupWindow))
+	useCases := httpGateway.UseCases{
+		Event:             usecase.NewEvent(repos.event, repos.sensor, esr, ucLogger, dedupWindow),
+		Sensor:            usecase.NewSensor(repos.sensor, ucLogger),
+		User:              usecase.NewUser(repos.user, repos.sensorOwner, repos.sensor, repos.location, ucLogger),
+		Location:          usecase.NewLocation(repos.location, repos.sensor, ucLogger),
+		EventSubscription: usecase.NewSubscription[domain.Event](esr, repos.sensor, ucLogger),
+		Actuator:          usecase.NewActuator(repos.actuator, repos.command, csr, ucLogger),
+	}
+	webhookSecret := []byte(cfg.Automation.WebhookSecret)
+	if len(webhookSecret) == 0 {
+		webhookSecret = make([]byte, 32)
+		if _, err := rand.Read(webhookSecret); err != nil {
+			fatal(logger, "can't generate webhook secret", err)
 		}
+		logger.Info("webhook secret is not set, a random one is used, the loop protection doesn't span the replicas")
 	}
-	metrics.InitMetricsServer(metricsPort)
+	automationHeartbeat := newHeartbeat(healthRegistry, "automation", time.Duration(cfg.Automation.TickPeriod))
+	useCases.Automation = usecase.NewAutomation(repos.automation, repos.sensor, useCases.Actuator,
+		webhook.NewSender(webhook.WithTimeout(time.Duration(cfg.Automation.WebhookTimeout)), webhook.WithSecret(webhookSecret)),
+		ucLogger, usecase.WithHeartbeat(automationHeartbeat.Beat))
+	useCases.Event.AddListener(useCases.Automation)
+	automationDone := make(chan struct{})
+	go func() {
+		defer close(automationDone)
+		defer automationHeartbeat.Stop()
+		useCases.Automation.Run(ctx, time.Duration(cfg.Automation.TickPeriod))
+	}()
+
+	if cfg.Events.Provisioning {
+		useCases.Provisioning = usecase.NewProvisioning(repos.pending, repos.user, useCases.Sensor, useCases.User,
+			useCases.Event, ucLogger, usecase.WithPendingLimits(usecase.PendingLimits{
+				Sensors: cfg.Events.MaxPendingSensors,
+				Events:  cfg.Events.PendingEvents,
+			}))
+		useCases.Event.SetProvisioning(useCases.Provisioning)
+	}
+
+	schedulerHeartbeat := newHeartbeat(healthRegistry, "scheduler", time.Duration(cfg.Scheduler.TickPeriod))
+	useCases.Scheduler = usecase.NewScheduler(repos.job, repos.jobLocker, ucLogger, usecase.WithHeartbeat(schedulerHeartbeat.Beat))
+	if err := useCases.Scheduler.Register("expire_commands", "* * * * *", useCases.Actuator.ExpireCommands); err != nil {
+		fatal(logger, "can't register job", err)
+	}
+	if err := useCases.Scheduler.Register("release_idempotency_keys", "* * * * *", useCases.Event.ReleaseIdempotencyKeys); err != nil {
+		fatal(logger, "can't register job", err)
+	}
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		defer schedulerHeartbeat.Stop()
+		useCases.Scheduler.Run(ctx, time.Duration(cfg.Scheduler.TickPeriod))
+	}()
+
+	coalescerDone := make(chan struct{})
+	if cfg.RateLimit.CoalesceContactClosure {
+		coalescerHeartbeat := newHeartbeat(healthRegistry, "coalescer", time.Duration(cfg.RateLimit.CoalesceWindow))
+		useCases.Coalescer = usecase.NewCoalescer(useCases.Event, repos.sensor, ucLogger,
+			usecase.WithHeartbeat(coalescerHeartbeat.Beat))
+		go func() {
+			defer close(coalescerDone)
+			defer coalescerHeartbeat.Stop()
+			useCases.Coalescer.Run(ctx, time.Duration(cfg.RateLimit.CoalesceWindow))
+		}()
+	} else {
+		close(coalescerDone)
+	}
+
+	r := httpGateway.NewServer(useCases,
+		httpGateway.WithHost(cfg.HTTP.Host),
+		httpGateway.WithPort(cfg.HTTP.Port),
+		httpGateway.WithReadHeaderTimeout(time.Duration(cfg.HTTP.ReadHeaderTimeout)),
+		httpGateway.WithIdleTimeout(time.Duration(cfg.HTTP.IdleTimeout)),
+		httpGateway.WithShutdownTimeout(time.Duration(cfg.HTTP.ShutdownTimeout)),
+		httpGateway.WithWebSocketOptions(
+			httpGateway.WithWriteTimeout(time.Duration(cfg.WebSocket.WriteTimeout)),
+			httpGateway.WithBatchPeriod(time.Duration(cfg.WebSocket.BatchPeriod)),
+		),
+		httpGateway.WithAdminToken(cfg.Admin.Token),
+		httpGateway.WithWebhookSecret(webhookSecret),
+		httpGateway.WithEffectiveConfig(cfg.Redacted()),
+		httpGateway.WithBackupMaxBytes(cfg.Admin.BackupMaxBytes),
+		httpGateway.WithBackup(backup.New(backup.Repositories{
+			Users:        repos.user,
+			SensorOwners: repos.sensorOwner,
+			Sensors:      repos.sensor,
+			Events:       repos.event,
+			Locations:    repos.location,
+		}, backup.WithLogger(logger))),
+		httpGateway.WithHealth(healthRegistry),
+		httpGateway.WithLogger(logger),
+		httpGateway.WithTrustedProxies(cfg.HTTP.TrustedProxies),
+		httpGateway.WithRateLimits(httpGateway.RateLimits{
+			IP:      newLimiter(cfg.RateLimit.PerIP, cfg.RateLimit.MaxKeys),
+			APIKey:  newLimiter(cfg.RateLimit.PerAPIKey, cfg.RateLimit.MaxKeys),
+			Sensor:  newLimiter(cfg.RateLimit.PerSensor, cfg.RateLimit.MaxKeys),
+			APIKeys: cfg.RateLimit.APIKeys,
+		}),
+	)
+
+	metrics.InitMetricsServer(int(cfg.Metrics.Port))
 
 	if err := r.Run(ctx, cancel); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Printf("error during server shutdown: %v", err)
+		logger.Error("error during server shutdown", "error", err)
+	}
+	// the running jobs, the rule actions and the coalesced events flush still use the storage,
+	// so they are waited for before the pool is closed
+	cancel()
+	<-automationDone
+	<-schedulerDone
+	<-coalescerDone
+}
+
+// newLimiter - nil if the limit is disabled
+func newLimiter(limit config.LimitConfig, maxKeys int) *ratelimit.Limiter {
+	if limit.Rate <= 0 {
+		return nil
 	}
+	return ratelimit.New(ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}, ratelimit.WithMaxKeys(maxKeys))
 }
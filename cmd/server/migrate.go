@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"homework/internal/config"
+	"homework/internal/migration"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const migrateUsage = `Usage: %s migrate <command> [config flags]
+
+Commands:
+  up             apply all the pending migrations
+  down [N|all]   roll back the last N migrations, 1 by default
+  status         print the schema version and the migrations with their state
+  version        print the schema version
+  force VERSION  set the version without migrating and clear the dirty flag after a failed
+                 migration was fixed by hand, -1 - no migration applied
+`
+
+var errMigrateUsage = errors.New("invalid usage")
+
+// runMigrate - the migrate subcommand, args follow "migrate"
+func runMigrate(name string, args []string, stdout, stderr io.Writer, lookupEnv func(string) (string, bool)) error {
+	usageErr := func() error {
+		fmt.Fprintf(stderr, migrateUsage, name)
+		return errMigrateUsage
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return usageErr()
+	}
+	// the command argument precedes the config flags, the version of force may be -1
+	command, args := args[0], args[1:]
+	var argument string
+	if len(args) > 0 && (!strings.HasPrefix(args[0], "-") || command == "force") {
+		argument, args = args[0], args[1:]
+	}
+	var action func(m *migration.Migrator) error
+	switch {
+	case command == "up" && argument == "":
+		action = (*migration.Migrator).Up
+	case command == "down":
+		steps := 1
+		switch argument {
+		case "":
+		case "all":
+			steps = 0
+		default:
+			var err error
+			if steps, err = strconv.Atoi(argument); err != nil || steps < 1 {
+				return usageErr()
+			}
+		}
+		action = func(m *migration.Migrator) error { return m.Down(steps) }
+	case command == "force" && argument != "":
+		version, err := strconv.Atoi(argument)
+		if err != nil || version < -1 {
+			return usageErr()
+		}
+		action = func(m *migration.Migrator) error { return m.Force(version) }
+	case (command == "status" || command == "version") && argument == "":
+		action = func(*migration.Migrator) error { return nil }
+	default:
+		return usageErr()
+	}
+
+	cfg, err := config.Load(name+" migrate "+command, args, lookupEnv)
+	if err != nil {
+		return err
+	}
+	if cfg.Storage.Backend != config.StorageBackendPostgres {
+		return fmt.Errorf("migrations are applied to the %q storage only", config.StorageBackendPostgres)
+	}
+	m, err := migration.New(cfg.Storage.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	if err := action(m); err != nil {
+		return err
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if command == "version" {
+		fmt.Fprintln(stdout, formatVersion(status))
+		return nil
+	}
+	printStatus(stdout, status)
+	return nil
+}
+
+func formatVersion(status migration.Status) string {
+	if status.Dirty {
+		return fmt.Sprintf("%d (dirty)", status.Version)
+	}
+	return strconv.FormatUint(uint64(status.Version), 10)
+}
+
+// printStatus - the version and every migration with its state
+func printStatus(w io.Writer, status migration.Status) {
+	fmt.Fprintf(w, "version %s, latest %d\n", formatVersion(status), status.Latest())
+	for _, m := range status.Migrations {
+		state := "applied"
+		switch {
+		case m.Version > status.Version:
+			state = "pending"
+		case m.Version == status.Version && status.Dirty:
+			state = "dirty"
+		}
+		fmt.Fprintf(w, "%06d %-40s %s\n", m.Version, m.Name, state)
+	}
+}
+
+// migrateOnStart - applies the pending migrations if enabled and checks the schema is not behind them
+func migrateOnStart(logger *slog.Logger, cfg config.StorageConfig) error {
+	m, err := migration.New(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if cfg.AutoMigrate {
+		if err := m.Up(); err != nil {
+			return err
+		}
+	}
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if err := status.Check(); errors.Is(err, migration.ErrSchemaBehind) {
+		return fmt.Errorf("%w, run \"%s migrate up\" or enable storage.auto_migrate", err, os.Args[0])
+	} else if err != nil {
+		return err
+	}
+	if status.Version > status.Latest() {
+		logger.Warn("database schema is ahead of the migrations", "version", status.Version, "latest", status.Latest())
+		return nil
+	}
+	logger.Info("database schema is up to date", "version", status.Version)
+	return nil
+}
@@ -0,0 +1,164 @@
+// simulator - virtual sensors pushing realistic traffic to the home controller and measuring
+// the latency from the ingestion to the delivery to the websocket subscribers
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"homework/internal/domain"
+	"homework/internal/simulator"
+	"homework/pkg/client"
+)
+
+const (
+	serverEnv     = "SIMULATOR_SERVER"
+	apiKeyEnv     = "SIMULATOR_API_KEY"
+	defaultServer = "http://localhost:8080"
+)
+
+// errUsage - the usage was already printed, so the error itself is not
+var errUsage = errors.New("invalid usage")
+
+const usage = `Usage: simulator [flags]
+
+Registers the virtual sensors of each type, pushes their readings over HTTP
+until the duration is over or interrupted and prints the latency percentiles
+from the ingestion to the delivery to the websocket subscribers.
+
+Flags:
+`
+
+// app - the command with its environment, the tests replace the streams and the client options
+type app struct {
+	stdout        io.Writer
+	stderr        io.Writer
+	lookupEnv     func(string) (string, bool)
+	clientOptions []func(*client.Client)
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	a := &app{stdout: os.Stdout, stderr: os.Stderr, lookupEnv: os.LookupEnv}
+	if err := a.run(ctx, os.Args[1:]); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "simulator:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run - parses the flags, runs the simulation and prints the report
+func (a *app) run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("simulator", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprint(a.stderr, usage)
+		fs.PrintDefaults()
+	}
+	server := fs.String("server", a.env(serverEnv, defaultServer), "base URL of the controller (env "+serverEnv+")")
+	apiKey := fs.String("api-key", a.env(apiKeyEnv, ""), "API key sent in X-API-Key (env "+apiKeyEnv+")")
+	sensors := fs.Int("sensors", simulator.DefaultSensorsPerType, "virtual sensors of each type")
+	types := fs.String("types", "", "comma separated sensor types, all the types by default")
+	rate := fs.Float64("rate", simulator.DefaultRate, "readings per second of each sensor")
+	duration := fs.Duration("duration", time.Minute, "how long to push, 0 - until interrupted")
+	subscribers := fs.Int("subscribers", simulator.DefaultSubscribers, "websocket subscribers, spread over the sensors")
+	dropout := fs.Float64("dropout", simulator.DefaultDropoutRate, "dropouts per second of each sensor, 0 - no dropouts")
+	dropoutDuration := fs.Duration("dropout-duration", simulator.DefaultDropoutDuration, "mean duration of a dropout")
+	serialBase := fs.Int64("serial-base", simulator.DefaultSerialBase, "serial number of the first sensor")
+	seed := fs.Uint64("seed", 0, "seed of the signals, 0 - random")
+	reportEvery := fs.Duration("report-every", simulator.DefaultReportPeriod, "progress period, 0 - no progress")
+	verbose := fs.Bool("v", false, "log the dropouts and the failed pushes")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+	if fs.NArg() > 0 || *sensors < 1 || *rate <= 0 || *subscribers < 0 || *dropout < 0 {
+		fmt.Fprintln(a.stderr, "unexpected arguments or invalid flag values")
+		fs.Usage()
+		return errUsage
+	}
+	sensorTypes, err := parseSensorTypes(*types)
+	if err != nil {
+		fmt.Fprintln(a.stderr, err)
+		fs.Usage()
+		return errUsage
+	}
+
+	options := append([]func(*client.Client){
+		client.WithHTTPClient(&http.Client{Timeout: client.DefaultTimeout}),
+		client.WithAPIKey(*apiKey),
+		client.WithUserAgent("simulator"),
+	}, a.clientOptions...)
+	c, err := client.New(*server, options...)
+	if err != nil {
+		return err
+	}
+
+	publisher := simulator.NewHTTPPublisher(c)
+	defer publisher.Close()
+
+	level := slog.LevelInfo
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	simOptions := []func(*simulator.Simulator){
+		simulator.WithSensorTypes(sensorTypes...),
+		simulator.WithSensorsPerType(*sensors),
+		simulator.WithRate(*rate),
+		simulator.WithDuration(*duration),
+		simulator.WithSubscribers(*subscribers),
+		simulator.WithDropouts(*dropout, *dropoutDuration),
+		simulator.WithSerialBase(*serialBase),
+		simulator.WithReportPeriod(*reportEvery),
+		simulator.WithLogger(slog.New(slog.NewTextHandler(a.stderr, &slog.HandlerOptions{Level: level}))),
+	}
+	if *seed != 0 {
+		simOptions = append(simOptions, simulator.WithSeed(*seed))
+	}
+	report, err := simulator.New(c, publisher, simOptions...).Run(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stdout, report)
+	return nil
+}
+
+func (a *app) env(name, fallback string) string {
+	if value, ok := a.lookupEnv(name); ok {
+		return value
+	}
+	return fallback
+}
+
+// parseSensorTypes - comma separated sensor types, all the types if empty
+func parseSensorTypes(value string) ([]domain.SensorType, error) {
+	var types []domain.SensorType
+	if value == "" {
+		for _, info := range domain.SensorTypes() {
+			types = append(types, info.Type)
+		}
+		return types, nil
+	}
+	for _, name := range strings.Split(value, ",") {
+		t := domain.SensorType(strings.TrimSpace(name))
+		if _, ok := domain.LookupSensorType(t); !ok {
+			return nil, fmt.Errorf("unknown sensor type %q", t)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"homework/internal/testserver"
+	"homework/pkg/client"
+)
+
+func TestSimulator(t *testing.T) {
+	srv := testserver.SetupTestServer()
+	defer srv.Close()
+	simulator := func(args ...string) (string, error) {
+		stdout := &bytes.Buffer{}
+		a := &app{
+			stdout: stdout,
+			stderr: &bytes.Buffer{},
+			lookupEnv: func(name string) (string, bool) {
+				if name == serverEnv {
+					return srv.URL, true
+				}
+				return "", false
+			},
+			clientOptions: []func(*client.Client){
+				client.WithHTTPClient(&http.Client{Transport: testserver.SpecTransport(t, nil), Timeout: 5 * time.Second}),
+			},
+		}
+		err := a.run(context.Background(), args)
+		return stdout.String(), err
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		out, err := simulator("-sensors", "1", "-types", "cc, temperature", "-rate", "20", "-duration", "500ms",
+			"-subscribers", "2", "-dropout", "0", "-seed", "1", "-report-every", "0")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out, "sensors=2 subscribers=2 duration="), out)
+		assert.Contains(t, out, "failed=0 dropouts=0")
+
+		sensors, err := srv.UseCases.Sensor.GetSensors(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, sensors, 2)
+	})
+
+	t.Run("fail, usage", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"extra"},
+			{"-sensors", "0"},
+			{"-rate", "0"},
+			{"-types", "cc,thermostat"},
+			{"-transport", "mqtt"},
+		} {
+			_, err := simulator(args...)
+			assert.ErrorIs(t, err, errUsage, args)
+		}
+
+		_, err := simulator("-server", "localhost")
+		assert.ErrorIs(t, err, client.ErrInvalidBaseURL)
+	})
+}
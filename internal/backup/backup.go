@@ -0,0 +1,58 @@
+// Package backup - export of the controller state (locations, users, sensors, owner bindings, location access and
+// the event history) to a versioned archive and its import into any storage implementing the repository interfaces
+package backup
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"log/slog"
+)
+
+var (
+	ErrInvalidArchive     = errors.New("invalid backup archive")
+	ErrUnsupportedVersion = errors.New("unsupported backup archive version")
+	ErrChecksumMismatch   = errors.New("backup archive checksum mismatch")
+)
+
+// Repositories - the storage the state is exported from or imported into
+type Repositories struct {
+	Users        usecase.UserRepository
+	SensorOwners usecase.SensorOwnerRepository
+	Sensors      usecase.SensorRepository
+	Events       usecase.EventRepository
+	Locations    usecase.LocationRepository
+}
+
+// UserRestorer - user repository able to keep the IDs of the restored users,
+// the users are saved with new IDs into the repositories lacking it
+type UserRestorer interface {
+	RestoreUser(ctx context.Context, user *domain.User) error
+}
+
+// SensorRestorer - sensor repository able to keep the IDs, registration time and version of the restored sensors,
+// the sensors are registered anew in the repositories lacking it
+type SensorRestorer interface {
+	RestoreSensor(ctx context.Context, sensor *domain.Sensor) error
+}
+
+// Archiver - exports the state of the repositories to an archive and imports an archive into them
+type Archiver struct {
+	repos  Repositories
+	logger *slog.Logger
+}
+
+func New(repos Repositories, options ...func(*Archiver)) *Archiver {
+	a := &Archiver{repos: repos, logger: slog.Default()}
+	for _, o := range options {
+		o(a)
+	}
+	return a
+}
+
+func WithLogger(logger *slog.Logger) func(*Archiver) {
+	return func(a *Archiver) {
+		a.logger = logger
+	}
+}
@@ -0,0 +1,378 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventInMemory "homework/internal/repository/event/inmemory"
+	locationInMemory "homework/internal/repository/location/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+	userInMemory "homework/internal/repository/user/inmemory"
+)
+
+func newRepositories() Repositories {
+	return Repositories{
+		Users:        userInMemory.NewUserRepository(),
+		SensorOwners: userInMemory.NewSensorOwnerRepository(),
+		Sensors:      sensorInMemory.NewSensorRepository(),
+		Events:       eventInMemory.NewEventRepository(),
+		Locations:    locationInMemory.NewLocationRepository(),
+	}
+}
+
+var start = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// populate - a home with a floor and a room, two users, two sensors and their events
+func populate(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	home := &domain.Location{Kind: domain.LocationKindHome, Name: "home"}
+	require.NoError(t, repos.Locations.SaveLocation(ctx, home))
+	floor := &domain.Location{ParentID: home.ID, Kind: domain.LocationKindFloor, Name: "1st"}
+	require.NoError(t, repos.Locations.SaveLocation(ctx, floor))
+	room := &domain.Location{ParentID: floor.ID, Kind: domain.LocationKindRoom, Name: "kitchen"}
+	require.NoError(t, repos.Locations.SaveLocation(ctx, room))
+
+	alice, bob := &domain.User{Name: "alice"}, &domain.User{Name: "bob"}
+	require.NoError(t, repos.Users.SaveUser(ctx, alice))
+	require.NoError(t, repos.Users.SaveUser(ctx, bob))
+
+	temperature := &domain.Sensor{
+		SerialNumber: "0000000001",
+		Type:         domain.SensorTypeTemperature,
+		CurrentState: 215,
+		Description:  "kitchen",
+		IsActive:     true,
+		LastActivity: start.Add(2 * time.Minute),
+		Calibration:  domain.Calibration{{Kind: domain.CalibrationOffset, Value: -5}},
+		LocationID:   room.ID,
+		Labels:       map[string]string{"kind": "climate"},
+		RegisteredAt: start.Add(-time.Hour),
+		Version:      3,
+	}
+	door := &domain.Sensor{
+		SerialNumber: "0000000002",
+		Type:         domain.SensorTypeContactClosure,
+		IsActive:     true,
+		RegisteredAt: start.Add(-time.Hour),
+		LastActivity: start,
+		Version:      1,
+	}
+	// restored rather than saved to get the registration time without monotonic clock
+	restorer := repos.Sensors.(SensorRestorer)
+	require.NoError(t, restorer.RestoreSensor(ctx, temperature))
+	require.NoError(t, restorer.RestoreSensor(ctx, door))
+
+	require.NoError(t, repos.SensorOwners.SaveSensorOwner(ctx, domain.SensorOwner{UserID: alice.ID, SensorID: temperature.ID}))
+	require.NoError(t, repos.SensorOwners.SaveSensorOwner(ctx, domain.SensorOwner{UserID: bob.ID, SensorID: door.ID}))
+	require.NoError(t, repos.Locations.SaveLocationAccess(ctx, domain.LocationAccess{UserID: bob.ID, LocationID: home.ID}))
+
+	for i, raw := range []int64{210, 215, 220} {
+		require.NoError(t, repos.Events.SaveEvent(ctx, &domain.Event{
+			Timestamp:          start.Add(time.Duration(i) * time.Minute),
+			SensorSerialNumber: temperature.SerialNumber,
+			SensorID:           temperature.ID,
+			Payload:            raw - 5,
+			RawPayload:         raw,
+			IdempotencyKey:     "key-" + string(rune('a'+i)),
+		}))
+	}
+	// the events having the same timestamp are all kept
+	for _, payload := range []int64{1, 0} {
+		require.NoError(t, repos.Events.SaveEvent(ctx, &domain.Event{
+			Timestamp:          start,
+			SensorSerialNumber: door.SerialNumber,
+			SensorID:           door.ID,
+			Payload:            payload,
+			RawPayload:         payload,
+		}))
+	}
+}
+
+func export(t *testing.T, repos Repositories) []byte {
+	var buf bytes.Buffer
+	_, err := New(repos).Export(context.Background(), &buf)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func history(t *testing.T, repos Repositories, sensorID int64) []*domain.Event {
+	events, err := repos.Events.GetEventsHistoryBySensorID(context.Background(), sensorID, time.Time{}, endOfTime)
+	require.NoError(t, err)
+	return events
+}
+
+func TestArchiver_Export(t *testing.T) {
+	repos := newRepositories()
+	populate(t, repos)
+
+	var buf bytes.Buffer
+	manifest, err := New(repos).Export(context.Background(), &buf)
+	require.NoError(t, err)
+	assert.Equal(t, Version, manifest.Version)
+	assert.Equal(t, Counts{Locations: 3, Users: 2, Sensors: 2, SensorOwners: 2, LocationAccess: 1, Events: 5}, manifest.Counts)
+
+	verified, err := Verify(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Counts, verified.Counts)
+	assert.Equal(t, manifest.SHA256, verified.SHA256)
+	assert.True(t, manifest.CreatedAt.Equal(verified.CreatedAt))
+}
+
+func TestArchiver_Import(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ok, restored into empty storage with the same IDs", func(t *testing.T) {
+		source := newRepositories()
+		populate(t, source)
+		archive := export(t, source)
+
+		target := newRepositories()
+		summary, err := New(target).Import(ctx, bytes.NewReader(archive))
+		require.NoError(t, err)
+		assert.Equal(t, summary.Manifest.Counts, summary.Imported)
+		assert.Equal(t, Counts{}, summary.Skipped)
+		assert.Equal(t, Counts{}, summary.Remapped)
+
+		users, err := target.Users.GetUsers(ctx)
+		require.NoError(t, err)
+		expectedUsers, err := source.Users.GetUsers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expectedUsers, users)
+
+		for _, sn := range []string{"0000000001", "0000000002"} {
+			expected, err := source.Sensors.GetSensorBySerialNumber(ctx, sn)
+			require.NoError(t, err)
+			actual, err := target.Sensors.GetSensorBySerialNumber(ctx, sn)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+
+			expectedEvents := history(t, source, expected.ID)
+			actualEvents := history(t, target, actual.ID)
+			require.Len(t, actualEvents, len(expectedEvents))
+			for i := range expectedEvents {
+				assert.Equal(t, *expectedEvents[i], *actualEvents[i])
+			}
+		}
+
+		owners, err := target.SensorOwners.GetSensorsByUserID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.SensorOwner{{UserID: 1, SensorID: 1}}, owners)
+		access, err := target.Locations.GetLocationAccessByUserID(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.LocationAccess{{UserID: 2, LocationID: 1}}, access)
+	})
+
+	t.Run("ok, taken IDs remapped", func(t *testing.T) {
+		source := newRepositories()
+		populate(t, source)
+		archive := export(t, source)
+
+		target := newRepositories()
+		carol := &domain.User{Name: "carol"}
+		require.NoError(t, target.Users.SaveUser(ctx, carol))
+		other := &domain.Sensor{SerialNumber: "0000000099", Type: domain.SensorTypeMotion}
+		require.NoError(t, target.Sensors.SaveSensor(ctx, other))
+		garage := &domain.Location{Kind: domain.LocationKindHome, Name: "garage"}
+		require.NoError(t, target.Locations.SaveLocation(ctx, garage))
+
+		summary, err := New(target).Import(ctx, bytes.NewReader(archive))
+		require.NoError(t, err)
+		assert.Equal(t, summary.Manifest.Counts, summary.Imported)
+		assert.Equal(t, Counts{Locations: 3, Users: 2, Sensors: 2}, summary.Remapped)
+
+		users, err := target.Users.GetUsers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.User{{ID: 1, Name: "carol"}, {ID: 2, Name: "alice"}, {ID: 3, Name: "bob"}}, users)
+
+		temperature, err := target.Sensors.GetSensorBySerialNumber(ctx, "0000000001")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), temperature.ID)
+		kitchen, err := target.Locations.GetLocationByID(ctx, temperature.LocationID)
+		require.NoError(t, err)
+		assert.Equal(t, "kitchen", kitchen.Name)
+
+		owners, err := target.SensorOwners.GetSensorsByUserID(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.SensorOwner{{UserID: 2, SensorID: 2}}, owners)
+		events := history(t, target, temperature.ID)
+		require.Len(t, events, 3)
+		assert.Equal(t, "0000000001", events[0].SensorSerialNumber)
+	})
+
+	t.Run("ok, imported twice", func(t *testing.T) {
+		source := newRepositories()
+		populate(t, source)
+		archive := export(t, source)
+
+		target := newRepositories()
+		_, err := New(target).Import(ctx, bytes.NewReader(archive))
+		require.NoError(t, err)
+		summary, err := New(target).Import(ctx, bytes.NewReader(archive))
+		require.NoError(t, err)
+		assert.Equal(t, Counts{}, summary.Imported)
+		assert.Equal(t, summary.Manifest.Counts, summary.Skipped)
+		assert.Len(t, history(t, target, 2), 2)
+	})
+
+	t.Run("ok, resumed after the last event", func(t *testing.T) {
+		source := newRepositories()
+		populate(t, source)
+		target := newRepositories()
+		_, err := New(target).Import(ctx, bytes.NewReader(export(t, source)))
+		require.NoError(t, err)
+
+		// the first one has the timestamp of the last imported event
+		for i, payload := range []int64{1, 2} {
+			require.NoError(t, source.Events.SaveEvent(ctx, &domain.Event{
+				Timestamp:          start.Add(time.Duration(2+i) * time.Minute),
+				SensorSerialNumber: "0000000001",
+				SensorID:           1,
+				Payload:            payload,
+			}))
+		}
+		summary, err := New(target).Import(ctx, bytes.NewReader(export(t, source)))
+		require.NoError(t, err)
+		assert.Equal(t, Counts{Events: 2}, summary.Imported)
+		assert.Equal(t, history(t, source, 1), history(t, target, 1))
+	})
+
+	t.Run("ok, storage unable to keep the IDs", func(t *testing.T) {
+		source := newRepositories()
+		populate(t, source)
+		archive := export(t, source)
+
+		target := newRepositories()
+		// hides RestoreUser and RestoreSensor
+		target.Users = struct{ usecase.UserRepository }{target.Users}
+		target.Sensors = struct{ usecase.SensorRepository }{target.Sensors}
+		require.NoError(t, target.Users.SaveUser(ctx, &domain.User{Name: "carol"}))
+
+		summary, err := New(target).Import(ctx, bytes.NewReader(archive))
+		require.NoError(t, err)
+		assert.Equal(t, summary.Manifest.Counts, summary.Imported)
+		assert.Equal(t, 2, summary.Remapped.Users)
+
+		owners, err := target.SensorOwners.GetSensorsByUserID(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, owners, 1)
+		sensor, err := target.Sensors.GetSensorByID(ctx, owners[0].SensorID)
+		require.NoError(t, err)
+		assert.Equal(t, "0000000001", sensor.SerialNumber)
+	})
+
+	t.Run("err, invalid archive, nothing written", func(t *testing.T) {
+		source := newRepositories()
+		populate(t, source)
+		archive := rewrite(t, export(t, source), func(line string) string {
+			return strings.Replace(line, `"name":"bob"`, `"name":"mallory"`, 1)
+		})
+
+		target := newRepositories()
+		_, err := New(target).Import(ctx, bytes.NewReader(archive))
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+		users, err := target.Users.GetUsers(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+}
+
+// rewrite - the archive with its lines replaced by modify
+func rewrite(t *testing.T, archive []byte, modify func(line string) string) []byte {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		if line := modify(scanner.Text()); line != "" {
+			_, err := io.WriteString(w, line+"\n")
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestVerify(t *testing.T) {
+	repos := newRepositories()
+	populate(t, repos)
+	archive := export(t, repos)
+
+	tests := []struct {
+		name   string
+		modify func(line string) string
+		err    error
+	}{
+		{
+			name:   "tampered record",
+			modify: func(line string) string { return strings.Replace(line, `"payload":215`, `"payload":100`, 1) },
+			err:    ErrChecksumMismatch,
+		},
+		{
+			name: "dropped record",
+			modify: func(line string) string {
+				if strings.Contains(line, `"type":"sensor_owner"`) && strings.Contains(line, `"user_id":2`) {
+					return ""
+				}
+				return line
+			},
+			err: ErrChecksumMismatch,
+		},
+		{
+			name: "truncated",
+			modify: func(line string) string {
+				if strings.Contains(line, `"type":"trailer"`) {
+					return ""
+				}
+				return line
+			},
+			err: ErrInvalidArchive,
+		},
+		{
+			name: "unsupported version",
+			modify: func(line string) string {
+				return strings.Replace(line, `"version":1,"created_at"`, `"version":2,"created_at"`, 1)
+			},
+			err: ErrUnsupportedVersion,
+		},
+		{
+			name:   "unknown reference",
+			modify: func(line string) string { return strings.Replace(line, `"location_id":3`, `"location_id":42`, 1) },
+			err:    ErrInvalidArchive,
+		},
+		{
+			name: "missing parent",
+			modify: func(line string) string {
+				if strings.Contains(line, `"type":"location"`) && strings.Contains(line, `"id":1,`) {
+					return ""
+				}
+				return line
+			},
+			err: ErrInvalidArchive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(bytes.NewReader(rewrite(t, archive, tt.modify)))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("err, not gzip", func(t *testing.T) {
+		_, err := Verify(strings.NewReader(`{"type":"header"}`))
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+}
@@ -0,0 +1,164 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"homework/internal/domain"
+	"io"
+	"sort"
+	"time"
+)
+
+// endOfTime - the end of the exported event history
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// Export - writes the state of the repositories to w as a compressed archive. The records are streamed, only
+// the event history of one sensor at a time is held in memory. w is not closed
+func (a *Archiver) Export(ctx context.Context, w io.Writer) (Manifest, error) {
+	aw, err := newWriter(w, time.Now().UTC())
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	locations, err := a.repos.Locations.GetLocations(ctx)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("can't get locations: %w", err)
+	}
+	// the references to the records missing in the archive are dropped, so that it stays valid
+	exported := make(map[int64]struct{}, len(locations))
+	for _, l := range parentsFirst(locations) {
+		exported[l.ID] = struct{}{}
+		err := aw.write(recordLocation, archiveLocation{ID: l.ID, ParentID: l.ParentID, Kind: l.Kind, Name: l.Name})
+		if err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	users, err := a.repos.Users.GetUsers(ctx)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("can't get users: %w", err)
+	}
+	for _, u := range users {
+		if err := aw.write(recordUser, archiveUser{ID: u.ID, Name: u.Name}); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	sensors, err := a.repos.Sensors.GetSensors(ctx)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("can't get sensors: %w", err)
+	}
+	sort.Slice(sensors, func(i, j int) bool { return sensors[i].ID < sensors[j].ID })
+	sensorIDs := make(map[int64]struct{}, len(sensors))
+	for _, s := range sensors {
+		sensorIDs[s.ID] = struct{}{}
+		if _, ok := exported[s.LocationID]; !ok {
+			s.LocationID = 0
+		}
+		if err := aw.write(recordSensor, toArchiveSensor(s)); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	for _, u := range users {
+		owners, err := a.repos.SensorOwners.GetSensorsByUserID(ctx, u.ID)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("can't get sensors of user %d: %w", u.ID, err)
+		}
+		for _, o := range owners {
+			if _, ok := sensorIDs[o.SensorID]; !ok {
+				continue
+			}
+			if err := aw.write(recordSensorOwner, archiveSensorOwner{UserID: o.UserID, SensorID: o.SensorID}); err != nil {
+				return Manifest{}, err
+			}
+		}
+	}
+	for _, u := range users {
+		access, err := a.repos.Locations.GetLocationAccessByUserID(ctx, u.ID)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("can't get location access of user %d: %w", u.ID, err)
+		}
+		for _, la := range access {
+			if _, ok := exported[la.LocationID]; !ok {
+				continue
+			}
+			err := aw.write(recordLocationAccess, archiveLocationAccess{UserID: la.UserID, LocationID: la.LocationID})
+			if err != nil {
+				return Manifest{}, err
+			}
+		}
+	}
+
+	for _, s := range sensors {
+		events, err := a.repos.Events.GetEventsHistoryBySensorID(ctx, s.ID, time.Time{}, endOfTime)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("can't get events of sensor %d: %w", s.ID, err)
+		}
+		sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+		for _, e := range events {
+			if err := aw.write(recordEvent, toArchiveEvent(s.ID, e)); err != nil {
+				return Manifest{}, err
+			}
+		}
+	}
+
+	manifest, err := aw.close()
+	if err != nil {
+		return Manifest{}, err
+	}
+	a.logger.Info("backup exported", "counts", manifest.Counts, "sha256", manifest.SHA256)
+	return manifest, nil
+}
+
+// parentsFirst - the locations ordered so that every parent precedes its children, the locations
+// with a missing parent are dropped as the import couldn't attach them
+func parentsFirst(locations []domain.Location) []domain.Location {
+	sort.Slice(locations, func(i, j int) bool { return locations[i].ID < locations[j].ID })
+	placed := make(map[int64]struct{}, len(locations))
+	res := make([]domain.Location, 0, len(locations))
+	for progress := true; progress; {
+		progress = false
+		for _, l := range locations {
+			_, done := placed[l.ID]
+			_, parentPlaced := placed[l.ParentID]
+			if !done && (l.ParentID == 0 || parentPlaced) {
+				placed[l.ID] = struct{}{}
+				res = append(res, l)
+				progress = true
+			}
+		}
+	}
+	return res
+}
+
+func toArchiveSensor(s domain.Sensor) archiveSensor {
+	return archiveSensor{
+		ID:            s.ID,
+		SerialNumber:  s.SerialNumber,
+		Type:          s.Type,
+		CurrentState:  s.CurrentState,
+		Description:   s.Description,
+		IsActive:      s.IsActive,
+		RegisteredAt:  s.RegisteredAt,
+		LastActivity:  s.LastActivity,
+		Channels:      s.Channels,
+		CurrentValues: s.CurrentValues,
+		Calibration:   s.Calibration,
+		LocationID:    s.LocationID,
+		Labels:        s.Labels,
+		Version:       s.Version,
+	}
+}
+
+func toArchiveEvent(sensorID int64, e *domain.Event) archiveEvent {
+	return archiveEvent{
+		SensorID:       sensorID,
+		Timestamp:      e.Timestamp,
+		Payload:        e.Payload,
+		Values:         e.Values,
+		RawPayload:     e.RawPayload,
+		RawValues:      e.RawValues,
+		IdempotencyKey: e.IdempotencyKey,
+	}
+}
@@ -0,0 +1,388 @@
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"homework/internal/domain"
+	"io"
+	"time"
+)
+
+// The archive is a gzip compressed stream of JSON lines {"type": ..., "data": ...}: the header, the records
+// in the order of recordOrder, so every record follows the ones it refers to, and the trailer with the number
+// of the records of each type and the SHA-256 of all the preceding lines
+const (
+	Format  = "home-controller-backup"
+	Version = 1
+)
+
+// ContentType - media type of the archive
+const ContentType = "application/gzip"
+
+const (
+	recordHeader         = "header"
+	recordLocation       = "location"
+	recordUser           = "user"
+	recordSensor         = "sensor"
+	recordSensorOwner    = "sensor_owner"
+	recordLocationAccess = "location_access"
+	recordEvent          = "event"
+	recordTrailer        = "trailer"
+)
+
+var recordOrder = map[string]int{
+	recordHeader:         0,
+	recordLocation:       1,
+	recordUser:           2,
+	recordSensor:         3,
+	recordSensorOwner:    4,
+	recordLocationAccess: 5,
+	recordEvent:          6,
+	recordTrailer:        7,
+}
+
+// Counts - number of the records of each type
+type Counts struct {
+	Locations      int `json:"locations"`
+	Users          int `json:"users"`
+	Sensors        int `json:"sensors"`
+	SensorOwners   int `json:"sensor_owners"`
+	LocationAccess int `json:"location_access"`
+	Events         int `json:"events"`
+}
+
+func (c *Counts) add(recordType string) {
+	switch recordType {
+	case recordLocation:
+		c.Locations++
+	case recordUser:
+		c.Users++
+	case recordSensor:
+		c.Sensors++
+	case recordSensorOwner:
+		c.SensorOwners++
+	case recordLocationAccess:
+		c.LocationAccess++
+	case recordEvent:
+		c.Events++
+	}
+}
+
+// Manifest - description of a written or verified archive
+type Manifest struct {
+	Version   int
+	CreatedAt time.Time
+	Counts    Counts
+	SHA256    string
+}
+
+type record struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type trailer struct {
+	Counts Counts `json:"counts"`
+	SHA256 string `json:"sha256"`
+}
+
+type archiveLocation struct {
+	ID       int64               `json:"id"`
+	ParentID int64               `json:"parent_id,omitempty"`
+	Kind     domain.LocationKind `json:"kind"`
+	Name     string              `json:"name"`
+}
+
+type archiveUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type archiveSensor struct {
+	ID            int64              `json:"id"`
+	SerialNumber  string             `json:"serial_number"`
+	Type          domain.SensorType  `json:"type"`
+	CurrentState  int64              `json:"current_state"`
+	Description   string             `json:"description,omitempty"`
+	IsActive      bool               `json:"is_active"`
+	RegisteredAt  time.Time          `json:"registered_at"`
+	LastActivity  time.Time          `json:"last_activity"`
+	Channels      []domain.Channel   `json:"channels,omitempty"`
+	CurrentValues map[string]int64   `json:"current_values,omitempty"`
+	Calibration   domain.Calibration `json:"calibration,omitempty"`
+	LocationID    int64              `json:"location_id,omitempty"`
+	Labels        map[string]string  `json:"labels,omitempty"`
+	Version       int64              `json:"version"`
+}
+
+type archiveSensorOwner struct {
+	UserID   int64 `json:"user_id"`
+	SensorID int64 `json:"sensor_id"`
+}
+
+type archiveLocationAccess struct {
+	UserID     int64 `json:"user_id"`
+	LocationID int64 `json:"location_id"`
+}
+
+type archiveEvent struct {
+	SensorID       int64            `json:"sensor_id"`
+	Timestamp      time.Time        `json:"timestamp"`
+	Payload        int64            `json:"payload"`
+	Values         map[string]int64 `json:"values,omitempty"`
+	RawPayload     int64            `json:"raw_payload"`
+	RawValues      map[string]int64 `json:"raw_values,omitempty"`
+	IdempotencyKey string           `json:"idempotency_key,omitempty"`
+}
+
+// writer - writes the records of an archive, counting them and hashing the lines
+type writer struct {
+	gz      *gzip.Writer
+	hash    hash.Hash
+	counts  Counts
+	created time.Time
+}
+
+func newWriter(w io.Writer, created time.Time) (*writer, error) {
+	aw := &writer{gz: gzip.NewWriter(w), hash: sha256.New(), created: created}
+	if err := aw.write(recordHeader, header{Format: Format, Version: Version, CreatedAt: created}); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+func (w *writer) write(recordType string, data any) error {
+	line, err := encode(recordType, data)
+	if err != nil {
+		return err
+	}
+	w.hash.Write(line)
+	if _, err := w.gz.Write(line); err != nil {
+		return fmt.Errorf("can't write backup archive: %w", err)
+	}
+	w.counts.add(recordType)
+	return nil
+}
+
+// close - writes the trailer and flushes the compressed stream, the underlying writer is not closed
+func (w *writer) close() (Manifest, error) {
+	sum := hex.EncodeToString(w.hash.Sum(nil))
+	line, err := encode(recordTrailer, trailer{Counts: w.counts, SHA256: sum})
+	if err != nil {
+		return Manifest{}, err
+	}
+	if _, err := w.gz.Write(line); err != nil {
+		return Manifest{}, fmt.Errorf("can't write backup archive: %w", err)
+	}
+	if err := w.gz.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("can't write backup archive: %w", err)
+	}
+	return Manifest{Version: Version, CreatedAt: w.created, Counts: w.counts, SHA256: sum}, nil
+}
+
+func encode(recordType string, data any) ([]byte, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode %s record: %w", recordType, err)
+	}
+	line, err := json.Marshal(record{Type: recordType, Data: raw})
+	if err != nil {
+		return nil, fmt.Errorf("can't encode %s record: %w", recordType, err)
+	}
+	return append(line, '\n'), nil
+}
+
+// walk - reads the archive checking its structure, references and checksum, and passes every record between
+// the header and the trailer to handle as *archiveLocation, *archiveUser and so on. The records are handled
+// while being read, so a handled record doesn't mean the archive is valid until walk returns no error
+func walk(r io.Reader, handle func(data any) error) (Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	v := newValidator()
+	br := bufio.NewReader(gz)
+	sum := sha256.New()
+	var manifest Manifest
+	order := -1
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) == 0 {
+				return Manifest{}, fmt.Errorf("%w: no trailer, the archive is truncated", ErrInvalidArchive)
+			}
+			return Manifest{}, fmt.Errorf("%w: line %d is not terminated", ErrInvalidArchive, n)
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return Manifest{}, fmt.Errorf("%w: line %d: %w", ErrInvalidArchive, n, err)
+		}
+		recOrder, known := recordOrder[rec.Type]
+		switch {
+		case !known:
+			return Manifest{}, fmt.Errorf("%w: line %d: unknown record type %q", ErrInvalidArchive, n, rec.Type)
+		case n == 1 && rec.Type != recordHeader:
+			return Manifest{}, fmt.Errorf("%w: no header", ErrInvalidArchive)
+		case recOrder < order || (n > 1 && rec.Type == recordHeader):
+			return Manifest{}, fmt.Errorf("%w: line %d: %s record out of order", ErrInvalidArchive, n, rec.Type)
+		}
+		order = recOrder
+
+		switch rec.Type {
+		case recordHeader:
+			var h header
+			if err := json.Unmarshal(rec.Data, &h); err != nil {
+				return Manifest{}, fmt.Errorf("%w: header: %w", ErrInvalidArchive, err)
+			}
+			if h.Format != Format {
+				return Manifest{}, fmt.Errorf("%w: unknown format %q", ErrInvalidArchive, h.Format)
+			}
+			if h.Version != Version {
+				return Manifest{}, fmt.Errorf("%w: %d, supported %d", ErrUnsupportedVersion, h.Version, Version)
+			}
+			manifest.Version, manifest.CreatedAt = h.Version, h.CreatedAt
+		case recordTrailer:
+			var t trailer
+			if err := json.Unmarshal(rec.Data, &t); err != nil {
+				return Manifest{}, fmt.Errorf("%w: trailer: %w", ErrInvalidArchive, err)
+			}
+			if rest, _ := br.Peek(1); len(rest) > 0 {
+				return Manifest{}, fmt.Errorf("%w: data after the trailer", ErrInvalidArchive)
+			}
+			if actual := hex.EncodeToString(sum.Sum(nil)); t.SHA256 != actual {
+				return Manifest{}, fmt.Errorf("%w: expected %s, actual %s", ErrChecksumMismatch, t.SHA256, actual)
+			}
+			if t.Counts != manifest.Counts {
+				return Manifest{}, fmt.Errorf("%w: expected %+v records, actual %+v", ErrChecksumMismatch, t.Counts, manifest.Counts)
+			}
+			manifest.SHA256 = t.SHA256
+			return manifest, nil
+		default:
+			data, err := v.decode(rec)
+			if err != nil {
+				return Manifest{}, fmt.Errorf("%w: line %d: %w", ErrInvalidArchive, n, err)
+			}
+			if err := handle(data); err != nil {
+				return Manifest{}, err
+			}
+			manifest.Counts.add(rec.Type)
+		}
+		sum.Write(line)
+	}
+}
+
+// validator - checks every record refers to the records preceding it
+type validator struct {
+	locations  map[int64]struct{}
+	users      map[int64]struct{}
+	sensors    map[int64]struct{}
+	serials    map[string]struct{}
+	lastEvents map[int64]time.Time
+}
+
+func newValidator() *validator {
+	return &validator{
+		locations:  make(map[int64]struct{}),
+		users:      make(map[int64]struct{}),
+		sensors:    make(map[int64]struct{}),
+		serials:    make(map[string]struct{}),
+		lastEvents: make(map[int64]time.Time),
+	}
+}
+
+func (v *validator) decode(rec record) (any, error) {
+	var data any
+	switch rec.Type {
+	case recordLocation:
+		data = &archiveLocation{}
+	case recordUser:
+		data = &archiveUser{}
+	case recordSensor:
+		data = &archiveSensor{}
+	case recordSensorOwner:
+		data = &archiveSensorOwner{}
+	case recordLocationAccess:
+		data = &archiveLocationAccess{}
+	case recordEvent:
+		data = &archiveEvent{}
+	}
+	if err := json.Unmarshal(rec.Data, data); err != nil {
+		return nil, fmt.Errorf("%s: %w", rec.Type, err)
+	}
+	if err := v.check(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", rec.Type, err)
+	}
+	return data, nil
+}
+
+func (v *validator) check(data any) error {
+	known := func(ids map[int64]struct{}, id int64) bool {
+		_, ok := ids[id]
+		return ok
+	}
+	switch d := data.(type) {
+	case *archiveLocation:
+		switch {
+		case d.ID <= 0 || known(v.locations, d.ID):
+			return fmt.Errorf("invalid or duplicate id %d", d.ID)
+		case d.ParentID != 0 && !known(v.locations, d.ParentID):
+			return fmt.Errorf("unknown parent location %d", d.ParentID)
+		case !d.Kind.IsValid():
+			return fmt.Errorf("unknown kind %q", d.Kind)
+		}
+		v.locations[d.ID] = struct{}{}
+	case *archiveUser:
+		if d.ID <= 0 || known(v.users, d.ID) {
+			return fmt.Errorf("invalid or duplicate id %d", d.ID)
+		}
+		v.users[d.ID] = struct{}{}
+	case *archiveSensor:
+		_, duplicate := v.serials[d.SerialNumber]
+		switch {
+		case d.ID <= 0 || known(v.sensors, d.ID):
+			return fmt.Errorf("invalid or duplicate id %d", d.ID)
+		case d.SerialNumber == "" || duplicate:
+			return fmt.Errorf("empty or duplicate serial number %q", d.SerialNumber)
+		case d.LocationID != 0 && !known(v.locations, d.LocationID):
+			return fmt.Errorf("unknown location %d", d.LocationID)
+		}
+		v.sensors[d.ID] = struct{}{}
+		v.serials[d.SerialNumber] = struct{}{}
+	case *archiveSensorOwner:
+		if !known(v.users, d.UserID) || !known(v.sensors, d.SensorID) {
+			return fmt.Errorf("unknown user %d or sensor %d", d.UserID, d.SensorID)
+		}
+	case *archiveLocationAccess:
+		if !known(v.users, d.UserID) || !known(v.locations, d.LocationID) {
+			return fmt.Errorf("unknown user %d or location %d", d.UserID, d.LocationID)
+		}
+	case *archiveEvent:
+		if !known(v.sensors, d.SensorID) {
+			return fmt.Errorf("unknown sensor %d", d.SensorID)
+		}
+		// the import resumes the history of a sensor after its last imported event
+		if d.Timestamp.Before(v.lastEvents[d.SensorID]) {
+			return fmt.Errorf("events of sensor %d are not in chronological order", d.SensorID)
+		}
+		v.lastEvents[d.SensorID] = d.Timestamp
+	}
+	return nil
+}
@@ -0,0 +1,366 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"io"
+	"time"
+)
+
+// Summary - result of an import: the records written, the records already present in the storage,
+// and the users, sensors and locations whose IDs differ from the ones in the archive
+type Summary struct {
+	Manifest Manifest
+	Imported Counts
+	Skipped  Counts
+	Remapped Counts
+}
+
+// Verify - reads the whole archive checking its format version, structure, references and checksum
+func Verify(r io.Reader) (Manifest, error) {
+	return walk(r, func(any) error { return nil })
+}
+
+// Import - verifies the archive and restores it into the repositories, nothing is written if the archive is invalid.
+//
+// The IDs of the users and sensors are kept where the storage supports it and the ID is free, the locations
+// get new IDs, and the references are remapped accordingly. The records already present are skipped:
+// the locations matching by parent, kind and name, the users by ID and name or by name, the sensors
+// by serial number, and the events not later than the last event of the sensor in the storage.
+// So an interrupted import resumes when repeated, and an archive imported twice changes nothing
+func (a *Archiver) Import(ctx context.Context, r io.ReadSeeker) (Summary, error) {
+	if _, err := Verify(r); err != nil {
+		return Summary{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return Summary{}, fmt.Errorf("can't rewind backup archive: %w", err)
+	}
+
+	im, err := a.newImporter(ctx)
+	if err != nil {
+		return Summary{}, err
+	}
+	manifest, err := walk(r, func(data any) error { return im.restore(ctx, data) })
+	if err != nil {
+		return Summary{}, err
+	}
+	im.summary.Manifest = manifest
+	a.logger.Info("backup imported",
+		"imported", im.summary.Imported, "skipped", im.summary.Skipped, "remapped", im.summary.Remapped)
+	return im.summary, nil
+}
+
+type locationKey struct {
+	parentID int64
+	kind     domain.LocationKind
+	name     string
+}
+
+// eventKey - identifies the events having the same timestamp
+type eventKey struct {
+	payload    int64
+	rawPayload int64
+}
+
+// importer - state of an import: the IDs in the storage by the IDs in the archive and the records present
+type importer struct {
+	repos   Repositories
+	summary Summary
+
+	locations map[int64]int64
+	users     map[int64]int64
+	sensors   map[int64]int64
+	serials   map[int64]string
+
+	// the records present in the storage not matched yet
+	freeLocations map[locationKey][]int64
+	freeUsers     map[string][]int64
+
+	// lastEvents - timestamp of the last event of the sensors present in the storage, lastEventKeys - the events
+	// having that timestamp, which are not imported again
+	lastEvents    map[int64]time.Time
+	lastEventKeys map[int64]map[eventKey]int
+
+	owners map[int64]map[int64]struct{}
+	access map[int64]map[int64]struct{}
+}
+
+func (a *Archiver) newImporter(ctx context.Context) (*importer, error) {
+	im := &importer{
+		repos:         a.repos,
+		locations:     make(map[int64]int64),
+		users:         make(map[int64]int64),
+		sensors:       make(map[int64]int64),
+		serials:       make(map[int64]string),
+		freeLocations: make(map[locationKey][]int64),
+		freeUsers:     make(map[string][]int64),
+		lastEvents:    make(map[int64]time.Time),
+		lastEventKeys: make(map[int64]map[eventKey]int),
+		owners:        make(map[int64]map[int64]struct{}),
+		access:        make(map[int64]map[int64]struct{}),
+	}
+
+	locations, err := a.repos.Locations.GetLocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get locations: %w", err)
+	}
+	for _, l := range locations {
+		key := locationKey{parentID: l.ParentID, kind: l.Kind, name: l.Name}
+		im.freeLocations[key] = append(im.freeLocations[key], l.ID)
+	}
+	users, err := a.repos.Users.GetUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get users: %w", err)
+	}
+	for _, u := range users {
+		im.freeUsers[u.Name] = append(im.freeUsers[u.Name], u.ID)
+	}
+	return im, nil
+}
+
+func (im *importer) restore(ctx context.Context, data any) error {
+	switch d := data.(type) {
+	case *archiveLocation:
+		return im.restoreLocation(ctx, d)
+	case *archiveUser:
+		return im.restoreUser(ctx, d)
+	case *archiveSensor:
+		return im.restoreSensor(ctx, d)
+	case *archiveSensorOwner:
+		return im.restoreSensorOwner(ctx, d)
+	case *archiveLocationAccess:
+		return im.restoreLocationAccess(ctx, d)
+	case *archiveEvent:
+		return im.restoreEvent(ctx, d)
+	}
+	return nil
+}
+
+func (im *importer) restoreLocation(ctx context.Context, l *archiveLocation) error {
+	location := domain.Location{ParentID: im.locations[l.ParentID], Kind: l.Kind, Name: l.Name}
+	key := locationKey{parentID: location.ParentID, kind: location.Kind, name: location.Name}
+	if id, ok := claim(im.freeLocations, key, l.ID); ok {
+		im.locations[l.ID] = id
+		im.summary.Skipped.Locations++
+	} else {
+		if err := im.repos.Locations.SaveLocation(ctx, &location); err != nil {
+			return fmt.Errorf("can't restore location %d: %w", l.ID, err)
+		}
+		im.locations[l.ID] = location.ID
+		im.summary.Imported.Locations++
+	}
+	if im.locations[l.ID] != l.ID {
+		im.summary.Remapped.Locations++
+	}
+	return nil
+}
+
+func (im *importer) restoreUser(ctx context.Context, u *archiveUser) error {
+	if id, ok := claim(im.freeUsers, u.Name, u.ID); ok {
+		im.users[u.ID] = id
+		im.summary.Skipped.Users++
+	} else {
+		user := domain.User{ID: u.ID, Name: u.Name}
+		var err error
+		if restorer, ok := im.repos.Users.(UserRestorer); ok {
+			err = restorer.RestoreUser(ctx, &user)
+		} else {
+			user.ID = 0
+			err = im.repos.Users.SaveUser(ctx, &user)
+		}
+		if err != nil {
+			return fmt.Errorf("can't restore user %d: %w", u.ID, err)
+		}
+		im.users[u.ID] = user.ID
+		im.summary.Imported.Users++
+	}
+	if im.users[u.ID] != u.ID {
+		im.summary.Remapped.Users++
+	}
+	return nil
+}
+
+func (im *importer) restoreSensor(ctx context.Context, s *archiveSensor) error {
+	existing, err := im.repos.Sensors.GetSensorBySerialNumber(ctx, s.SerialNumber)
+	switch {
+	case err == nil:
+		im.sensors[s.ID] = existing.ID
+		im.summary.Skipped.Sensors++
+		if err := im.loadLastEvents(ctx, existing.ID); err != nil {
+			return err
+		}
+	case errors.Is(err, usecase.ErrSensorNotFound):
+		sensor := fromArchiveSensor(s)
+		sensor.LocationID = im.locations[s.LocationID]
+		if restorer, ok := im.repos.Sensors.(SensorRestorer); ok {
+			err = restorer.RestoreSensor(ctx, &sensor)
+		} else {
+			sensor.ID, sensor.RegisteredAt, sensor.Version = 0, time.Time{}, 0
+			err = im.repos.Sensors.SaveSensor(ctx, &sensor)
+		}
+		if err != nil {
+			return fmt.Errorf("can't restore sensor %s: %w", s.SerialNumber, err)
+		}
+		im.sensors[s.ID] = sensor.ID
+		im.summary.Imported.Sensors++
+	default:
+		return fmt.Errorf("can't get sensor %s: %w", s.SerialNumber, err)
+	}
+	im.serials[im.sensors[s.ID]] = s.SerialNumber
+	if im.sensors[s.ID] != s.ID {
+		im.summary.Remapped.Sensors++
+	}
+	return nil
+}
+
+// loadLastEvents - remembers the last events of the sensor present in the storage
+func (im *importer) loadLastEvents(ctx context.Context, sensorID int64) error {
+	lastEvents, err := im.repos.Events.GetLastEventsBySensorID(ctx, sensorID, 1)
+	if err != nil {
+		return fmt.Errorf("can't get last event of sensor %d: %w", sensorID, err)
+	}
+	if len(lastEvents) == 0 {
+		return nil
+	}
+	last := lastEvents[0]
+	events, err := im.repos.Events.GetEventsHistoryBySensorID(ctx, sensorID, last.Timestamp, last.Timestamp)
+	if err != nil {
+		return fmt.Errorf("can't get last events of sensor %d: %w", sensorID, err)
+	}
+	keys := make(map[eventKey]int, len(events))
+	for _, e := range events {
+		keys[eventKey{payload: e.Payload, rawPayload: e.RawPayload}]++
+	}
+	im.lastEvents[sensorID] = last.Timestamp
+	im.lastEventKeys[sensorID] = keys
+	return nil
+}
+
+func (im *importer) restoreSensorOwner(ctx context.Context, o *archiveSensorOwner) error {
+	owner := domain.SensorOwner{UserID: im.users[o.UserID], SensorID: im.sensors[o.SensorID]}
+	owned, ok := im.owners[owner.UserID]
+	if !ok {
+		bindings, err := im.repos.SensorOwners.GetSensorsByUserID(ctx, owner.UserID)
+		if err != nil {
+			return fmt.Errorf("can't get sensors of user %d: %w", owner.UserID, err)
+		}
+		owned = make(map[int64]struct{}, len(bindings))
+		for _, b := range bindings {
+			owned[b.SensorID] = struct{}{}
+		}
+		im.owners[owner.UserID] = owned
+	}
+	if _, exists := owned[owner.SensorID]; exists {
+		im.summary.Skipped.SensorOwners++
+		return nil
+	}
+	if err := im.repos.SensorOwners.SaveSensorOwner(ctx, owner); err != nil {
+		return fmt.Errorf("can't restore sensor %d owner %d: %w", owner.SensorID, owner.UserID, err)
+	}
+	owned[owner.SensorID] = struct{}{}
+	im.summary.Imported.SensorOwners++
+	return nil
+}
+
+func (im *importer) restoreLocationAccess(ctx context.Context, la *archiveLocationAccess) error {
+	access := domain.LocationAccess{UserID: im.users[la.UserID], LocationID: im.locations[la.LocationID]}
+	granted, ok := im.access[access.UserID]
+	if !ok {
+		existing, err := im.repos.Locations.GetLocationAccessByUserID(ctx, access.UserID)
+		if err != nil {
+			return fmt.Errorf("can't get location access of user %d: %w", access.UserID, err)
+		}
+		granted = make(map[int64]struct{}, len(existing))
+		for _, e := range existing {
+			granted[e.LocationID] = struct{}{}
+		}
+		im.access[access.UserID] = granted
+	}
+	if _, exists := granted[access.LocationID]; exists {
+		im.summary.Skipped.LocationAccess++
+		return nil
+	}
+	if err := im.repos.Locations.SaveLocationAccess(ctx, access); err != nil {
+		return fmt.Errorf("can't restore location %d access of user %d: %w", access.LocationID, access.UserID, err)
+	}
+	granted[access.LocationID] = struct{}{}
+	im.summary.Imported.LocationAccess++
+	return nil
+}
+
+func (im *importer) restoreEvent(ctx context.Context, e *archiveEvent) error {
+	sensorID := im.sensors[e.SensorID]
+	if last, ok := im.lastEvents[sensorID]; ok {
+		key := eventKey{payload: e.Payload, rawPayload: e.RawPayload}
+		switch {
+		case e.Timestamp.Before(last):
+			im.summary.Skipped.Events++
+			return nil
+		case e.Timestamp.Equal(last) && im.lastEventKeys[sensorID][key] > 0:
+			im.lastEventKeys[sensorID][key]--
+			im.summary.Skipped.Events++
+			return nil
+		}
+	}
+
+	event := domain.Event{
+		Timestamp:          e.Timestamp,
+		SensorSerialNumber: im.serials[sensorID],
+		SensorID:           sensorID,
+		Payload:            e.Payload,
+		Values:             e.Values,
+		RawPayload:         e.RawPayload,
+		RawValues:          e.RawValues,
+		IdempotencyKey:     e.IdempotencyKey,
+	}
+	err := im.repos.Events.SaveEvent(ctx, &event)
+	if errors.Is(err, usecase.ErrDuplicateEvent) {
+		im.summary.Skipped.Events++
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't restore event of sensor %d: %w", sensorID, err)
+	}
+	im.summary.Imported.Events++
+	return nil
+}
+
+// claim - takes the record present in the storage matching the key, the one with the preferred ID if any
+func claim[K comparable](free map[K][]int64, key K, preferred int64) (int64, bool) {
+	ids := free[key]
+	if len(ids) == 0 {
+		return 0, false
+	}
+	i := 0
+	for j, id := range ids {
+		if id == preferred {
+			i = j
+			break
+		}
+	}
+	id := ids[i]
+	free[key] = append(ids[:i:i], ids[i+1:]...)
+	return id, true
+}
+
+func fromArchiveSensor(s *archiveSensor) domain.Sensor {
+	return domain.Sensor{
+		ID:            s.ID,
+		SerialNumber:  s.SerialNumber,
+		Type:          s.Type,
+		CurrentState:  s.CurrentState,
+		Description:   s.Description,
+		IsActive:      s.IsActive,
+		RegisteredAt:  s.RegisteredAt,
+		LastActivity:  s.LastActivity,
+		Channels:      s.Channels,
+		CurrentValues: s.CurrentValues,
+		Calibration:   s.Calibration,
+		LocationID:    s.LocationID,
+		Labels:        s.Labels,
+		Version:       s.Version,
+	}
+}
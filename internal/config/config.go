@@ -0,0 +1,478 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type StorageBackend = string
+
+const ( // Supported storage backends
+	StorageBackendPostgres StorageBackend = "postgres"
+	StorageBackendInMemory StorageBackend = "inmemory"
+)
+
+type TraceExporter = string
+
+const ( // Supported span exporters
+	TraceExporterNone   TraceExporter = "none"
+	TraceExporterStdout TraceExporter = "stdout"
+	TraceExporterOTLP   TraceExporter = "otlp"
+)
+
+type LogFormat = string
+
+const ( // Supported log output formats
+	LogFormatText LogFormat = "text"
+	LogFormatJSON LogFormat = "json"
+)
+
+const redactedValue = "xxxxx"
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Duration - time.Duration that is (un)marshalled in the human-readable form ("5s", "500ms")
+// by both the YAML and TOML decoders as well as by the JSON encoder
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Config - effective configuration of the home controller
+type Config struct {
+	HTTP       HTTPConfig       `yaml:"http" toml:"http" json:"http"`
+	Metrics    MetricsConfig    `yaml:"metrics" toml:"metrics" json:"metrics"`
+	Storage    StorageConfig    `yaml:"storage" toml:"storage" json:"storage"`
+	WebSocket  WebSocketConfig  `yaml:"websocket" toml:"websocket" json:"websocket"`
+	Admin      AdminConfig      `yaml:"admin" toml:"admin" json:"admin"`
+	Health     HealthConfig     `yaml:"health" toml:"health" json:"health"`
+	Tracing    TracingConfig    `yaml:"tracing" toml:"tracing" json:"tracing"`
+	Log        LogConfig        `yaml:"log" toml:"log" json:"log"`
+	Automation AutomationConfig `yaml:"automation" toml:"automation" json:"automation"`
+	Scheduler  SchedulerConfig  `yaml:"scheduler" toml:"scheduler" json:"scheduler"`
+	Events     EventsConfig     `yaml:"events" toml:"events" json:"events"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit" toml:"rate_limit" json:"rate_limit"`
+}
+
+type HTTPConfig struct {
+	Host              string   `yaml:"host" toml:"host" json:"host"`
+	Port              uint16   `yaml:"port" toml:"port" json:"port"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout" json:"read_header_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout" json:"idle_timeout"`
+	ShutdownTimeout   Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" json:"shutdown_timeout"`
+	// TrustedProxies - IPs and CIDRs of the reverse proxies whose X-Forwarded-For is trusted for the client IP,
+	// empty means the client IP is the peer address
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" json:"trusted_proxies"`
+}
+
+type MetricsConfig struct {
+	Port uint16 `yaml:"port" toml:"port" json:"port"`
+}
+
+type StorageConfig struct {
+	Backend        StorageBackend `yaml:"backend" toml:"backend" json:"backend"`
+	DatabaseURL    string         `yaml:"database_url" toml:"database_url" json:"database_url"`
+	MaxConns       int32          `yaml:"max_conns" toml:"max_conns" json:"max_conns"`
+	MinConns       int32          `yaml:"min_conns" toml:"min_conns" json:"min_conns"`
+	ConnectTimeout Duration       `yaml:"connect_timeout" toml:"connect_timeout" json:"connect_timeout"`
+	// AutoMigrate - apply the pending schema migrations on start, the service refuses to start on a schema behind them otherwise
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate" json:"auto_migrate"`
+}
+
+type WebSocketConfig struct {
+	WriteTimeout Duration `yaml:"write_timeout" toml:"write_timeout" json:"write_timeout"`
+	BatchPeriod  Duration `yaml:"batch_period" toml:"batch_period" json:"batch_period"`
+}
+
+type AdminConfig struct {
+	// Token - bearer token required by the /api/admin endpoints, they are not served if empty
+	Token string `yaml:"token" toml:"token" json:"token"`
+	// BackupMaxBytes - size limit of the archive uploaded to POST /api/admin/backup
+	BackupMaxBytes int64 `yaml:"backup_max_bytes" toml:"backup_max_bytes" json:"backup_max_bytes"`
+}
+
+type HealthConfig struct {
+	// CheckTimeout - time every single dependency check of /healthz and /readyz is given
+	CheckTimeout Duration `yaml:"check_timeout" toml:"check_timeout" json:"check_timeout"`
+}
+
+type TracingConfig struct {
+	Exporter    TraceExporter `yaml:"exporter" toml:"exporter" json:"exporter"`
+	ServiceName string        `yaml:"service_name" toml:"service_name" json:"service_name"`
+	// OTLPEndpoint - host:port of the OTLP/HTTP collector, OTEL_EXPORTER_OTLP_* variables are used if empty
+	OTLPEndpoint string `yaml:"otlp_endpoint" toml:"otlp_endpoint" json:"otlp_endpoint"`
+	OTLPInsecure bool   `yaml:"otlp_insecure" toml:"otlp_insecure" json:"otlp_insecure"`
+	// SampleRatio - share of the root traces that are recorded, the remote parent decision is respected otherwise
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" json:"sample_ratio"`
+}
+
+type LogConfig struct {
+	// Level - minimal level of the records written: debug, info, warn or error
+	Level  string    `yaml:"level" toml:"level" json:"level"`
+	Format LogFormat `yaml:"format" toml:"format" json:"format"`
+}
+
+type AutomationConfig struct {
+	// TickPeriod - how often the schedule and offline triggers are evaluated
+	TickPeriod Duration `yaml:"tick_period" toml:"tick_period" json:"tick_period"`
+	// WebhookTimeout - limit of a single webhook action request
+	WebhookTimeout Duration `yaml:"webhook_timeout" toml:"webhook_timeout" json:"webhook_timeout"`
+	// WebhookSecret - key of the webhook signatures, the rule chain depth is trusted only from the requests carrying
+	// the signature. Random for every process if empty, so the replicas must share a configured one
+	WebhookSecret string `yaml:"webhook_secret" toml:"webhook_secret" json:"webhook_secret"`
+}
+
+type EventsConfig struct {
+	// DedupWindow - how long an idempotency key of an accepted event is kept
+	DedupWindow Duration `yaml:"dedup_window" toml:"dedup_window" json:"dedup_window"`
+	// Provisioning - keep the events of the unknown sensors in the pending list until the sensors are claimed
+	// instead of rejecting them
+	Provisioning bool `yaml:"provisioning" toml:"provisioning" json:"provisioning"`
+	// MaxPendingSensors - size of the pending list, the events of the other unknown sensors are rejected
+	MaxPendingSensors int `yaml:"max_pending_sensors" toml:"max_pending_sensors" json:"max_pending_sensors"`
+	// PendingEvents - how many first events of a pending sensor are buffered to be replayed once it is claimed
+	PendingEvents int `yaml:"pending_events" toml:"pending_events" json:"pending_events"`
+}
+
+// LimitConfig - token bucket of Rate requests per second refilled up to Burst, zero rate disables the limit
+type LimitConfig struct {
+	Rate  float64 `yaml:"rate" toml:"rate" json:"rate"`
+	Burst int     `yaml:"burst" toml:"burst" json:"burst"`
+}
+
+type RateLimitConfig struct {
+	PerIP     LimitConfig `yaml:"per_ip" toml:"per_ip" json:"per_ip"`
+	PerAPIKey LimitConfig `yaml:"per_api_key" toml:"per_api_key" json:"per_api_key"`
+	PerSensor LimitConfig `yaml:"per_sensor" toml:"per_sensor" json:"per_sensor"`
+	// APIKeys - the known API keys, only their X-API-Key gets a bucket of PerAPIKey, the requests with other keys
+	// are limited by PerIP only, so the random keys don't take the memory of the limiter
+	APIKeys []string `yaml:"api_keys" toml:"api_keys" json:"api_keys"`
+	// MaxKeys - how many client IPs, API keys or sensors each limit tracks at most, the least recently seen is dropped
+	MaxKeys int `yaml:"max_keys" toml:"max_keys" json:"max_keys"`
+	// CoalesceContactClosure - keep the latest contact closure event over the sensor limit instead of rejecting it
+	CoalesceContactClosure bool `yaml:"coalesce_contact_closure" toml:"coalesce_contact_closure" json:"coalesce_contact_closure"`
+	// CoalesceWindow - how often the coalesced events are ingested
+	CoalesceWindow Duration `yaml:"coalesce_window" toml:"coalesce_window" json:"coalesce_window"`
+}
+
+type SchedulerConfig struct {
+	// TickPeriod - how often the job schedules are checked, the jobs fire not more often than once a minute anyway
+	TickPeriod Duration `yaml:"tick_period" toml:"tick_period" json:"tick_period"`
+}
+
+// SlogLevel - parsed Level, must be called on a validated config only
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.Level))
+	return level
+}
+
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Host:              "localhost",
+			Port:              8080,
+			ReadHeaderTimeout: Duration(10 * time.Second),
+			IdleTimeout:       Duration(2 * time.Minute),
+			ShutdownTimeout:   Duration(10 * time.Second),
+		},
+		Metrics: MetricsConfig{
+			Port: 8008,
+		},
+		Storage: StorageConfig{
+			Backend:        StorageBackendPostgres,
+			MaxConns:       10,
+			MinConns:       0,
+			ConnectTimeout: Duration(5 * time.Second),
+		},
+		WebSocket: WebSocketConfig{
+			WriteTimeout: Duration(5 * time.Second),
+			BatchPeriod:  Duration(500 * time.Millisecond),
+		},
+		Health: HealthConfig{
+			CheckTimeout: Duration(2 * time.Second),
+		},
+		Tracing: TracingConfig{
+			Exporter:    TraceExporterNone,
+			ServiceName: "home-controller",
+			SampleRatio: 1,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: LogFormatText,
+		},
+		Admin: AdminConfig{
+			BackupMaxBytes: 1 << 30,
+		},
+		Automation: AutomationConfig{
+			TickPeriod:     Duration(time.Second),
+			WebhookTimeout: Duration(5 * time.Second),
+		},
+		Scheduler: SchedulerConfig{
+			TickPeriod: Duration(time.Second),
+		},
+		Events: EventsConfig{
+			DedupWindow:       Duration(24 * time.Hour),
+			MaxPendingSensors: 1000,
+			PendingEvents:     100,
+		},
+		RateLimit: RateLimitConfig{
+			PerIP:          LimitConfig{Burst: 100},
+			PerAPIKey:      LimitConfig{Burst: 100},
+			PerSensor:      LimitConfig{Burst: 10},
+			MaxKeys:        10000,
+			CoalesceWindow: Duration(time.Second),
+		},
+	}
+}
+
+// Validate - checks the whole config and reports every invalid field at once
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s: %s", ErrInvalidConfig, field, fmt.Sprintf(format, args...)))
+	}
+
+	if c.HTTP.Port == 0 {
+		fail("http.port", "must be in range 1..65535")
+	}
+	if c.HTTP.ReadHeaderTimeout <= 0 {
+		fail("http.read_header_timeout", "must be positive, got %v", time.Duration(c.HTTP.ReadHeaderTimeout))
+	}
+	if c.HTTP.IdleTimeout <= 0 {
+		fail("http.idle_timeout", "must be positive, got %v", time.Duration(c.HTTP.IdleTimeout))
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		fail("http.shutdown_timeout", "must be positive, got %v", time.Duration(c.HTTP.ShutdownTimeout))
+	}
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			fail("http.trusted_proxies", "%q is neither an IP nor a CIDR", proxy)
+		}
+	}
+
+	if c.Metrics.Port == 0 {
+		fail("metrics.port", "must be in range 1..65535")
+	} else if c.Metrics.Port == c.HTTP.Port {
+		fail("metrics.port", "must differ from http.port (%d)", c.HTTP.Port)
+	}
+
+	switch c.Storage.Backend {
+	case StorageBackendPostgres:
+		if c.Storage.DatabaseURL == "" {
+			fail("storage.database_url", "is required for the %q backend", StorageBackendPostgres)
+		}
+		if c.Storage.MaxConns < 1 {
+			fail("storage.max_conns", "must be at least 1, got %d", c.Storage.MaxConns)
+		}
+		if c.Storage.MinConns < 0 || c.Storage.MinConns > c.Storage.MaxConns {
+			fail("storage.min_conns", "must be in range 0..max_conns (%d), got %d", c.Storage.MaxConns, c.Storage.MinConns)
+		}
+		if c.Storage.ConnectTimeout <= 0 {
+			fail("storage.connect_timeout", "must be positive, got %v", time.Duration(c.Storage.ConnectTimeout))
+		}
+	case StorageBackendInMemory:
+	default:
+		fail("storage.backend", "must be one of %q, %q, got %q",
+			StorageBackendPostgres, StorageBackendInMemory, c.Storage.Backend)
+	}
+
+	if c.WebSocket.WriteTimeout <= 0 {
+		fail("websocket.write_timeout", "must be positive, got %v", time.Duration(c.WebSocket.WriteTimeout))
+	}
+	if c.WebSocket.BatchPeriod <= 0 {
+		fail("websocket.batch_period", "must be positive, got %v", time.Duration(c.WebSocket.BatchPeriod))
+	}
+
+	if c.Health.CheckTimeout <= 0 {
+		fail("health.check_timeout", "must be positive, got %v", time.Duration(c.Health.CheckTimeout))
+	}
+
+	switch c.Tracing.Exporter {
+	case TraceExporterNone:
+	case TraceExporterStdout, TraceExporterOTLP:
+		if c.Tracing.ServiceName == "" {
+			fail("tracing.service_name", "is required when tracing is enabled")
+		}
+	default:
+		fail("tracing.exporter", "must be one of %q, %q, %q, got %q",
+			TraceExporterNone, TraceExporterStdout, TraceExporterOTLP, c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio", "must be in range 0..1, got %v", c.Tracing.SampleRatio)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		fail("log.level", "must be one of debug, info, warn, error, got %q", c.Log.Level)
+	}
+	if c.Log.Format != LogFormatText && c.Log.Format != LogFormatJSON {
+		fail("log.format", "must be one of %q, %q, got %q", LogFormatText, LogFormatJSON, c.Log.Format)
+	}
+	if c.Admin.BackupMaxBytes <= 0 {
+		fail("admin.backup_max_bytes", "must be positive, got %d", c.Admin.BackupMaxBytes)
+	}
+	if c.Automation.TickPeriod <= 0 {
+		fail("automation.tick_period", "must be positive, got %v", time.Duration(c.Automation.TickPeriod))
+	}
+	if c.Automation.WebhookTimeout <= 0 {
+		fail("automation.webhook_timeout", "must be positive, got %v", time.Duration(c.Automation.WebhookTimeout))
+	}
+	if c.Scheduler.TickPeriod <= 0 {
+		fail("scheduler.tick_period", "must be positive, got %v", time.Duration(c.Scheduler.TickPeriod))
+	}
+	if c.Events.DedupWindow <= 0 {
+		fail("events.dedup_window", "must be positive, got %v", time.Duration(c.Events.DedupWindow))
+	}
+	if c.Events.Provisioning {
+		if c.Events.MaxPendingSensors < 1 {
+			fail("events.max_pending_sensors", "must be positive if the provisioning is enabled, got %d", c.Events.MaxPendingSensors)
+		}
+		if c.Events.PendingEvents < 1 {
+			fail("events.pending_events", "must be positive if the provisioning is enabled, got %d", c.Events.PendingEvents)
+		}
+	}
+	for name, limit := range map[string]LimitConfig{
+		"rate_limit.per_ip":      c.RateLimit.PerIP,
+		"rate_limit.per_api_key": c.RateLimit.PerAPIKey,
+		"rate_limit.per_sensor":  c.RateLimit.PerSensor,
+	} {
+		if limit.Rate < 0 {
+			fail(name+".rate", "must not be negative, got %v", limit.Rate)
+		}
+		if limit.Rate > 0 && limit.Burst < 1 {
+			fail(name+".burst", "must be positive if the limit is enabled, got %d", limit.Burst)
+		}
+	}
+	if c.RateLimit.MaxKeys < 1 {
+		fail("rate_limit.max_keys", "must be positive, got %d", c.RateLimit.MaxKeys)
+	}
+	if c.RateLimit.CoalesceContactClosure && c.RateLimit.CoalesceWindow <= 0 {
+		fail("rate_limit.coalesce_window", "must be positive, got %v", time.Duration(c.RateLimit.CoalesceWindow))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redacted - returns a copy of the config that is safe to expose, all the secrets are masked
+func (c Config) Redacted() Config {
+	c.Storage.DatabaseURL = redactDSN(c.Storage.DatabaseURL)
+	if c.Admin.Token != "" {
+		c.Admin.Token = redactedValue
+	}
+	if c.Automation.WebhookSecret != "" {
+		c.Automation.WebhookSecret = redactedValue
+	}
+	if len(c.RateLimit.APIKeys) > 0 {
+		keys := make([]string, len(c.RateLimit.APIKeys))
+		for i := range keys {
+			keys[i] = redactedValue
+		}
+		c.RateLimit.APIKeys = keys
+	}
+	return c
+}
+
+// secretDSNKeys - connection parameters holding the secrets, in both forms of the DSN
+var secretDSNKeys = []string{"password", "sslpassword"}
+
+// redactDSN - masks the passwords of the postgres URL or keyword/value DSN, the value pgx can't parse
+// is masked as a whole, as it may still hold a password
+func redactDSN(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	if _, err := pgconn.ParseConfig(raw); err != nil {
+		return redactedValue
+	}
+	if strings.HasPrefix(raw, "postgres://") || strings.HasPrefix(raw, "postgresql://") {
+		return redactURL(raw)
+	}
+	return redactKeywordValue(raw)
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), redactedValue)
+	}
+	q := u.Query()
+	for _, key := range secretDSNKeys {
+		if q.Has(key) {
+			q.Set(key, redactedValue)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// redactKeywordValue - masks the values of the secret keys of `key=value key='quoted value'` DSN keeping the rest
+// as is, the DSN is expected to be parsed by pgx already
+func redactKeywordValue(dsn string) string {
+	var b strings.Builder
+	for i := 0; i < len(dsn); {
+		if dsn[i] == ' ' || dsn[i] == '\t' || dsn[i] == '\n' || dsn[i] == '\r' {
+			b.WriteByte(dsn[i])
+			i++
+			continue
+		}
+		eq := strings.IndexByte(dsn[i:], '=')
+		if eq < 0 {
+			return redactedValue
+		}
+		key := strings.TrimSpace(dsn[i : i+eq])
+		b.WriteString(dsn[i : i+eq+1])
+		i += eq + 1
+		for i < len(dsn) && dsn[i] == ' ' {
+			b.WriteByte(' ')
+			i++
+		}
+
+		start := i
+		if i < len(dsn) && dsn[i] == '\'' {
+			for i++; i < len(dsn) && dsn[i] != '\''; i++ {
+				if dsn[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(dsn) {
+				return redactedValue
+			}
+			i++
+		} else {
+			for ; i < len(dsn) && !strings.ContainsRune(" \t\n\r", rune(dsn[i])); i++ {
+				if dsn[i] == '\\' {
+					i++
+				}
+			}
+			i = min(i, len(dsn))
+		}
+		if slices.Contains(secretDSNKeys, key) {
+			b.WriteString(redactedValue)
+		} else {
+			b.WriteString(dsn[start:i])
+		}
+	}
+	return b.String()
+}
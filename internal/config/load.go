@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFileFlag = "config"
+	configFileEnv  = "CONFIG_FILE"
+)
+
+var ErrUnsupportedConfigFormat = errors.New("unsupported config file format")
+
+// binding - single config field that can be overridden from the environment and the command line
+type binding struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, raw string) error
+}
+
+func stringSetter(field func(c *Config) *string) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		*field(c) = raw
+		return nil
+	}
+}
+
+// listSetter - comma separated values, an empty string clears the list
+func listSetter(field func(c *Config) *[]string) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		*field(c) = values
+		return nil
+	}
+}
+
+func portSetter(field func(c *Config) *uint16) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := strconv.ParseUint(raw, 10, 16)
+		if err != nil {
+			return err
+		}
+		*field(c) = uint16(v)
+		return nil
+	}
+}
+
+func int64Setter(field func(c *Config) *int64) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		*field(c) = v
+		return nil
+	}
+}
+
+func int32Setter(field func(c *Config) *int32) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return err
+		}
+		*field(c) = int32(v)
+		return nil
+	}
+}
+
+func intSetter(field func(c *Config) *int) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		*field(c) = v
+		return nil
+	}
+}
+
+func durationSetter(field func(c *Config) *Duration) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		return field(c).UnmarshalText([]byte(raw))
+	}
+}
+
+func boolSetter(field func(c *Config) *bool) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		*field(c) = v
+		return nil
+	}
+}
+
+func float64Setter(field func(c *Config) *float64) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		*field(c) = v
+		return nil
+	}
+}
+
+var bindings = []binding{
+	{"http-host", "HTTP_HOST", "HTTP listen host", stringSetter(func(c *Config) *string { return &c.HTTP.Host })},
+	{"http-port", "HTTP_PORT", "HTTP listen port", portSetter(func(c *Config) *uint16 { return &c.HTTP.Port })},
+	{
+		"http-read-header-timeout", "HTTP_READ_HEADER_TIMEOUT", "time allowed to read request headers",
+		durationSetter(func(c *Config) *Duration { return &c.HTTP.ReadHeaderTimeout }),
+	},
+	{
+		"http-idle-timeout", "HTTP_IDLE_TIMEOUT", "keep-alive connection idle timeout",
+		durationSetter(func(c *Config) *Duration { return &c.HTTP.IdleTimeout }),
+	},
+	{
+		"http-shutdown-timeout", "HTTP_SHUTDOWN_TIMEOUT", "time allowed for graceful shutdown",
+		durationSetter(func(c *Config) *Duration { return &c.HTTP.ShutdownTimeout }),
+	},
+	{
+		"http-trusted-proxies", "HTTP_TRUSTED_PROXIES", "comma separated IPs and CIDRs of the proxies trusted to set X-Forwarded-For",
+		listSetter(func(c *Config) *[]string { return &c.HTTP.TrustedProxies }),
+	},
+	{"metrics-port", "METRICS_PORT", "prometheus metrics listen port", portSetter(func(c *Config) *uint16 { return &c.Metrics.Port })},
+	{
+		"storage-backend", "STORAGE_BACKEND", "storage backend (postgres, inmemory)",
+		stringSetter(func(c *Config) *string { return &c.Storage.Backend }),
+	},
+	{"database-url", "DATABASE_URL", "postgres connection URL", stringSetter(func(c *Config) *string { return &c.Storage.DatabaseURL })},
+	{
+		"database-max-conns", "DATABASE_MAX_CONNS", "postgres pool max connections",
+		int32Setter(func(c *Config) *int32 { return &c.Storage.MaxConns }),
+	},
+	{
+		"database-min-conns", "DATABASE_MIN_CONNS", "postgres pool min connections",
+		int32Setter(func(c *Config) *int32 { return &c.Storage.MinConns }),
+	},
+	{
+		"database-connect-timeout", "DATABASE_CONNECT_TIMEOUT", "postgres connect timeout",
+		durationSetter(func(c *Config) *Duration { return &c.Storage.ConnectTimeout }),
+	},
+	{
+		"database-auto-migrate", "DATABASE_AUTO_MIGRATE", "apply the pending schema migrations on start",
+		boolSetter(func(c *Config) *bool { return &c.Storage.AutoMigrate }),
+	},
+	{
+		"ws-write-timeout", "WS_WRITE_TIMEOUT", "websocket message write timeout",
+		durationSetter(func(c *Config) *Duration { return &c.WebSocket.WriteTimeout }),
+	},
+	{
+		"ws-batch-period", "WS_BATCH_PERIOD", "websocket events batching period",
+		durationSetter(func(c *Config) *Duration { return &c.WebSocket.BatchPeriod }),
+	},
+	{
+		"health-check-timeout", "HEALTH_CHECK_TIMEOUT", "timeout of a single health check",
+		durationSetter(func(c *Config) *Duration { return &c.Health.CheckTimeout }),
+	},
+	{
+		"tracing-exporter", "TRACING_EXPORTER", "span exporter (none, stdout, otlp)",
+		stringSetter(func(c *Config) *string { return &c.Tracing.Exporter }),
+	},
+	{
+		"tracing-service-name", "TRACING_SERVICE_NAME", "service name reported with the spans",
+		stringSetter(func(c *Config) *string { return &c.Tracing.ServiceName }),
+	},
+	{
+		"tracing-otlp-endpoint", "TRACING_OTLP_ENDPOINT", "OTLP/HTTP collector host:port",
+		stringSetter(func(c *Config) *string { return &c.Tracing.OTLPEndpoint }),
+	},
+	{
+		"tracing-otlp-insecure", "TRACING_OTLP_INSECURE", "use plain HTTP for the OTLP collector",
+		boolSetter(func(c *Config) *bool { return &c.Tracing.OTLPInsecure }),
+	},
+	{
+		"tracing-sample-ratio", "TRACING_SAMPLE_RATIO", "share of the root traces to record (0..1)",
+		float64Setter(func(c *Config) *float64 { return &c.Tracing.SampleRatio }),
+	},
+	{"log-level", "LOG_LEVEL", "minimal log level (debug, info, warn, error)", stringSetter(func(c *Config) *string { return &c.Log.Level })},
+	{"log-format", "LOG_FORMAT", "log output format (text, json)", stringSetter(func(c *Config) *string { return &c.Log.Format })},
+	{"admin-token", "ADMIN_TOKEN", "bearer token for the admin API", stringSetter(func(c *Config) *string { return &c.Admin.Token })},
+	{
+		"admin-backup-max-bytes", "ADMIN_BACKUP_MAX_BYTES", "size limit of the uploaded backup archive",
+		int64Setter(func(c *Config) *int64 { return &c.Admin.BackupMaxBytes }),
+	},
+	{
+		"automation-tick-period", "AUTOMATION_TICK_PERIOD", "period of the schedule and offline triggers evaluation",
+		durationSetter(func(c *Config) *Duration { return &c.Automation.TickPeriod }),
+	},
+	{
+		"automation-webhook-timeout", "AUTOMATION_WEBHOOK_TIMEOUT", "timeout of a webhook action request",
+		durationSetter(func(c *Config) *Duration { return &c.Automation.WebhookTimeout }),
+	},
+	{
+		"automation-webhook-secret", "AUTOMATION_WEBHOOK_SECRET", "key of the webhook signatures, random if empty",
+		stringSetter(func(c *Config) *string { return &c.Automation.WebhookSecret }),
+	},
+	{
+		"scheduler-tick-period", "SCHEDULER_TICK_PERIOD", "period of the job schedules check",
+		durationSetter(func(c *Config) *Duration { return &c.Scheduler.TickPeriod }),
+	},
+	{
+		"events-dedup-window", "EVENTS_DEDUP_WINDOW", "how long an idempotency key of an accepted event is kept",
+		durationSetter(func(c *Config) *Duration { return &c.Events.DedupWindow }),
+	},
+	{
+		"events-provisioning", "EVENTS_PROVISIONING", "keep the events of the unknown sensors until the sensors are claimed",
+		boolSetter(func(c *Config) *bool { return &c.Events.Provisioning }),
+	},
+	{
+		"events-max-pending-sensors", "EVENTS_MAX_PENDING_SENSORS", "how many unknown sensors wait to be claimed",
+		intSetter(func(c *Config) *int { return &c.Events.MaxPendingSensors }),
+	},
+	{
+		"events-pending-events", "EVENTS_PENDING_EVENTS", "how many first events of an unknown sensor are buffered",
+		intSetter(func(c *Config) *int { return &c.Events.PendingEvents }),
+	},
+	{
+		"rate-limit-per-ip-rate", "RATE_LIMIT_PER_IP_RATE", "requests per second allowed per client IP (0 disables)",
+		float64Setter(func(c *Config) *float64 { return &c.RateLimit.PerIP.Rate }),
+	},
+	{
+		"rate-limit-per-ip-burst", "RATE_LIMIT_PER_IP_BURST", "requests burst allowed per client IP",
+		intSetter(func(c *Config) *int { return &c.RateLimit.PerIP.Burst }),
+	},
+	{
+		"rate-limit-per-api-key-rate", "RATE_LIMIT_PER_API_KEY_RATE", "requests per second allowed per API key (0 disables)",
+		float64Setter(func(c *Config) *float64 { return &c.RateLimit.PerAPIKey.Rate }),
+	},
+	{
+		"rate-limit-per-api-key-burst", "RATE_LIMIT_PER_API_KEY_BURST", "requests burst allowed per API key",
+		intSetter(func(c *Config) *int { return &c.RateLimit.PerAPIKey.Burst }),
+	},
+	{
+		"rate-limit-api-keys", "RATE_LIMIT_API_KEYS", "comma separated API keys limited per key, the others are limited per IP only",
+		listSetter(func(c *Config) *[]string { return &c.RateLimit.APIKeys }),
+	},
+	{
+		"rate-limit-max-keys", "RATE_LIMIT_MAX_KEYS", "how many IPs, API keys or sensors each limit tracks at most",
+		intSetter(func(c *Config) *int { return &c.RateLimit.MaxKeys }),
+	},
+	{
+		"rate-limit-per-sensor-rate", "RATE_LIMIT_PER_SENSOR_RATE", "events per second allowed per sensor (0 disables)",
+		float64Setter(func(c *Config) *float64 { return &c.RateLimit.PerSensor.Rate }),
+	},
+	{
+		"rate-limit-per-sensor-burst", "RATE_LIMIT_PER_SENSOR_BURST", "events burst allowed per sensor",
+		intSetter(func(c *Config) *int { return &c.RateLimit.PerSensor.Burst }),
+	},
+	{
+		"rate-limit-coalesce-contact-closure", "RATE_LIMIT_COALESCE_CONTACT_CLOSURE",
+		"coalesce contact closure events over the sensor limit instead of rejecting them",
+		boolSetter(func(c *Config) *bool { return &c.RateLimit.CoalesceContactClosure }),
+	},
+	{
+		"rate-limit-coalesce-window", "RATE_LIMIT_COALESCE_WINDOW", "how often the coalesced events are ingested",
+		durationSetter(func(c *Config) *Duration { return &c.RateLimit.CoalesceWindow }),
+	},
+}
+
+// Load - builds the effective config from (in ascending precedence order) defaults, a YAML/TOML file,
+// environment variables and command line flags, then validates it.
+// The config file is selected with the -config flag or CONFIG_FILE environment variable.
+func Load(name string, args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configPath := fs.String(configFileFlag, "", "path to the YAML or TOML config file (env "+configFileEnv+")")
+	flagValues := make(map[string]*string, len(bindings))
+	for _, b := range bindings {
+		flagValues[b.flag] = fs.String(b.flag, "", fmt.Sprintf("%s (env %s)", b.usage, b.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+
+	if *configPath == "" {
+		*configPath, _ = lookupEnv(configFileEnv)
+	}
+	if *configPath != "" {
+		if err := loadFile(&cfg, *configPath); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var errs []error
+	for _, b := range bindings {
+		if raw, ok := lookupEnv(b.env); ok {
+			if err := b.set(&cfg, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%w: env %s=%q: %w", ErrInvalidConfig, b.env, raw, err))
+			}
+		}
+	}
+
+	explicit := make(map[string]struct{})
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = struct{}{}
+	})
+	for _, b := range bindings {
+		if _, ok := explicit[b.flag]; !ok {
+			continue
+		}
+		raw := *flagValues[b.flag]
+		if err := b.set(&cfg, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%w: flag -%s=%q: %w", ErrInvalidConfig, b.flag, raw, err))
+		}
+	}
+	if len(errs) > 0 {
+		return Config{}, errors.Join(errs...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// LoadFromOS - Load for the current process arguments and environment
+func LoadFromOS() (Config, error) {
+	return Load(os.Args[0], os.Args[1:], os.LookupEnv)
+}
+
+func loadFile(cfg *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("can't read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: can't parse YAML config %s: %w", ErrInvalidConfig, path, err)
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return fmt.Errorf("%w: can't parse TOML config %s: %w", ErrInvalidConfig, path, err)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedConfigFormat, path)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package domain
+
+import "time"
+
+// ActuatorType - тип исполнительного устройства
+type ActuatorType string
+
+const (
+	ActuatorTypeRelay  ActuatorType = "relay"
+	ActuatorTypeValve  ActuatorType = "valve"
+	ActuatorTypeDimmer ActuatorType = "dimmer"
+)
+
+// CommandAction - действие, которое команда требует от исполнительного устройства
+type CommandAction string
+
+const (
+	CommandActionOn    CommandAction = "on"
+	CommandActionOff   CommandAction = "off"
+	CommandActionOpen  CommandAction = "open"
+	CommandActionClose CommandAction = "close"
+	CommandActionSet   CommandAction = "set"
+)
+
+// DimmerMaxLevel - уровень яркости диммера в команде set задается в процентах
+const DimmerMaxLevel = 100
+
+// actuatorActions - действия, которые принимает исполнительное устройство каждого типа
+var actuatorActions = map[ActuatorType][]CommandAction{
+	ActuatorTypeRelay:  {CommandActionOn, CommandActionOff},
+	ActuatorTypeValve:  {CommandActionOpen, CommandActionClose},
+	ActuatorTypeDimmer: {CommandActionOn, CommandActionOff, CommandActionSet},
+}
+
+// IsValid - является ли тип исполнительного устройства известным
+func (t ActuatorType) IsValid() bool {
+	_, ok := actuatorActions[t]
+	return ok
+}
+
+// Accepts - принимает ли исполнительное устройство типа t действие action
+func (t ActuatorType) Accepts(action CommandAction) bool {
+	for _, a := range actuatorActions[t] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Actuator - структура для хранения исполнительного устройства: реле, клапана, диммера
+type Actuator struct {
+	ID           int64
+	SerialNumber string
+	Type         ActuatorType
+	Description  string
+	RegisteredAt time.Time
+}
+
+// CommandStatus - этап жизненного цикла команды
+type CommandStatus string
+
+const (
+	CommandStatusPending   CommandStatus = "pending"
+	CommandStatusDelivered CommandStatus = "delivered"
+	CommandStatusAcked     CommandStatus = "acked"
+	CommandStatusFailed    CommandStatus = "failed"
+	CommandStatusExpired   CommandStatus = "expired"
+)
+
+// commandTransitions - допустимые переходы между этапами, из acked, failed и expired переходов нет.
+// Устройство может подтвердить команду, не дожидаясь отметки о доставке, например, при получении по опросу.
+var commandTransitions = map[CommandStatus][]CommandStatus{
+	CommandStatusPending:   {CommandStatusDelivered, CommandStatusAcked, CommandStatusFailed, CommandStatusExpired},
+	CommandStatusDelivered: {CommandStatusAcked, CommandStatusFailed, CommandStatusExpired},
+}
+
+// CanBecome - допустим ли переход команды из этапа s в этап next
+func (s CommandStatus) CanBecome(next CommandStatus) bool {
+	for _, st := range commandTransitions[s] {
+		if st == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal - завершен ли жизненный цикл команды
+func (s CommandStatus) IsFinal() bool {
+	return len(commandTransitions[s]) == 0
+}
+
+// Command - структура для хранения команды исполнительному устройству
+// Value - параметр действия, уровень для set
+// Error - причина неуспеха, о которой сообщило устройство
+// ExpiresAt - время, после которого не завершенная команда считается просроченной
+type Command struct {
+	ID         int64
+	ActuatorID int64
+	Action     CommandAction
+	Value      int64
+	Status     CommandStatus
+	Error      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// IsExpired - истек ли к моменту now срок не завершенной команды
+func (c *Command) IsExpired(now time.Time) bool {
+	return !c.Status.IsFinal() && !now.Before(c.ExpiresAt)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActuatorType_Accepts(t *testing.T) {
+	assert.True(t, ActuatorTypeRelay.Accepts(CommandActionOn))
+	assert.False(t, ActuatorTypeRelay.Accepts(CommandActionSet))
+	assert.True(t, ActuatorTypeValve.Accepts(CommandActionClose))
+	assert.False(t, ActuatorTypeValve.Accepts(CommandActionOff))
+	assert.True(t, ActuatorTypeDimmer.Accepts(CommandActionSet))
+	assert.False(t, ActuatorType("pump").Accepts(CommandActionOn))
+	assert.False(t, ActuatorType("pump").IsValid())
+}
+
+func TestCommandStatus_CanBecome(t *testing.T) {
+	tests := []struct {
+		from CommandStatus
+		to   CommandStatus
+		want bool
+	}{
+		{CommandStatusPending, CommandStatusDelivered, true},
+		{CommandStatusPending, CommandStatusAcked, true},
+		{CommandStatusDelivered, CommandStatusFailed, true},
+		{CommandStatusDelivered, CommandStatusExpired, true},
+		{CommandStatusDelivered, CommandStatusPending, false},
+		{CommandStatusDelivered, CommandStatusDelivered, false},
+		{CommandStatusAcked, CommandStatusFailed, false},
+		{CommandStatusExpired, CommandStatusAcked, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"/"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanBecome(tt.to))
+		})
+	}
+}
+
+func TestCommand_IsExpired(t *testing.T) {
+	now := time.Now()
+	command := Command{Status: CommandStatusDelivered, ExpiresAt: now}
+	assert.True(t, command.IsExpired(now))
+	assert.False(t, command.IsExpired(now.Add(-time.Second)))
+
+	command.Status = CommandStatusAcked
+	assert.False(t, command.IsExpired(now.Add(time.Hour)))
+}
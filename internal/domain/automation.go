@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+// TriggerKind - вид события, запускающего правило автоматизации
+type TriggerKind string
+
+const (
+	// TriggerEvent - событие датчика SensorID, значение канала Channel которого удовлетворяет Operator и Value
+	TriggerEvent TriggerKind = "event"
+	// TriggerSchedule - запуск каждые Every
+	TriggerSchedule TriggerKind = "schedule"
+	// TriggerOffline - от датчика SensorID нет событий дольше After, срабатывает один раз до следующего события
+	TriggerOffline TriggerKind = "offline"
+)
+
+// ConditionKind - вид дополнительного условия правила автоматизации
+type ConditionKind string
+
+const (
+	// ConditionTimeWindow - текущее время суток в интервале [From, To), интервал может переходить через полночь
+	ConditionTimeWindow ConditionKind = "time_window"
+	// ConditionSensorState - CurrentState датчика SensorID удовлетворяет Operator и Value
+	ConditionSensorState ConditionKind = "sensor_state"
+)
+
+// ActionKind - вид действия правила автоматизации
+type ActionKind string
+
+const (
+	// ActionCommand - команда Command со значением Value исполнительному устройству ActuatorID
+	ActionCommand ActionKind = "command"
+	// ActionWebhook - POST запрос с описанием срабатывания на URL
+	ActionWebhook ActionKind = "webhook"
+	// ActionAlert - оповещение с текстом Message
+	ActionAlert ActionKind = "alert"
+)
+
+// CompareOperator - оператор сравнения значения датчика с порогом
+type CompareOperator string
+
+const (
+	CompareEq  CompareOperator = "eq"
+	CompareNe  CompareOperator = "ne"
+	CompareGt  CompareOperator = "gt"
+	CompareGte CompareOperator = "gte"
+	CompareLt  CompareOperator = "lt"
+	CompareLte CompareOperator = "lte"
+)
+
+// IsValid - является ли оператор известным
+func (o CompareOperator) IsValid() bool {
+	switch o {
+	case CompareEq, CompareNe, CompareGt, CompareGte, CompareLt, CompareLte:
+		return true
+	}
+	return false
+}
+
+// Compare - удовлетворяет ли значение value оператору o с порогом threshold
+func (o CompareOperator) Compare(value, threshold int64) bool {
+	switch o {
+	case CompareEq:
+		return value == threshold
+	case CompareNe:
+		return value != threshold
+	case CompareGt:
+		return value > threshold
+	case CompareGte:
+		return value >= threshold
+	case CompareLt:
+		return value < threshold
+	case CompareLte:
+		return value <= threshold
+	}
+	return false
+}
+
+// Trigger - запускающее правило событие, используются только поля, относящиеся к Kind
+type Trigger struct {
+	Kind     TriggerKind     `json:"kind"`
+	SensorID int64           `json:"sensor_id,omitempty"`
+	Channel  string          `json:"channel,omitempty"`
+	Operator CompareOperator `json:"operator,omitempty"`
+	Value    int64           `json:"value,omitempty"`
+	Every    time.Duration   `json:"every,omitempty"`
+	After    time.Duration   `json:"after,omitempty"`
+}
+
+// Matches - запускает ли событие датчика правило с триггером вида TriggerEvent
+func (t Trigger) Matches(event *Event) bool {
+	if t.Kind != TriggerEvent || t.SensorID != event.SensorID {
+		return false
+	}
+	value, ok := event.ChannelValue(t.Channel)
+	return ok && t.Operator.Compare(value, t.Value)
+}
+
+// Condition - условие, при невыполнении которого запущенное правило пропускается
+// From, To - время суток в формате ЧЧ:ММ
+type Condition struct {
+	Kind     ConditionKind   `json:"kind"`
+	From     string          `json:"from,omitempty"`
+	To       string          `json:"to,omitempty"`
+	SensorID int64           `json:"sensor_id,omitempty"`
+	Operator CompareOperator `json:"operator,omitempty"`
+	Value    int64           `json:"value,omitempty"`
+}
+
+// ParseTimeOfDay - время суток ЧЧ:ММ в виде смещения от полуночи
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("time of day %q is not HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// InWindow - попадает ли момент now в интервал условия вида ConditionTimeWindow, невалидный интервал не выполняется
+func (c Condition) InWindow(now time.Time) bool {
+	from, err := ParseTimeOfDay(c.From)
+	if err != nil {
+		return false
+	}
+	to, err := ParseTimeOfDay(c.To)
+	if err != nil {
+		return false
+	}
+	y, m, d := now.Date()
+	sinceMidnight := now.Sub(time.Date(y, m, d, 0, 0, 0, 0, now.Location()))
+	if from <= to {
+		return sinceMidnight >= from && sinceMidnight < to
+	}
+	return sinceMidnight >= from || sinceMidnight < to
+}
+
+// Action - действие правила, используются только поля, относящиеся к Kind
+type Action struct {
+	Kind       ActionKind    `json:"kind"`
+	ActuatorID int64         `json:"actuator_id,omitempty"`
+	Command    CommandAction `json:"command,omitempty"`
+	Value      int64         `json:"value,omitempty"`
+	URL        string        `json:"url,omitempty"`
+	Message    string        `json:"message,omitempty"`
+}
+
+// metadataAddresses - адреса сервисов метаданных облаков вне частных и link-local диапазонов
+var metadataAddresses = []netip.Addr{
+	netip.MustParseAddr("100.100.100.200"), // Alibaba Cloud
+}
+
+// IsInternalAddress - является ли адрес внутренним: loopback, частные сети (RFC 1918, RFC 4193), link-local
+// (в том числе 169.254.169.254 сервисов метаданных) или неуказанный адрес, вебхуки на такие адреса запрещены
+func IsInternalAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		slices.Contains(metadataAddresses, addr)
+}
+
+// AutomationRule - структура для хранения правила автоматизации "если триггер и условия, то действия"
+// Cooldown - минимальный интервал между запусками правила, защищает от зацикливания и дребезга датчиков
+type AutomationRule struct {
+	ID         int64
+	Name       string
+	Enabled    bool
+	Trigger    Trigger
+	Conditions []Condition
+	Actions    []Action
+	Cooldown   time.Duration
+	CreatedAt  time.Time
+}
+
+// ExecutionStatus - итог запуска правила автоматизации
+type ExecutionStatus string
+
+const (
+	ExecutionSucceeded ExecutionStatus = "succeeded"
+	ExecutionFailed    ExecutionStatus = "failed"
+	ExecutionSkipped   ExecutionStatus = "skipped"
+)
+
+// AutomationExecution - запись журнала запусков правила
+// Depth - число правил в цепочке, которая привела к запуску, считается по событиям, пришедшим от вебхуков
+// Reason - причина пропуска или ошибки действий
+type AutomationExecution struct {
+	ID        int64
+	RuleID    int64
+	Trigger   TriggerKind
+	Status    ExecutionStatus
+	Reason    string
+	Depth     int
+	StartedAt time.Time
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigger_Matches(t *testing.T) {
+	event := &Event{SensorID: 1, Payload: 1, Values: map[string]int64{"humidity": 8000}}
+	tests := []struct {
+		name    string
+		trigger Trigger
+		want    bool
+	}{
+		{"payload equals", Trigger{Kind: TriggerEvent, SensorID: 1, Operator: CompareEq, Value: 1}, true},
+		{"channel above", Trigger{Kind: TriggerEvent, SensorID: 1, Channel: "humidity", Operator: CompareGt, Value: 7000}, true},
+		{"channel below", Trigger{Kind: TriggerEvent, SensorID: 1, Channel: "humidity", Operator: CompareLte, Value: 7000}, false},
+		{"unknown channel", Trigger{Kind: TriggerEvent, SensorID: 1, Channel: "temperature", Operator: CompareNe, Value: 0}, false},
+		{"another sensor", Trigger{Kind: TriggerEvent, SensorID: 2, Operator: CompareEq, Value: 1}, false},
+		{"not an event trigger", Trigger{Kind: TriggerOffline, SensorID: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.trigger.Matches(event))
+		})
+	}
+}
+
+func TestCondition_InWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 5, 1, hour, minute, 0, 0, time.UTC)
+	}
+	day := Condition{Kind: ConditionTimeWindow, From: "08:00", To: "20:00"}
+	night := Condition{Kind: ConditionTimeWindow, From: "22:00", To: "06:30"}
+
+	assert.True(t, day.InWindow(at(8, 0)))
+	assert.True(t, day.InWindow(at(19, 59)))
+	assert.False(t, day.InWindow(at(20, 0)))
+	assert.True(t, night.InWindow(at(23, 15)))
+	assert.True(t, night.InWindow(at(6, 0)))
+	assert.False(t, night.InWindow(at(12, 0)))
+	assert.False(t, Condition{Kind: ConditionTimeWindow, From: "25:00", To: "06:00"}.InWindow(at(1, 0)))
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	d, err := ParseTimeOfDay("06:30")
+	assert.NoError(t, err)
+	assert.Equal(t, 6*time.Hour+30*time.Minute, d)
+
+	_, err = ParseTimeOfDay("6.30")
+	assert.Error(t, err)
+}
+
+func TestIsInternalAddress(t *testing.T) {
+	for _, addr := range []string{
+		"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.10", "169.254.169.254", "100.100.100.200",
+		"fd00:ec2::254", "fe80::1", "0.0.0.0", "::", "::ffff:127.0.0.1",
+	} {
+		assert.True(t, IsInternalAddress(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"93.184.216.34", "8.8.8.8", "2606:4700::1111", "172.32.0.1"} {
+		assert.False(t, IsInternalAddress(netip.MustParseAddr(addr)), addr)
+	}
+}
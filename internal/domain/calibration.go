@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"math"
+	"sort"
+)
+
+// CalibrationStepKind - вид шага преобразования показаний датчика
+type CalibrationStepKind string
+
+const (
+	// CalibrationOffset - сдвиг значения на Value
+	CalibrationOffset CalibrationStepKind = "offset"
+	// CalibrationGain - умножение значения на Value
+	CalibrationGain CalibrationStepKind = "gain"
+	// CalibrationLookup - кусочно-линейная интерполяция по таблице Points,
+	// за пределами таблицы значение экстраполируется по крайним отрезкам
+	CalibrationLookup CalibrationStepKind = "lookup"
+	// CalibrationClamp - ограничение значения диапазоном [Min, Max], любая из границ может отсутствовать
+	CalibrationClamp CalibrationStepKind = "clamp"
+	// CalibrationMovingAverage - скользящее среднее по Window последним значениям
+	CalibrationMovingAverage CalibrationStepKind = "moving_average"
+)
+
+// CalibrationPoint - точка таблицы калибровки: сырое значение и соответствующее ему откалиброванное
+type CalibrationPoint struct {
+	Raw   float64 `json:"raw"`
+	Value float64 `json:"value"`
+}
+
+// CalibrationStep - шаг преобразования показаний, используются только поля, относящиеся к Kind
+type CalibrationStep struct {
+	Kind   CalibrationStepKind `json:"kind"`
+	Value  float64             `json:"value,omitempty"`
+	Points []CalibrationPoint  `json:"points,omitempty"`
+	Min    *float64            `json:"min,omitempty"`
+	Max    *float64            `json:"max,omitempty"`
+	Window int                 `json:"window,omitempty"`
+}
+
+// Calibration - цепочка преобразований сырых показаний датчика, шаги применяются по порядку.
+// Все шаги работают в единицах payload, результат округляется до целого.
+type Calibration []CalibrationStep
+
+// Depth - сколько предыдущих сырых показаний нужно для вычисления скользящих средних цепочки
+func (c Calibration) Depth() int {
+	depth := 0
+	for _, step := range c {
+		if step.Kind == CalibrationMovingAverage && step.Window > 1 {
+			depth += step.Window - 1
+		}
+	}
+	return depth
+}
+
+// Apply - откалиброванное значение сырого показания raw,
+// previous - предыдущие сырые показания датчика в хронологическом порядке
+func (c Calibration) Apply(raw int64, previous []int64) int64 {
+	if len(c) == 0 {
+		return raw
+	}
+	if depth := c.Depth(); len(previous) > depth {
+		previous = previous[len(previous)-depth:]
+	}
+	series := make([]float64, 0, len(previous)+1)
+	for _, p := range previous {
+		series = append(series, float64(p))
+	}
+	series = append(series, float64(raw))
+	for _, step := range c {
+		series = step.apply(series)
+	}
+	return int64(math.Round(series[len(series)-1]))
+}
+
+func (s CalibrationStep) apply(series []float64) []float64 {
+	res := make([]float64, len(series))
+	for i, v := range series {
+		switch s.Kind {
+		case CalibrationOffset:
+			res[i] = v + s.Value
+		case CalibrationGain:
+			res[i] = v * s.Value
+		case CalibrationLookup:
+			res[i] = interpolate(s.Points, v)
+		case CalibrationClamp:
+			if s.Min != nil {
+				v = math.Max(v, *s.Min)
+			}
+			if s.Max != nil {
+				v = math.Min(v, *s.Max)
+			}
+			res[i] = v
+		case CalibrationMovingAverage:
+			from := max(0, i-s.Window+1)
+			sum := 0.0
+			for _, p := range series[from : i+1] {
+				sum += p
+			}
+			res[i] = sum / float64(i+1-from)
+		default:
+			res[i] = v
+		}
+	}
+	return res
+}
+
+// interpolate - кусочно-линейная интерполяция по таблице, упорядоченной по Raw, из хотя бы двух точек
+func interpolate(points []CalibrationPoint, v float64) float64 {
+	if len(points) < 2 {
+		return v
+	}
+	i := sort.Search(len(points), func(i int) bool { return points[i].Raw >= v })
+	i = min(max(i, 1), len(points)-1)
+	lo, hi := points[i-1], points[i]
+	return lo.Value + (v-lo.Raw)*(hi.Value-lo.Value)/(hi.Raw-lo.Raw)
+}
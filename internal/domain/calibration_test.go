@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalibration_Apply(t *testing.T) {
+	lo, hi := 0.0, 1000.0
+	tests := []struct {
+		name        string
+		calibration Calibration
+		raw         int64
+		previous    []int64
+		want        int64
+	}{
+		{"no calibration", nil, 42, nil, 42},
+		{"gain and offset", Calibration{{Kind: CalibrationGain, Value: 1.5}, {Kind: CalibrationOffset, Value: -10}}, 100, nil, 140},
+		{"lookup interpolation", Calibration{{Kind: CalibrationLookup, Points: []CalibrationPoint{
+			{Raw: 0, Value: 0}, {Raw: 100, Value: 1000}, {Raw: 200, Value: 1500},
+		}}}, 150, nil, 1250},
+		{"lookup extrapolation", Calibration{{Kind: CalibrationLookup, Points: []CalibrationPoint{
+			{Raw: 0, Value: 0}, {Raw: 100, Value: 1000},
+		}}}, 120, nil, 1200},
+		{"clamp", Calibration{{Kind: CalibrationClamp, Min: &lo, Max: &hi}}, 1200, nil, 1000},
+		{"moving average", Calibration{{Kind: CalibrationMovingAverage, Window: 3}}, 30, []int64{100, 10, 20}, 20},
+		{"moving average of short history", Calibration{{Kind: CalibrationMovingAverage, Window: 3}}, 30, []int64{10}, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.calibration.Apply(tt.raw, tt.previous))
+		})
+	}
+}
+
+func TestCalibration_Depth(t *testing.T) {
+	calibration := Calibration{
+		{Kind: CalibrationMovingAverage, Window: 3},
+		{Kind: CalibrationOffset, Value: 1},
+		{Kind: CalibrationMovingAverage, Window: 2},
+	}
+	assert.Equal(t, 3, calibration.Depth())
+}
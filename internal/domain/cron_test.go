@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, spec := range []string{"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"*/0 * * * *", "5-1 * * * *", "a * * * *", "@often"} {
+		_, err := ParseCron(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	from := time.Date(2024, time.May, 1, 10, 7, 30, 0, time.UTC) // Wednesday
+
+	for spec, expected := range map[string]time.Time{
+		"* * * * *":      time.Date(2024, time.May, 1, 10, 8, 0, 0, time.UTC),
+		"*/15 * * * *":   time.Date(2024, time.May, 1, 10, 15, 0, 0, time.UTC),
+		"5 * * * *":      time.Date(2024, time.May, 1, 11, 5, 0, 0, time.UTC),
+		"30 3 * * *":     time.Date(2024, time.May, 2, 3, 30, 0, 0, time.UTC),
+		"0 9-17/4 * * *": time.Date(2024, time.May, 1, 13, 0, 0, 0, time.UTC),
+		"0 0 * * 0":      time.Date(2024, time.May, 5, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":      time.Date(2024, time.May, 5, 0, 0, 0, 0, time.UTC),
+		"0 0 15 * 1":     time.Date(2024, time.May, 6, 0, 0, 0, 0, time.UTC), // Day of month or day of week
+		"0 0 29 2 *":     time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"@monthly":       time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+		"0 9 1,15 * *":   time.Date(2024, time.May, 15, 9, 0, 0, 0, time.UTC),
+	} {
+		schedule, err := ParseCron(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, expected, schedule.Next(from), spec)
+	}
+
+	never, err := ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(from).IsZero())
+}
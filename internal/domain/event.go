@@ -4,8 +4,39 @@ import "time"
 
 // Event - структура события по датчику
 type Event struct {
+	// ID - идентификатор события, назначается хранилищем при сохранении
+	ID                 int64 `json:",omitempty"`
 	Timestamp          time.Time
 	SensorSerialNumber string
 	SensorID           int64
 	Payload            int64
+	// Values - значения по каналам многоканального датчика, Payload при этом равен значению первого канала
+	Values map[string]int64 `json:",omitempty"`
+	// RawPayload, RawValues - показания датчика до калибровки, Payload и Values содержат откалиброванные значения
+	RawPayload int64
+	RawValues  map[string]int64 `json:",omitempty"`
+	// IdempotencyKey - ключ идемпотентности, переданный клиентом; событие датчика с уже принятым ключом повторно не сохраняется
+	IdempotencyKey string `json:",omitempty"`
+	// TraceParent - контекст трассировки W3C запроса, принявшего событие, не сохраняется и не передается подписчикам
+	TraceParent string `json:"-"`
+	// AutomationDepth - число правил автоматизации в цепочке, которая привела к событию, не сохраняется
+	AutomationDepth int `json:"-"`
+}
+
+// ChannelValue - значение канала события, пустое имя соответствует Payload
+func (e *Event) ChannelValue(channel string) (int64, bool) {
+	if channel == "" {
+		return e.Payload, true
+	}
+	v, ok := e.Values[channel]
+	return v, ok
+}
+
+// RawChannelValue - сырое значение канала события до калибровки, пустое имя соответствует RawPayload
+func (e *Event) RawChannelValue(channel string) (int64, bool) {
+	if channel == "" {
+		return e.RawPayload, true
+	}
+	v, ok := e.RawValues[channel]
+	return v, ok
 }
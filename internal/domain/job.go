@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// JobStatus - итог последнего запуска периодической задачи
+type JobStatus string
+
+const (
+	JobStatusNever     JobStatus = ""
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// Job - структура для хранения периодической задачи
+// Schedule - расписание в формате cron, Enabled - запускается ли задача по расписанию, вручную ее можно запустить всегда
+// LastRunAt, LastStatus, LastError, LastDuration - состояние последнего запуска, общее для всех реплик
+type Job struct {
+	Name         string
+	Schedule     string
+	Enabled      bool
+	LastRunAt    time.Time
+	LastStatus   JobStatus
+	LastError    string
+	LastDuration time.Duration
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+const maxLabelLength = 63
+
+// SelectorOperator - операция требования селектора меток
+type SelectorOperator string
+
+const (
+	SelectorEquals    SelectorOperator = "="
+	SelectorNotEquals SelectorOperator = "!="
+	SelectorExists    SelectorOperator = "exists"
+	SelectorNotExists SelectorOperator = "!exists"
+)
+
+// SelectorRequirement - одно требование селектора, Value не используется в проверках наличия метки
+type SelectorRequirement struct {
+	Key      string
+	Operator SelectorOperator
+	Value    string
+}
+
+// Selector - селектор меток, датчик подходит под селектор, если выполнены все требования.
+// Пустой селектор подходит под любой датчик.
+type Selector []SelectorRequirement
+
+// isLabelChar - допустимый символ ключа и значения метки, в ключе дополнительно допускается '/'
+func isLabelChar(r rune, key bool) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case r == '.', r == '_', r == '-':
+		return true
+	case r == '/':
+		return key
+	}
+	return false
+}
+
+func validateLabelPart(s string, key bool) error {
+	if key && s == "" {
+		return fmt.Errorf("empty label key")
+	}
+	if len(s) > maxLabelLength {
+		return fmt.Errorf("label %q is longer than %d characters", s, maxLabelLength)
+	}
+	for _, r := range s {
+		if !isLabelChar(r, key) {
+			return fmt.Errorf("label %q contains invalid character %q", s, r)
+		}
+	}
+	return nil
+}
+
+// ValidateLabels - проверка ключей и значений меток: латинские буквы, цифры, '.', '_', '-',
+// в ключе также '/', не длиннее 63 символов, значение может быть пустым
+func ValidateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if err := validateLabelPart(k, true); err != nil {
+			return err
+		}
+		if err := validateLabelPart(v, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseSelector - разбор селектора вида `kind=leak,floor!=0,battery,!muted`:
+// `key=value` (или `key==value`), `key!=value`, `key` - метка задана, `!key` - метка не задана
+func ParseSelector(s string) (Selector, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	terms := strings.Split(s, ",")
+	selector := make(Selector, 0, len(terms))
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		var req SelectorRequirement
+		switch {
+		case strings.HasPrefix(term, "!") && !strings.Contains(term, "="):
+			req = SelectorRequirement{Key: strings.TrimSpace(term[1:]), Operator: SelectorNotExists}
+		case strings.Contains(term, "!="):
+			key, value, _ := strings.Cut(term, "!=")
+			req = SelectorRequirement{Key: strings.TrimSpace(key), Operator: SelectorNotEquals, Value: strings.TrimSpace(value)}
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(term, "=")
+			value = strings.TrimPrefix(value, "=")
+			req = SelectorRequirement{Key: strings.TrimSpace(key), Operator: SelectorEquals, Value: strings.TrimSpace(value)}
+		default:
+			req = SelectorRequirement{Key: term, Operator: SelectorExists}
+		}
+
+		if err := validateLabelPart(req.Key, true); err != nil {
+			return nil, fmt.Errorf("invalid selector term %q: %w", term, err)
+		}
+		if err := validateLabelPart(req.Value, false); err != nil {
+			return nil, fmt.Errorf("invalid selector term %q: %w", term, err)
+		}
+		selector = append(selector, req)
+	}
+	return selector, nil
+}
+
+// Matches - подходят ли метки под селектор, `key!=value` выполняется и при отсутствии метки
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, ok := labels[req.Key]
+		switch req.Operator {
+		case SelectorEquals:
+			if !ok || value != req.Value {
+				return false
+			}
+		case SelectorNotEquals:
+			if ok && value == req.Value {
+				return false
+			}
+		case SelectorExists:
+			if !ok {
+				return false
+			}
+		case SelectorNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String - запись селектора в том же синтаксисе, что принимает ParseSelector
+func (s Selector) String() string {
+	terms := make([]string, 0, len(s))
+	for _, req := range s {
+		switch req.Operator {
+		case SelectorExists:
+			terms = append(terms, req.Key)
+		case SelectorNotExists:
+			terms = append(terms, "!"+req.Key)
+		default:
+			terms = append(terms, req.Key+string(req.Operator)+req.Value)
+		}
+	}
+	return strings.Join(terms, ",")
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		want     Selector
+		wantErr  bool
+	}{
+		{"empty", " ", nil, false},
+		{"equals", "kind=leak", Selector{{Key: "kind", Operator: SelectorEquals, Value: "leak"}}, false},
+		{"double equals", "kind==leak", Selector{{Key: "kind", Operator: SelectorEquals, Value: "leak"}}, false},
+		{"combined", "kind=leak, floor!=0", Selector{
+			{Key: "kind", Operator: SelectorEquals, Value: "leak"},
+			{Key: "floor", Operator: SelectorNotEquals, Value: "0"},
+		}, false},
+		{"existence", "battery,!muted", Selector{
+			{Key: "battery", Operator: SelectorExists},
+			{Key: "muted", Operator: SelectorNotExists},
+		}, false},
+		{"empty value", "zone=", Selector{{Key: "zone", Operator: SelectorEquals}}, false},
+		{"prefixed key", "example.com/zone=north", Selector{{Key: "example.com/zone", Operator: SelectorEquals, Value: "north"}}, false},
+		{"empty term", "kind=leak,,floor=1", nil, true},
+		{"empty key", "=leak", nil, true},
+		{"invalid value", "kind=a=b", nil, true},
+		{"negated equality", "!kind=leak", nil, true},
+		{"space in value", "kind=water leak", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSelector(tt.selector)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	labels := map[string]string{"kind": "leak", "floor": "2"}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"kind=leak", true},
+		{"kind=leak,floor!=0", true},
+		{"kind=smoke", false},
+		{"floor!=2", false},
+		{"zone!=north", true},
+		{"floor", true},
+		{"zone", false},
+		{"!zone", true},
+		{"!floor", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := ParseSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, selector.Matches(labels))
+		})
+	}
+}
+
+func TestSelector_String(t *testing.T) {
+	selector, err := ParseSelector("kind==leak, floor!=0,battery,!muted")
+	require.NoError(t, err)
+	assert.Equal(t, "kind=leak,floor!=0,battery,!muted", selector.String())
+}
+
+func TestValidateLabels(t *testing.T) {
+	assert.NoError(t, ValidateLabels(map[string]string{"kind": "leak", "example.com/zone": "", "floor": "2"}))
+	assert.Error(t, ValidateLabels(map[string]string{"": "leak"}))
+	assert.Error(t, ValidateLabels(map[string]string{"kind": "a/b"}))
+	assert.Error(t, ValidateLabels(map[string]string{"kind,floor": "1"}))
+}
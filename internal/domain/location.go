@@ -0,0 +1,72 @@
+package domain
+
+// LocationKind - уровень локации в иерархии дом → этаж → комната
+type LocationKind string
+
+const (
+	LocationKindHome  LocationKind = "home"
+	LocationKindFloor LocationKind = "floor"
+	LocationKindRoom  LocationKind = "room"
+)
+
+// Location - структура для хранения локации
+// ParentID - id родительской локации, 0 у домов
+type Location struct {
+	ID       int64
+	ParentID int64
+	Kind     LocationKind
+	Name     string
+}
+
+// LocationAccess - структура для связи пользователя и локации
+// Доступ к локации дает доступ ко всем ее дочерним локациям и к датчикам в них.
+type LocationAccess struct {
+	UserID     int64
+	LocationID int64
+}
+
+// parentKinds - допустимые уровни родительской локации, комнаты могут располагаться прямо в доме
+var parentKinds = map[LocationKind][]LocationKind{
+	LocationKindHome:  nil,
+	LocationKindFloor: {LocationKindHome},
+	LocationKindRoom:  {LocationKindHome, LocationKindFloor},
+}
+
+// IsValid - является ли уровень локации известным
+func (k LocationKind) IsValid() bool {
+	_, ok := parentKinds[k]
+	return ok
+}
+
+// CanContain - может ли локация уровня k быть родительской для локации уровня child
+func (k LocationKind) CanContain(child LocationKind) bool {
+	for _, parent := range parentKinds[child] {
+		if parent == k {
+			return true
+		}
+	}
+	return false
+}
+
+// LocationSubtree - id локаций roots и всех их потомков среди locations
+func LocationSubtree(locations []Location, roots ...int64) map[int64]struct{} {
+	children := make(map[int64][]int64, len(locations))
+	for _, l := range locations {
+		if l.ParentID != 0 {
+			children[l.ParentID] = append(children[l.ParentID], l.ID)
+		}
+	}
+
+	subtree := make(map[int64]struct{}, len(roots))
+	queue := append([]int64(nil), roots...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := subtree[id]; ok {
+			continue
+		}
+		subtree[id] = struct{}{}
+		queue = append(queue, children[id]...)
+	}
+	return subtree
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocationKind_CanContain(t *testing.T) {
+	tests := []struct {
+		parent LocationKind
+		child  LocationKind
+		want   bool
+	}{
+		{LocationKindHome, LocationKindFloor, true},
+		{LocationKindHome, LocationKindRoom, true},
+		{LocationKindFloor, LocationKindRoom, true},
+		{LocationKindHome, LocationKindHome, false},
+		{LocationKindFloor, LocationKindFloor, false},
+		{LocationKindRoom, LocationKindRoom, false},
+		{LocationKindRoom, LocationKindFloor, false},
+		{LocationKindHome, "garage", false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.parent)+"/"+string(tt.child), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.parent.CanContain(tt.child))
+		})
+	}
+}
+
+func TestLocationSubtree(t *testing.T) {
+	locations := []Location{
+		{ID: 1, Kind: LocationKindHome},
+		{ID: 2, ParentID: 1, Kind: LocationKindFloor},
+		{ID: 3, ParentID: 2, Kind: LocationKindRoom},
+		{ID: 4, ParentID: 1, Kind: LocationKindRoom},
+		{ID: 5, Kind: LocationKindHome},
+		{ID: 6, ParentID: 5, Kind: LocationKindRoom},
+	}
+
+	assert.Equal(t, map[int64]struct{}{1: {}, 2: {}, 3: {}, 4: {}}, LocationSubtree(locations, 1))
+	assert.Equal(t, map[int64]struct{}{2: {}, 3: {}, 6: {}}, LocationSubtree(locations, 2, 6, 3))
+	assert.Empty(t, LocationSubtree(locations))
+}
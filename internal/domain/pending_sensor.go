@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// PendingSensor - датчик с неизвестным серийным номером, приславший события в режиме автоподключения,
+// ожидает подключения администратором или владельцем
+// Seen - сколько событий прислал датчик, Buffered - сколько первых из них сохранено для переноса в историю
+// Events - сохраненные события в порядке получения, заполняются только при получении датчика по серийному номеру
+type PendingSensor struct {
+	SerialNumber string
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	Seen         int64
+	Buffered     int64
+	Events       []Event
+}
@@ -9,16 +9,64 @@ type SensorType string
 const (
 	SensorTypeContactClosure SensorType = "cc"
 	SensorTypeADC            SensorType = "adc"
+	SensorTypeTemperature    SensorType = "temperature"
+	SensorTypeHumidity       SensorType = "humidity"
+	SensorTypeMotion         SensorType = "motion"
+	SensorTypePowerMeter     SensorType = "power_meter"
 )
 
+// Channel - именованный канал многоканального датчика, Type определяет интерпретацию его значений,
+// Calibration - преобразование сырых показаний канала
+type Channel struct {
+	Name        string      `json:"name"`
+	Type        SensorType  `json:"type"`
+	Calibration Calibration `json:"calibration,omitempty"`
+}
+
 // Sensor - структура для хранения данных датчика
+// Channels - каналы датчика, пусто у одноканальных датчиков, передающих единственное значение в Payload
+// CurrentValues - последние значения по каналам, CurrentState соответствует первому каналу
+// Calibration - преобразование сырых показаний одноканального датчика, у многоканальных задается по каналам
+// LocationID - id локации, в которой установлен датчик, 0 если не задана
+// Labels - произвольные метки датчика для выборки селекторами
+// Version - номер версии, увеличивается репозиторием при каждом сохранении датчика
 type Sensor struct {
-	ID           int64
-	SerialNumber string
-	Type         SensorType
-	CurrentState int64
-	Description  string
-	IsActive     bool
-	RegisteredAt time.Time
-	LastActivity time.Time
+	ID            int64
+	SerialNumber  string
+	Type          SensorType
+	CurrentState  int64
+	Description   string
+	IsActive      bool
+	RegisteredAt  time.Time
+	LastActivity  time.Time
+	Channels      []Channel
+	CurrentValues map[string]int64
+	Calibration   Calibration
+	LocationID    int64
+	Labels        map[string]string
+	Version       int64
+}
+
+// Channel - канал датчика по имени, false если такого канала нет
+func (s *Sensor) Channel(name string) (Channel, bool) {
+	for _, c := range s.Channels {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Channel{}, false
+}
+
+// IsMultiChannel - объявлены ли у датчика каналы
+func (s *Sensor) IsMultiChannel() bool {
+	return len(s.Channels) > 0
+}
+
+// CalibrationDepth - сколько предыдущих показаний нужно для калибровки датчика и всех его каналов
+func (s *Sensor) CalibrationDepth() int {
+	depth := s.Calibration.Depth()
+	for _, c := range s.Channels {
+		depth = max(depth, c.Calibration.Depth())
+	}
+	return depth
 }
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"math"
+	"sort"
+)
+
+// ValueRange - диапазон допустимых показаний датчика в единицах измерения, границы включаются
+type ValueRange struct {
+	Min float64
+	Max float64
+}
+
+// SensorTypeInfo - метаданные типа датчика, описывающие, как интерпретировать сырое значение payload.
+// Значение в единицах измерения вычисляется как payload*Scale + Offset и округляется до Precision знаков.
+type SensorTypeInfo struct {
+	Type        SensorType
+	Description string
+	Unit        string
+	Scale       float64
+	Offset      float64
+	// Range - диапазон допустимых значений, показания вне него отклоняются; nil - без ограничений
+	Range     *ValueRange
+	Precision int
+}
+
+// Value - перевод сырого значения payload в единицы измерения
+func (i SensorTypeInfo) Value(raw int64) float64 {
+	value := float64(raw)*i.Scale + i.Offset
+	pow := math.Pow10(i.Precision)
+	return math.Round(value*pow) / pow
+}
+
+// InRange - является ли сырое значение payload допустимым показанием датчика этого типа
+func (i SensorTypeInfo) InRange(raw int64) bool {
+	if i.Range == nil {
+		return true
+	}
+	value := i.Value(raw)
+	return value >= i.Range.Min && value <= i.Range.Max
+}
+
+// sensorTypes - встроенные типы датчиков.
+// cc и adc передают сырые значения (счётчики, коды АЦП) и не проверяются на диапазон.
+var sensorTypes = map[SensorType]SensorTypeInfo{
+	SensorTypeContactClosure: {
+		Type: SensorTypeContactClosure, Description: "Сухой контакт", Scale: 1,
+	},
+	SensorTypeADC: {
+		Type: SensorTypeADC, Description: "Аналогово-цифровой преобразователь", Scale: 1,
+	},
+	SensorTypeTemperature: {
+		Type: SensorTypeTemperature, Description: "Датчик температуры", Unit: "°C", Scale: 0.01,
+		Range: &ValueRange{Min: -40, Max: 125}, Precision: 2,
+	},
+	SensorTypeHumidity: {
+		Type: SensorTypeHumidity, Description: "Датчик относительной влажности", Unit: "%", Scale: 0.01,
+		Range: &ValueRange{Min: 0, Max: 100}, Precision: 2,
+	},
+	SensorTypeMotion: {
+		Type: SensorTypeMotion, Description: "Датчик движения", Scale: 1,
+		Range: &ValueRange{Min: 0, Max: 1},
+	},
+	SensorTypePowerMeter: {
+		Type: SensorTypePowerMeter, Description: "Счётчик мощности", Unit: "W", Scale: 0.1,
+		Range: &ValueRange{Min: 0, Max: 100_000}, Precision: 1,
+	},
+}
+
+// LookupSensorType - метаданные типа датчика, false если тип неизвестен
+func LookupSensorType(t SensorType) (SensorTypeInfo, bool) {
+	info, ok := sensorTypes[t]
+	return info, ok
+}
+
+// SensorTypes - все поддерживаемые типы датчиков, упорядоченные по названию
+func SensorTypes() []SensorTypeInfo {
+	res := make([]SensorTypeInfo, 0, len(sensorTypes))
+	for _, info := range sensorTypes {
+		res = append(res, info)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Type < res[j].Type })
+	return res
+}
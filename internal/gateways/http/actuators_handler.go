@@ -0,0 +1,418 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/codec"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/metrics"
+	"homework/internal/usecase"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+)
+
+func actuatorGetImpl(actuator *domain.Actuator) dtos.Actuator {
+	registeredAt := strfmt.DateTime(actuator.RegisteredAt)
+	actuatorType := string(actuator.Type)
+	return dtos.Actuator{
+		Description:  &actuator.Description,
+		ID:           &actuator.ID,
+		RegisteredAt: &registeredAt,
+		SerialNumber: &actuator.SerialNumber,
+		Type:         &actuatorType,
+	}
+}
+
+func commandGetImpl(command *domain.Command) dtos.Command {
+	action := string(command.Action)
+	status := string(command.Status)
+	createdAt := strfmt.DateTime(command.CreatedAt)
+	updatedAt := strfmt.DateTime(command.UpdatedAt)
+	expiresAt := strfmt.DateTime(command.ExpiresAt)
+	return dtos.Command{
+		Action:     &action,
+		ActuatorID: &command.ActuatorID,
+		CreatedAt:  &createdAt,
+		Error:      command.Error,
+		ExpiresAt:  &expiresAt,
+		ID:         &command.ID,
+		Status:     &status,
+		UpdatedAt:  &updatedAt,
+		Value:      command.Value,
+	}
+}
+
+func commandDtos(commands []domain.Command) []dtos.Command {
+	res := make([]dtos.Command, 0, len(commands))
+	for _, c := range commands {
+		res = append(res, commandGetImpl(&c))
+	}
+	return res
+}
+
+// abortWithCommandError - maps the actuator usecase errors to the response codes
+func abortWithCommandError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrActuatorNotFound), errors.Is(err, usecase.ErrCommandNotFound):
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, usecase.ErrInvalidCommand), errors.Is(err, usecase.ErrWrongActuatorSN),
+		errors.Is(err, usecase.ErrWrongActuatorType):
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, usecase.ErrCommandConflict):
+		abortWithAPIError(ctx, http.StatusConflict, err)
+	default:
+		abortWithError(ctx, err)
+	}
+}
+
+func actuatorsGetImpl(ctx *gin.Context, uc UseCases) any {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	actuators, err := uc.Actuator.GetActuators(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	actuatorDtos := make([]dtos.Actuator, 0, len(actuators))
+	for _, a := range actuators {
+		actuatorDtos = append(actuatorDtos, actuatorGetImpl(&a))
+	}
+	return listBody(ctx, actuatorDtos, func(a dtos.Actuator) int64 { return *a.ID })
+}
+
+func actuatorsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actuatorDtos := actuatorsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, actuatorDtos)
+		}
+	}
+}
+
+func actuatorsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actuatorDtos := actuatorsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, actuatorDtos)
+		}
+	}
+}
+
+func actuatorsPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actuatorDto := &dtos.ActuatorToCreate{}
+		if extractDto(ctx, actuatorDto) == nil {
+			actuator := domain.Actuator{
+				SerialNumber: *actuatorDto.SerialNumber,
+				Type:         domain.ActuatorType(*actuatorDto.Type),
+				Description:  actuatorDto.Description,
+				RegisteredAt: time.Now(),
+			}
+
+			registered, err := uc.Actuator.RegisterActuator(ctx, &actuator)
+			if err != nil {
+				abortWithCommandError(ctx, err)
+				return
+			}
+
+			abortWithStatusDto(ctx, http.StatusOK, actuatorGetImpl(registered))
+		}
+	}
+}
+
+func actuatorIdParam(ctx *gin.Context) (int64, bool) {
+	actuatorId, err := strconv.ParseInt(ctx.Param("actuator_id"), 10, 64)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return 0, false
+	}
+	return actuatorId, true
+}
+
+func actuatorByIdCommonHandler(ctx *gin.Context, uc UseCases) *domain.Actuator {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	actuatorId, ok := actuatorIdParam(ctx)
+	if !ok {
+		return nil
+	}
+
+	actuator, err := uc.Actuator.GetActuatorByID(ctx, actuatorId)
+	if errors.Is(err, usecase.ErrActuatorNotFound) {
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+		return nil
+	} else if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	return actuator
+}
+
+func actuatorByIdGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actuator := actuatorByIdCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, actuatorGetImpl(actuator))
+		}
+	}
+}
+
+func actuatorByIdHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actuator := actuatorByIdCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, actuatorGetImpl(actuator))
+		}
+	}
+}
+
+func commandsGetImpl(ctx *gin.Context, uc UseCases) []dtos.Command {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	actuatorId, ok := actuatorIdParam(ctx)
+	if !ok {
+		return nil
+	}
+
+	commands, err := uc.Actuator.GetCommands(ctx, actuatorId)
+	if err != nil {
+		abortWithCommandError(ctx, err)
+		return nil
+	}
+	return commandDtos(commands)
+}
+
+func commandsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		commandDtos := commandsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, commandDtos)
+		}
+	}
+}
+
+func commandsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		commandDtos := commandsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, commandDtos)
+		}
+	}
+}
+
+func commandsPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actuatorId, ok := actuatorIdParam(ctx)
+		if !ok {
+			return
+		}
+
+		commandDto := &dtos.CommandToCreate{}
+		if extractDto(ctx, commandDto) == nil {
+			command := domain.Command{
+				Action: domain.CommandAction(*commandDto.Action),
+				Value:  commandDto.Value,
+			}
+
+			queued, err := uc.Actuator.EnqueueCommand(ctx, actuatorId, &command, time.Duration(commandDto.TTLSeconds)*time.Second)
+			if err != nil {
+				abortWithCommandError(ctx, err)
+				return
+			}
+
+			abortWithStatusDto(ctx, http.StatusCreated, commandGetImpl(queued))
+		}
+	}
+}
+
+func commandsPollHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := isFormatSupported(ctx); err != nil {
+			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+			return
+		}
+
+		actuatorId, ok := actuatorIdParam(ctx)
+		if !ok {
+			return
+		}
+
+		commands, err := uc.Actuator.PollCommands(ctx, actuatorId)
+		if err != nil {
+			abortWithCommandError(ctx, err)
+			return
+		}
+		abortWithStatusDto(ctx, http.StatusOK, commandDtos(commands))
+	}
+}
+
+func commandByIdGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := isFormatSupported(ctx); err != nil {
+			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+			return
+		}
+
+		actuatorId, ok := actuatorIdParam(ctx)
+		if !ok {
+			return
+		}
+		commandId, err := strconv.ParseInt(ctx.Param("command_id"), 10, 64)
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		command, err := uc.Actuator.GetCommandByID(ctx, actuatorId, commandId)
+		if err != nil {
+			abortWithCommandError(ctx, err)
+			return
+		}
+		abortWithStatusDto(ctx, http.StatusOK, commandGetImpl(command))
+	}
+}
+
+func commandAckHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actuatorId, ok := actuatorIdParam(ctx)
+		if !ok {
+			return
+		}
+		commandId, err := strconv.ParseInt(ctx.Param("command_id"), 10, 64)
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		ackDto := &dtos.CommandAck{}
+		if extractDto(ctx, ackDto) == nil {
+			command, err := uc.Actuator.AckCommand(ctx, actuatorId, commandId, domain.CommandStatus(*ackDto.Status), ackDto.Error)
+			if err != nil {
+				abortWithCommandError(ctx, err)
+				return
+			}
+			abortWithStatusDto(ctx, http.StatusOK, commandGetImpl(command))
+		}
+	}
+}
+
+// commandChannelAdapter - encodes the commands marking them delivered, the ones delivered or finished
+// meanwhile are skipped
+func commandChannelAdapter(ctx context.Context, uc UseCases, logger *slog.Logger, c codec.Codec, cc <-chan domain.Command) <-chan wsMessage {
+	out := make(chan wsMessage)
+	go func() {
+		defer close(out)
+		for command := range cc {
+			if err := uc.Actuator.MarkDelivered(ctx, &command); err != nil {
+				if !errors.Is(err, usecase.ErrCommandConflict) {
+					logger.ErrorContext(ctx, "unable to mark command delivered", "command_id", command.ID, "error", err)
+				}
+				continue
+			}
+			encoded, err := c.Marshal(commandGetImpl(&command))
+			if err != nil {
+				metrics.WSMessageDropped(metrics.DropReasonEncodeError)
+				logger.ErrorContext(ctx, "unable to encode command", "command_id", command.ID, "error", err)
+				return
+			}
+			select {
+			case out <- wsMessage{data: encoded}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func commandsSubscribeHandler(uc UseCases, ws *WebSocketHandler) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := isFormatSupported(ctx); err != nil {
+			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+			return
+		}
+
+		actuatorId, ok := actuatorIdParam(ctx)
+		if !ok {
+			return
+		}
+
+		subscription, err := uc.Actuator.SubscribeCommands(ctx, actuatorId)
+		if err != nil {
+			abortWithCommandError(ctx, err)
+			return
+		}
+
+		defer func() {
+			err := uc.Actuator.UnsubscribeCommands(ctx, actuatorId, subscription.Id)
+			if err != nil {
+				ws.logger.ErrorContext(ctx, "unable to unsubscribe",
+					"subscription_id", subscription.Id, "actuator_id", actuatorId, "error", err)
+			}
+		}()
+
+		// The commands queued before the subscription are sent first, the ones queued in between
+		// come twice and are sent once as only one of them is marked delivered
+		pending, err := uc.Actuator.GetPendingCommands(ctx, actuatorId)
+		if err != nil {
+			abortWithCommandError(ctx, err)
+			return
+		}
+		backlog := make(chan domain.Command, len(pending))
+		for _, c := range pending {
+			backlog <- c
+		}
+		close(backlog)
+
+		commands := mergeChannels(backlog, subscription.SubscriptionReadHandle.Ch)
+		err = ws.HandleSubscription(ctx, commandChannelAdapter(ctx, uc, ws.logger, responseCodec(ctx), commands))
+		if err != nil && !errors.Is(err, context.Canceled) {
+			ws.logger.ErrorContext(ctx, "error processing commands subscription", "actuator_id", actuatorId, "error", err)
+			return
+		}
+	}
+}
+
+func setupActuatorsHandler(r *gin.RouterGroup, uc UseCases, ws *WebSocketHandler) {
+	r.GET("", actuatorsGetHandler(uc))
+	r.HEAD("", actuatorsHeadHandler(uc))
+	r.POST("", actuatorsPostHandler(uc))
+	r.OPTIONS("", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPost))
+
+	r.GET("/:actuator_id", actuatorByIdGetHandler(uc))
+	r.HEAD("/:actuator_id", actuatorByIdHeadHandler(uc))
+	r.OPTIONS("/:actuator_id", optionsHandler(http.MethodGet, http.MethodHead))
+
+	r.GET("/:actuator_id/commands", commandsGetHandler(uc))
+	r.HEAD("/:actuator_id/commands", commandsHeadHandler(uc))
+	r.POST("/:actuator_id/commands", commandsPostHandler(uc))
+	r.OPTIONS("/:actuator_id/commands", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPost))
+
+	r.POST("/:actuator_id/commands/poll", commandsPollHandler(uc))
+	r.OPTIONS("/:actuator_id/commands/poll", optionsHandler(http.MethodPost))
+
+	r.GET("/:actuator_id/commands/events", commandsSubscribeHandler(uc, ws))
+
+	r.GET("/:actuator_id/commands/:command_id", commandByIdGetHandler(uc))
+	r.OPTIONS("/:actuator_id/commands/:command_id", optionsHandler(http.MethodGet))
+
+	r.POST("/:actuator_id/commands/:command_id/ack", commandAckHandler(uc))
+	r.OPTIONS("/:actuator_id/commands/:command_id/ack", optionsHandler(http.MethodPost))
+}
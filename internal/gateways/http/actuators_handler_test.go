@@ -0,0 +1,106 @@
+package http
+
+import (
+	"encoding/json"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	actuatorInMemory "homework/internal/repository/actuator/inmemory"
+	subscriptionInMemory "homework/internal/repository/subscription/inmemory"
+)
+
+func actuatorsRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	uc := UseCases{
+		Actuator: usecase.NewActuator(
+			actuatorInMemory.NewActuatorRepository(),
+			actuatorInMemory.NewCommandRepository(),
+			subscriptionInMemory.NewSubscriptionRepository[domain.Command](),
+		),
+	}
+
+	r := gin.New()
+	setupActuatorsHandler(r.Group("/actuators"), uc, nil)
+	return r
+}
+
+func serveJSON(r *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", JSONType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestActuatorCommands(t *testing.T) {
+	r := actuatorsRouter(t)
+
+	w := serveJSON(r, http.MethodPost, "/actuators", `{"serial_number":"1234567890","type":"dimmer"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var actuator dtos.Actuator
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actuator))
+	require.Equal(t, int64(1), *actuator.ID)
+
+	t.Run("fail, invalid actuator type", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/actuators", `{"serial_number":"1234567891","type":"pump"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("fail, action not accepted", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/actuators/1/commands", `{"action":"open"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("fail, actuator not found", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/actuators/2/commands", `{"action":"on"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("ok, enqueue, poll and ack", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/actuators/1/commands", `{"action":"set","value":40,"ttl_seconds":30}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var command dtos.Command
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &command))
+		assert.Equal(t, dtos.CommandStatusPending, *command.Status)
+
+		w = serveJSON(r, http.MethodPost, "/actuators/1/commands/poll", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var polled []dtos.Command
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &polled))
+		require.Len(t, polled, 1)
+		assert.Equal(t, *command.ID, *polled[0].ID)
+		assert.Equal(t, dtos.CommandStatusDelivered, *polled[0].Status)
+
+		w = serveJSON(r, http.MethodPost, "/actuators/1/commands/poll", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, "[]", w.Body.String())
+
+		w = serveJSON(r, http.MethodPost, "/actuators/1/commands/1/ack", `{"status":"failed","error":"overheated"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = serveJSON(r, http.MethodPost, "/actuators/1/commands/1/ack", `{"status":"acked"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = serveJSON(r, http.MethodGet, "/actuators/1/commands/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &command))
+		assert.Equal(t, dtos.CommandStatusFailed, *command.Status)
+		assert.Equal(t, "overheated", command.Error)
+	})
+
+	t.Run("fail, command of another actuator", func(t *testing.T) {
+		w := serveJSON(r, http.MethodGet, "/actuators/2/commands/1", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrAdminUnauthorized = errors.New("valid admin bearer token required")
+	errConfigUnavailable = errors.New("effective configuration is not available")
+)
+
+// adminAuthMiddleware - requires the bearer token, every request is rejected if the token is empty
+func adminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		got, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			ctx.Header("WWW-Authenticate", "Bearer")
+			abortWithAPIError(ctx, http.StatusUnauthorized, ErrAdminUnauthorized)
+		}
+	}
+}
+
+func adminConfigGetHandler(cfg any) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := isFormatSupported(ctx); err != nil {
+			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+			return
+		}
+		if cfg == nil {
+			abortWithAPIError(ctx, http.StatusNotFound, errConfigUnavailable)
+			return
+		}
+
+		abortWithStatusDto(ctx, http.StatusOK, cfg)
+	}
+}
+
+func setupAdminHandler(r *gin.RouterGroup, s *Server, uc UseCases) {
+	r.Use(adminAuthMiddleware(s.adminToken))
+
+	r.GET("/config", adminConfigGetHandler(s.effectiveConfig))
+	r.OPTIONS("/config", optionsHandler(http.MethodGet))
+
+	setupJobsHandler(r.Group("/jobs"), uc)
+	setupBackupHandler(r.Group("/backup"), s)
+	setupPendingSensorsHandler(r.Group("/pending-sensors"), uc)
+}
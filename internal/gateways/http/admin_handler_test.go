@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	get := func(s *Server, authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/config", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("ok, valid token", func(t *testing.T) {
+		s := NewServer(UseCases{}, WithAdminToken("secret"), WithEffectiveConfig(map[string]string{}))
+		assert.Equal(t, http.StatusOK, get(s, "Bearer secret"))
+	})
+
+	t.Run("fail, wrong token", func(t *testing.T) {
+		s := NewServer(UseCases{}, WithAdminToken("secret"))
+		assert.Equal(t, http.StatusUnauthorized, get(s, "Bearer other"))
+		assert.Equal(t, http.StatusUnauthorized, get(s, ""))
+	})
+
+	t.Run("fail, no token configured", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get(NewServer(UseCases{}), "Bearer "))
+	})
+
+	t.Run("fail, middleware with empty token", func(t *testing.T) {
+		r := gin.New()
+		r.GET("/", adminAuthMiddleware(""), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,347 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+)
+
+const maxExecutionsLimit = 1000
+
+func automationRuleGetImpl(rule *domain.AutomationRule) dtos.AutomationRule {
+	createdAt := strfmt.DateTime(rule.CreatedAt)
+	trigger := string(rule.Trigger.Kind)
+	dto := dtos.AutomationRule{
+		Actions:         make([]*dtos.AutomationAction, 0, len(rule.Actions)),
+		Conditions:      make([]*dtos.AutomationCondition, 0, len(rule.Conditions)),
+		CooldownSeconds: int64(rule.Cooldown / time.Second),
+		CreatedAt:       &createdAt,
+		Enabled:         &rule.Enabled,
+		ID:              &rule.ID,
+		Name:            &rule.Name,
+		Trigger: &dtos.AutomationTrigger{
+			AfterSeconds: int64(rule.Trigger.After / time.Second),
+			Channel:      rule.Trigger.Channel,
+			EverySeconds: int64(rule.Trigger.Every / time.Second),
+			Kind:         &trigger,
+			Operator:     string(rule.Trigger.Operator),
+			SensorID:     rule.Trigger.SensorID,
+			Value:        rule.Trigger.Value,
+		},
+	}
+	for _, c := range rule.Conditions {
+		kind := string(c.Kind)
+		dto.Conditions = append(dto.Conditions, &dtos.AutomationCondition{
+			From:     c.From,
+			Kind:     &kind,
+			Operator: string(c.Operator),
+			SensorID: c.SensorID,
+			To:       c.To,
+			Value:    c.Value,
+		})
+	}
+	for _, a := range rule.Actions {
+		kind := string(a.Kind)
+		dto.Actions = append(dto.Actions, &dtos.AutomationAction{
+			ActuatorID: a.ActuatorID,
+			Command:    string(a.Command),
+			Kind:       &kind,
+			Message:    a.Message,
+			URL:        a.URL,
+			Value:      a.Value,
+		})
+	}
+	return dto
+}
+
+// automationRuleFromDto - the rule is enabled unless the opposite is requested explicitly
+func automationRuleFromDto(dto *dtos.AutomationRuleToCreate) domain.AutomationRule {
+	rule := domain.AutomationRule{
+		Name:     *dto.Name,
+		Enabled:  dto.Enabled == nil || *dto.Enabled,
+		Cooldown: time.Duration(dto.CooldownSeconds) * time.Second,
+		Trigger: domain.Trigger{
+			Kind:     domain.TriggerKind(*dto.Trigger.Kind),
+			SensorID: dto.Trigger.SensorID,
+			Channel:  dto.Trigger.Channel,
+			Operator: domain.CompareOperator(dto.Trigger.Operator),
+			Value:    dto.Trigger.Value,
+			Every:    time.Duration(dto.Trigger.EverySeconds) * time.Second,
+			After:    time.Duration(dto.Trigger.AfterSeconds) * time.Second,
+		},
+	}
+	for _, c := range dto.Conditions {
+		if c == nil {
+			continue
+		}
+		rule.Conditions = append(rule.Conditions, domain.Condition{
+			Kind:     domain.ConditionKind(*c.Kind),
+			From:     c.From,
+			To:       c.To,
+			SensorID: c.SensorID,
+			Operator: domain.CompareOperator(c.Operator),
+			Value:    c.Value,
+		})
+	}
+	for _, a := range dto.Actions {
+		if a == nil {
+			continue
+		}
+		rule.Actions = append(rule.Actions, domain.Action{
+			Kind:       domain.ActionKind(*a.Kind),
+			ActuatorID: a.ActuatorID,
+			Command:    domain.CommandAction(a.Command),
+			Value:      a.Value,
+			URL:        a.URL,
+			Message:    a.Message,
+		})
+	}
+	return rule
+}
+
+func automationExecutionGetImpl(execution *domain.AutomationExecution) dtos.AutomationExecution {
+	depth := int64(execution.Depth)
+	startedAt := strfmt.DateTime(execution.StartedAt)
+	status := string(execution.Status)
+	trigger := string(execution.Trigger)
+	return dtos.AutomationExecution{
+		Depth:     &depth,
+		ID:        &execution.ID,
+		Reason:    execution.Reason,
+		RuleID:    &execution.RuleID,
+		StartedAt: &startedAt,
+		Status:    &status,
+		Trigger:   &trigger,
+	}
+}
+
+// abortWithAutomationError - maps the automation usecase errors to the response codes
+func abortWithAutomationError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrRuleNotFound):
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, usecase.ErrInvalidRule):
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+	default:
+		abortWithError(ctx, err)
+	}
+}
+
+func automationsGetImpl(ctx *gin.Context, uc UseCases) any {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	rules, err := uc.Automation.GetRules(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	ruleDtos := make([]dtos.AutomationRule, 0, len(rules))
+	for _, r := range rules {
+		ruleDtos = append(ruleDtos, automationRuleGetImpl(&r))
+	}
+	return listBody(ctx, ruleDtos, func(r dtos.AutomationRule) int64 { return *r.ID })
+}
+
+func automationsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ruleDtos := automationsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, ruleDtos)
+		}
+	}
+}
+
+func automationsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ruleDtos := automationsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, ruleDtos)
+		}
+	}
+}
+
+func automationsPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ruleDto := &dtos.AutomationRuleToCreate{}
+		if extractDto(ctx, ruleDto) == nil {
+			rule := automationRuleFromDto(ruleDto)
+
+			created, err := uc.Automation.CreateRule(ctx, &rule)
+			if err != nil {
+				abortWithAutomationError(ctx, err)
+				return
+			}
+
+			abortWithStatusDto(ctx, http.StatusCreated, automationRuleGetImpl(created))
+		}
+	}
+}
+
+func ruleIdParam(ctx *gin.Context) (int64, bool) {
+	ruleId, err := strconv.ParseInt(ctx.Param("rule_id"), 10, 64)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return 0, false
+	}
+	return ruleId, true
+}
+
+func automationByIdCommonHandler(ctx *gin.Context, uc UseCases) *domain.AutomationRule {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	ruleId, ok := ruleIdParam(ctx)
+	if !ok {
+		return nil
+	}
+
+	rule, err := uc.Automation.GetRuleByID(ctx, ruleId)
+	if errors.Is(err, usecase.ErrRuleNotFound) {
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+		return nil
+	} else if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	return rule
+}
+
+func automationByIdGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rule := automationByIdCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, automationRuleGetImpl(rule))
+		}
+	}
+}
+
+func automationByIdHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rule := automationByIdCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, automationRuleGetImpl(rule))
+		}
+	}
+}
+
+func automationByIdPutHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ruleId, ok := ruleIdParam(ctx)
+		if !ok {
+			return
+		}
+
+		ruleDto := &dtos.AutomationRuleToCreate{}
+		if extractDto(ctx, ruleDto) == nil {
+			rule := automationRuleFromDto(ruleDto)
+			rule.ID = ruleId
+
+			updated, err := uc.Automation.UpdateRule(ctx, &rule)
+			if err != nil {
+				abortWithAutomationError(ctx, err)
+				return
+			}
+
+			abortWithStatusDto(ctx, http.StatusOK, automationRuleGetImpl(updated))
+		}
+	}
+}
+
+func automationByIdDeleteHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ruleId, ok := ruleIdParam(ctx)
+		if !ok {
+			return
+		}
+
+		if err := uc.Automation.DeleteRule(ctx, ruleId); err != nil {
+			abortWithAutomationError(ctx, err)
+			return
+		}
+
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func automationExecutionsGetImpl(ctx *gin.Context, uc UseCases) []dtos.AutomationExecution {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	ruleId, ok := ruleIdParam(ctx)
+	if !ok {
+		return nil
+	}
+
+	limit := 0
+	if limitQ, ok := ctx.GetQuery("limit"); ok {
+		var err error
+		if limit, err = strconv.Atoi(limitQ); err != nil || limit < 1 || limit > maxExecutionsLimit {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, fmt.Errorf("limit must be an integer in [1, %d]", maxExecutionsLimit))
+			return nil
+		}
+	}
+
+	executions, err := uc.Automation.GetExecutions(ctx, ruleId, limit)
+	if err != nil {
+		abortWithAutomationError(ctx, err)
+		return nil
+	}
+
+	executionDtos := make([]dtos.AutomationExecution, 0, len(executions))
+	for _, e := range executions {
+		executionDtos = append(executionDtos, automationExecutionGetImpl(&e))
+	}
+	return executionDtos
+}
+
+func automationExecutionsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		executionDtos := automationExecutionsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, executionDtos)
+		}
+	}
+}
+
+func automationExecutionsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		executionDtos := automationExecutionsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, executionDtos)
+		}
+	}
+}
+
+func setupAutomationsHandler(r *gin.RouterGroup, uc UseCases) {
+	r.GET("", automationsGetHandler(uc))
+	r.HEAD("", automationsHeadHandler(uc))
+	r.POST("", automationsPostHandler(uc))
+	r.OPTIONS("", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPost))
+
+	r.GET("/:rule_id", automationByIdGetHandler(uc))
+	r.HEAD("/:rule_id", automationByIdHeadHandler(uc))
+	r.PUT("/:rule_id", automationByIdPutHandler(uc))
+	r.DELETE("/:rule_id", automationByIdDeleteHandler(uc))
+	r.OPTIONS("/:rule_id", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete))
+
+	r.GET("/:rule_id/executions", automationExecutionsGetHandler(uc))
+	r.HEAD("/:rule_id/executions", automationExecutionsHeadHandler(uc))
+	r.OPTIONS("/:rule_id/executions", optionsHandler(http.MethodGet, http.MethodHead))
+}
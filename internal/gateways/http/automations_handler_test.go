@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	automationInMemory "homework/internal/repository/automation/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+)
+
+func automationsRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	uc := UseCases{
+		Automation: usecase.NewAutomation(
+			automationInMemory.NewAutomationRepository(),
+			sensorInMemory.NewSensorRepository(),
+			nil,
+			nil,
+		),
+	}
+
+	r := gin.New()
+	setupAutomationsHandler(r.Group("/automations"), uc)
+	return r
+}
+
+func TestAutomationRules(t *testing.T) {
+	r := automationsRouter(t)
+
+	w := serveJSON(r, http.MethodPost, "/automations",
+		`{"name":"hourly","trigger":{"kind":"schedule","every_seconds":3600},"actions":[{"kind":"alert","message":"tick"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var rule dtos.AutomationRule
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rule))
+	require.Equal(t, int64(1), *rule.ID)
+	assert.True(t, *rule.Enabled)
+	assert.Equal(t, int64(3600), rule.Trigger.EverySeconds)
+
+	t.Run("fail, invalid rule", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/automations",
+			`{"name":"hook","trigger":{"kind":"schedule","every_seconds":3600},"actions":[{"kind":"webhook","url":"/hook"}]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = serveJSON(r, http.MethodPost, "/automations",
+			`{"name":"missing","trigger":{"kind":"offline","sensor_id":5,"after_seconds":600},"actions":[{"kind":"alert","message":"down"}]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("ok, update and read", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPut, "/automations/1",
+			`{"name":"daily","enabled":false,"cooldown_seconds":60,"trigger":{"kind":"schedule","every_seconds":86400},"actions":[{"kind":"alert","message":"tock"}]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = serveJSON(r, http.MethodGet, "/automations/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var updated dtos.AutomationRule
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		assert.Equal(t, "daily", *updated.Name)
+		assert.False(t, *updated.Enabled)
+		assert.Equal(t, int64(60), updated.CooldownSeconds)
+		assert.Equal(t, rule.CreatedAt.String(), updated.CreatedAt.String())
+
+		w = serveJSON(r, http.MethodGet, "/automations/1/executions?limit=10", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		w = serveJSON(r, http.MethodGet, "/automations/1/executions?limit=0", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("ok, delete", func(t *testing.T) {
+		w := serveJSON(r, http.MethodDelete, "/automations/1", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		for _, target := range []string{"/automations/1", "/automations/1/executions"} {
+			w = serveJSON(r, http.MethodGet, target, "")
+			assert.Equal(t, http.StatusNotFound, w.Code, target)
+		}
+		w = serveJSON(r, http.MethodDelete, "/automations/1", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
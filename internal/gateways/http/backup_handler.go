@@ -0,0 +1,150 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"homework/internal/backup"
+	"homework/internal/gateways/http/dtos"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+)
+
+var (
+	errBackupUnavailable = errors.New("backup is not available")
+	errBackupTooLarge    = errors.New("backup archive is too large")
+)
+
+func backupCountsDto(counts backup.Counts) *dtos.BackupCounts {
+	events := int64(counts.Events)
+	locationAccess := int64(counts.LocationAccess)
+	locations := int64(counts.Locations)
+	sensorOwners := int64(counts.SensorOwners)
+	sensors := int64(counts.Sensors)
+	users := int64(counts.Users)
+	return &dtos.BackupCounts{
+		Events:         &events,
+		LocationAccess: &locationAccess,
+		Locations:      &locations,
+		SensorOwners:   &sensorOwners,
+		Sensors:        &sensors,
+		Users:          &users,
+	}
+}
+
+func backupManifestDto(manifest backup.Manifest) dtos.BackupSummary {
+	createdAt := strfmt.DateTime(manifest.CreatedAt)
+	version := int64(manifest.Version)
+	return dtos.BackupSummary{
+		Archive:   backupCountsDto(manifest.Counts),
+		CreatedAt: &createdAt,
+		Sha256:    &manifest.SHA256,
+		Version:   &version,
+	}
+}
+
+// backupGetHandler - streams the archive of the state, an export failing after the response has started
+// is logged, and the client gets an archive without the trailer failing verification
+func backupGetHandler(archiver *backup.Archiver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if archiver == nil {
+			abortWithAPIError(ctx, http.StatusNotFound, errBackupUnavailable)
+			return
+		}
+
+		fileName := fmt.Sprintf("home-controller-%s.backup.gz", time.Now().UTC().Format("20060102T150405Z"))
+		ctx.Header("Content-Type", backup.ContentType)
+		ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+		ctx.Status(http.StatusOK)
+		if _, err := archiver.Export(ctx, ctx.Writer); err != nil {
+			_ = ctx.Error(fmt.Errorf("can't export backup: %w", err))
+			ctx.Abort()
+		}
+	}
+}
+
+// backupPostHandler - verifies the uploaded archive and restores it unless only the verification is requested.
+// The archive of up to maxBytes is spooled to a temporary file, as the import reads it twice
+func backupPostHandler(archiver *backup.Archiver, maxBytes int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := isFormatSupported(ctx); err != nil {
+			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+			return
+		}
+		if archiver == nil {
+			abortWithAPIError(ctx, http.StatusNotFound, errBackupUnavailable)
+			return
+		}
+		if mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type")); mediaType != backup.ContentType &&
+			mediaType != "application/octet-stream" {
+			err := fmt.Errorf("got %w: %v", UnsupportedContentType, ctx.GetHeader("Content-Type"))
+			abortWithAPIError(ctx, http.StatusUnsupportedMediaType, err)
+			return
+		}
+		verifyOnly := false
+		if verifyQ, ok := ctx.GetQuery("verify"); ok {
+			var err error
+			if verifyOnly, err = strconv.ParseBool(verifyQ); err != nil {
+				abortWithAPIError(ctx, http.StatusUnprocessableEntity, errors.New("verify must be a boolean"))
+				return
+			}
+		}
+
+		f, err := os.CreateTemp("", "backup-*.gz")
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		if _, err := io.Copy(f, http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortWithError(ctx, fmt.Errorf("%w: the limit is %d bytes", errBackupTooLarge, tooLarge.Limit))
+				return
+			}
+			abortWithAPIError(ctx, http.StatusBadRequest, fmt.Errorf("%w: %w", errMalformedBody, err))
+			return
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		if verifyOnly {
+			manifest, err := backup.Verify(f)
+			if err != nil {
+				abortWithError(ctx, err)
+				return
+			}
+			abortWithStatusDto(ctx, http.StatusOK, backupManifestDto(manifest))
+			return
+		}
+		summary, err := archiver.Import(ctx, f)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		dto := backupManifestDto(summary.Manifest)
+		dto.Imported = backupCountsDto(summary.Imported)
+		dto.Skipped = backupCountsDto(summary.Skipped)
+		dto.Remapped = backupCountsDto(summary.Remapped)
+		abortWithStatusDto(ctx, http.StatusOK, dto)
+	}
+}
+
+func setupBackupHandler(r *gin.RouterGroup, s *Server) {
+	r.GET("", backupGetHandler(s.backup))
+	maxBytes := s.backupMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultBackupMaxBytes
+	}
+	r.POST("", backupPostHandler(s.backup, maxBytes))
+	r.OPTIONS("", optionsHandler(http.MethodGet, http.MethodPost))
+}
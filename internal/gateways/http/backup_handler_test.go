@@ -0,0 +1,127 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"homework/internal/backup"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventInMemory "homework/internal/repository/event/inmemory"
+	locationInMemory "homework/internal/repository/location/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+	userInMemory "homework/internal/repository/user/inmemory"
+)
+
+func backupRouter(repos backup.Repositories) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setupBackupHandler(r.Group("/backup"), &Server{backup: backup.New(repos)})
+	return r
+}
+
+func newBackupRepositories() backup.Repositories {
+	return backup.Repositories{
+		Users:        userInMemory.NewUserRepository(),
+		SensorOwners: userInMemory.NewSensorOwnerRepository(),
+		Sensors:      sensorInMemory.NewSensorRepository(),
+		Events:       eventInMemory.NewEventRepository(),
+		Locations:    locationInMemory.NewLocationRepository(),
+	}
+}
+
+func postBackup(r *gin.Engine, target, contentType string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+	source := newBackupRepositories()
+	user := &domain.User{Name: "alice"}
+	require.NoError(t, source.Users.SaveUser(ctx, user))
+	sensor := &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure}
+	require.NoError(t, source.Sensors.SaveSensor(ctx, sensor))
+	require.NoError(t, source.SensorOwners.SaveSensorOwner(ctx, domain.SensorOwner{UserID: user.ID, SensorID: sensor.ID}))
+
+	w := serveJSON(backupRouter(source), http.MethodGet, "/backup", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, backup.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	archive := w.Body.Bytes()
+
+	target := backupRouter(newBackupRepositories())
+
+	t.Run("ok, verify", func(t *testing.T) {
+		w := postBackup(target, "/backup?verify=true", backup.ContentType, archive)
+		require.Equal(t, http.StatusOK, w.Code)
+		var summary dtos.BackupSummary
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+		assert.Equal(t, int64(backup.Version), *summary.Version)
+		assert.Equal(t, int64(1), *summary.Archive.SensorOwners)
+		assert.Nil(t, summary.Imported)
+	})
+
+	t.Run("ok, import", func(t *testing.T) {
+		w := postBackup(target, "/backup", backup.ContentType, archive)
+		require.Equal(t, http.StatusOK, w.Code)
+		var summary dtos.BackupSummary
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+		assert.Equal(t, int64(1), *summary.Imported.Users)
+		assert.Equal(t, int64(1), *summary.Imported.Sensors)
+		assert.Equal(t, int64(0), *summary.Skipped.Users)
+
+		w = postBackup(target, "/backup", "application/octet-stream", archive)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+		assert.Equal(t, int64(0), *summary.Imported.Users)
+		assert.Equal(t, int64(1), *summary.Skipped.Users)
+	})
+
+	t.Run("err, invalid archive", func(t *testing.T) {
+		w := postBackup(target, "/backup", backup.ContentType, archive[:len(archive)/2])
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var problem dtos.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, "invalid_backup", *problem.Code)
+	})
+
+	t.Run("err, too large", func(t *testing.T) {
+		limited := func(maxBytes int) *gin.Engine {
+			r := gin.New()
+			s := &Server{backup: backup.New(newBackupRepositories()), backupMaxBytes: int64(maxBytes)}
+			setupBackupHandler(r.Group("/backup"), s)
+			return r
+		}
+
+		w := postBackup(limited(len(archive)-1), "/backup", backup.ContentType, archive)
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		var problem dtos.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, "backup_too_large", *problem.Code)
+
+		assert.Equal(t, http.StatusOK, postBackup(limited(len(archive)), "/backup?verify=true", backup.ContentType, archive).Code)
+	})
+
+	t.Run("err, unsupported content type", func(t *testing.T) {
+		w := postBackup(target, "/backup", JSONType, archive)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("err, unavailable", func(t *testing.T) {
+		r := gin.New()
+		setupBackupHandler(r.Group("/backup"), &Server{})
+		assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodGet, "/backup", "").Code)
+	})
+}
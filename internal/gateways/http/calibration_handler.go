@@ -0,0 +1,136 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func calibrationDto(calibration domain.Calibration) dtos.SensorCalibration {
+	dto := dtos.SensorCalibration{Steps: make([]*dtos.CalibrationStep, 0, len(calibration))}
+	for _, step := range calibration {
+		kind := string(step.Kind)
+		stepDto := &dtos.CalibrationStep{
+			Kind:   &kind,
+			Max:    step.Max,
+			Min:    step.Min,
+			Value:  step.Value,
+			Window: int64(step.Window),
+		}
+		for _, p := range step.Points {
+			stepDto.Points = append(stepDto.Points, &dtos.CalibrationPoint{Raw: &p.Raw, Value: &p.Value})
+		}
+		dto.Steps = append(dto.Steps, stepDto)
+	}
+	return dto
+}
+
+func calibrationFromDto(dto *dtos.SensorCalibration) domain.Calibration {
+	calibration := make(domain.Calibration, 0, len(dto.Steps))
+	for _, stepDto := range dto.Steps {
+		if stepDto == nil {
+			continue
+		}
+		step := domain.CalibrationStep{
+			Kind:   domain.CalibrationStepKind(*stepDto.Kind),
+			Value:  stepDto.Value,
+			Min:    stepDto.Min,
+			Max:    stepDto.Max,
+			Window: int(stepDto.Window),
+		}
+		for _, p := range stepDto.Points {
+			if p != nil {
+				step.Points = append(step.Points, domain.CalibrationPoint{Raw: *p.Raw, Value: *p.Value})
+			}
+		}
+		calibration = append(calibration, step)
+	}
+	return calibration
+}
+
+// channelCalibration - calibration of the sensor or of its channel resolved by resolveChannel
+func channelCalibration(sensor *domain.Sensor, channel string) (string, domain.Calibration, error) {
+	name, _, err := resolveChannel(sensor, channel)
+	if err != nil {
+		return "", nil, err
+	}
+	if name == "" {
+		return "", sensor.Calibration, nil
+	}
+	c, _ := sensor.Channel(name)
+	return name, c.Calibration, nil
+}
+
+func sensorCalibrationGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sensor := sensorByIdCommonHandler(ctx, uc)
+		if ctx.IsAborted() {
+			return
+		}
+
+		_, calibration, err := channelCalibration(sensor, ctx.Query("channel"))
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		abortWithStatusDto(ctx, http.StatusOK, calibrationDto(calibration))
+	}
+}
+
+func sensorCalibrationPutHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		calibrationIn := &dtos.SensorCalibration{}
+		if extractDto(ctx, calibrationIn) != nil {
+			return
+		}
+		sensor := sensorByIdCommonHandler(ctx, uc)
+		if ctx.IsAborted() {
+			return
+		}
+		checkIfMatch(ctx, sensorETag(sensor))
+		if ctx.IsAborted() {
+			return
+		}
+		channel, _, err := channelCalibration(sensor, ctx.Query("channel"))
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		sens, err := uc.Sensor.SetCalibration(ctx, sensor.ID, ifMatchVersion(ctx, sensor), channel, calibrationFromDto(calibrationIn))
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		_, calibration, _ := channelCalibration(sens, channel)
+		abortWithStatusDto(ctx, http.StatusOK, calibrationDto(calibration))
+	}
+}
+
+func sensorCalibrationRecomputeHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := isFormatSupported(ctx); err != nil {
+			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+			return
+		}
+
+		sensorId, err := strconv.ParseInt(ctx.Param("sensor_id"), 10, 64)
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		recomputed, err := uc.Event.RecalibrateHistory(ctx, sensorId)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		recomputedDto := int64(recomputed)
+		abortWithStatusDto(ctx, http.StatusOK, dtos.CalibrationRecompute{Recomputed: &recomputedDto})
+	}
+}
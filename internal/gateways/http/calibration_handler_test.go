@@ -0,0 +1,50 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalibrationDto(t *testing.T) {
+	lo := -4000.0
+	calibration := domain.Calibration{
+		{Kind: domain.CalibrationLookup, Points: []domain.CalibrationPoint{{Raw: 0, Value: -4000}, {Raw: 4095, Value: 12500}}},
+		{Kind: domain.CalibrationClamp, Min: &lo},
+		{Kind: domain.CalibrationMovingAverage, Window: 5},
+	}
+
+	dto := calibrationDto(calibration)
+	assert.NoError(t, dto.Validate(nil))
+	assert.Equal(t, calibration, calibrationFromDto(&dto))
+
+	empty := calibrationDto(nil)
+	assert.NotNil(t, empty.Steps)
+	assert.NoError(t, empty.Validate(nil))
+}
+
+func TestChannelCalibration(t *testing.T) {
+	calibration := domain.Calibration{{Kind: domain.CalibrationOffset, Value: -35}}
+	sensor := &domain.Sensor{
+		Type: domain.SensorTypeADC,
+		Channels: []domain.Channel{
+			{Name: "temperature", Type: domain.SensorTypeTemperature, Calibration: calibration},
+			{Name: "humidity", Type: domain.SensorTypeHumidity},
+		},
+	}
+
+	channel, got, err := channelCalibration(sensor, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "temperature", channel)
+	assert.Equal(t, calibration, got)
+
+	channel, got, err = channelCalibration(sensor, "humidity")
+	assert.NoError(t, err)
+	assert.Equal(t, "humidity", channel)
+	assert.Empty(t, got)
+
+	_, _, err = channelCalibration(sensor, "pressure")
+	assert.ErrorIs(t, err, usecase.ErrUnknownChannel)
+}
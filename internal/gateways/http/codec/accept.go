@@ -0,0 +1,69 @@
+package codec
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaRange - single element of the Accept header, Type and Subtype may be "*"
+type MediaRange struct {
+	Type    string
+	Subtype string
+	Q       float64
+}
+
+// Matches - whether the media type falls into the range
+func (m MediaRange) Matches(mediaType string) bool {
+	typ, subtype, ok := strings.Cut(normalizeType(mediaType), "/")
+	if !ok {
+		return false
+	}
+	return (m.Type == "*" || m.Type == typ) && (m.Subtype == "*" || m.Subtype == subtype)
+}
+
+// specificity - the more specific range takes precedence: type/subtype over type/* over */*
+func (m MediaRange) specificity() int {
+	switch {
+	case m.Type == "*":
+		return 0
+	case m.Subtype == "*":
+		return 1
+	}
+	return 2
+}
+
+// ParseAccept - media ranges of the Accept header ordered by the quality, the malformed ones are skipped
+func ParseAccept(header string) []MediaRange {
+	var ranges []MediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(normalizeType(params[0]), "/")
+		if !ok || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+			continue
+		}
+		r := MediaRange{Type: typ, Subtype: subtype, Q: 1}
+		for _, p := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(strings.TrimSpace(name), "q") {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q >= 0 && q <= 1 {
+					r.Q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].Q > ranges[j].Q })
+	return ranges
+}
+
+// quality - q of the most specific range matching the media type, 0 if none does
+func quality(ranges []MediaRange, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		if r.Matches(mediaType) && r.specificity() > specificity {
+			q, specificity = r.Q, r.specificity()
+		}
+	}
+	return q
+}
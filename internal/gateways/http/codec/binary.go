@@ -0,0 +1,56 @@
+package codec
+
+import (
+	"reflect"
+
+	"github.com/ugorji/go/codec"
+)
+
+// binaryCodec - MessagePack and CBOR, both encode the JSON document of the value
+type binaryCodec struct {
+	contentType ContentType
+	handle      codec.Handle
+}
+
+// Msgpack - application/msgpack, the strings are encoded as str, not bin
+func Msgpack() Codec {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.RawToString = true
+	h.MapType = reflect.TypeOf(map[string]any(nil))
+	return binaryCodec{contentType: MsgpackType, handle: h}
+}
+
+// CBOR - application/cbor (RFC 8949)
+func CBOR() Codec {
+	h := &codec.CborHandle{}
+	h.MapType = reflect.TypeOf(map[string]any(nil))
+	return binaryCodec{contentType: CBORType, handle: h}
+}
+
+func (c binaryCodec) ContentType() ContentType {
+	return c.contentType
+}
+
+func (binaryCodec) Binary() bool {
+	return true
+}
+
+func (c binaryCodec) Marshal(v any) ([]byte, error) {
+	doc, err := toDocument(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	if err := codec.NewEncoderBytes(&out, c.handle).Encode(doc); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c binaryCodec) Unmarshal(data []byte, v any) error {
+	var doc any
+	if err := codec.NewDecoderBytes(data, c.handle).Decode(&doc); err != nil {
+		return err
+	}
+	return fromDocument(doc, v)
+}
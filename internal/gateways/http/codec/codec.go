@@ -0,0 +1,160 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+type ContentType = string
+
+const ( // Supported media types
+	JSONType    ContentType = "application/json"
+	MsgpackType ContentType = "application/msgpack"
+	CBORType    ContentType = "application/cbor"
+)
+
+// Codec - encoding of the request and response bodies in a media type
+type Codec interface {
+	ContentType() ContentType
+	// Binary - whether the encoding is not text, the websocket messages are sent in binary frames then
+	Binary() bool
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// Registry - codecs the API speaks, the first one is used when the client has no preference
+type Registry struct {
+	codecs []Codec
+}
+
+func NewRegistry(codecs ...Codec) *Registry {
+	return &Registry{codecs: codecs}
+}
+
+// Default - JSON, MessagePack and CBOR, JSON is preferred
+func Default() *Registry {
+	return NewRegistry(JSON(), Msgpack(), CBOR())
+}
+
+// Default - codec used when the client has no preference
+func (r *Registry) Default() Codec {
+	return r.codecs[0]
+}
+
+// Lookup - codec of the Content-Type header value, the media type parameters (charset etc.) are ignored
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	for _, c := range r.codecs {
+		if c.ContentType() == mediaType {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate - codec of the highest quality by the Accept header value, the registry order breaks ties,
+// false if the client accepts none of the codecs
+func (r *Registry) Negotiate(accept string) (Codec, bool) {
+	ranges := ParseAccept(accept)
+	if len(ranges) == 0 {
+		return r.Default(), true
+	}
+
+	var best Codec
+	bestQ := 0.0
+	for _, c := range r.codecs {
+		if q := quality(ranges, c.ContentType()); q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	return best, best != nil
+}
+
+// MediaTypes - media types of the codecs in the preference order
+func (r *Registry) MediaTypes() []ContentType {
+	res := make([]ContentType, 0, len(r.codecs))
+	for _, c := range r.codecs {
+		res = append(res, c.ContentType())
+	}
+	return res
+}
+
+// toDocument - the generic tree (maps, slices, strings, int64, float64, bool, nil) of the value's JSON form,
+// so every codec carries exactly the fields and formats of the JSON API
+func toDocument(v any) (any, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(doc), nil
+}
+
+func normalizeNumbers(doc any) any {
+	switch d := doc.(type) {
+	case map[string]any:
+		for k, v := range d {
+			d[k] = normalizeNumbers(v)
+		}
+	case []any:
+		for i, v := range d {
+			d[i] = normalizeNumbers(v)
+		}
+	case json.Number:
+		if i, err := d.Int64(); err == nil {
+			return i
+		}
+		f, _ := d.Float64()
+		return f
+	}
+	return doc
+}
+
+// fromDocument - fills the value from the generic tree the way the JSON body would
+func fromDocument(doc any, v any) error {
+	encoded, err := json.Marshal(stringKeys(doc))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
+
+// stringKeys - the binary formats allow non-string map keys, JSON objects don't
+func stringKeys(doc any) any {
+	switch d := doc.(type) {
+	case map[string]any:
+		for k, v := range d {
+			d[k] = stringKeys(v)
+		}
+	case map[any]any:
+		res := make(map[string]any, len(d))
+		for k, v := range d {
+			res[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return res
+	case []any:
+		for i, v := range d {
+			d[i] = stringKeys(v)
+		}
+	}
+	return doc
+}
+
+// normalizeType - lower-cased media type without parameters
+func normalizeType(mediaType string) string {
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
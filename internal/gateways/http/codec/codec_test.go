@@ -0,0 +1,116 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sample struct {
+	ID        *int64            `json:"id"`
+	Name      string            `json:"name"`
+	Ratio     float64           `json:"ratio"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Values    []int64           `json:"values"`
+	CreatedAt strfmt.DateTime   `json:"created_at"`
+	Skipped   string            `json:"-"`
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	id := int64(1) << 40
+	createdAt, err := strfmt.ParseDateTime("2024-05-01T10:00:00.000Z")
+	require.NoError(t, err)
+	in := sample{
+		ID:        &id,
+		Name:      "hall",
+		Ratio:     0.25,
+		Labels:    map[string]string{"floor": "2"},
+		Values:    []int64{-1, 0, 1 << 50},
+		CreatedAt: createdAt,
+		Skipped:   "not encoded",
+	}
+
+	for _, c := range Default().codecs {
+		t.Run(c.ContentType(), func(t *testing.T) {
+			data, err := c.Marshal(in)
+			require.NoError(t, err)
+
+			var out sample
+			require.NoError(t, c.Unmarshal(data, &out))
+			expected := in
+			expected.Skipped = ""
+			assert.Equal(t, expected.Name, out.Name)
+			assert.Equal(t, *expected.ID, *out.ID)
+			assert.Equal(t, expected.Ratio, out.Ratio)
+			assert.Equal(t, expected.Labels, out.Labels)
+			assert.Equal(t, expected.Values, out.Values)
+			assert.Equal(t, expected.CreatedAt.String(), out.CreatedAt.String())
+			assert.Empty(t, out.Skipped)
+		})
+	}
+
+	t.Run("binary formats are smaller", func(t *testing.T) {
+		jsonData, err := JSON().Marshal(in)
+		require.NoError(t, err)
+		for _, c := range []Codec{Msgpack(), CBOR()} {
+			data, err := c.Marshal(in)
+			require.NoError(t, err)
+			assert.Less(t, len(data), len(jsonData), c.ContentType())
+		}
+	})
+
+	t.Run("fail, garbage", func(t *testing.T) {
+		for _, c := range Default().codecs {
+			var out sample
+			assert.Error(t, c.Unmarshal([]byte{0xc1, 0xff, 0x00}, &out), c.ContentType())
+		}
+	})
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := Default()
+
+	c, ok := r.Lookup("application/json; charset=utf-8")
+	require.True(t, ok)
+	assert.Equal(t, JSONType, c.ContentType())
+	c, ok = r.Lookup("Application/MsgPack")
+	require.True(t, ok)
+	assert.Equal(t, MsgpackType, c.ContentType())
+	_, ok = r.Lookup("application/xml")
+	assert.False(t, ok)
+	_, ok = r.Lookup("")
+	assert.False(t, ok)
+}
+
+func TestRegistry_Negotiate(t *testing.T) {
+	r := Default()
+	tests := []struct {
+		accept   string
+		expected ContentType
+	}{
+		{"", JSONType},
+		{"*/*", JSONType},
+		{"application/*", JSONType},
+		{"application/cbor", CBORType},
+		{"application/json;q=0.5, application/msgpack", MsgpackType},
+		{"application/*;q=0.2, application/cbor;q=0.9, application/json;q=0.1", CBORType},
+		{"application/x-protobuf", ""},
+		{"text/html, application/json;q=0", ""},
+		{"application/*, application/json;q=0", MsgpackType},
+		{"application/xml", ""},
+		{"garbage", JSONType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			c, ok := r.Negotiate(tt.accept)
+			if tt.expected == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, c.ContentType())
+		})
+	}
+}
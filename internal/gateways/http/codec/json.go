@@ -0,0 +1,25 @@
+package codec
+
+import "encoding/json"
+
+type jsonCodec struct{}
+
+func JSON() Codec {
+	return jsonCodec{}
+}
+
+func (jsonCodec) ContentType() ContentType {
+	return JSONType
+}
+
+func (jsonCodec) Binary() bool {
+	return false
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
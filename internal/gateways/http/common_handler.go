@@ -1,41 +1,59 @@
 package http
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"homework/internal/domain"
-	"homework/internal/gateways/http/dtos"
-	"log"
+	"homework/internal/gateways/http/codec"
+	"homework/internal/metrics"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-openapi/strfmt"
 )
 
-type ContentType = string
+type ContentType = codec.ContentType
 
 const ( // Supported Content-Type
-	JSONType ContentType = "application/json"
-	TextType ContentType = "plain/text"
+	JSONType    ContentType = codec.JSONType
+	MsgpackType ContentType = codec.MsgpackType
+	CBORType    ContentType = codec.CBORType
+	TextType    ContentType = "plain/text"
+
+	codecContextKey = "codec"
 )
 
+var codecs = codec.Default() // Formats of the request and response bodies
+
 var ( // Errors
 	UnsupportedContentType = errors.New("unsupported Content-Type")
 	UnsupportedAcceptType  = errors.New("unsupported Accept type")
 )
 
+// extractDto - decodes the request body by its Content-Type and validates it,
+// the error responses (415, 400, 422) are already sent if it fails
 func extractDto(ctx *gin.Context, dto Validator) error {
-	if err := requireContentType(ctx, JSONType); err != nil {
+	c, ok := codecs.Lookup(ctx.GetHeader("Content-Type"))
+	if !ok {
+		err := fmt.Errorf("got %w: %v", UnsupportedContentType, ctx.GetHeader("Content-Type"))
 		abortWithAPIError(ctx, http.StatusUnsupportedMediaType, err)
 		return err
 	}
 
-	if err := ctx.BindJSON(dto); err != nil {
-		return err // Return 400
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err == nil {
+		err = c.Unmarshal(body, dto)
+	}
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusBadRequest, fmt.Errorf("%w: %w", errMalformedBody, err))
+		return err
 	}
 
 	if err := dto.Validate(nil); err != nil {
@@ -45,35 +63,45 @@ func extractDto(ctx *gin.Context, dto Validator) error {
 	return nil
 }
 
-func isFormatSupported(ctx *gin.Context, contentType ...ContentType) error {
-	expType := ctx.GetHeader("Accept")
-	expCategories := strings.Split(expType, "/")
-	if len(expCategories) != 2 {
-		return nil
-	}
-	if expCategories[0] == "*" && expCategories[1] == "*" {
-		return nil
-	}
-
-	for _, ct := range contentType {
-		if expCategories[1] == "*" && strings.HasPrefix(ct, expCategories[0]) {
-			return nil
-		}
-		if expType == ct {
-			return nil
-		}
+// isFormatSupported - negotiates the codec of the response by the Accept header quality values,
+// fails if the client accepts none of the supported media types
+func isFormatSupported(ctx *gin.Context) error {
+	accept := ctx.GetHeader("Accept")
+	c, ok := codecs.Negotiate(accept)
+	if !ok {
+		return fmt.Errorf("%w requested: %v", UnsupportedAcceptType, accept)
 	}
-	return fmt.Errorf("%w requested: %v", UnsupportedAcceptType, expType)
+	ctx.Set(codecContextKey, c)
+	ctx.Header("Vary", "Accept")
+	return nil
 }
 
-func formatAPIError(err error) *dtos.Error {
-	errReason := err.Error()
-	dto := dtos.Error{Reason: &errReason}
-	return &dto
+// responseCodec - codec negotiated by isFormatSupported, the handlers not checking Accept
+// fall back to JSON if the client accepts none of the codecs
+func responseCodec(ctx *gin.Context) codec.Codec {
+	if value, ok := ctx.Get(codecContextKey); ok {
+		return value.(codec.Codec)
+	}
+	if c, ok := codecs.Negotiate(ctx.GetHeader("Accept")); ok {
+		return c
+	}
+	return codecs.Default()
 }
 
-func abortWithAPIError(ctx *gin.Context, code int, err error) {
-	ctx.AbortWithStatusJSON(code, formatAPIError(err))
+// abortWithStatusDto - writes the body in the negotiated format, JSON is rendered by gin as before
+func abortWithStatusDto(ctx *gin.Context, code int, obj any) {
+	c := responseCodec(ctx)
+	if c.ContentType() == codec.JSONType {
+		ctx.AbortWithStatusJSON(code, obj)
+		return
+	}
+	data, err := c.Marshal(obj)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	ctx.Abort()
+	ctx.Data(code, c.ContentType(), data)
 }
 
 type Validator = interface {
@@ -87,39 +115,62 @@ func optionsHandler(methods ...string) gin.HandlerFunc {
 	}
 }
 
-func requireContentType(ctx *gin.Context, contentType ...ContentType) error {
-	realType := ctx.GetHeader("Content-Type")
-	for _, ct := range contentType {
-		if realType == ct {
-			return nil
+func headImpl(ctx *gin.Context, obj any) {
+	c := responseCodec(ctx)
+	if encoded, err := c.Marshal(obj); err == nil {
+		contentType := c.ContentType()
+		if contentType == codec.JSONType {
+			contentType += "; charset=utf-8"
 		}
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("Content-Length", strconv.Itoa(len(encoded)))
+		ctx.Status(http.StatusOK)
 	}
-	return fmt.Errorf("got %w: %v", UnsupportedContentType, realType)
 }
 
-func headImpl(ctx *gin.Context, jsonObj any) {
-	if sensorBytes, err := json.Marshal(jsonObj); err == nil {
-		ctx.Header("Content-Length", strconv.Itoa(len(sensorBytes)))
-		ctx.Status(http.StatusOK)
-	}
+// wsMessage - encoded subscription message along with the trace context of the event it was made of
+type wsMessage struct {
+	data        []byte
+	traceParent string
 }
 
-func eventChannelAdapter(ec <-chan domain.Event) <-chan []byte {
-	out := make(chan []byte)
+func eventChannelAdapter(ctx context.Context, logger *slog.Logger, c codec.Codec, ec <-chan domain.Event) <-chan wsMessage {
+	out := make(chan wsMessage)
 	go func() {
 		defer close(out)
 		for i := range ec {
-			encoded, err := json.Marshal(&i)
+			encoded, err := c.Marshal(&i)
 			if err != nil {
-				log.Printf("unable to encode event %v: %v", i.SensorID, err)
+				metrics.WSMessageDropped(metrics.DropReasonEncodeError)
+				logger.ErrorContext(ctx, "unable to encode event", "sensor_id", i.SensorID, "error", err)
 				return
 			}
-			out <- encoded
+			out <- wsMessage{data: encoded, traceParent: i.TraceParent}
 		}
 	}()
 	return out
 }
 
+// mergeChannels - fans the channels in, the result is closed once all of them are closed
+func mergeChannels[T any](chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	wg := sync.WaitGroup{}
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for i := range ch {
+				out <- i
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
 func channelBatcher[T any](ec <-chan T, period time.Duration) <-chan T {
 	toSend := time.NewTicker(period)
 	out := make(chan T)
@@ -129,6 +180,9 @@ func channelBatcher[T any](ec <-chan T, period time.Duration) <-chan T {
 
 		var buffer []T
 		flushBuffer := func() {
+			if len(buffer) > 0 {
+				metrics.WSBatchFlushed(len(buffer))
+			}
 			for _, i := range buffer {
 				out <- i
 			}
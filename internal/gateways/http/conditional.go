@@ -0,0 +1,140 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ETagHeader            = "ETag"
+	LastModifiedHeader    = "Last-Modified"
+	IfNoneMatchHeader     = "If-None-Match"
+	IfModifiedSinceHeader = "If-Modified-Since"
+	IfMatchHeader         = "If-Match"
+
+	weakETagPrefix = "W/"
+	anyETag        = "*"
+)
+
+var errPreconditionFailed = errors.New("resource has been modified, If-Match does not match the current ETag")
+
+// sensorETag - strong validator of the sensor, the version is bumped on every change of the sensor
+func sensorETag(sensor *domain.Sensor) string {
+	return `"` + strconv.FormatInt(sensor.ID, 10) + "-" + strconv.FormatInt(sensor.Version, 10) + `"`
+}
+
+// sensorsETag - strong validator of the sensor list, changes once any sensor is added, removed or changed,
+// the sensors must be in the order they are rendered in
+func sensorsETag(sensors []domain.Sensor) string {
+	h := sha256.New()
+	for _, s := range sensors {
+		_, _ = fmt.Fprintf(h, "%d-%d;", s.ID, s.Version)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches - whether the header value (a list of the entity tags or "*") matches the tag,
+// the weak comparison treats W/"x" and "x" as the same
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == anyETag {
+			return true
+		}
+		if strings.HasPrefix(candidate, weakETagPrefix) {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, weakETagPrefix)
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// representationETag - the strong tags must differ between the representations, so the tags
+// of the formats other than JSON are suffixed with the format
+func representationETag(etag string, contentType ContentType) string {
+	if contentType == JSONType {
+		return etag
+	}
+	_, format, _ := strings.Cut(contentType, "/")
+	return strings.TrimSuffix(etag, `"`) + "+" + format + `"`
+}
+
+// setValidators - sets ETag and Last-Modified of the representation and responds with 304 if the client copy
+// is fresh, If-Modified-Since is ignored when If-None-Match is given as RFC 9110 requires
+func setValidators(ctx *gin.Context, etag string, lastModified time.Time) {
+	etag = representationETag(etag, responseCodec(ctx).ContentType())
+	ctx.Header(ETagHeader, etag)
+	if !lastModified.IsZero() {
+		ctx.Header(LastModifiedHeader, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := ctx.GetHeader(IfNoneMatchHeader); ifNoneMatch != "" {
+		if etagMatches(ifNoneMatch, etag, true) {
+			ctx.AbortWithStatus(http.StatusNotModified)
+		}
+		return
+	}
+	if lastModified.IsZero() {
+		return
+	}
+	since, err := http.ParseTime(ctx.GetHeader(IfModifiedSinceHeader))
+	if err == nil && !lastModified.Truncate(time.Second).After(since) {
+		ctx.AbortWithStatus(http.StatusNotModified)
+	}
+}
+
+// checkIfMatch - responds with 412 unless If-Match is missing or matches the current ETag of the resource
+// in any format. The update itself is then conditional on the version the tag was checked against,
+// see ifMatchVersion, so a change saved in between is detected as well
+func checkIfMatch(ctx *gin.Context, etag string) {
+	ifMatch := ctx.GetHeader(IfMatchHeader)
+	if ifMatch == "" {
+		return
+	}
+	for _, contentType := range codecs.MediaTypes() {
+		if etagMatches(ifMatch, representationETag(etag, contentType), false) {
+			return
+		}
+	}
+	abortWithAPIError(ctx, http.StatusPreconditionFailed, errPreconditionFailed)
+}
+
+// ifMatchVersion - version of the sensor the update is conditional on once checkIfMatch has passed,
+// 0 if If-Match is missing or is "*", so the update applies to any version
+func ifMatchVersion(ctx *gin.Context, sensor *domain.Sensor) int64 {
+	ifMatch := ctx.GetHeader(IfMatchHeader)
+	if ifMatch == "" {
+		return 0
+	}
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(candidate) == anyETag {
+			return 0
+		}
+	}
+	return sensor.Version
+}
+
+// latestActivity - Last-Modified of the sensor list
+func latestActivity(sensors []domain.Sensor) time.Time {
+	var latest time.Time
+	for _, s := range sensors {
+		if s.LastActivity.After(latest) {
+			latest = s.LastActivity
+		}
+	}
+	return latest
+}
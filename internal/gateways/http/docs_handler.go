@@ -0,0 +1,74 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	docsPath            = "/docs/"
+	docsInitializerPath = "/swagger-initializer.js"
+)
+
+// docsInitializer - points the bundled Swagger UI to the served spec instead of the petstore
+const docsInitializer = `window.onload = function () {
+  window.ui = SwaggerUIBundle({
+    url: "../openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// openAPIJSON - JSON form of the YAML spec
+func openAPIJSON(spec []byte) ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("cannot parse OpenAPI spec: %w", err)
+	}
+	return json.Marshal(doc)
+}
+
+func openAPIGetHandler(doc []byte, err error) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		ctx.Data(http.StatusOK, JSONType, doc)
+	}
+}
+
+// docsGetHandler - Swagger UI bundled into the binary
+func docsGetHandler(ui fs.FS) gin.HandlerFunc {
+	files := http.StripPrefix(strings.TrimSuffix(docsPath, "/"), http.FileServer(http.FS(ui)))
+	return func(ctx *gin.Context) {
+		if ctx.Param("filepath") == docsInitializerPath {
+			ctx.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(docsInitializer))
+			return
+		}
+		// the group prefix (/api) is not a part of the bundle paths, the original request is kept for the access log
+		req := ctx.Request.Clone(ctx)
+		req.URL.Path = docsPath + strings.TrimPrefix(ctx.Param("filepath"), "/")
+		files.ServeHTTP(ctx.Writer, req)
+	}
+}
+
+func setupDocsHandler(r *gin.RouterGroup, spec []byte) {
+	doc, err := openAPIJSON(spec)
+	r.GET("/openapi.json", openAPIGetHandler(doc, err))
+	r.HEAD("/openapi.json", openAPIGetHandler(doc, err))
+
+	r.GET(docsPath+"*filepath", docsGetHandler(swaggerFiles.FS))
+	r.HEAD(docsPath+"*filepath", docsGetHandler(swaggerFiles.FS))
+}
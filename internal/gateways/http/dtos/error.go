@@ -1,79 +0,0 @@
-// Code generated by go-swagger; DO NOT EDIT.
-
-package dtos
-
-// This file was generated by the swagger tool.
-// Editing this file might prove futile when you re-run the swagger generate command
-
-import (
-	"context"
-
-	"github.com/go-openapi/errors"
-	"github.com/go-openapi/strfmt"
-	"github.com/go-openapi/swag"
-	"github.com/go-openapi/validate"
-)
-
-// Error Error
-//
-// Ошибка исполнения запроса
-// Example: {"reason":"Произошла ошибка"}
-//
-// swagger:model Error
-type Error struct {
-
-	// Причина
-	// Required: true
-	// Min Length: 1
-	Reason *string `json:"reason"`
-}
-
-// Validate validates this error
-func (m *Error) Validate(formats strfmt.Registry) error {
-	var res []error
-
-	if err := m.validateReason(formats); err != nil {
-		res = append(res, err)
-	}
-
-	if len(res) > 0 {
-		return errors.CompositeValidationError(res...)
-	}
-	return nil
-}
-
-func (m *Error) validateReason(formats strfmt.Registry) error {
-
-	if err := validate.Required("reason", "body", m.Reason); err != nil {
-		return err
-	}
-
-	if err := validate.MinLength("reason", "body", *m.Reason, 1); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// ContextValidate validates this error based on context it is used
-func (m *Error) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
-	return nil
-}
-
-// MarshalBinary interface implementation
-func (m *Error) MarshalBinary() ([]byte, error) {
-	if m == nil {
-		return nil, nil
-	}
-	return swag.WriteJSON(m)
-}
-
-// UnmarshalBinary interface implementation
-func (m *Error) UnmarshalBinary(b []byte) error {
-	var res Error
-	if err := swag.ReadJSON(b, &res); err != nil {
-		return err
-	}
-	*m = res
-	return nil
-}
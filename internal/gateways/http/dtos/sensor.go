@@ -8,6 +8,7 @@ package dtos
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -18,15 +19,28 @@ import (
 // Sensor Sensor
 //
 // Датчик умного дома
-// Example: {"current_state":1,"description":"Датчик температуры","id":1,"is_active":true,"last_activity":"2018-01-01T00:00:00Z","registered_at":"2018-01-01T00:00:00Z","serial_number":"1234567890","type":"cc"}
+// Example: {"current_state":1,"current_value":1,"description":"Датчик температуры","id":1,"is_active":true,"last_activity":"2018-01-01T00:00:00Z","registered_at":"2018-01-01T00:00:00Z","serial_number":"1234567890","type":"cc"}
 //
 // swagger:model Sensor
 type Sensor struct {
 
+	// Каналы многоканального датчика, не указываются у датчиков с единственным значением
+	Channels []*SensorChannel `json:"channels,omitempty"`
+
+	// Последние значения payload по каналам
+	ChannelStates map[string]int64 `json:"channel_states,omitempty"`
+
+	// Последние значения по каналам в единицах измерения их типов
+	ChannelValues map[string]float64 `json:"channel_values,omitempty"`
+
 	// Состояние датчика, соответствует значению в payload последнего обработанного события.
 	// Required: true
 	CurrentState *int64 `json:"current_state"`
 
+	// Состояние датчика в единицах измерения его типа
+	// Required: true
+	CurrentValue *float64 `json:"current_value"`
+
 	// Описание
 	// Required: true
 	Description *string `json:"description"`
@@ -40,11 +54,17 @@ type Sensor struct {
 	// Required: true
 	IsActive *bool `json:"is_active"`
 
+	// Метки датчика для выборки селекторами
+	Labels map[string]string `json:"labels,omitempty"`
+
 	// Время последнего события
 	// Required: true
 	// Format: date-time
 	LastActivity *strfmt.DateTime `json:"last_activity"`
 
+	// Идентификатор локации датчика, отсутствует если датчик не размещен
+	LocationID int64 `json:"location_id,omitempty"`
+
 	// Дата/время регистрации
 	// Required: true
 	// Format: date-time
@@ -57,18 +77,29 @@ type Sensor struct {
 
 	// Тип
 	// Required: true
-	// Enum: [cc adc]
+	// Enum: [cc adc temperature humidity motion power_meter]
 	Type *string `json:"type"`
+
+	// Единица измерения
+	Unit string `json:"unit,omitempty"`
 }
 
 // Validate validates this sensor
 func (m *Sensor) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateChannels(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateCurrentState(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateCurrentValue(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDescription(formats); err != nil {
 		res = append(res, err)
 	}
@@ -103,6 +134,32 @@ func (m *Sensor) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Sensor) validateChannels(formats strfmt.Registry) error {
+	if swag.IsZero(m.Channels) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Channels); i++ {
+		if swag.IsZero(m.Channels[i]) { // not required
+			continue
+		}
+
+		if m.Channels[i] != nil {
+			if err := m.Channels[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("channels" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("channels" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Sensor) validateCurrentState(formats strfmt.Registry) error {
 
 	if err := validate.Required("current_state", "body", m.CurrentState); err != nil {
@@ -112,6 +169,15 @@ func (m *Sensor) validateCurrentState(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Sensor) validateCurrentValue(formats strfmt.Registry) error {
+
+	if err := validate.Required("current_value", "body", m.CurrentValue); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *Sensor) validateDescription(formats strfmt.Registry) error {
 
 	if err := validate.Required("description", "body", m.Description); err != nil {
@@ -186,7 +252,7 @@ var sensorTypeTypePropEnum []interface{}
 
 func init() {
 	var res []string
-	if err := json.Unmarshal([]byte(`["cc","adc"]`), &res); err != nil {
+	if err := json.Unmarshal([]byte(`["cc","adc","temperature","humidity","motion","power_meter"]`), &res); err != nil {
 		panic(err)
 	}
 	for _, v := range res {
@@ -201,6 +267,18 @@ const (
 
 	// SensorTypeAdc captures enum value "adc"
 	SensorTypeAdc string = "adc"
+
+	// SensorTypeTemperature captures enum value "temperature"
+	SensorTypeTemperature string = "temperature"
+
+	// SensorTypeHumidity captures enum value "humidity"
+	SensorTypeHumidity string = "humidity"
+
+	// SensorTypeMotion captures enum value "motion"
+	SensorTypeMotion string = "motion"
+
+	// SensorTypePowerMeter captures enum value "power_meter"
+	SensorTypePowerMeter string = "power_meter"
 )
 
 // prop value enum
@@ -225,8 +303,42 @@ func (m *Sensor) validateType(formats strfmt.Registry) error {
 	return nil
 }
 
-// ContextValidate validates this sensor based on context it is used
+// ContextValidate validate this sensor based on the context it is used
 func (m *Sensor) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateChannels(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *Sensor) contextValidateChannels(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Channels); i++ {
+
+		if m.Channels[i] != nil {
+
+			if swag.IsZero(m.Channels[i]) { // not required
+				return nil
+			}
+
+			if err := m.Channels[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("channels" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("channels" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
 	return nil
 }
 
@@ -22,21 +22,27 @@ import (
 // swagger:model SensorEvent
 type SensorEvent struct {
 
-	// Информация от датчика
-	// Required: true
-	Payload *int64 `json:"payload"`
+	// Идентификатор события, назначенный клиентом; повторное событие датчика с тем же идентификатором не сохраняется
+	// Max Length: 255
+	EventID string `json:"event_id,omitempty"`
+
+	// Информация от датчика, обязательна для датчиков без каналов
+	Payload *int64 `json:"payload,omitempty"`
 
 	// Серийный номер датчика
 	// Required: true
 	// Pattern: ^\d{10}$
 	SensorSerialNumber *string `json:"sensor_serial_number"`
+
+	// Значения по каналам многоканального датчика
+	Values map[string]int64 `json:"values,omitempty"`
 }
 
 // Validate validates this sensor event
 func (m *SensorEvent) Validate(formats strfmt.Registry) error {
 	var res []error
 
-	if err := m.validatePayload(formats); err != nil {
+	if err := m.validateEventID(formats); err != nil {
 		res = append(res, err)
 	}
 
@@ -50,9 +56,12 @@ func (m *SensorEvent) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
-func (m *SensorEvent) validatePayload(formats strfmt.Registry) error {
+func (m *SensorEvent) validateEventID(formats strfmt.Registry) error {
+	if swag.IsZero(m.EventID) { // not required
+		return nil
+	}
 
-	if err := validate.Required("payload", "body", m.Payload); err != nil {
+	if err := validate.MaxLength("event_id", "body", m.EventID, 255); err != nil {
 		return err
 	}
 
@@ -17,19 +17,27 @@ import (
 // SensorHistory SensorHistory
 //
 // История событий датчика
-// Example: {"payload":10,"timestamp":"2018-01-01T00:00:00Z"}
+// Example: {"payload":10,"raw_payload":10,"timestamp":"2018-01-01T00:00:00Z","value":10}
 //
 // swagger:model SensorHistory
 type SensorHistory struct {
 
-	// Состояние датчика
+	// Откалиброванное состояние датчика
 	// Required: true
 	Payload *int64 `json:"payload"`
 
+	// Сырое состояние датчика до калибровки
+	// Required: true
+	RawPayload *int64 `json:"raw_payload"`
+
 	// Время возникновения события
 	// Required: true
 	// Format: date-time
 	Timestamp *strfmt.DateTime `json:"timestamp"`
+
+	// Состояние датчика в единицах измерения его типа
+	// Required: true
+	Value *float64 `json:"value"`
 }
 
 // Validate validates this sensor history
@@ -40,10 +48,18 @@ func (m *SensorHistory) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateRawPayload(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateTimestamp(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateValue(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -59,6 +75,15 @@ func (m *SensorHistory) validatePayload(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *SensorHistory) validateRawPayload(formats strfmt.Registry) error {
+
+	if err := validate.Required("raw_payload", "body", m.RawPayload); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *SensorHistory) validateTimestamp(formats strfmt.Registry) error {
 
 	if err := validate.Required("timestamp", "body", m.Timestamp); err != nil {
@@ -72,6 +97,15 @@ func (m *SensorHistory) validateTimestamp(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *SensorHistory) validateValue(formats strfmt.Registry) error {
+
+	if err := validate.Required("value", "body", m.Value); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ContextValidate validates this sensor history based on context it is used
 func (m *SensorHistory) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	return nil
@@ -8,6 +8,7 @@ package dtos
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -23,6 +24,9 @@ import (
 // swagger:model SensorToCreate
 type SensorToCreate struct {
 
+	// Каналы многоканального датчика, не указываются у датчиков с единственным значением
+	Channels []*SensorChannel `json:"channels,omitempty"`
+
 	// Описание
 	// Required: true
 	Description *string `json:"description"`
@@ -31,6 +35,9 @@ type SensorToCreate struct {
 	// Required: true
 	IsActive *bool `json:"is_active"`
 
+	// Метки датчика для выборки селекторами
+	Labels map[string]string `json:"labels,omitempty"`
+
 	// Серийный номер
 	// Required: true
 	// Pattern: ^\d{10}$
@@ -38,7 +45,7 @@ type SensorToCreate struct {
 
 	// Тип
 	// Required: true
-	// Enum: [cc adc]
+	// Enum: [cc adc temperature humidity motion power_meter]
 	Type *string `json:"type"`
 }
 
@@ -46,6 +53,10 @@ type SensorToCreate struct {
 func (m *SensorToCreate) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateChannels(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDescription(formats); err != nil {
 		res = append(res, err)
 	}
@@ -68,6 +79,32 @@ func (m *SensorToCreate) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *SensorToCreate) validateChannels(formats strfmt.Registry) error {
+	if swag.IsZero(m.Channels) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Channels); i++ {
+		if swag.IsZero(m.Channels[i]) { // not required
+			continue
+		}
+
+		if m.Channels[i] != nil {
+			if err := m.Channels[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("channels" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("channels" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *SensorToCreate) validateDescription(formats strfmt.Registry) error {
 
 	if err := validate.Required("description", "body", m.Description); err != nil {
@@ -103,7 +140,7 @@ var sensorToCreateTypeTypePropEnum []interface{}
 
 func init() {
 	var res []string
-	if err := json.Unmarshal([]byte(`["cc","adc"]`), &res); err != nil {
+	if err := json.Unmarshal([]byte(`["cc","adc","temperature","humidity","motion","power_meter"]`), &res); err != nil {
 		panic(err)
 	}
 	for _, v := range res {
@@ -118,6 +155,18 @@ const (
 
 	// SensorToCreateTypeAdc captures enum value "adc"
 	SensorToCreateTypeAdc string = "adc"
+
+	// SensorToCreateTypeTemperature captures enum value "temperature"
+	SensorToCreateTypeTemperature string = "temperature"
+
+	// SensorToCreateTypeHumidity captures enum value "humidity"
+	SensorToCreateTypeHumidity string = "humidity"
+
+	// SensorToCreateTypeMotion captures enum value "motion"
+	SensorToCreateTypeMotion string = "motion"
+
+	// SensorToCreateTypePowerMeter captures enum value "power_meter"
+	SensorToCreateTypePowerMeter string = "power_meter"
 )
 
 // prop value enum
@@ -142,8 +191,42 @@ func (m *SensorToCreate) validateType(formats strfmt.Registry) error {
 	return nil
 }
 
-// ContextValidate validates this sensor to create based on context it is used
+// ContextValidate validate this sensor to create based on the context it is used
 func (m *SensorToCreate) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateChannels(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *SensorToCreate) contextValidateChannels(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Channels); i++ {
+
+		if m.Channels[i] != nil {
+
+			if swag.IsZero(m.Channels[i]) { // not required
+				return nil
+			}
+
+			if err := m.Channels[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("channels" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("channels" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
 	return nil
 }
 
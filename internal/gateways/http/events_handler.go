@@ -1,26 +1,84 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"homework/internal/domain"
 	"homework/internal/gateways/http/dtos"
+	"homework/internal/metrics"
+	"homework/internal/usecase"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var (
+	errEmptyEvent             = errors.New("either payload or values is required")
+	errIdempotencyKeyMismatch = errors.New("event_id differs from the Idempotency-Key header")
+	errIdempotencyKeyTooLong  = fmt.Errorf("idempotency key must not be longer than %d characters", maxIdempotencyKeyLength)
+)
+
+// idempotencyKey - the key of the event given either by the header or by event_id of the body
+func idempotencyKey(ctx *gin.Context, eventDto *dtos.SensorEvent) (string, error) {
+	key := ctx.GetHeader(IdempotencyKeyHeader)
+	switch {
+	case len(key) > maxIdempotencyKeyLength:
+		return "", errIdempotencyKeyTooLong
+	case key == "":
+		return eventDto.EventID, nil
+	case eventDto.EventID != "" && eventDto.EventID != key:
+		return "", errIdempotencyKeyMismatch
+	}
+	return key, nil
+}
+
 func eventsPostHandler(uc UseCases) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		eventDto := &dtos.SensorEvent{}
 		if extractDto(ctx, eventDto) == nil {
+			if eventDto.Payload == nil && len(eventDto.Values) == 0 {
+				abortWithAPIError(ctx, http.StatusUnprocessableEntity, errEmptyEvent)
+				return
+			}
+			key, err := idempotencyKey(ctx, eventDto)
+			if err != nil {
+				abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+				return
+			}
 			event := domain.Event{
 				Timestamp:          time.Now(),
 				SensorSerialNumber: *eventDto.SensorSerialNumber,
-				Payload:            *eventDto.Payload,
+				Values:             eventDto.Values,
+				IdempotencyKey:     key,
+			}
+			if eventDto.Payload != nil {
+				event.Payload = *eventDto.Payload
+			}
+			if ok, retryAfter := allowSensorEvent(ctx, uc, event.SensorSerialNumber); !ok {
+				coalesceOrReject(ctx, uc, &event, retryAfter)
+				return
 			}
 
 			if err := uc.Event.ReceiveEvent(ctx, &event); err != nil {
-				abortWithAPIError(ctx, http.StatusInternalServerError, err)
+				if errors.Is(err, usecase.ErrDuplicateEvent) {
+					// Retry of an accepted event, the client gets a success without the event stored twice
+					ctx.Header(IdempotentReplayedHeader, "true")
+					ctx.Status(http.StatusOK)
+					return
+				}
+				if errors.Is(err, usecase.ErrSensorPending) {
+					// Unknown sensor, the event is kept until the sensor is claimed
+					ctx.Status(http.StatusAccepted)
+					return
+				}
+				abortWithError(ctx, err)
 				return
 			}
 
@@ -29,6 +87,24 @@ func eventsPostHandler(uc UseCases) gin.HandlerFunc {
 	}
 }
 
+// coalesceOrReject - handles an event over the sensor limit, the contact closure bounce storms are coalesced
+// into the latest state if the coalescer is enabled, the other events are rejected with 429
+func coalesceOrReject(ctx *gin.Context, uc UseCases, event *domain.Event, retryAfter time.Duration) {
+	if uc.Coalescer != nil {
+		err := uc.Coalescer.Coalesce(ctx, event)
+		if err == nil {
+			metrics.RequestThrottled(metrics.ThrottleScopeSensor, metrics.ThrottleActionCoalesced)
+			ctx.Status(http.StatusAccepted)
+			return
+		}
+		if !errors.Is(err, usecase.ErrEventNotCoalescible) {
+			abortWithError(ctx, err)
+			return
+		}
+	}
+	abortThrottled(ctx, metrics.ThrottleScopeSensor, retryAfter)
+}
+
 func setupEventsHandler(r *gin.RouterGroup, uc UseCases) {
 	r.POST("", eventsPostHandler(uc))
 	r.OPTIONS("", optionsHandler(http.MethodPost))
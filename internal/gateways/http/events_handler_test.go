@@ -0,0 +1,183 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/codec"
+	"homework/internal/ratelimit"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventInMemory "homework/internal/repository/event/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+	subscriptionInMemory "homework/internal/repository/subscription/inmemory"
+)
+
+func eventsRouter(t *testing.T) (*gin.Engine, usecase.EventRepository) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	sr := sensorInMemory.NewSensorRepository()
+	er := eventInMemory.NewEventRepository()
+	uc := UseCases{
+		Event:  usecase.NewEvent(er, sr, subscriptionInMemory.NewSubscriptionRepository[domain.Event]()),
+		Sensor: usecase.NewSensor(sr),
+	}
+	_, err := uc.Sensor.RegisterSensor(context.Background(), &domain.Sensor{
+		SerialNumber: "0000000001",
+		Type:         domain.SensorTypeContactClosure,
+	})
+	require.NoError(t, err)
+
+	r := gin.New()
+	setupEventsHandler(r.Group("/events"), uc)
+	return r, er
+}
+
+func postEvent(r *gin.Engine, body, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", JSONType)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestEventsIdempotency(t *testing.T) {
+	r, er := eventsRouter(t)
+	history := func() []*domain.Event {
+		events, err := er.GetEventsHistoryBySensorID(context.Background(), 1, time.Time{}, time.Now())
+		require.NoError(t, err)
+		return events
+	}
+
+	t.Run("ok, retry by header is accepted once", func(t *testing.T) {
+		w := postEvent(r, `{"sensor_serial_number":"0000000001","payload":1}`, "retry-1")
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+
+		w = postEvent(r, `{"sensor_serial_number":"0000000001","payload":1}`, "retry-1")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+		assert.Len(t, history(), 1)
+	})
+
+	t.Run("ok, retry by event_id is accepted once", func(t *testing.T) {
+		body := `{"sensor_serial_number":"0000000001","payload":0,"event_id":"retry-2"}`
+		require.Equal(t, http.StatusCreated, postEvent(r, body, "").Code)
+		require.Equal(t, http.StatusOK, postEvent(r, body, "retry-2").Code)
+		assert.Len(t, history(), 2)
+	})
+
+	t.Run("ok, events without key are all stored", func(t *testing.T) {
+		body := `{"sensor_serial_number":"0000000001","payload":1}`
+		require.Equal(t, http.StatusCreated, postEvent(r, body, "").Code)
+		require.Equal(t, http.StatusCreated, postEvent(r, body, "").Code)
+		assert.Len(t, history(), 4)
+	})
+
+	t.Run("fail, invalid key", func(t *testing.T) {
+		w := postEvent(r, `{"sensor_serial_number":"0000000001","payload":1,"event_id":"a"}`, "b")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = postEvent(r, `{"sensor_serial_number":"0000000001","payload":1}`, strings.Repeat("a", 256))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = postEvent(r, `{"sensor_serial_number":"0000000001","payload":1,"event_id":"`+strings.Repeat("a", 256)+`"}`, "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Len(t, history(), 4)
+	})
+}
+
+func TestEventsRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sr := sensorInMemory.NewSensorRepository()
+	er := eventInMemory.NewEventRepository()
+	uc := UseCases{
+		Event:  usecase.NewEvent(er, sr, subscriptionInMemory.NewSubscriptionRepository[domain.Event]()),
+		Sensor: usecase.NewSensor(sr),
+	}
+	for serial, sensorType := range map[string]domain.SensorType{
+		"0000000001": domain.SensorTypeContactClosure,
+		"0000000002": domain.SensorTypeTemperature,
+	} {
+		_, err := uc.Sensor.RegisterSensor(context.Background(), &domain.Sensor{SerialNumber: serial, Type: sensorType})
+		require.NoError(t, err)
+	}
+	uc.Coalescer = usecase.NewCoalescer(uc.Event, sr)
+
+	r := gin.New()
+	sensorLimiter := ratelimit.New(ratelimit.Limit{Rate: 0.5, Burst: 1})
+	r.Use(rateLimitMiddleware(RateLimits{Sensor: sensorLimiter}))
+	setupEventsHandler(r.Group("/events"), uc)
+
+	t.Run("ok, contact closure bounce is coalesced", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, postEvent(r, `{"sensor_serial_number":"0000000001","payload":1}`, "").Code)
+		require.Equal(t, http.StatusAccepted, postEvent(r, `{"sensor_serial_number":"0000000001","payload":0}`, "").Code)
+		require.Equal(t, http.StatusAccepted, postEvent(r, `{"sensor_serial_number":"0000000001","payload":1}`, "").Code)
+		assert.Equal(t, 1, uc.Coalescer.Pending())
+	})
+
+	t.Run("fail, other sensors are rejected", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, postEvent(r, `{"sensor_serial_number":"0000000002","payload":2000}`, "").Code)
+		w := postEvent(r, `{"sensor_serial_number":"0000000002","payload":2000}`, "")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get(RetryAfterHeader))
+	})
+
+	t.Run("ok, wrong and unknown serial numbers take no bucket", func(t *testing.T) {
+		tracked := sensorLimiter.Len()
+		for i := 0; i < 3; i++ {
+			for _, serial := range []string{"bogus-" + strconv.Itoa(i), "00000001" + strconv.Itoa(10+i)} {
+				w := postEvent(r, `{"sensor_serial_number":"`+serial+`","payload":1}`, "")
+				assert.NotEqual(t, http.StatusTooManyRequests, w.Code)
+			}
+		}
+		assert.Equal(t, tracked, sensorLimiter.Len())
+	})
+}
+
+func TestEventsBinaryBody(t *testing.T) {
+	r, er := eventsRouter(t)
+	post := func(contentType string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/events", bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, c := range []codec.Codec{codec.Msgpack(), codec.CBOR()} {
+		t.Run("ok, "+c.ContentType(), func(t *testing.T) {
+			body, err := c.Marshal(map[string]any{"sensor_serial_number": "0000000001", "payload": 1})
+			require.NoError(t, err)
+			require.Equal(t, http.StatusCreated, post(c.ContentType(), body).Code)
+
+			events, err := er.GetEventsHistoryBySensorID(context.Background(), 1, time.Time{}, time.Now())
+			require.NoError(t, err)
+			require.Len(t, events, i+1)
+			assert.Equal(t, int64(1), events[i].Payload)
+		})
+	}
+
+	t.Run("fail, malformed body", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post(MsgpackType, []byte{0xc1}).Code)
+	})
+
+	t.Run("fail, unsupported media type", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnsupportedMediaType, post("application/xml", []byte("<event/>")).Code)
+	})
+}
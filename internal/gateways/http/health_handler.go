@@ -0,0 +1,32 @@
+package http
+
+import (
+	"context"
+	"homework/internal/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func healthReportHandler(probe func(ctx context.Context) health.Report) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		report := probe(ctx)
+		code := http.StatusOK
+		if !report.Healthy() {
+			code = http.StatusServiceUnavailable
+		}
+		ctx.Header("Cache-Control", "no-store")
+		ctx.AbortWithStatusJSON(code, report)
+	}
+}
+
+func livenessHandler(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.AbortWithStatusJSON(http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+func setupHealthHandler(r gin.IRouter, registry *health.Registry) {
+	r.GET("/livez", livenessHandler)
+	r.GET("/healthz", healthReportHandler(registry.Health))
+	r.GET("/readyz", healthReportHandler(registry.Readiness))
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"homework/internal/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthRoutes(t *testing.T) {
+	registry := health.NewRegistry()
+	dbErr := error(nil)
+	registry.Register("database", func(context.Context) error { return dbErr })
+
+	engine := gin.New()
+	setupHealthHandler(engine, registry)
+
+	probe := func(path string) (int, health.Report) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		engine.ServeHTTP(w, req)
+
+		var report health.Report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		return w.Code, report
+	}
+
+	code, report := probe("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+
+	dbErr = errors.New("connection refused")
+	code, report = probe("/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+
+	registry.SetShuttingDown()
+	code, report = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusShuttingDown, report.Status)
+
+	code, _ = probe("/livez")
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+)
+
+func jobGetImpl(uc UseCases, job *domain.Job) dtos.Job {
+	running := uc.Scheduler.IsRunning(job.Name)
+	dto := dtos.Job{
+		Enabled:        &job.Enabled,
+		LastDurationMs: int64(job.LastDuration / time.Millisecond),
+		LastError:      job.LastError,
+		LastStatus:     string(job.LastStatus),
+		Name:           &job.Name,
+		Running:        &running,
+		Schedule:       &job.Schedule,
+	}
+	if !job.LastRunAt.IsZero() {
+		lastRunAt := strfmt.DateTime(job.LastRunAt)
+		dto.LastRunAt = &lastRunAt
+	}
+	if job.Enabled {
+		if next := uc.Scheduler.NextRunAt(job); !next.IsZero() {
+			nextRunAt := strfmt.DateTime(next)
+			dto.NextRunAt = &nextRunAt
+		}
+	}
+	return dto
+}
+
+// abortWithJobError - maps the scheduler usecase errors to the response codes
+func abortWithJobError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrJobNotFound):
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, usecase.ErrInvalidJob):
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, usecase.ErrJobRunning):
+		abortWithAPIError(ctx, http.StatusConflict, err)
+	default:
+		abortWithError(ctx, err)
+	}
+}
+
+func jobsGetImpl(ctx *gin.Context, uc UseCases) []dtos.Job {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	jobs, err := uc.Scheduler.GetJobs(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	jobDtos := make([]dtos.Job, 0, len(jobs))
+	for _, j := range jobs {
+		jobDtos = append(jobDtos, jobGetImpl(uc, &j))
+	}
+	return jobDtos
+}
+
+func jobsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		jobDtos := jobsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, jobDtos)
+		}
+	}
+}
+
+func jobsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		jobDtos := jobsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, jobDtos)
+		}
+	}
+}
+
+func jobByNameCommonHandler(ctx *gin.Context, uc UseCases) *domain.Job {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	job, err := uc.Scheduler.GetJobByName(ctx, ctx.Param("job_name"))
+	if errors.Is(err, usecase.ErrJobNotFound) {
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+		return nil
+	} else if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	return job
+}
+
+func jobByNameGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		job := jobByNameCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, jobGetImpl(uc, job))
+		}
+	}
+}
+
+func jobByNameHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		job := jobByNameCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, jobGetImpl(uc, job))
+		}
+	}
+}
+
+func jobByNamePutHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		jobDto := &dtos.JobToUpdate{}
+		if extractDto(ctx, jobDto) == nil {
+			job, err := uc.Scheduler.UpdateJob(ctx, ctx.Param("job_name"), *jobDto.Schedule, *jobDto.Enabled)
+			if err != nil {
+				abortWithJobError(ctx, err)
+				return
+			}
+
+			abortWithStatusDto(ctx, http.StatusOK, jobGetImpl(uc, job))
+		}
+	}
+}
+
+// jobRunPostHandler - the job runs in background, its outcome is seen in the job state later
+func jobRunPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := uc.Scheduler.TriggerJob(ctx, ctx.Param("job_name")); err != nil {
+			abortWithJobError(ctx, err)
+			return
+		}
+
+		ctx.AbortWithStatus(http.StatusAccepted)
+	}
+}
+
+func setupJobsHandler(r *gin.RouterGroup, uc UseCases) {
+	r.GET("", jobsGetHandler(uc))
+	r.HEAD("", jobsHeadHandler(uc))
+	r.OPTIONS("", optionsHandler(http.MethodGet, http.MethodHead))
+
+	r.GET("/:job_name", jobByNameGetHandler(uc))
+	r.HEAD("/:job_name", jobByNameHeadHandler(uc))
+	r.PUT("/:job_name", jobByNamePutHandler(uc))
+	r.OPTIONS("/:job_name", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPut))
+
+	r.POST("/:job_name/run", jobRunPostHandler(uc))
+	r.OPTIONS("/:job_name/run", optionsHandler(http.MethodPost))
+}
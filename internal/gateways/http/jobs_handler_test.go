@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jobInMemory "homework/internal/repository/job/inmemory"
+)
+
+func jobsRouter(t *testing.T, run usecase.JobFunc) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	scheduler := usecase.NewScheduler(jobInMemory.NewJobRepository(), jobInMemory.NewJobLocker())
+	require.NoError(t, scheduler.Register("report", "@daily", run))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx, time.Hour)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	r := gin.New()
+	setupJobsHandler(r.Group("/jobs"), UseCases{Scheduler: scheduler})
+	require.Eventually(t, func() bool {
+		return serveJSON(r, http.MethodGet, "/jobs/report", "").Code == http.StatusOK
+	}, time.Second, time.Millisecond)
+	return r
+}
+
+func TestJobs(t *testing.T) {
+	ran := make(chan struct{}, 1)
+	r := jobsRouter(t, func(context.Context) error {
+		ran <- struct{}{}
+		return nil
+	})
+
+	w := serveJSON(r, http.MethodGet, "/jobs", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var jobs []dtos.Job
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jobs))
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "@daily", *jobs[0].Schedule)
+	assert.True(t, *jobs[0].Enabled)
+	assert.NotNil(t, jobs[0].NextRunAt)
+	assert.Nil(t, jobs[0].LastRunAt)
+
+	t.Run("ok, trigger", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/jobs/report/run", "")
+		require.Equal(t, http.StatusAccepted, w.Code)
+		<-ran
+
+		require.Eventually(t, func() bool {
+			var job dtos.Job
+			w := serveJSON(r, http.MethodGet, "/jobs/report", "")
+			return json.Unmarshal(w.Body.Bytes(), &job) == nil && job.LastStatus == dtos.JobLastStatusSucceeded
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("ok, update", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPut, "/jobs/report", `{"schedule":"*/5 * * * *","enabled":false}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var job dtos.Job
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		assert.Equal(t, "*/5 * * * *", *job.Schedule)
+		assert.False(t, *job.Enabled)
+		assert.Nil(t, job.NextRunAt)
+	})
+
+	t.Run("fail, invalid schedule", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPut, "/jobs/report", `{"schedule":"every day","enabled":true}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("fail, job not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodGet, "/jobs/missing", "").Code)
+		assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodPost, "/jobs/missing/run", "").Code)
+		w := serveJSON(r, http.MethodPut, "/jobs/missing", `{"schedule":"@daily","enabled":true}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestJobs_Running(t *testing.T) {
+	release := make(chan struct{})
+	r := jobsRouter(t, func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	require.Equal(t, http.StatusAccepted, serveJSON(r, http.MethodPost, "/jobs/report/run", "").Code)
+	assert.Equal(t, http.StatusConflict, serveJSON(r, http.MethodPost, "/jobs/report/run", "").Code)
+
+	var job dtos.Job
+	require.NoError(t, json.Unmarshal(serveJSON(r, http.MethodGet, "/jobs/report", "").Body.Bytes(), &job))
+	assert.True(t, *job.Running)
+	close(release)
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// selectorQuery - label selector from the optional selector query parameter
+func selectorQuery(ctx *gin.Context) (domain.Selector, error) {
+	return domain.ParseSelector(ctx.Query("selector"))
+}
+
+func sensorLabelsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sensor := sensorByIdCommonHandler(ctx, uc)
+		if ctx.IsAborted() {
+			return
+		}
+		labels := dtos.SensorLabels(sensor.Labels)
+		if labels == nil {
+			labels = dtos.SensorLabels{}
+		}
+		abortWithStatusDto(ctx, http.StatusOK, labels)
+	}
+}
+
+func sensorLabelsPutHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		labelsIn := dtos.SensorLabels{}
+		if extractDto(ctx, &labelsIn) != nil {
+			return
+		}
+		sensor := sensorByIdCommonHandler(ctx, uc)
+		if ctx.IsAborted() {
+			return
+		}
+		checkIfMatch(ctx, sensorETag(sensor))
+		if ctx.IsAborted() {
+			return
+		}
+
+		sens, err := uc.Sensor.SetLabels(ctx, sensor.ID, ifMatchVersion(ctx, sensor), labelsIn)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		abortWithStatusDto(ctx, http.StatusOK, dtos.SensorLabels(sens.Labels))
+	}
+}
+
+func sensorsHistoryCommonHandler(ctx *gin.Context, uc UseCases) []dtos.SensorHistorySeries {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	selector, err := selectorQuery(ctx)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return nil
+	}
+	startTime, endTime, err := historyPeriod(ctx)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return nil
+	}
+
+	sensors, err := uc.Sensor.GetSensorsBySelector(ctx, selector)
+	if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	seriesDtos := make([]dtos.SensorHistorySeries, 0, len(sensors))
+	for _, sensor := range sensors {
+		channel, channelType, err := resolveChannel(&sensor, ctx.Query("channel"))
+		if err != nil {
+			continue // The sensor doesn't have the requested channel
+		}
+
+		hist, err := uc.Event.GetEventsHistoryBySensorID(ctx, sensor.ID, startTime, endTime)
+		if err != nil {
+			abortWithError(ctx, err)
+			return nil
+		}
+
+		histDtos := historyDtos(hist, channel, channelType)
+		series := dtos.SensorHistorySeries{
+			History:  make([]*dtos.SensorHistory, 0, len(histDtos)),
+			SensorID: &sensor.ID,
+		}
+		for i := range histDtos {
+			series.History = append(series.History, &histDtos[i])
+		}
+		seriesDtos = append(seriesDtos, series)
+	}
+	return seriesDtos
+}
+
+func sensorsHistoryGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		seriesDtos := sensorsHistoryCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, seriesDtos)
+		}
+	}
+}
+
+func sensorsHistoryHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		seriesDtos := sensorsHistoryCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, seriesDtos)
+		}
+	}
+}
+
+func sensorsSubscribeHandler(uc UseCases, ws *WebSocketHandler) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := isFormatSupported(ctx); err != nil {
+			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+			return
+		}
+
+		selector, err := selectorQuery(ctx)
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		subscriptions, err := uc.EventSubscription.SubscribeSelector(ctx, selector)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		defer func() {
+			for _, subscription := range subscriptions {
+				err := uc.EventSubscription.Unsubscribe(ctx, subscription.SensorID, subscription.Id)
+				if err != nil {
+					ws.logger.ErrorContext(ctx, "unable to unsubscribe",
+						"subscription_id", subscription.Id, "sensor_id", subscription.SensorID, "error", err)
+				}
+			}
+		}()
+
+		chans := make([]<-chan domain.Event, 0, len(subscriptions))
+		for _, subscription := range subscriptions {
+			notifyEvent, err := uc.Event.GetLastEventBySensorID(ctx, subscription.SensorID)
+			if err == nil {
+				subscription.SubscriptionWriteHandle.Ch <- *notifyEvent
+			}
+			chans = append(chans, subscription.SubscriptionReadHandle.Ch)
+		}
+
+		err = ws.HandleSubscription(ctx, channelBatcher(eventChannelAdapter(ctx, ws.logger, responseCodec(ctx), mergeChannels(chans...)), ws.batchPeriod))
+		if err != nil && !errors.Is(err, context.Canceled) {
+			ws.logger.ErrorContext(ctx, "error processing sensors subscription", "selector", selector.String(), "error", err)
+			return
+		}
+	}
+}
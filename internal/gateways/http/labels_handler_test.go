@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventInMemory "homework/internal/repository/event/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+)
+
+func labeledSensorsRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	sr := sensorInMemory.NewSensorRepository()
+	er := eventInMemory.NewEventRepository()
+	uc := UseCases{
+		Event:  usecase.NewEvent(er, sr, nil),
+		Sensor: usecase.NewSensor(sr),
+	}
+
+	ctx := context.Background()
+	for _, sensor := range []*domain.Sensor{
+		{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure, Labels: map[string]string{"kind": "leak", "floor": "2"}},
+		{SerialNumber: "0000000002", Type: domain.SensorTypeContactClosure, Labels: map[string]string{"kind": "leak", "floor": "0"}},
+		{SerialNumber: "0000000003", Type: domain.SensorTypeTemperature},
+	} {
+		_, err := uc.Sensor.RegisterSensor(ctx, sensor)
+		require.NoError(t, err)
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{
+			SensorID:   sensor.ID,
+			Payload:    1,
+			RawPayload: 1,
+			Timestamp:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}))
+	}
+
+	r := gin.New()
+	setupSensorsHandler(r.Group("/sensors"), uc, nil)
+	return r
+}
+
+func TestSensorsSelector(t *testing.T) {
+	r := labeledSensorsRouter(t)
+
+	t.Run("ok, sensors filtered", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sensors?selector="+url.QueryEscape("kind=leak,floor!=0"), nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var sensors []dtos.Sensor
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		require.Len(t, sensors, 1)
+		assert.Equal(t, "0000000001", *sensors[0].SerialNumber)
+		assert.Equal(t, map[string]string{"kind": "leak", "floor": "2"}, sensors[0].Labels)
+	})
+
+	t.Run("fail, invalid selector", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sensors?selector="+url.QueryEscape("kind=a b"), nil))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("ok, history of matching sensors", func(t *testing.T) {
+		query := url.Values{
+			"selector":   {"kind=leak"},
+			"start_date": {"2023-01-01T00:00:00Z"},
+			"end_date":   {"2025-01-01T00:00:00Z"},
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sensors/history?"+query.Encode(), nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var series []dtos.SensorHistorySeries
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &series))
+		require.Len(t, series, 2)
+		for _, s := range series {
+			assert.NoError(t, s.Validate(nil))
+			assert.Len(t, s.History, 1)
+		}
+	})
+}
+
+func TestMergeChannels(t *testing.T) {
+	a, b := make(chan int), make(chan int)
+	merged := mergeChannels[int](a, b)
+
+	go func() {
+		a <- 1
+		close(a)
+		b <- 2
+		close(b)
+	}()
+
+	var got []int
+	for i := range merged {
+		got = append(got, i)
+	}
+	assert.ElementsMatch(t, []int{1, 2}, got)
+}
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func locationGetImpl(location *domain.Location) dtos.Location {
+	kind := string(location.Kind)
+	return dtos.Location{
+		ID:       &location.ID,
+		Kind:     &kind,
+		Name:     &location.Name,
+		ParentID: location.ParentID,
+	}
+}
+
+func locationDtos(locations []domain.Location) []dtos.Location {
+	res := make([]dtos.Location, 0, len(locations))
+	for _, l := range locations {
+		res = append(res, locationGetImpl(&l))
+	}
+	return res
+}
+
+func locationsGetImpl(ctx *gin.Context, uc UseCases) any {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	locations, err := uc.Location.GetLocations(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+	return listBody(ctx, locationDtos(locations), func(l dtos.Location) int64 { return *l.ID })
+}
+
+func locationsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationDtos := locationsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, locationDtos)
+		}
+	}
+}
+
+func locationsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationDtos := locationsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, locationDtos)
+		}
+	}
+}
+
+func locationsPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationDto := &dtos.LocationToCreate{}
+		if extractDto(ctx, locationDto) == nil {
+			location := domain.Location{
+				ParentID: locationDto.ParentID,
+				Kind:     domain.LocationKind(*locationDto.Kind),
+				Name:     *locationDto.Name,
+			}
+
+			created, err := uc.Location.CreateLocation(ctx, &location)
+			if err != nil {
+				if errors.Is(err, usecase.ErrInvalidLocation) || errors.Is(err, usecase.ErrLocationNotFound) {
+					abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+					return
+				}
+				abortWithError(ctx, err)
+				return
+			}
+
+			abortWithStatusDto(ctx, http.StatusOK, locationGetImpl(created))
+		}
+	}
+}
+
+func locationByIdCommonHandler(ctx *gin.Context, uc UseCases) *domain.Location {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	locationId, err := strconv.ParseInt(ctx.Param("location_id"), 10, 64)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return nil
+	}
+
+	location, err := uc.Location.GetLocationByID(ctx, locationId)
+	if errors.Is(err, usecase.ErrLocationNotFound) {
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+		return nil
+	} else if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	return location
+}
+
+func locationByIdGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		location := locationByIdCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, locationGetImpl(location))
+		}
+	}
+}
+
+func locationByIdHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		location := locationByIdCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, locationGetImpl(location))
+		}
+	}
+}
+
+func locationSensorsCommonHandler(ctx *gin.Context, uc UseCases) []dtos.Sensor {
+	location := locationByIdCommonHandler(ctx, uc)
+	if ctx.IsAborted() {
+		return nil
+	}
+
+	sensors, err := uc.Location.GetLocationSensors(ctx, location.ID)
+	if errors.Is(err, usecase.ErrLocationNotFound) {
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+		return nil
+	} else if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	sensorDtos := make([]dtos.Sensor, 0, len(sensors))
+	for _, sens := range sensors {
+		sensorDtos = append(sensorDtos, sensorGetImpl(&sens))
+	}
+	return sensorDtos
+}
+
+func locationSensorsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sensorDtos := locationSensorsCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, sensorDtos)
+		}
+	}
+}
+
+func locationSensorsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sensorDtos := locationSensorsCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, sensorDtos)
+		}
+	}
+}
+
+func locationSensorPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationId, err := strconv.ParseInt(ctx.Param("location_id"), 10, 64)
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		bindingDto := &dtos.SensorToLocationBinding{}
+		if extractDto(ctx, bindingDto) == nil {
+			err := uc.Location.AssignSensor(ctx, locationId, *bindingDto.SensorID)
+			if err != nil {
+				if errors.Is(err, usecase.ErrLocationNotFound) || errors.Is(err, usecase.ErrSensorNotFound) {
+					abortWithAPIError(ctx, http.StatusNotFound, err)
+					return
+				}
+				abortWithError(ctx, err)
+				return
+			}
+
+			ctx.Status(http.StatusCreated)
+		}
+	}
+}
+
+func setupLocationsHandler(r *gin.RouterGroup, uc UseCases) {
+	r.GET("", locationsGetHandler(uc))
+	r.HEAD("", locationsHeadHandler(uc))
+	r.POST("", locationsPostHandler(uc))
+	r.OPTIONS("", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPost))
+
+	r.GET("/:location_id", locationByIdGetHandler(uc))
+	r.HEAD("/:location_id", locationByIdHeadHandler(uc))
+	r.OPTIONS("/:location_id", optionsHandler(http.MethodGet, http.MethodHead))
+
+	r.GET("/:location_id/sensors", locationSensorsGetHandler(uc))
+	r.HEAD("/:location_id/sensors", locationSensorsHeadHandler(uc))
+	r.POST("/:location_id/sensors", locationSensorPostHandler(uc))
+	r.OPTIONS("/:location_id/sensors", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPost))
+}
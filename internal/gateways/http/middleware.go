@@ -0,0 +1,174 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"homework/internal/gateways/webhook"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/ratelimit"
+	"homework/internal/usecase"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader  = "X-Request-ID"
+	APIKeyHeader     = "X-API-Key"
+	RetryAfterHeader = "Retry-After"
+
+	requestIDContextKey  = "request_id"
+	rateLimitsContextKey = "rate_limits"
+	maxRequestIDLength   = 128
+)
+
+var errRateLimited = errors.New("rate limit exceeded")
+
+// validRequestID - accepts the client supplied IDs made of printable ASCII characters only,
+// so they can be safely echoed back and written to the logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDMiddleware - propagates the X-Request-ID header of the request or generates a new one,
+// the ID is echoed in the response and stored in the request context for the logs
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDContextKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// requestID - correlation ID of the request, empty if requestIDMiddleware is not installed
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDContextKey)
+}
+
+// accessLogMiddleware - replaces the gin text logger, a record per request is written after it is served
+func accessLogMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("size", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		logger.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+// recoveryMiddleware - logs the recovered panic and responds with 500
+func recoveryMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logger.ErrorContext(c.Request.Context(), "panic recovered", "panic", recovered)
+		abortWithAPIError(c, http.StatusInternalServerError, errInternal)
+	})
+}
+
+// automationDepthMiddleware - marks the requests sent by the automation webhooks with the length of the rule chain,
+// so the events they post are recognized by the loop protection. The depth is taken only along with a valid signature
+// made with the secret, so a client can't suppress the rules by sending the header
+func automationDepthMiddleware(secret []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		depth, ok := webhook.VerifyDepth(secret, c.GetHeader(webhook.DepthHeader), c.GetHeader(webhook.SignatureHeader), time.Now())
+		if ok {
+			c.Request = c.Request.WithContext(usecase.WithAutomationDepth(c.Request.Context(), depth))
+		}
+		c.Next()
+	}
+}
+
+// RateLimits - token bucket limits of the API requests by the client IP, by the X-API-Key header
+// and of the events by the sensor serial number, nil limiters are not applied.
+// Only the keys of APIKeys are limited by APIKey, the requests with the other keys are limited by IP only
+type RateLimits struct {
+	IP      *ratelimit.Limiter
+	APIKey  *ratelimit.Limiter
+	Sensor  *ratelimit.Limiter
+	APIKeys []string
+}
+
+// rateLimitMiddleware - rejects the requests over the client IP and API key limits with 429,
+// the sensor limit is left to the events handler, which knows the serial number once the body is parsed
+func rateLimitMiddleware(limits RateLimits) gin.HandlerFunc {
+	apiKeys := make(map[string]bool, len(limits.APIKeys))
+	for _, key := range limits.APIKeys {
+		apiKeys[key] = true
+	}
+	return func(c *gin.Context) {
+		c.Set(rateLimitsContextKey, limits)
+		if ok, retryAfter := limits.IP.Allow(c.ClientIP()); !ok {
+			abortThrottled(c, metrics.ThrottleScopeIP, retryAfter)
+			return
+		}
+		if key := c.GetHeader(APIKeyHeader); apiKeys[key] {
+			if ok, retryAfter := limits.APIKey.Allow(key); !ok {
+				abortThrottled(c, metrics.ThrottleScopeAPIKey, retryAfter)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// allowSensorEvent - takes a token of the sensor limit, the events are not limited
+// unless rateLimitMiddleware is installed. Only the registered sensors get a bucket, the events with a wrong
+// or unknown serial number are rejected or buffered for the provisioning by the usecase without taking one
+func allowSensorEvent(c *gin.Context, uc UseCases, serialNumber string) (bool, time.Duration) {
+	value, _ := c.Get(rateLimitsContextKey)
+	limits, _ := value.(RateLimits)
+	if !limits.Sensor.Enabled() || uc.Sensor == nil {
+		return true, 0
+	}
+	if registered, err := uc.Sensor.IsRegistered(c, serialNumber); err != nil || !registered {
+		return true, 0
+	}
+	return limits.Sensor.Allow(serialNumber)
+}
+
+// abortThrottled - responds with 429 and the whole seconds the client should wait before a retry
+func abortThrottled(c *gin.Context, scope metrics.ThrottleScope, retryAfter time.Duration) {
+	metrics.RequestThrottled(scope, metrics.ThrottleActionRejected)
+	seconds := int(math.Max(1, math.Ceil(retryAfter.Seconds())))
+	c.Header(RetryAfterHeader, strconv.Itoa(seconds))
+	abortWithAPIError(c, http.StatusTooManyRequests, fmt.Errorf("%w: too many requests by %s", errRateLimited, scope))
+}
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/gateways/webhook"
+	"homework/internal/logging"
+	"homework/internal/ratelimit"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	logs := &bytes.Buffer{}
+	logger, err := logging.New(logs, logging.Options{Format: logging.FormatJSON})
+	require.NoError(t, err)
+
+	engine := gin.New()
+	engine.ContextWithFallback = true
+	engine.Use(requestIDMiddleware(), accessLogMiddleware(logger), recoveryMiddleware(logger))
+	engine.GET("/fail", func(c *gin.Context) {
+		abortWithAPIError(c, http.StatusNotFound, errors.New("not found"))
+	})
+	engine.GET("/panic", func(*gin.Context) {
+		panic("boom")
+	})
+
+	do := func(path, id string) (*httptest.ResponseRecorder, dtos.Problem) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if id != "" {
+			req.Header.Set(RequestIDHeader, id)
+		}
+		engine.ServeHTTP(w, req)
+
+		var dto dtos.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &dto))
+		return w, dto
+	}
+
+	t.Run("ok, client id is propagated", func(t *testing.T) {
+		logs.Reset()
+		w, dto := do("/fail", "client-id-1")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "client-id-1", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "client-id-1", dto.RequestID)
+		assert.Contains(t, logs.String(), `"request_id":"client-id-1"`)
+		assert.Contains(t, logs.String(), `"level":"WARN"`)
+	})
+
+	t.Run("ok, invalid id is replaced", func(t *testing.T) {
+		w, dto := do("/fail", strings.Repeat("x", maxRequestIDLength+1))
+
+		id := w.Header().Get(RequestIDHeader)
+		assert.Len(t, id, 36)
+		assert.Equal(t, id, dto.RequestID)
+	})
+
+	t.Run("ok, panic is logged with the request id", func(t *testing.T) {
+		logs.Reset()
+		w, dto := do("/panic", "client-id-2")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "client-id-2", dto.RequestID)
+		assert.Equal(t, "internal_error", *dto.Code)
+		assert.Contains(t, logs.String(), `"msg":"panic recovered"`)
+	})
+}
+
+func TestAutomationDepthMiddleware(t *testing.T) {
+	secret := []byte("secret")
+	engine := gin.New()
+	engine.Use(automationDepthMiddleware(secret))
+	engine.GET("/depth", func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.Itoa(usecase.AutomationDepth(c.Request.Context())))
+	})
+
+	now := time.Now()
+	for name, tc := range map[string]struct {
+		depth, signature, expected string
+	}{
+		"no header":          {"", "", "0"},
+		"signed":             {"2", webhook.Sign(secret, 2, now), "2"},
+		"unsigned":           {"3", "", "0"},
+		"signed other depth": {"3", webhook.Sign(secret, 2, now), "0"},
+		"other secret":       {"2", webhook.Sign([]byte("other"), 2, now), "0"},
+		"expired signature":  {"2", webhook.Sign(secret, 2, now.Add(-time.Hour)), "0"},
+		"negative":           {"-1", webhook.Sign(secret, -1, now), "0"},
+		"not a number":       {"two", "", "0"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/depth", nil)
+		if tc.depth != "" {
+			req.Header.Set(webhook.DepthHeader, tc.depth)
+		}
+		if tc.signature != "" {
+			req.Header.Set(webhook.SignatureHeader, tc.signature)
+		}
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, tc.expected, w.Body.String(), name)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	engine := gin.New()
+	apiKeyLimiter := ratelimit.New(ratelimit.Limit{Rate: 0.01, Burst: 1})
+	engine.Use(rateLimitMiddleware(RateLimits{
+		IP:      ratelimit.New(ratelimit.Limit{Rate: 0.01, Burst: 3}),
+		APIKey:  apiKeyLimiter,
+		APIKeys: []string{"key"},
+	}))
+	engine.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	get := func(ip, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = ip + ":40000"
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("fail, over the API key limit", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, get("10.0.0.1", "key").Code)
+		w := get("10.0.0.2", "key")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "100", w.Header().Get(RetryAfterHeader))
+
+		var apiErr dtos.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+		assert.Equal(t, "rate_limited", *apiErr.Code)
+		assert.Contains(t, apiErr.Detail, "api_key")
+	})
+
+	t.Run("ok, unknown API keys are limited by IP only", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusNoContent, get("10.0.0.5", "unknown-"+strconv.Itoa(i)).Code)
+		}
+		assert.Equal(t, 1, apiKeyLimiter.Len(), "only the known key has a bucket")
+		assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.5", "unknown-3").Code)
+	})
+
+	t.Run("fail, over the client IP limit", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, get("10.0.0.3", "").Code)
+		require.Equal(t, http.StatusNoContent, get("10.0.0.3", "").Code)
+		require.Equal(t, http.StatusNoContent, get("10.0.0.3", "").Code)
+		w := get("10.0.0.3", "")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get(RetryAfterHeader))
+		assert.Equal(t, http.StatusNoContent, get("10.0.0.4", "").Code)
+	})
+}
+
+func TestRateLimitClientIP(t *testing.T) {
+	get := func(s *Server, remoteAddr, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodHead, "/api/openapi.json", nil)
+		req.RemoteAddr = remoteAddr + ":40000"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		s.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+	limits := func() RateLimits {
+		return RateLimits{IP: ratelimit.New(ratelimit.Limit{Rate: 0.01, Burst: 1})}
+	}
+
+	t.Run("ok, X-Forwarded-For is ignored by default", func(t *testing.T) {
+		s := NewServer(UseCases{}, WithRateLimits(limits()))
+		require.Equal(t, http.StatusOK, get(s, "10.0.0.1", "192.0.2.1"))
+		assert.Equal(t, http.StatusTooManyRequests, get(s, "10.0.0.1", "192.0.2.2"), "a spoofed header doesn't reset the limit")
+	})
+
+	t.Run("ok, X-Forwarded-For of a trusted proxy", func(t *testing.T) {
+		s := NewServer(UseCases{}, WithRateLimits(limits()), WithTrustedProxies([]string{"10.0.0.0/8"}))
+		require.Equal(t, http.StatusOK, get(s, "10.0.0.1", "192.0.2.1"))
+		assert.Equal(t, http.StatusOK, get(s, "10.0.0.1", "192.0.2.2"))
+		assert.Equal(t, http.StatusTooManyRequests, get(s, "10.0.0.2", "192.0.2.1"))
+		require.Equal(t, http.StatusOK, get(s, "172.16.0.1", "192.0.2.3"))
+		assert.Equal(t, http.StatusTooManyRequests, get(s, "172.16.0.1", "192.0.2.4"), "an untrusted peer is limited by its address")
+	})
+}
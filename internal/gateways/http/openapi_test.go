@@ -0,0 +1,267 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"homework/api"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/codec"
+	"homework/internal/usecase"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	actuatorInMemory "homework/internal/repository/actuator/inmemory"
+	automationInMemory "homework/internal/repository/automation/inmemory"
+	eventInMemory "homework/internal/repository/event/inmemory"
+	locationInMemory "homework/internal/repository/location/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+	subscriptionInMemory "homework/internal/repository/subscription/inmemory"
+	userInMemory "homework/internal/repository/user/inmemory"
+)
+
+func init() {
+	// the binary bodies are checked against the same schemas as JSON
+	for _, c := range []codec.Codec{codec.Msgpack(), codec.CBOR()} {
+		openapi3filter.RegisterBodyDecoder(c.ContentType(), codecBodyDecoder(c))
+	}
+}
+
+func codecBodyDecoder(c codec.Codec) openapi3filter.BodyDecoder {
+	return func(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (any, error) {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		var doc any
+		if err := c.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		return value, json.Unmarshal(encoded, &value)
+	}
+}
+
+func specRouter(t *testing.T) routers.Router {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPI)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	return router
+}
+
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// specValidationMiddleware - fails the test if a response doesn't match the spec or a request the spec rejects
+// is served successfully, so the handlers can't drift from the spec
+func specValidationMiddleware(t *testing.T, router routers.Router) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			t.Errorf("%s %s is not described by the spec: %v", c.Request.Method, c.Request.URL, err)
+			return
+		}
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		}
+		requestErr := openapi3filter.ValidateRequest(c, requestInput)
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if requestErr != nil && recorder.Status() < http.StatusBadRequest {
+			t.Errorf("%s %s is invalid by the spec, but served with %d: %v",
+				c.Request.Method, c.Request.URL, recorder.Status(), requestErr)
+		}
+		err = openapi3filter.ValidateResponse(c, &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 recorder.Status(),
+			Header:                 recorder.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+			Options: &openapi3filter.Options{
+				IncludeResponseStatus: true,
+				ExcludeResponseBody:   c.Request.Method == http.MethodHead,
+			},
+		})
+		if err != nil {
+			t.Errorf("%s %s response %d doesn't match the spec: %v", c.Request.Method, c.Request.URL, recorder.Status(), err)
+		}
+	}
+}
+
+func TestSpecCoversRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := specRouter(t)
+	server := NewServer(UseCases{}, WithAdminToken("token"))
+	param := regexp.MustCompile(`:[a-z_]+`)
+
+	for _, route := range server.router.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/") || strings.Contains(route.Path, "*") || route.Path == "/api/openapi.json" {
+			continue // the probes, the docs and the spec itself
+		}
+		path := param.ReplaceAllString(route.Path, "1")
+		req := httptest.NewRequest(route.Method, path, nil)
+		_, _, err := router.FindRoute(req)
+		assert.NoError(t, err, "%s %s", route.Method, route.Path)
+	}
+}
+
+func TestDocs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := NewServer(UseCases{})
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/openapi.json")
+	require.Equal(t, http.StatusOK, w.Code)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	w = get("/api/docs/")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "swagger-ui")
+	w = get("/api/docs/swagger-initializer.js")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "../openapi.json")
+	assert.Equal(t, http.StatusOK, get("/api/docs/swagger-ui-bundle.js").Code)
+	assert.Equal(t, http.StatusMovedPermanently, get("/api/docs").Code)
+}
+
+func TestSpecConformance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sr := sensorInMemory.NewSensorRepository()
+	lr := locationInMemory.NewLocationRepository()
+	actuator := usecase.NewActuator(
+		actuatorInMemory.NewActuatorRepository(),
+		actuatorInMemory.NewCommandRepository(),
+		subscriptionInMemory.NewSubscriptionRepository[domain.Command](),
+	)
+	uc := UseCases{
+		Event:    usecase.NewEvent(eventInMemory.NewEventRepository(), sr, subscriptionInMemory.NewSubscriptionRepository[domain.Event]()),
+		Sensor:   usecase.NewSensor(sr),
+		User:     usecase.NewUser(userInMemory.NewUserRepository(), userInMemory.NewSensorOwnerRepository(), sr, lr),
+		Location: usecase.NewLocation(lr, sr),
+		Actuator: actuator,
+		Automation: usecase.NewAutomation(
+			automationInMemory.NewAutomationRepository(), sr, actuator, nil,
+		),
+	}
+
+	r := gin.New()
+	r.Use(specValidationMiddleware(t, specRouter(t)))
+	apiGroup := r.Group("/api")
+	setupRouter(apiGroup, uc, nil)
+	setupRouter(apiGroup.Group("/v2", apiVersionMiddleware(APIVersionV2)), uc, nil)
+
+	do := func(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", JSONType)
+		}
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	expect := func(code int, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := do(method, path, body, headers...)
+		require.Equal(t, code, w.Code, "%s %s: %s", method, path, w.Body.String())
+		return w
+	}
+
+	for i := 1; i <= 3; i++ {
+		expect(http.StatusOK, http.MethodPost, "/api/sensors",
+			fmt.Sprintf(`{"serial_number":"000000000%d","type":"cc","description":"door","is_active":true}`, i))
+	}
+	expect(http.StatusUnprocessableEntity, http.MethodPost, "/api/sensors", `{"serial_number":"1","type":"cc"}`)
+	expect(http.StatusCreated, http.MethodPost, "/api/events", `{"sensor_serial_number":"0000000001","payload":1}`)
+	expect(http.StatusOK, http.MethodGet, "/api/sensors", "")
+	expect(http.StatusOK, http.MethodHead, "/api/sensors", "")
+	expect(http.StatusOK, http.MethodGet, "/api/sensors", "", "Accept", MsgpackType)
+	expect(http.StatusOK, http.MethodGet, "/api/sensors/1", "")
+	expect(http.StatusNotFound, http.MethodGet, "/api/sensors/42", "")
+	expect(http.StatusOK, http.MethodGet, "/api/v2/sensors/1", "")
+	expect(http.StatusOK, http.MethodPut, "/api/sensors/1/labels", `{"room":"hall"}`)
+	expect(http.StatusOK, http.MethodGet, "/api/sensors/1/labels", "")
+	expect(http.StatusOK, http.MethodGet, "/api/sensors/1/calibration", "")
+	expect(http.StatusOK, http.MethodGet, "/api/sensor-types", "")
+
+	from := url.QueryEscape(time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	to := url.QueryEscape(time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	expect(http.StatusOK, http.MethodGet, "/api/sensors/1/history?start_date="+from+"&end_date="+to, "")
+
+	w := expect(http.StatusOK, http.MethodGet, "/api/v2/sensors?limit=2", "")
+	var page Page[map[string]any]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Items, 2)
+	require.NotEmpty(t, page.NextCursor)
+	w = expect(http.StatusOK, http.MethodGet, "/api/v2/sensors?limit=2&cursor="+page.NextCursor, "")
+	page = Page[map[string]any]{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextCursor)
+	expect(http.StatusUnprocessableEntity, http.MethodGet, "/api/v2/sensors?cursor=not-a-cursor", "")
+	expect(http.StatusOK, http.MethodHead, "/api/v2/sensors", "")
+
+	expect(http.StatusOK, http.MethodPost, "/api/users", `{"name":"alice"}`)
+	expect(http.StatusCreated, http.MethodPost, "/api/users/1/sensors", `{"sensor_id":1}`)
+	expect(http.StatusOK, http.MethodGet, "/api/users/1/sensors", "")
+
+	expect(http.StatusOK, http.MethodPost, "/api/locations", `{"kind":"home","name":"home"}`)
+	expect(http.StatusOK, http.MethodGet, "/api/locations", "")
+	expect(http.StatusOK, http.MethodGet, "/api/v2/locations", "")
+	expect(http.StatusOK, http.MethodGet, "/api/locations/1", "")
+
+	expect(http.StatusOK, http.MethodPost, "/api/actuators", `{"serial_number":"0000000001","type":"relay"}`)
+	expect(http.StatusOK, http.MethodGet, "/api/actuators", "")
+	expect(http.StatusOK, http.MethodGet, "/api/v2/actuators", "")
+	expect(http.StatusOK, http.MethodGet, "/api/actuators/1", "")
+
+	expect(http.StatusCreated, http.MethodPost, "/api/automations",
+		`{"name":"door","trigger":{"kind":"event","sensor_id":1,"operator":"eq","value":1},`+
+			`"actions":[{"kind":"command","actuator_id":1,"command":"on"}]}`)
+	expect(http.StatusOK, http.MethodGet, "/api/automations", "")
+	expect(http.StatusOK, http.MethodGet, "/api/v2/automations", "")
+	expect(http.StatusOK, http.MethodGet, "/api/automations/1", "")
+}
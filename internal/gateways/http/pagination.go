@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	APIVersionV1 = 1
+	APIVersionV2 = 2
+
+	apiVersionContextKey = "api_version"
+
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+var errInvalidPagination = errors.New("invalid pagination parameters")
+
+// Page - v2 representation of the lists, the items are ordered by id
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// apiVersionMiddleware - marks the requests of the version group, the handlers shared by the versions
+// pick the representation by it
+func apiVersionMiddleware(version int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionContextKey, version)
+		c.Next()
+	}
+}
+
+// apiVersion - version of the API the request is served by, v1 unless apiVersionMiddleware says otherwise
+func apiVersion(c *gin.Context) int {
+	if version := c.GetInt(apiVersionContextKey); version != 0 {
+		return version
+	}
+	return APIVersionV1
+}
+
+// listBody - the list as is for v1 and its page by the limit and cursor query parameters for v2,
+// responds with 422 on invalid parameters
+func listBody[T any](ctx *gin.Context, items []T, id func(T) int64) any {
+	if apiVersion(ctx) < APIVersionV2 {
+		return items
+	}
+
+	limit, after, err := pageQuery(ctx)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return nil
+	}
+
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool { return id(sorted[i]) < id(sorted[j]) })
+	start := sort.Search(len(sorted), func(i int) bool { return id(sorted[i]) > after })
+
+	page := Page[T]{Items: sorted[start:]}
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(id(page.Items[limit-1]))
+	}
+	return page
+}
+
+// pageQuery - page size and the id the page starts after
+func pageQuery(ctx *gin.Context) (int, int64, error) {
+	limit := DefaultPageLimit
+	if value, ok := ctx.GetQuery("limit"); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > MaxPageLimit {
+			return 0, 0, fmt.Errorf("%w: limit must be an integer in [1, %d]", errInvalidPagination, MaxPageLimit)
+		}
+		limit = parsed
+	}
+
+	var after int64
+	if value, ok := ctx.GetQuery("cursor"); ok {
+		parsed, err := decodeCursor(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%w: malformed cursor", errInvalidPagination)
+		}
+		after = parsed
+	}
+	return limit, after, nil
+}
+
+// encodeCursor - opaque cursor of the page following the item, the clients must not rely on its format
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(decoded), 10, 64)
+}
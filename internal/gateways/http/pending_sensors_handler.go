@@ -0,0 +1,187 @@
+package http
+
+import (
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+)
+
+var (
+	errProvisioningDisabled = errors.New("sensor provisioning is disabled")
+	errClaimUserMismatch    = errors.New("user_id differs from the user of the path")
+)
+
+func pendingSensorGetImpl(pending *domain.PendingSensor) dtos.PendingSensor {
+	firstSeen := strfmt.DateTime(pending.FirstSeen)
+	lastSeen := strfmt.DateTime(pending.LastSeen)
+	dto := dtos.PendingSensor{
+		Buffered:     &pending.Buffered,
+		FirstSeen:    &firstSeen,
+		LastSeen:     &lastSeen,
+		Seen:         &pending.Seen,
+		SerialNumber: &pending.SerialNumber,
+	}
+	for _, event := range pending.Events {
+		timestamp := strfmt.DateTime(event.Timestamp)
+		dto.Readings = append(dto.Readings, &dtos.PendingSensorReading{
+			Payload:   &event.Payload,
+			Timestamp: &timestamp,
+			Values:    event.Values,
+		})
+	}
+	return dto
+}
+
+// checkProvisioning - responds with 404 unless the provisioning is enabled
+func checkProvisioning(ctx *gin.Context, uc UseCases) bool {
+	if uc.Provisioning == nil {
+		abortWithAPIError(ctx, http.StatusNotFound, errProvisioningDisabled)
+		return false
+	}
+	return true
+}
+
+func pendingSensorsGetImpl(ctx *gin.Context, uc UseCases) []dtos.PendingSensor {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+	if !checkProvisioning(ctx, uc) {
+		return nil
+	}
+
+	pending, err := uc.Provisioning.GetPendingSensors(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	pendingDtos := make([]dtos.PendingSensor, 0, len(pending))
+	for _, p := range pending {
+		pendingDtos = append(pendingDtos, pendingSensorGetImpl(&p))
+	}
+	return pendingDtos
+}
+
+func pendingSensorsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		pendingDtos := pendingSensorsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, pendingDtos)
+		}
+	}
+}
+
+func pendingSensorsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		pendingDtos := pendingSensorsGetImpl(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, pendingDtos)
+		}
+	}
+}
+
+func pendingSensorCommonHandler(ctx *gin.Context, uc UseCases) *domain.PendingSensor {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+	if !checkProvisioning(ctx, uc) {
+		return nil
+	}
+
+	pending, err := uc.Provisioning.GetPendingSensor(ctx, ctx.Param("serial_number"))
+	if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+	return pending
+}
+
+func pendingSensorGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		pending := pendingSensorCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, pendingSensorGetImpl(pending))
+		}
+	}
+}
+
+func pendingSensorHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		pending := pendingSensorCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, pendingSensorGetImpl(pending))
+		}
+	}
+}
+
+func pendingSensorDeleteHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !checkProvisioning(ctx, uc) {
+			return
+		}
+
+		if err := uc.Provisioning.DismissPendingSensor(ctx, ctx.Param("serial_number")); err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// pendingSensorClaimImpl - claims the pending sensor of the path for the user of the claim,
+// 0 leaves the sensor unattached
+func pendingSensorClaimImpl(ctx *gin.Context, uc UseCases, claimDto *dtos.SensorClaim) {
+	sensor := &domain.Sensor{
+		SerialNumber: ctx.Param("serial_number"),
+		Type:         domain.SensorType(*claimDto.Type),
+		Description:  claimDto.Description,
+		IsActive:     true,
+	}
+	res, err := uc.Provisioning.ClaimSensor(ctx, sensor, claimDto.UserID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	replayed, rejected := int64(res.Replayed), int64(res.Rejected)
+	sensorDto := sensorGetImpl(res.Sensor)
+	abortWithStatusDto(ctx, http.StatusCreated, dtos.SensorClaimResult{
+		Rejected: &rejected,
+		Replayed: &replayed,
+		Sensor:   &sensorDto,
+	})
+}
+
+func pendingSensorClaimPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !checkProvisioning(ctx, uc) {
+			return
+		}
+
+		claimDto := &dtos.SensorClaim{}
+		if extractDto(ctx, claimDto) == nil {
+			pendingSensorClaimImpl(ctx, uc, claimDto)
+		}
+	}
+}
+
+func setupPendingSensorsHandler(r *gin.RouterGroup, uc UseCases) {
+	r.GET("", pendingSensorsGetHandler(uc))
+	r.HEAD("", pendingSensorsHeadHandler(uc))
+	r.OPTIONS("", optionsHandler(http.MethodGet, http.MethodHead))
+
+	r.GET("/:serial_number", pendingSensorGetHandler(uc))
+	r.HEAD("/:serial_number", pendingSensorHeadHandler(uc))
+	r.DELETE("/:serial_number", pendingSensorDeleteHandler(uc))
+	r.OPTIONS("/:serial_number", optionsHandler(http.MethodGet, http.MethodHead, http.MethodDelete))
+
+	r.POST("/:serial_number/claim", pendingSensorClaimPostHandler(uc))
+	r.OPTIONS("/:serial_number/claim", optionsHandler(http.MethodPost))
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventInMemory "homework/internal/repository/event/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+	subscriptionInMemory "homework/internal/repository/subscription/inmemory"
+	userInMemory "homework/internal/repository/user/inmemory"
+)
+
+func pendingSensorsRouter(t *testing.T, provisioning bool) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	sr := sensorInMemory.NewSensorRepository()
+	ur := userInMemory.NewUserRepository()
+	uc := UseCases{
+		Event:  usecase.NewEvent(eventInMemory.NewEventRepository(), sr, subscriptionInMemory.NewSubscriptionRepository[domain.Event]()),
+		Sensor: usecase.NewSensor(sr),
+		User:   usecase.NewUser(ur, userInMemory.NewSensorOwnerRepository(), sr, nil),
+	}
+	if provisioning {
+		uc.Provisioning = usecase.NewProvisioning(sensorInMemory.NewPendingSensorRepository(), ur, uc.Sensor, uc.User, uc.Event)
+		uc.Event.SetProvisioning(uc.Provisioning)
+	}
+	_, err := uc.User.RegisterUser(context.Background(), &domain.User{Name: "alice"})
+	require.NoError(t, err)
+
+	r := gin.New()
+	setupEventsHandler(r.Group("/events"), uc)
+	setupUsersHandler(r.Group("/users"), uc)
+	setupPendingSensorsHandler(r.Group("/admin/pending-sensors"), uc)
+	return r
+}
+
+func TestPendingSensors(t *testing.T) {
+	r := pendingSensorsRouter(t, true)
+
+	for _, payload := range []string{"2150", "13000", "2200"} {
+		w := serveJSON(r, http.MethodPost, "/events", `{"sensor_serial_number":"1234567890","payload":`+payload+`}`)
+		require.Equal(t, http.StatusAccepted, w.Code)
+	}
+	require.Equal(t, http.StatusAccepted,
+		serveJSON(r, http.MethodPost, "/events", `{"sensor_serial_number":"1234567891","payload":1}`).Code)
+
+	t.Run("ok, list", func(t *testing.T) {
+		w := serveJSON(r, http.MethodGet, "/admin/pending-sensors", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var pending []dtos.PendingSensor
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pending))
+		require.Len(t, pending, 2)
+		assert.Equal(t, "1234567890", *pending[0].SerialNumber)
+		assert.Equal(t, int64(3), *pending[0].Seen)
+		assert.Empty(t, pending[0].Readings)
+	})
+
+	t.Run("ok, get", func(t *testing.T) {
+		w := serveJSON(r, http.MethodGet, "/admin/pending-sensors/1234567890", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var pending dtos.PendingSensor
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pending))
+		require.Len(t, pending.Readings, 3)
+		assert.Equal(t, int64(2150), *pending.Readings[0].Payload)
+	})
+
+	t.Run("fail, claim for unknown user", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/admin/pending-sensors/1234567890/claim", `{"type":"temperature","user_id":2}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusOK, serveJSON(r, http.MethodGet, "/admin/pending-sensors/1234567890", "").Code)
+	})
+
+	t.Run("fail, owner claim for other user", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/users/1/pending-sensors/1234567890/claim", `{"type":"temperature","user_id":2}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("fail, owner claim for unknown user", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/users/2/pending-sensors/1234567890/claim", `{"type":"temperature"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusOK, serveJSON(r, http.MethodGet, "/admin/pending-sensors/1234567890", "").Code)
+	})
+
+	t.Run("ok, claim", func(t *testing.T) {
+		w := serveJSON(r, http.MethodPost, "/admin/pending-sensors/1234567890/claim", `{"type":"temperature","user_id":1}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var res dtos.SensorClaimResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, int64(2), *res.Replayed)
+		assert.Equal(t, int64(1), *res.Rejected)
+		assert.Equal(t, int64(2200), *res.Sensor.CurrentState)
+
+		assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodGet, "/admin/pending-sensors/1234567890", "").Code)
+		w = serveJSON(r, http.MethodPost, "/events", `{"sensor_serial_number":"1234567890","payload":2300}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("ok, owner claim", func(t *testing.T) {
+		require.Equal(t, http.StatusAccepted,
+			serveJSON(r, http.MethodPost, "/events", `{"sensor_serial_number":"1234567892","payload":1}`).Code)
+
+		w := serveJSON(r, http.MethodPost, "/users/1/pending-sensors/1234567892/claim", `{"type":"cc"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var res dtos.SensorClaimResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, int64(1), *res.Replayed)
+		assert.Equal(t, "1234567892", *res.Sensor.SerialNumber)
+		assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodGet, "/admin/pending-sensors/1234567892", "").Code)
+	})
+
+	t.Run("ok, dismiss", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, serveJSON(r, http.MethodDelete, "/admin/pending-sensors/1234567891", "").Code)
+		assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodDelete, "/admin/pending-sensors/1234567891", "").Code)
+	})
+}
+
+func TestPendingSensorsDisabled(t *testing.T) {
+	r := pendingSensorsRouter(t, false)
+
+	w := serveJSON(r, http.MethodPost, "/events", `{"sensor_serial_number":"1234567890","payload":1}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodGet, "/admin/pending-sensors", "").Code)
+	w = serveJSON(r, http.MethodPost, "/admin/pending-sensors/1234567890/claim", `{"type":"temperature"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"errors"
+	"homework/internal/backup"
+	"homework/internal/gateways/http/codec"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	oaerrors "github.com/go-openapi/errors"
+)
+
+const (
+	ProblemType ContentType = "application/problem+json" // Content-Type of the JSON errors (RFC 7807)
+
+	problemTypePrefix    = "urn:home-controller:problem:"
+	codeValidationFailed = "validation_failed"
+)
+
+var (
+	errMalformedBody = errors.New("malformed request body")
+	errInternal      = errors.New("internal server error")
+)
+
+// errorMapping - HTTP status and stable machine-readable code of the errors wrapping the sentinel
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings - the sentinel errors the clients may branch on, the first matching one is used
+var errorMappings = []errorMapping{
+	{usecase.ErrWrongSensorSerialNumber, http.StatusUnprocessableEntity, "wrong_sensor_serial_number"},
+	{usecase.ErrWrongSensorType, http.StatusUnprocessableEntity, "wrong_sensor_type"},
+	{usecase.ErrInvalidEventTimestamp, http.StatusUnprocessableEntity, "invalid_event_timestamp"},
+	{usecase.ErrInvalidUserName, http.StatusUnprocessableEntity, "invalid_user_name"},
+	{usecase.ErrSensorNotFound, http.StatusNotFound, "sensor_not_found"},
+	{usecase.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{usecase.ErrEventNotFound, http.StatusNotFound, "event_not_found"},
+	{usecase.ErrSubscriptionNotFound, http.StatusNotFound, "subscription_not_found"},
+	{usecase.ErrPayloadOutOfRange, http.StatusUnprocessableEntity, "payload_out_of_range"},
+	{usecase.ErrInvalidSensorChannel, http.StatusUnprocessableEntity, "invalid_sensor_channel"},
+	{usecase.ErrUnknownChannel, http.StatusUnprocessableEntity, "unknown_channel"},
+	{usecase.ErrInvalidCalibration, http.StatusUnprocessableEntity, "invalid_calibration"},
+	{usecase.ErrLocationNotFound, http.StatusNotFound, "location_not_found"},
+	{usecase.ErrInvalidLocation, http.StatusUnprocessableEntity, "invalid_location"},
+	{usecase.ErrInvalidSensorLabels, http.StatusUnprocessableEntity, "invalid_sensor_labels"},
+	{usecase.ErrActuatorNotFound, http.StatusNotFound, "actuator_not_found"},
+	{usecase.ErrWrongActuatorSN, http.StatusUnprocessableEntity, "wrong_actuator_serial_number"},
+	{usecase.ErrWrongActuatorType, http.StatusUnprocessableEntity, "wrong_actuator_type"},
+	{usecase.ErrCommandNotFound, http.StatusNotFound, "command_not_found"},
+	{usecase.ErrInvalidCommand, http.StatusUnprocessableEntity, "invalid_command"},
+	{usecase.ErrCommandConflict, http.StatusConflict, "command_conflict"},
+	{usecase.ErrRuleNotFound, http.StatusNotFound, "rule_not_found"},
+	{usecase.ErrInvalidRule, http.StatusUnprocessableEntity, "invalid_rule"},
+	{usecase.ErrJobNotFound, http.StatusNotFound, "job_not_found"},
+	{usecase.ErrInvalidJob, http.StatusUnprocessableEntity, "invalid_job"},
+	{usecase.ErrJobRunning, http.StatusConflict, "job_running"},
+	{usecase.ErrDuplicateEvent, http.StatusConflict, "duplicate_event"},
+	{usecase.ErrPendingSensorNotFound, http.StatusNotFound, "pending_sensor_not_found"},
+	{usecase.ErrSensorVersionConflict, http.StatusPreconditionFailed, "precondition_failed"},
+	{backup.ErrInvalidArchive, http.StatusUnprocessableEntity, "invalid_backup"},
+	{backup.ErrUnsupportedVersion, http.StatusUnprocessableEntity, "unsupported_backup_version"},
+	{backup.ErrChecksumMismatch, http.StatusUnprocessableEntity, "backup_checksum_mismatch"},
+
+	{UnsupportedContentType, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	{UnsupportedAcceptType, http.StatusNotAcceptable, "not_acceptable"},
+	{errMalformedBody, http.StatusBadRequest, "malformed_body"},
+	{errPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{errRateLimited, http.StatusTooManyRequests, "rate_limited"},
+	{ErrAdminUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{errEmptyEvent, http.StatusUnprocessableEntity, "empty_event"},
+	{errIdempotencyKeyTooLong, http.StatusUnprocessableEntity, "invalid_idempotency_key"},
+	{errIdempotencyKeyMismatch, http.StatusUnprocessableEntity, "invalid_idempotency_key"},
+	{errConfigUnavailable, http.StatusNotFound, "config_unavailable"},
+	{errBackupUnavailable, http.StatusNotFound, "backup_unavailable"},
+	{errBackupTooLarge, http.StatusRequestEntityTooLarge, "backup_too_large"},
+	{errProvisioningDisabled, http.StatusNotFound, "provisioning_disabled"},
+	{errClaimUserMismatch, http.StatusUnprocessableEntity, "invalid_claim"},
+	{errInvalidPagination, http.StatusUnprocessableEntity, "invalid_pagination"},
+	{errInternal, http.StatusInternalServerError, "internal_error"},
+}
+
+// lookupErrorMapping - mapping of the first sentinel the error wraps
+func lookupErrorMapping(err error) (errorMapping, bool) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+// abortWithError - responds with the status the error is mapped to, the unknown errors are 500
+func abortWithError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if m, ok := lookupErrorMapping(err); ok {
+		status = m.status
+	}
+	abortWithAPIError(ctx, status, err)
+}
+
+// abortWithAPIError - responds with the problem of the error and the given status, the error itself
+// is attached to the context for the access log, the clients only get the details safe to expose
+func abortWithAPIError(ctx *gin.Context, status int, err error) {
+	_ = ctx.Error(err)
+	if responseCodec(ctx).ContentType() == codec.JSONType {
+		ctx.Header("Content-Type", ProblemType)
+	}
+	abortWithStatusDto(ctx, status, newProblem(ctx, status, err))
+}
+
+func newProblem(ctx *gin.Context, status int, err error) *dtos.Problem {
+	if status >= http.StatusInternalServerError {
+		err = errInternal
+	}
+	code := statusCode(status)
+	title := http.StatusText(status)
+	detail := err.Error()
+	var invalidParams []*dtos.ProblemInvalidParam
+
+	if m, ok := lookupErrorMapping(err); ok {
+		code, title, detail = m.code, capitalize(m.err.Error()), errorDetail(err, m.err)
+	} else if params := validationParams(err); len(params) > 0 {
+		code, detail, invalidParams = codeValidationFailed, "request body is invalid", params
+	}
+
+	problemType := problemTypePrefix + code
+	statusCode := int64(status)
+	return &dtos.Problem{
+		Type:          &problemType,
+		Title:         &title,
+		Status:        &statusCode,
+		Code:          &code,
+		Detail:        detail,
+		Instance:      ctx.Request.URL.Path,
+		RequestID:     requestID(ctx),
+		InvalidParams: invalidParams,
+	}
+}
+
+// statusCode - code of the errors without a mapping, e.g. not_found
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// errorDetail - message of the outermost error starting with the sentinel message, i.e. the one
+// the sentinel is wrapped in with "%w: details", the wrappers above add the internal context
+// (ids, dumps of the entities) and are only logged
+func errorDetail(err, sentinel error) string {
+	queue := []error{err}
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		if strings.HasPrefix(e.Error(), sentinel.Error()) && errors.Is(e, sentinel) {
+			return e.Error()
+		}
+		switch u := e.(type) {
+		case interface{ Unwrap() error }:
+			if inner := u.Unwrap(); inner != nil {
+				queue = append(queue, inner)
+			}
+		case interface{ Unwrap() []error }:
+			queue = append(queue, u.Unwrap()...)
+		}
+	}
+	return sentinel.Error()
+}
+
+// validationParams - field errors of the DTO validation, nil if the error is not a validation one
+func validationParams(err error) []*dtos.ProblemInvalidParam {
+	var params []*dtos.ProblemInvalidParam
+	var collect func(error)
+	collect = func(e error) {
+		switch v := e.(type) {
+		case *oaerrors.CompositeError:
+			for _, inner := range v.Errors {
+				collect(inner)
+			}
+		case *oaerrors.Validation:
+			name, reason := v.Name, v.Error()
+			params = append(params, &dtos.ProblemInvalidParam{Name: &name, Reason: &reason})
+		}
+	}
+
+	var composite *oaerrors.CompositeError
+	var validation *oaerrors.Validation
+	switch {
+	case errors.As(err, &composite):
+		collect(composite)
+	case errors.As(err, &validation):
+		collect(validation)
+	}
+	return params
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
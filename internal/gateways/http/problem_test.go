@@ -0,0 +1,101 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework/internal/gateways/http/dtos"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorDetail(t *testing.T) {
+	err := fmt.Errorf("cannot save event {1 0000000001 {}}: %w", fmt.Errorf("%w: %q", usecase.ErrUnknownChannel, "humidity"))
+	assert.Equal(t, `unknown sensor channel: "humidity"`, errorDetail(err, usecase.ErrUnknownChannel))
+
+	err = fmt.Errorf("invalid sensor serial number in event {1 0000000001 {}}: %w", usecase.ErrSensorNotFound)
+	assert.Equal(t, "sensor not found", errorDetail(err, usecase.ErrSensorNotFound))
+
+	err = errors.Join(errors.New("first"), fmt.Errorf("%w: rule 1", usecase.ErrInvalidRule))
+	assert.Equal(t, "invalid automation rule: rule 1", errorDetail(err, usecase.ErrInvalidRule))
+}
+
+func TestProblems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/mapped", func(c *gin.Context) {
+		abortWithError(c, fmt.Errorf("cannot register sensor {1 xyz}: %w", usecase.ErrWrongSensorSerialNumber))
+	})
+	r.GET("/internal", func(c *gin.Context) {
+		abortWithError(c, errors.New("pq: relation \"sensors\" does not exist"))
+	})
+	r.GET("/explicit", func(c *gin.Context) {
+		abortWithAPIError(c, http.StatusUnprocessableEntity, fmt.Errorf("bound sensor: %w", usecase.ErrSensorNotFound))
+	})
+	r.POST("/validated", func(c *gin.Context) {
+		_ = extractDto(c, &dtos.SensorToCreate{})
+	})
+	serve := func(method, path, body string) (*httptest.ResponseRecorder, dtos.Problem) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", JSONType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var problem dtos.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		require.NoError(t, problem.Validate(nil))
+		return w, problem
+	}
+
+	t.Run("ok, sentinel is mapped to status and code", func(t *testing.T) {
+		w, problem := serve(http.MethodGet, "/mapped", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, ProblemType, w.Header().Get("Content-Type"))
+		assert.Equal(t, "wrong_sensor_serial_number", *problem.Code)
+		assert.Equal(t, "urn:home-controller:problem:wrong_sensor_serial_number", *problem.Type)
+		assert.Equal(t, "Wrong sensor serial number", *problem.Title)
+		assert.Equal(t, "wrong sensor serial number", problem.Detail)
+		assert.Equal(t, int64(http.StatusUnprocessableEntity), *problem.Status)
+		assert.Equal(t, "/mapped", problem.Instance)
+	})
+
+	t.Run("ok, internal details are not exposed", func(t *testing.T) {
+		w, problem := serve(http.MethodGet, "/internal", "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "internal_error", *problem.Code)
+		assert.NotContains(t, w.Body.String(), "relation")
+	})
+
+	t.Run("ok, explicit status keeps the code", func(t *testing.T) {
+		w, problem := serve(http.MethodGet, "/explicit", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "sensor_not_found", *problem.Code)
+	})
+
+	t.Run("ok, field errors", func(t *testing.T) {
+		w, problem := serve(http.MethodPost, "/validated",
+			`{"serial_number":"12","type":"cc","description":"","is_active":true,"channels":[{"type":"adc"}]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, codeValidationFailed, *problem.Code)
+
+		names := make([]string, 0, len(problem.InvalidParams))
+		for _, p := range problem.InvalidParams {
+			names = append(names, *p.Name)
+		}
+		assert.ElementsMatch(t, []string{"serial_number", "channels.0.name"}, names)
+	})
+
+	t.Run("fail, malformed body", func(t *testing.T) {
+		w, problem := serve(http.MethodPost, "/validated", `{"serial_number":`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "malformed_body", *problem.Code)
+	})
+}
@@ -14,5 +14,9 @@ func setupRouter(r gin.IRouter, uc UseCases, ws *WebSocketHandler) {
 
 	setupEventsHandler(r.Group("/events"), uc)
 	setupSensorsHandler(r.Group("/sensors"), uc, ws)
+	setupSensorTypesHandler(r.Group("/sensor-types"))
 	setupUsersHandler(r.Group("/users"), uc)
+	setupLocationsHandler(r.Group("/locations"), uc)
+	setupActuatorsHandler(r.Group("/actuators"), uc, ws)
+	setupAutomationsHandler(r.Group("/automations"), uc)
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	eventRepository "homework/internal/repository/event/postgres"
+	locationRepository "homework/internal/repository/location/postgres"
 	sensorRepository "homework/internal/repository/sensor/postgres"
 	subscriptionRepository "homework/internal/repository/subscription/inmemory"
 	userRepository "homework/internal/repository/user/postgres"
@@ -33,13 +34,15 @@ var (
 	ur  = &userRepository.UserRepository{}
 	sor = &userRepository.SensorOwnerRepository{}
 	esr = &subscriptionRepository.SubscriptionRepository[domain.Event]{}
+	lr  = &locationRepository.LocationRepository{}
 )
 
 var useCases = UseCases{
 	Event:             usecase.NewEvent(er, sr, esr),
 	Sensor:            usecase.NewSensor(sr),
-	User:              usecase.NewUser(ur, sor, sr),
+	User:              usecase.NewUser(ur, sor, sr, lr),
 	EventSubscription: usecase.NewSubscription[domain.Event](esr, sr),
+	Location:          usecase.NewLocation(lr, sr),
 }
 
 var (
@@ -58,6 +61,7 @@ func init() {
 	*ur = *userRepository.NewUserRepository(testDbInstance)
 	*sor = *userRepository.NewSensorOwnerRepository(testDbInstance)
 	*esr = *subscriptionRepository.NewSubscriptionRepository[domain.Event]()
+	*lr = *locationRepository.NewLocationRepository(testDbInstance)
 
 	reg, err := useCases.Sensor.RegisterSensor(context.Background(), &domain.Sensor{
 		SerialNumber: "1233211230",
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// engineeringValue - raw payload converted to the units of the sensor type, the sensors of
+// an unknown type report their raw payload
+func engineeringValue(sensorType domain.SensorType, raw int64) (float64, string) {
+	info, ok := domain.LookupSensorType(sensorType)
+	if !ok {
+		return float64(raw), ""
+	}
+	return info.Value(raw), info.Unit
+}
+
+func sensorChannelDto(c domain.Channel) *dtos.SensorChannel {
+	name := c.Name
+	channelType := string(c.Type)
+	return &dtos.SensorChannel{Name: &name, Type: &channelType}
+}
+
+func sensorTypeInfoDto(info domain.SensorTypeInfo) dtos.SensorTypeInfo {
+	sensorType := string(info.Type)
+	precision := int32(info.Precision)
+	dto := dtos.SensorTypeInfo{
+		Description: &info.Description,
+		Offset:      &info.Offset,
+		Precision:   &precision,
+		Scale:       &info.Scale,
+		Type:        &sensorType,
+		Unit:        &info.Unit,
+	}
+	if info.Range != nil {
+		dto.Min = &info.Range.Min
+		dto.Max = &info.Range.Max
+	}
+	return dto
+}
+
+func sensorTypesGetHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := isFormatSupported(ctx); err != nil {
+			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+			return
+		}
+
+		types := domain.SensorTypes()
+		typeDtos := make([]dtos.SensorTypeInfo, 0, len(types))
+		for _, info := range types {
+			typeDtos = append(typeDtos, sensorTypeInfoDto(info))
+		}
+		abortWithStatusDto(ctx, http.StatusOK, typeDtos)
+	}
+}
+
+func setupSensorTypesHandler(r *gin.RouterGroup) {
+	r.GET("", sensorTypesGetHandler())
+	r.OPTIONS("", optionsHandler(http.MethodGet))
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"encoding/json"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/dtos"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineeringValue(t *testing.T) {
+	value, unit := engineeringValue(domain.SensorTypeTemperature, 2315)
+	assert.InDelta(t, 23.15, value, 1e-9)
+	assert.Equal(t, "°C", unit)
+
+	value, unit = engineeringValue(domain.SensorTypePowerMeter, 12345)
+	assert.InDelta(t, 1234.5, value, 1e-9)
+	assert.Equal(t, "W", unit)
+
+	value, unit = engineeringValue("unknown", 42)
+	assert.InDelta(t, 42, value, 1e-9)
+	assert.Empty(t, unit)
+}
+
+func TestSensorTypesRoutes(t *testing.T) {
+	engine := gin.New()
+	setupSensorTypesHandler(engine.Group("/sensor-types"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/sensor-types", nil)
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var types []dtos.SensorTypeInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &types))
+	require.Len(t, types, len(domain.SensorTypes()))
+	for _, dto := range types {
+		assert.NoError(t, dto.Validate(nil))
+		if *dto.Type == string(domain.SensorTypeHumidity) {
+			require.NotNil(t, dto.Max)
+			assert.InDelta(t, 100, *dto.Max, 1e-9)
+		}
+		if *dto.Type == string(domain.SensorTypeADC) {
+			assert.Nil(t, dto.Max)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodOptions, "/sensor-types", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
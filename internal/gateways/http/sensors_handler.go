@@ -3,11 +3,12 @@ package http
 import (
 	"context"
 	"errors"
+	"fmt"
 	"homework/internal/domain"
 	"homework/internal/gateways/http/dtos"
 	"homework/internal/usecase"
-	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
@@ -19,28 +20,58 @@ func sensorGetImpl(sensor *domain.Sensor) dtos.Sensor {
 	lastActivity := strfmt.DateTime(sensor.LastActivity)
 	registeredAt := strfmt.DateTime(sensor.RegisteredAt)
 	sensorType := string(sensor.Type)
+	currentValue, unit := engineeringValue(sensor.Type, sensor.CurrentState)
 	sensorDto := dtos.Sensor{
 		CurrentState: &sensor.CurrentState,
+		CurrentValue: &currentValue,
 		Description:  &sensor.Description,
 		ID:           &sensor.ID,
 		IsActive:     &sensor.IsActive,
+		Labels:       sensor.Labels,
 		LastActivity: &lastActivity,
+		LocationID:   sensor.LocationID,
 		RegisteredAt: &registeredAt,
 		SerialNumber: &sensor.SerialNumber,
 		Type:         &sensorType,
+		Unit:         unit,
+	}
+	if sensor.IsMultiChannel() {
+		sensorDto.Channels = make([]*dtos.SensorChannel, 0, len(sensor.Channels))
+		for _, c := range sensor.Channels {
+			sensorDto.Channels = append(sensorDto.Channels, sensorChannelDto(c))
+		}
+		sensorDto.ChannelStates = sensor.CurrentValues
+		sensorDto.ChannelValues = make(map[string]float64, len(sensor.CurrentValues))
+		for name, raw := range sensor.CurrentValues {
+			if c, ok := sensor.Channel(name); ok {
+				sensorDto.ChannelValues[name], _ = engineeringValue(c.Type, raw)
+			}
+		}
 	}
 	return sensorDto
 }
 
-func sensorsGetImpl(ctx *gin.Context, uc UseCases) []dtos.Sensor {
-	if err := isFormatSupported(ctx, JSONType); err != nil {
+func sensorsGetImpl(ctx *gin.Context, uc UseCases) any {
+	if err := isFormatSupported(ctx); err != nil {
 		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
 		return nil
 	}
 
-	sensors, err := uc.Sensor.GetSensors(ctx)
+	selector, err := selectorQuery(ctx)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return nil
+	}
+
+	sensors, err := uc.Sensor.GetSensorsBySelector(ctx, selector)
 	if err != nil {
-		abortWithAPIError(ctx, http.StatusInternalServerError, err)
+		abortWithError(ctx, err)
+		return nil
+	}
+	// The stable order keeps the representation, and so the ETag, the same while the sensors are unchanged
+	sort.Slice(sensors, func(i, j int) bool { return sensors[i].ID < sensors[j].ID })
+	setValidators(ctx, sensorsETag(sensors), latestActivity(sensors))
+	if ctx.IsAborted() {
 		return nil
 	}
 
@@ -49,14 +80,14 @@ func sensorsGetImpl(ctx *gin.Context, uc UseCases) []dtos.Sensor {
 		sensDto := sensorGetImpl(&sens)
 		sensorDtos = append(sensorDtos, sensDto)
 	}
-	return sensorDtos
+	return listBody(ctx, sensorDtos, func(s dtos.Sensor) int64 { return *s.ID })
 }
 
 func sensorsGetHandler(uc UseCases) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		sensorDtos := sensorsGetImpl(ctx, uc)
 		if !ctx.IsAborted() {
-			ctx.AbortWithStatusJSON(http.StatusOK, sensorDtos)
+			abortWithStatusDto(ctx, http.StatusOK, sensorDtos)
 		}
 	}
 }
@@ -79,37 +110,47 @@ func sensorsPostHandler(uc UseCases) gin.HandlerFunc {
 				Type:         domain.SensorType(*sensorDto.Type),
 				Description:  *sensorDto.Description,
 				IsActive:     *sensorDto.IsActive,
+				Labels:       sensorDto.Labels,
+			}
+			for _, c := range sensorDto.Channels {
+				if c != nil {
+					sensor.Channels = append(sensor.Channels, domain.Channel{Name: *c.Name, Type: domain.SensorType(*c.Type)})
+				}
 			}
 
 			sens, err := uc.Sensor.RegisterSensor(ctx, &sensor)
 			if err != nil {
-				abortWithAPIError(ctx, http.StatusInternalServerError, err)
+				if errors.Is(err, usecase.ErrInvalidSensorChannel) || errors.Is(err, usecase.ErrInvalidSensorLabels) {
+					abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+					return
+				}
+				abortWithError(ctx, err)
 				return
 			}
 
-			ctx.AbortWithStatusJSON(http.StatusOK, sensorGetImpl(sens))
+			abortWithStatusDto(ctx, http.StatusOK, sensorGetImpl(sens))
 		}
 	}
 }
 
 func sensorByIdCommonHandler(ctx *gin.Context, uc UseCases) *domain.Sensor {
-	if err := isFormatSupported(ctx, JSONType); err != nil {
+	if err := isFormatSupported(ctx); err != nil {
 		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
 		return nil
 	}
 
 	sensorId, err := strconv.ParseInt(ctx.Param("sensor_id"), 10, 64)
 	if err != nil {
-		ctx.AbortWithStatus(http.StatusUnprocessableEntity)
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
 		return nil
 	}
 
 	sensor, err := uc.Sensor.GetSensorByID(ctx, sensorId)
 	if errors.Is(err, usecase.ErrSensorNotFound) {
-		ctx.AbortWithStatus(http.StatusNotFound)
+		abortWithAPIError(ctx, http.StatusNotFound, err)
 		return nil
 	} else if err != nil {
-		abortWithAPIError(ctx, http.StatusInternalServerError, err)
+		abortWithError(ctx, err)
 		return nil
 	}
 
@@ -120,7 +161,10 @@ func sensorByIdGetHandler(uc UseCases) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		sensor := sensorByIdCommonHandler(ctx, uc)
 		if !ctx.IsAborted() {
-			ctx.AbortWithStatusJSON(http.StatusOK, sensorGetImpl(sensor))
+			setValidators(ctx, sensorETag(sensor), sensor.LastActivity)
+		}
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, sensorGetImpl(sensor))
 		}
 	}
 }
@@ -128,69 +172,119 @@ func sensorByIdGetHandler(uc UseCases) gin.HandlerFunc {
 func sensorByIdHeadHandler(uc UseCases) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		sensor := sensorByIdCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			setValidators(ctx, sensorETag(sensor), sensor.LastActivity)
+		}
 		if !ctx.IsAborted() {
 			headImpl(ctx, sensorGetImpl(sensor))
 		}
 	}
 }
 
-func sensorHistoryCommonHandler(ctx *gin.Context, uc UseCases) []dtos.SensorHistory {
-	if err := isFormatSupported(ctx, JSONType); err != nil {
-		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
-		return nil
+// resolveChannel - channel of the sensor the history or calibration is requested for and its type,
+// the first channel is used by default, the single-value sensors have only the unnamed one
+func resolveChannel(sensor *domain.Sensor, channel string) (string, domain.SensorType, error) {
+	if !sensor.IsMultiChannel() {
+		if channel != "" {
+			return "", "", fmt.Errorf("%w: sensor has no channels, got %q", usecase.ErrUnknownChannel, channel)
+		}
+		return "", sensor.Type, nil
 	}
-
-	sensorId, err := strconv.ParseInt(ctx.Param("sensor_id"), 10, 64)
-	if err != nil {
-		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
-		return nil
+	if channel == "" {
+		channel = sensor.Channels[0].Name
+	}
+	c, ok := sensor.Channel(channel)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %q", usecase.ErrUnknownChannel, channel)
 	}
+	return c.Name, c.Type, nil
+}
+
+// historyPeriod - required start_date and end_date query parameters
+func historyPeriod(ctx *gin.Context) (time.Time, time.Time, error) {
 	startDateQ, ok := ctx.GetQuery("start_date")
 	if !ok {
-		abortWithAPIError(ctx, http.StatusUnprocessableEntity, usecase.ErrInvalidEventTimestamp)
-		return nil
+		return time.Time{}, time.Time{}, usecase.ErrInvalidEventTimestamp
 	}
 	startTime, err := strfmt.ParseDateTime(startDateQ)
 	if err != nil {
-		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
-		return nil
+		return time.Time{}, time.Time{}, err
 	}
 	endDateQ, ok := ctx.GetQuery("end_date")
 	if !ok {
-		abortWithAPIError(ctx, http.StatusUnprocessableEntity, usecase.ErrInvalidEventTimestamp)
-		return nil
+		return time.Time{}, time.Time{}, usecase.ErrInvalidEventTimestamp
 	}
 	endTime, err := strfmt.ParseDateTime(endDateQ)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return time.Time(startTime), time.Time(endTime), nil
+}
+
+func sensorHistoryCommonHandler(ctx *gin.Context, uc UseCases) []dtos.SensorHistory {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	sensorId, err := strconv.ParseInt(ctx.Param("sensor_id"), 10, 64)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return nil
+	}
+	startTime, endTime, err := historyPeriod(ctx)
 	if err != nil {
 		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
 		return nil
 	}
 
-	_, err = uc.Sensor.GetSensorByID(ctx, sensorId)
+	sensor, err := uc.Sensor.GetSensorByID(ctx, sensorId)
 	if errors.Is(err, usecase.ErrSensorNotFound) {
-		ctx.AbortWithStatus(http.StatusNotFound)
+		abortWithAPIError(ctx, http.StatusNotFound, err)
 		return nil
 	} else if err != nil {
-		abortWithAPIError(ctx, http.StatusInternalServerError, err)
+		abortWithError(ctx, err)
+		return nil
+	}
+
+	channel, channelType, err := resolveChannel(sensor, ctx.Query("channel"))
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
 		return nil
 	}
 
-	hist, err := uc.Event.GetEventsHistoryBySensorID(ctx, sensorId, time.Time(startTime), time.Time(endTime))
+	hist, err := uc.Event.GetEventsHistoryBySensorID(ctx, sensorId, startTime, endTime)
 	if err != nil {
 		if errors.Is(err, usecase.ErrInvalidEventTimestamp) {
 			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
 			return nil
 		}
-		abortWithAPIError(ctx, http.StatusInternalServerError, err)
+		abortWithError(ctx, err)
 		return nil
 	}
 
+	return historyDtos(hist, channel, channelType)
+}
+
+// historyDtos - history of the sensor channel, events that don't carry the channel are skipped
+func historyDtos(hist []*domain.Event, channel string, channelType domain.SensorType) []dtos.SensorHistory {
 	histDtos := make([]dtos.SensorHistory, 0, len(hist))
 	for _, e := range hist {
+		raw, ok := e.ChannelValue(channel)
+		if !ok {
+			continue // The event doesn't carry the channel
+		}
+		rawPayload, ok := e.RawChannelValue(channel)
+		if !ok {
+			rawPayload = raw
+		}
 		timestampDto := strfmt.DateTime(e.Timestamp)
+		value, _ := engineeringValue(channelType, raw)
 		histDto := dtos.SensorHistory{
-			Payload:   &e.Payload,
-			Timestamp: &timestampDto,
+			Payload:    &raw,
+			RawPayload: &rawPayload,
+			Timestamp:  &timestampDto,
+			Value:      &value,
 		}
 		histDtos = append(histDtos, histDto)
 	}
@@ -201,7 +295,7 @@ func sensorHistoryGetHandler(uc UseCases) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		sensorHistoryDtos := sensorHistoryCommonHandler(ctx, uc)
 		if !ctx.IsAborted() {
-			ctx.AbortWithStatusJSON(http.StatusOK, sensorHistoryDtos)
+			abortWithStatusDto(ctx, http.StatusOK, sensorHistoryDtos)
 		}
 	}
 }
@@ -217,14 +311,14 @@ func sensorHistoryHeadHandler(uc UseCases) gin.HandlerFunc {
 
 func sensorSubscribeHandler(uc UseCases, ws *WebSocketHandler) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if err := isFormatSupported(ctx, JSONType); err != nil {
+		if err := isFormatSupported(ctx); err != nil {
 			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
 			return
 		}
 
 		sensorId, err := strconv.ParseInt(ctx.Param("sensor_id"), 10, 64)
 		if err != nil {
-			ctx.AbortWithStatus(http.StatusUnprocessableEntity)
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
 			return
 		}
 
@@ -234,14 +328,15 @@ func sensorSubscribeHandler(uc UseCases, ws *WebSocketHandler) gin.HandlerFunc {
 				abortWithAPIError(ctx, http.StatusNotFound, err)
 				return
 			}
-			abortWithAPIError(ctx, http.StatusInternalServerError, err)
+			abortWithError(ctx, err)
 			return
 		}
 
 		defer func() {
 			err := uc.EventSubscription.Unsubscribe(ctx, sensorId, subscription.Id)
 			if err != nil {
-				log.Printf("unable to unsubscribe %v from %v: %v", subscription.Id, sensorId, err)
+				ws.logger.ErrorContext(ctx, "unable to unsubscribe",
+					"subscription_id", subscription.Id, "sensor_id", sensorId, "error", err)
 			}
 		}()
 
@@ -250,9 +345,9 @@ func sensorSubscribeHandler(uc UseCases, ws *WebSocketHandler) gin.HandlerFunc {
 			subscription.SubscriptionWriteHandle.Ch <- *notifyEvent
 		}
 
-		err = ws.HandleSubscription(ctx, channelBatcher(eventChannelAdapter(subscription.SubscriptionReadHandle.Ch), BatchPeriod))
+		err = ws.HandleSubscription(ctx, channelBatcher(eventChannelAdapter(ctx, ws.logger, responseCodec(ctx), subscription.SubscriptionReadHandle.Ch), ws.batchPeriod))
 		if err != nil && !errors.Is(err, context.Canceled) {
-			log.Printf("error processing sensor subscription: %v", err)
+			ws.logger.ErrorContext(ctx, "error processing sensor subscription", "sensor_id", sensorId, "error", err)
 			return
 		}
 	}
@@ -264,6 +359,12 @@ func setupSensorsHandler(r *gin.RouterGroup, uc UseCases, ws *WebSocketHandler)
 	r.POST("", sensorsPostHandler(uc))
 	r.OPTIONS("", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPost))
 
+	r.GET("/events", sensorsSubscribeHandler(uc, ws))
+
+	r.GET("/history", sensorsHistoryGetHandler(uc))
+	r.HEAD("/history", sensorsHistoryHeadHandler(uc))
+	r.OPTIONS("/history", optionsHandler(http.MethodGet, http.MethodHead))
+
 	r.GET("/:sensor_id", sensorByIdGetHandler(uc))
 	r.HEAD("/:sensor_id", sensorByIdHeadHandler(uc))
 	r.OPTIONS("/:sensor_id", optionsHandler(http.MethodGet, http.MethodHead))
@@ -273,4 +374,15 @@ func setupSensorsHandler(r *gin.RouterGroup, uc UseCases, ws *WebSocketHandler)
 	r.GET("/:sensor_id/history", sensorHistoryGetHandler(uc))
 	r.HEAD("/:sensor_id/history", sensorHistoryHeadHandler(uc))
 	r.OPTIONS("/:sensor_id/history", optionsHandler(http.MethodGet, http.MethodHead))
+
+	r.GET("/:sensor_id/calibration", sensorCalibrationGetHandler(uc))
+	r.PUT("/:sensor_id/calibration", sensorCalibrationPutHandler(uc))
+	r.OPTIONS("/:sensor_id/calibration", optionsHandler(http.MethodGet, http.MethodPut))
+
+	r.POST("/:sensor_id/calibration/recompute", sensorCalibrationRecomputeHandler(uc))
+	r.OPTIONS("/:sensor_id/calibration/recompute", optionsHandler(http.MethodPost))
+
+	r.GET("/:sensor_id/labels", sensorLabelsGetHandler(uc))
+	r.PUT("/:sensor_id/labels", sensorLabelsPutHandler(uc))
+	r.OPTIONS("/:sensor_id/labels", optionsHandler(http.MethodGet, http.MethodPut))
 }
@@ -0,0 +1,260 @@
+package http
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/gateways/http/codec"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventInMemory "homework/internal/repository/event/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+	subscriptionInMemory "homework/internal/repository/subscription/inmemory"
+)
+
+func TestResolveChannel(t *testing.T) {
+	single := &domain.Sensor{Type: domain.SensorTypeTemperature}
+	multi := &domain.Sensor{
+		Type: domain.SensorTypeADC,
+		Channels: []domain.Channel{
+			{Name: "temperature", Type: domain.SensorTypeTemperature},
+			{Name: "humidity", Type: domain.SensorTypeHumidity},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		sensor      *domain.Sensor
+		channel     string
+		wantChannel string
+		wantType    domain.SensorType
+		wantErr     error
+	}{
+		{"single-value sensor", single, "", "", domain.SensorTypeTemperature, nil},
+		{"single-value sensor with channel", single, "humidity", "", "", usecase.ErrUnknownChannel},
+		{"first channel by default", multi, "", "temperature", domain.SensorTypeTemperature, nil},
+		{"selected channel", multi, "humidity", "humidity", domain.SensorTypeHumidity, nil},
+		{"unknown channel", multi, "pressure", "", "", usecase.ErrUnknownChannel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel, channelType, err := resolveChannel(tt.sensor, tt.channel)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantChannel, channel)
+			assert.Equal(t, tt.wantType, channelType)
+		})
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"1-2"`, `"1-2"`, false))
+	assert.True(t, etagMatches(`"1-1", "1-2"`, `"1-2"`, false))
+	assert.True(t, etagMatches(`*`, `"1-2"`, false))
+	assert.True(t, etagMatches(`W/"1-2"`, `"1-2"`, true))
+	assert.False(t, etagMatches(`W/"1-2"`, `"1-2"`, false))
+	assert.False(t, etagMatches(`"1-1"`, `"1-2"`, true))
+}
+
+func TestSensorsConditionalRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sr := sensorInMemory.NewSensorRepository()
+	uc := UseCases{
+		Event:  usecase.NewEvent(eventInMemory.NewEventRepository(), sr, subscriptionInMemory.NewSubscriptionRepository[domain.Event]()),
+		Sensor: usecase.NewSensor(sr),
+	}
+	ctx := context.Background()
+	_, err := uc.Sensor.RegisterSensor(ctx, &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure})
+	require.NoError(t, err)
+	require.NoError(t, uc.Event.ReceiveEvent(ctx, &domain.Event{
+		Timestamp:          time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC),
+		SensorSerialNumber: "0000000001",
+		Payload:            1,
+	}))
+
+	r := gin.New()
+	setupSensorsHandler(r.Group("/sensors"), uc, nil)
+	serve := func(method, path string, headers map[string]string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/sensors", "/sensors/1"} {
+		t.Run("ok, not modified "+path, func(t *testing.T) {
+			w := serve(http.MethodGet, path, nil, "")
+			require.Equal(t, http.StatusOK, w.Code)
+			etag := w.Header().Get(ETagHeader)
+			require.NotEmpty(t, etag)
+			assert.Equal(t, "Wed, 01 May 2024 10:00:00 GMT", w.Header().Get(LastModifiedHeader))
+
+			for _, method := range []string{http.MethodGet, http.MethodHead} {
+				w = serve(method, path, map[string]string{IfNoneMatchHeader: etag}, "")
+				assert.Equal(t, http.StatusNotModified, w.Code)
+				assert.Empty(t, w.Body.String())
+				assert.Equal(t, etag, w.Header().Get(ETagHeader))
+			}
+
+			w = serve(http.MethodGet, path, map[string]string{IfModifiedSinceHeader: "Wed, 01 May 2024 10:00:00 GMT"}, "")
+			assert.Equal(t, http.StatusNotModified, w.Code)
+			w = serve(http.MethodGet, path, map[string]string{IfModifiedSinceHeader: "Wed, 01 May 2024 09:59:59 GMT"}, "")
+			assert.Equal(t, http.StatusOK, w.Code)
+			// If-None-Match takes precedence over If-Modified-Since
+			w = serve(http.MethodGet, path, map[string]string{
+				IfNoneMatchHeader:     `"stale"`,
+				IfModifiedSinceHeader: "Wed, 01 May 2024 10:00:00 GMT",
+			}, "")
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+
+	t.Run("ok, ETag changes with the sensor and guards the updates", func(t *testing.T) {
+		etag := serve(http.MethodGet, "/sensors/1", nil, "").Header().Get(ETagHeader)
+		listETag := serve(http.MethodGet, "/sensors", nil, "").Header().Get(ETagHeader)
+		headers := map[string]string{"Content-Type": JSONType, IfMatchHeader: etag}
+
+		require.Equal(t, http.StatusOK, serve(http.MethodPut, "/sensors/1/labels", headers, `{"room":"hall"}`).Code)
+		assert.NotEqual(t, etag, serve(http.MethodGet, "/sensors/1", nil, "").Header().Get(ETagHeader))
+		assert.NotEqual(t, listETag, serve(http.MethodGet, "/sensors", nil, "").Header().Get(ETagHeader))
+
+		w := serve(http.MethodPut, "/sensors/1/labels", headers, `{"room":"kitchen"}`)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		sensor, err := uc.Sensor.GetSensorByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"room": "hall"}, sensor.Labels)
+	})
+}
+
+// racingSensorRepository - runs race once right before the next sensor save, as a concurrent request would
+type racingSensorRepository struct {
+	*sensorInMemory.SensorRepository
+	race func()
+}
+
+func (r *racingSensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor) error {
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return r.SensorRepository.SaveSensor(ctx, sensor)
+}
+
+func TestSensorsConditionalUpdateRace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sr := &racingSensorRepository{SensorRepository: sensorInMemory.NewSensorRepository()}
+	uc := UseCases{
+		Event:  usecase.NewEvent(eventInMemory.NewEventRepository(), sr, subscriptionInMemory.NewSubscriptionRepository[domain.Event]()),
+		Sensor: usecase.NewSensor(sr),
+	}
+	ctx := context.Background()
+	_, err := uc.Sensor.RegisterSensor(ctx, &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure})
+	require.NoError(t, err)
+
+	r := gin.New()
+	setupSensorsHandler(r.Group("/sensors"), uc, nil)
+	serve := func(method, path string, headers map[string]string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	event := &domain.Event{Timestamp: time.Now(), SensorSerialNumber: "0000000001", Payload: 1}
+
+	t.Run("fail, changed after the If-Match check", func(t *testing.T) {
+		etag := serve(http.MethodGet, "/sensors/1", nil, "").Header().Get(ETagHeader)
+		sr.race = func() { require.NoError(t, uc.Event.ReceiveEvent(ctx, event)) }
+
+		w := serve(http.MethodPut, "/sensors/1/labels", map[string]string{"Content-Type": JSONType, IfMatchHeader: etag},
+			`{"room":"hall"}`)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		sensor, err := uc.Sensor.GetSensorByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, sensor.Labels)
+		assert.Equal(t, int64(1), sensor.CurrentState)
+	})
+
+	t.Run("ok, unconditional update keeps the concurrent state", func(t *testing.T) {
+		event := &domain.Event{Timestamp: time.Now(), SensorSerialNumber: "0000000001", Payload: 0}
+		sr.race = func() { require.NoError(t, uc.Event.ReceiveEvent(ctx, event)) }
+
+		w := serve(http.MethodPut, "/sensors/1/labels", map[string]string{"Content-Type": JSONType}, `{"room":"hall"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		sensor, err := uc.Sensor.GetSensorByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"room": "hall"}, sensor.Labels)
+		assert.Equal(t, int64(0), sensor.CurrentState)
+		assert.Equal(t, event.Timestamp, sensor.LastActivity)
+	})
+}
+
+func TestSensorsContentNegotiation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sr := sensorInMemory.NewSensorRepository()
+	uc := UseCases{Sensor: usecase.NewSensor(sr)}
+	_, err := uc.Sensor.RegisterSensor(context.Background(), &domain.Sensor{
+		SerialNumber: "0000000001",
+		Type:         domain.SensorTypeContactClosure,
+		Description:  "door",
+	})
+	require.NoError(t, err)
+
+	r := gin.New()
+	setupSensorsHandler(r.Group("/sensors"), uc, nil)
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/sensors/1", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, c := range []codec.Codec{codec.Msgpack(), codec.CBOR()} {
+		t.Run("ok, "+c.ContentType(), func(t *testing.T) {
+			w := get(c.ContentType())
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, c.ContentType(), w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Header().Get(ETagHeader), "+")
+
+			var sensor map[string]any
+			require.NoError(t, c.Unmarshal(w.Body.Bytes(), &sensor))
+			assert.Equal(t, "0000000001", sensor["serial_number"])
+			assert.Equal(t, "door", sensor["description"])
+		})
+	}
+
+	t.Run("ok, q-values", func(t *testing.T) {
+		w := get("application/json;q=0.5, application/cbor;q=0.9, */*;q=0.1")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, CBORType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Vary"), "Accept")
+	})
+
+	t.Run("ok, no preference is JSON", func(t *testing.T) {
+		w := get("*/*")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), JSONType)
+		assert.NotContains(t, w.Header().Get(ETagHeader), "+")
+	})
+
+	t.Run("fail, not acceptable", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotAcceptable, get("application/xml").Code)
+		assert.Equal(t, http.StatusNotAcceptable, get("application/json;q=0").Code)
+	})
+}
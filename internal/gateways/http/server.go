@@ -4,20 +4,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"homework/api"
+	"homework/internal/backup"
 	"homework/internal/domain"
+	"homework/internal/health"
 	"homework/internal/metrics"
+	"homework/internal/tracing"
 	"homework/internal/usecase"
-	"log"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+	DefaultShutdownTimeout   = 10 * time.Second
+	// DefaultBackupMaxBytes - size limit of the uploaded backup archive unless another one is set
+	DefaultBackupMaxBytes = 1 << 30
+)
+
 type Server struct {
 	host   string
 	port   uint16
 	router *gin.Engine
 	wsh    *WebSocketHandler
+
+	readHeaderTimeout time.Duration
+	idleTimeout       time.Duration
+	shutdownTimeout   time.Duration
+	wsOptions         []func(*WebSocketHandler)
+
+	adminToken      string
+	webhookSecret   []byte
+	effectiveConfig any
+	backup          *backup.Archiver
+	backupMaxBytes  int64
+	health          *health.Registry
+	logger          *slog.Logger
+	rateLimits      RateLimits
+	trustedProxies  []string
 }
 
 type UseCases struct {
@@ -25,21 +53,65 @@ type UseCases struct {
 	Sensor            *usecase.Sensor
 	User              *usecase.User
 	EventSubscription *usecase.Subscription[domain.Event]
+	Location          *usecase.Location
+	Actuator          *usecase.Actuator
+	Automation        *usecase.Automation
+	Scheduler         *usecase.Scheduler
+	Coalescer         *usecase.Coalescer    // Nil unless the events over the sensor limit are coalesced
+	Provisioning      *usecase.Provisioning // Nil unless the events of the unknown sensors are kept until claimed
 }
 
 func NewServer(useCases UseCases, options ...func(*Server)) *Server {
-	r := gin.Default()
-	r.HandleMethodNotAllowed = true
-	r.Use(metrics.RedMiddleware())
-	apiGroup := r.Group("/api")
-	wsh := NewWebSocketHandler(useCases)
-	setupRouter(apiGroup, useCases, wsh)
-
-	s := &Server{router: r, host: "localhost", port: 8080, wsh: wsh}
+	s := &Server{
+		host:              "localhost",
+		port:              8080,
+		readHeaderTimeout: DefaultReadHeaderTimeout,
+		idleTimeout:       DefaultIdleTimeout,
+		shutdownTimeout:   DefaultShutdownTimeout,
+		logger:            slog.Default(),
+	}
 	for _, o := range options {
 		o(s)
 	}
+	if s.health == nil {
+		s.health = health.NewRegistry()
+	}
 
+	r := gin.New()
+	r.HandleMethodNotAllowed = true
+	// the client IP the rate limits and the access log use is the peer address unless it is a trusted proxy
+	if err := r.SetTrustedProxies(s.trustedProxies); err != nil {
+		s.logger.Error("invalid trusted proxies, X-Forwarded-For is ignored", "error", err)
+		_ = r.SetTrustedProxies(nil)
+	}
+	// lets the usecases pick the request span and ID up from *gin.Context
+	r.ContextWithFallback = true
+	r.Use(
+		requestIDMiddleware(),
+		accessLogMiddleware(s.logger),
+		recoveryMiddleware(s.logger),
+		metrics.RedMiddleware(),
+		tracing.GinMiddleware(),
+		automationDepthMiddleware(s.webhookSecret),
+	)
+	setupHealthHandler(r, s.health)
+	apiGroup := r.Group("/api", rateLimitMiddleware(s.rateLimits))
+	wsOptions := append([]func(*WebSocketHandler){func(h *WebSocketHandler) { h.logger = s.logger }}, s.wsOptions...)
+	wsh := NewWebSocketHandler(useCases, wsOptions...)
+	setupRouter(apiGroup, useCases, wsh)
+	// v2 shares the handlers, they render the version specific representations by the version in the context
+	v2Group := apiGroup.Group("/v2", apiVersionMiddleware(APIVersionV2))
+	setupRouter(v2Group, useCases, wsh)
+	if s.adminToken != "" {
+		setupAdminHandler(apiGroup.Group("/admin"), s, useCases)
+		setupAdminHandler(v2Group.Group("/admin"), s, useCases)
+	} else {
+		s.logger.Warn("admin API is disabled, no admin token is set")
+	}
+	setupDocsHandler(apiGroup, api.OpenAPI)
+
+	s.router = r
+	s.wsh = wsh
 	return s
 }
 
@@ -55,12 +127,109 @@ func WithPort(port uint16) func(*Server) {
 	}
 }
 
+func WithReadHeaderTimeout(timeout time.Duration) func(*Server) {
+	return func(s *Server) {
+		s.readHeaderTimeout = timeout
+	}
+}
+
+func WithIdleTimeout(timeout time.Duration) func(*Server) {
+	return func(s *Server) {
+		s.idleTimeout = timeout
+	}
+}
+
+// WithShutdownTimeout - limits the time given to the active connections to finish during the graceful shutdown
+func WithShutdownTimeout(timeout time.Duration) func(*Server) {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+func WithWebSocketOptions(options ...func(*WebSocketHandler)) func(*Server) {
+	return func(s *Server) {
+		s.wsOptions = append(s.wsOptions, options...)
+	}
+}
+
+// WithAdminToken - requires the bearer token to access the /api/admin endpoints, they are not served without it
+func WithAdminToken(token string) func(*Server) {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+// WithEffectiveConfig - exposes the given (already redacted) config at /api/admin/config
+func WithEffectiveConfig(cfg any) func(*Server) {
+	return func(s *Server) {
+		s.effectiveConfig = cfg
+	}
+}
+
+// WithBackup - serves the export and import of the state at /api/admin/backup
+func WithBackup(archiver *backup.Archiver) func(*Server) {
+	return func(s *Server) {
+		s.backup = archiver
+	}
+}
+
+// WithBackupMaxBytes - size limit of the archive uploaded to /api/admin/backup, DefaultBackupMaxBytes if not positive
+func WithBackupMaxBytes(limit int64) func(*Server) {
+	return func(s *Server) {
+		s.backupMaxBytes = limit
+	}
+}
+
+// WithHealth - sets the registry of the dependency checks served at /healthz and /readyz
+func WithHealth(registry *health.Registry) func(*Server) {
+	return func(s *Server) {
+		s.health = registry
+	}
+}
+
+// WithLogger - sets the logger of the server, the access log and the websocket handler
+func WithLogger(logger *slog.Logger) func(*Server) {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithWebhookSecret - key the automation webhooks are signed with, the rule chain depth is taken only from
+// the requests signed with it
+func WithWebhookSecret(secret []byte) func(*Server) {
+	return func(s *Server) {
+		s.webhookSecret = secret
+	}
+}
+
+// WithRateLimits - sets the request rate limits of the API
+func WithRateLimits(limits RateLimits) func(*Server) {
+	return func(s *Server) {
+		s.rateLimits = limits
+	}
+}
+
+// WithTrustedProxies - IPs and CIDRs of the reverse proxies whose X-Forwarded-For is trusted for the client IP,
+// by default none is trusted
+func WithTrustedProxies(proxies []string) func(*Server) {
+	return func(s *Server) {
+		s.trustedProxies = proxies
+	}
+}
+
+// Handler - the router of the server, e.g. to serve it with httptest
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
 func (s *Server) Run(ctx context.Context, cancel context.CancelFunc) error {
 	serverErr := make(chan error)
 
 	srv := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", s.host, s.port),
-		Handler: s.router,
+		Addr:              fmt.Sprintf("%s:%d", s.host, s.port),
+		Handler:           s.router,
+		ReadHeaderTimeout: s.readHeaderTimeout,
+		IdleTimeout:       s.idleTimeout,
 	}
 
 	go func() {
@@ -71,8 +240,11 @@ func (s *Server) Run(ctx context.Context, cancel context.CancelFunc) error {
 	case err := <-serverErr:
 		return fmt.Errorf("server crashed: %w", err)
 	case <-ctx.Done():
-		log.Printf("Gracefully shutting down the server...")
-		composedErr := errors.Join(s.wsh.Shutdown(), srv.Shutdown(ctx))
+		s.logger.Info("gracefully shutting down the server")
+		s.health.SetShuttingDown() // Stop receiving new traffic before the connections are drained
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer shutdownCancel()
+		composedErr := errors.Join(s.wsh.Shutdown(), srv.Shutdown(shutdownCtx))
 		cancel()
 		return composedErr
 	}
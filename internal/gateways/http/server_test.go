@@ -60,7 +60,7 @@ func TestConnectionAndSuccessfulFinalization(t *testing.T) {
 	uc := UseCases{
 		Event:             usecase.NewEvent(erMock, srMock, esrMock),
 		Sensor:            usecase.NewSensor(srMock),
-		User:              usecase.NewUser(urMock, sorMock, srMock),
+		User:              usecase.NewUser(urMock, sorMock, srMock, nil),
 		EventSubscription: usecase.NewSubscription[domain.Event](esrMock, srMock),
 	}
 
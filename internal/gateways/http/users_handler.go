@@ -29,11 +29,11 @@ func usersPostHandler(uc UseCases) gin.HandlerFunc {
 
 			userCreated, err := uc.User.RegisterUser(ctx, &userToCreate)
 			if err != nil {
-				abortWithAPIError(ctx, http.StatusInternalServerError, err)
+				abortWithError(ctx, err)
 				return
 			}
 
-			ctx.AbortWithStatusJSON(http.StatusOK, userGetImpl(userCreated))
+			abortWithStatusDto(ctx, http.StatusOK, userGetImpl(userCreated))
 		}
 	}
 }
@@ -47,10 +47,10 @@ func userSensorsCommonHandler(ctx *gin.Context, uc UseCases) (*int64, []dtos.Sen
 
 	sensors, err := uc.User.GetUserSensors(ctx, userId)
 	if errors.Is(err, usecase.ErrUserNotFound) {
-		ctx.AbortWithStatus(http.StatusNotFound)
+		abortWithAPIError(ctx, http.StatusNotFound, err)
 		return &userId, nil
 	} else if err != nil {
-		abortWithAPIError(ctx, http.StatusInternalServerError, err)
+		abortWithError(ctx, err)
 		return &userId, nil
 	}
 
@@ -65,21 +65,21 @@ func userSensorsCommonHandler(ctx *gin.Context, uc UseCases) (*int64, []dtos.Sen
 
 func userSensorsGetHandler(uc UseCases) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if err := isFormatSupported(ctx, JSONType); err != nil {
+		if err := isFormatSupported(ctx); err != nil {
 			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
 			return
 		}
 
 		_, sensorDtos := userSensorsCommonHandler(ctx, uc)
 		if !ctx.IsAborted() {
-			ctx.AbortWithStatusJSON(http.StatusOK, sensorDtos)
+			abortWithStatusDto(ctx, http.StatusOK, sensorDtos)
 		}
 	}
 }
 
 func userSensorsHeadHandler(uc UseCases) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if err := isFormatSupported(ctx, JSONType); err != nil {
+		if err := isFormatSupported(ctx); err != nil {
 			abortWithAPIError(ctx, http.StatusNotAcceptable, err)
 			return
 		}
@@ -102,7 +102,7 @@ func userSensorPostHandler(uc UseCases) gin.HandlerFunc {
 		if extractDto(ctx, bindingDto) == nil {
 			err := uc.User.AttachSensorToUser(ctx, *userId, *bindingDto.SensorID)
 			if err != nil {
-				abortWithAPIError(ctx, http.StatusInternalServerError, err)
+				abortWithError(ctx, err)
 				return
 			}
 
@@ -111,6 +111,96 @@ func userSensorPostHandler(uc UseCases) gin.HandlerFunc {
 	}
 }
 
+func userLocationsCommonHandler(ctx *gin.Context, uc UseCases) []dtos.Location {
+	if err := isFormatSupported(ctx); err != nil {
+		abortWithAPIError(ctx, http.StatusNotAcceptable, err)
+		return nil
+	}
+
+	userId, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+		return nil
+	}
+
+	locations, err := uc.User.GetUserLocations(ctx, userId)
+	if errors.Is(err, usecase.ErrUserNotFound) {
+		abortWithAPIError(ctx, http.StatusNotFound, err)
+		return nil
+	} else if err != nil {
+		abortWithError(ctx, err)
+		return nil
+	}
+	return locationDtos(locations)
+}
+
+func userLocationsGetHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationDtos := userLocationsCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			abortWithStatusDto(ctx, http.StatusOK, locationDtos)
+		}
+	}
+}
+
+func userLocationsHeadHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationDtos := userLocationsCommonHandler(ctx, uc)
+		if !ctx.IsAborted() {
+			headImpl(ctx, locationDtos)
+		}
+	}
+}
+
+func userLocationPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userId, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		bindingDto := &dtos.LocationToUserBinding{}
+		if extractDto(ctx, bindingDto) == nil {
+			err := uc.User.GrantLocationAccess(ctx, userId, *bindingDto.LocationID)
+			if err != nil {
+				if errors.Is(err, usecase.ErrUserNotFound) || errors.Is(err, usecase.ErrLocationNotFound) {
+					abortWithAPIError(ctx, http.StatusNotFound, err)
+					return
+				}
+				abortWithError(ctx, err)
+				return
+			}
+
+			ctx.Status(http.StatusCreated)
+		}
+	}
+}
+
+// userPendingSensorClaimPostHandler - the owner claim, the sensor is attached to the user of the path
+func userPendingSensorClaimPostHandler(uc UseCases) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userId, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+		if err != nil {
+			abortWithAPIError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if !checkProvisioning(ctx, uc) {
+			return
+		}
+
+		claimDto := &dtos.SensorClaim{}
+		if extractDto(ctx, claimDto) == nil {
+			if claimDto.UserID != 0 && claimDto.UserID != userId {
+				abortWithAPIError(ctx, http.StatusUnprocessableEntity, errClaimUserMismatch)
+				return
+			}
+			claimDto.UserID = userId
+			pendingSensorClaimImpl(ctx, uc, claimDto)
+		}
+	}
+}
+
 func setupUsersHandler(r *gin.RouterGroup, uc UseCases) {
 	r.POST("", usersPostHandler(uc))
 	r.OPTIONS("", optionsHandler(http.MethodPost))
@@ -119,4 +209,12 @@ func setupUsersHandler(r *gin.RouterGroup, uc UseCases) {
 	r.HEAD("/:user_id/sensors", userSensorsHeadHandler(uc))
 	r.POST("/:user_id/sensors", userSensorPostHandler(uc))
 	r.OPTIONS("/:user_id/sensors", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPost))
+
+	r.GET("/:user_id/locations", userLocationsGetHandler(uc))
+	r.HEAD("/:user_id/locations", userLocationsHeadHandler(uc))
+	r.POST("/:user_id/locations", userLocationPostHandler(uc))
+	r.OPTIONS("/:user_id/locations", optionsHandler(http.MethodGet, http.MethodHead, http.MethodPost))
+
+	r.POST("/:user_id/pending-sensors/:serial_number/claim", userPendingSensorClaimPostHandler(uc))
+	r.OPTIONS("/:user_id/pending-sensors/:serial_number/claim", optionsHandler(http.MethodPost))
 }
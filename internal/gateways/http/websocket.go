@@ -4,35 +4,66 @@ import (
 	"context"
 	"errors"
 	"homework/internal/metrics"
-	"log"
+	"homework/internal/tracing"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 
 	"nhooyr.io/websocket"
 )
 
-const (
+const ( // Defaults
 	WriteTimeout = 5 * time.Second
 	BatchPeriod  = 500 * time.Millisecond
 )
 
 type WebSocketHandler struct {
-	useCases UseCases
-	conns    map[*websocket.Conn]struct{}
-	mu       sync.Mutex
+	useCases     UseCases
+	conns        map[*websocket.Conn]struct{}
+	mu           sync.Mutex
+	writeTimeout time.Duration
+	batchPeriod  time.Duration
+	logger       *slog.Logger
 }
 
-func NewWebSocketHandler(useCases UseCases) *WebSocketHandler {
-	return &WebSocketHandler{
-		useCases: useCases,
-		conns:    make(map[*websocket.Conn]struct{}),
-		mu:       sync.Mutex{},
+func NewWebSocketHandler(useCases UseCases, options ...func(*WebSocketHandler)) *WebSocketHandler {
+	h := &WebSocketHandler{
+		useCases:     useCases,
+		conns:        make(map[*websocket.Conn]struct{}),
+		mu:           sync.Mutex{},
+		writeTimeout: WriteTimeout,
+		batchPeriod:  BatchPeriod,
+		logger:       slog.Default(),
+	}
+	for _, o := range options {
+		o(h)
+	}
+	return h
+}
+
+func WithWriteTimeout(timeout time.Duration) func(*WebSocketHandler) {
+	return func(h *WebSocketHandler) {
+		h.writeTimeout = timeout
+	}
+}
+
+// WithBatchPeriod - sets the period the subscription events are accumulated for before being sent
+func WithBatchPeriod(period time.Duration) func(*WebSocketHandler) {
+	return func(h *WebSocketHandler) {
+		h.batchPeriod = period
 	}
 }
 
-func (h *WebSocketHandler) HandleSubscription(c *gin.Context, ch <-chan []byte) error {
+// HandleSubscription - sends the messages to the websocket until either side is done,
+// the messages of the binary codecs are sent in binary frames
+func (h *WebSocketHandler) HandleSubscription(c *gin.Context, ch <-chan wsMessage) error {
+	msgType := websocket.MessageText
+	if responseCodec(c).Binary() {
+		msgType = websocket.MessageBinary
+	}
 	conn, err := websocket.Accept(c.Writer, c.Request, nil)
 	if err != nil {
 		return err
@@ -42,16 +73,13 @@ func (h *WebSocketHandler) HandleSubscription(c *gin.Context, ch <-chan []byte)
 		connCtx := conn.CloseRead(c)
 		h.mu.Lock()
 		h.conns[conn] = struct{}{}
-		metrics.AddCounter("ws_connections", 1)
+		metrics.WSConnectionOpened()
 		h.mu.Unlock()
 
 		for {
 			select {
 			case msg := <-ch:
-				ctxTimed, cancel := context.WithTimeout(connCtx, WriteTimeout)
-				err := conn.Write(ctxTimed, websocket.MessageText, msg)
-				cancel()
-				if err != nil {
+				if err := h.send(c, connCtx, conn, msgType, msg); err != nil {
 					return err
 				}
 			case <-connCtx.Done():
@@ -67,15 +95,34 @@ func (h *WebSocketHandler) HandleSubscription(c *gin.Context, ch <-chan []byte)
 	if _, ok := h.conns[conn]; ok {
 		closeErr = conn.CloseNow()
 		delete(h.conns, conn)
-		metrics.AddCounter("ws_connections", -1)
+		metrics.WSConnectionClosed()
 	}
 	h.mu.Unlock()
 
 	return errors.Join(routineErr, closeErr)
 }
 
+// send - writes the message within a span linked to the one the message's event was ingested in
+func (h *WebSocketHandler) send(c *gin.Context, connCtx context.Context, conn *websocket.Conn, msgType websocket.MessageType, msg wsMessage) (err error) {
+	spanOpts := []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindProducer)}
+	if link, ok := tracing.LinkFromTraceParent(msg.traceParent); ok {
+		spanOpts = append(spanOpts, trace.WithLinks(link))
+	}
+	_, span := tracing.Start(c, "ws.send", spanOpts...)
+	defer tracing.End(span, &err)
+
+	ctxTimed, cancel := context.WithTimeout(connCtx, h.writeTimeout)
+	defer cancel()
+	if err := conn.Write(ctxTimed, msgType, msg.data); err != nil {
+		metrics.WSMessageDropped(metrics.DropReasonWriteError)
+		return err
+	}
+	metrics.WSMessageSent()
+	return nil
+}
+
 func (h *WebSocketHandler) Shutdown() error {
-	log.Printf("Shutting down ws...")
+	h.logger.Info("shutting down websocket connections")
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -83,7 +130,7 @@ func (h *WebSocketHandler) Shutdown() error {
 
 	for conn := range h.conns {
 		err := conn.Close(websocket.StatusNormalClosure, "server shutting down")
-		metrics.AddCounter("ws_connections", -1)
+		metrics.WSConnectionClosed()
 		if err != nil {
 			errs = append(errs, err)
 		}
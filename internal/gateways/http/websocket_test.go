@@ -60,7 +60,7 @@ func (t *testSuite) TestWebSocketConnection() {
 	uc := UseCases{
 		Event:             usecase.NewEvent(erMock, srMock, esrMock),
 		Sensor:            usecase.NewSensor(srMock),
-		User:              usecase.NewUser(urMock, sorMock, srMock),
+		User:              usecase.NewUser(urMock, sorMock, srMock, nil),
 		EventSubscription: usecase.NewSubscription[domain.Event](esrMock, srMock),
 	}
 
@@ -100,7 +100,7 @@ func (t *testSuite) TestWebSocketConnectionFail() {
 	uc := UseCases{
 		Event:             usecase.NewEvent(erMock, srMock, esrMock),
 		Sensor:            usecase.NewSensor(srMock),
-		User:              usecase.NewUser(urMock, sorMock, srMock),
+		User:              usecase.NewUser(urMock, sorMock, srMock, nil),
 		EventSubscription: usecase.NewSubscription[domain.Event](esrMock, srMock),
 	}
 
@@ -150,7 +150,7 @@ func (t *testSuite) TestWebSocketShutdown_Server() {
 	uc := UseCases{
 		Event:             usecase.NewEvent(erMock, srMock, esrMock),
 		Sensor:            usecase.NewSensor(srMock),
-		User:              usecase.NewUser(urMock, sorMock, srMock),
+		User:              usecase.NewUser(urMock, sorMock, srMock, nil),
 		EventSubscription: usecase.NewSubscription[domain.Event](esrMock, srMock),
 	}
 
@@ -206,7 +206,7 @@ func (t *testSuite) TestWebSocketShutdown_Client() {
 	uc := UseCases{
 		Event:             usecase.NewEvent(erMock, srMock, esrMock),
 		Sensor:            usecase.NewSensor(srMock),
-		User:              usecase.NewUser(urMock, sorMock, srMock),
+		User:              usecase.NewUser(urMock, sorMock, srMock, nil),
 		EventSubscription: usecase.NewSubscription[domain.Event](esrMock, srMock),
 	}
 
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/tracing"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// DepthHeader - carries the length of the automation rule chain to the receiver,
+// the events posted back with it are not allowed to start new chains endlessly
+const DepthHeader = "X-Automation-Depth"
+
+// maxDrainBytes - part of the response body read before closing it, a drained connection is reused
+const maxDrainBytes = 64 << 10
+
+var (
+	ErrUnexpectedStatus = errors.New("webhook receiver responded with unexpected status")
+	ErrInternalAddress  = errors.New("webhook receiver address is internal")
+)
+
+// Sender - delivers the automation webhooks
+type Sender struct {
+	client  *http.Client
+	timeout time.Duration
+	secret  []byte
+}
+
+func NewSender(options ...func(*Sender)) *Sender {
+	s := &Sender{
+		client:  newClient(),
+		timeout: 5 * time.Second,
+	}
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+// WithTimeout - limit of a single webhook request including the response body
+func WithTimeout(timeout time.Duration) func(*Sender) {
+	return func(s *Sender) {
+		s.timeout = timeout
+	}
+}
+
+// WithSecret - signs the depth of every webhook, the receiving controller trusts the depth only with the signature
+func WithSecret(secret []byte) func(*Sender) {
+	return func(s *Sender) {
+		s.secret = secret
+	}
+}
+
+// WithClient - replaces the client refusing the internal addresses
+func WithClient(client *http.Client) func(*Sender) {
+	return func(s *Sender) {
+		s.client = client
+	}
+}
+
+// newClient - the client dialing only the public addresses, the check is done on the resolved address,
+// so the names and redirects leading to the internal addresses are refused too. The environment proxies are
+// not used as the proxy address would be checked instead of the receiver's
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   refuseInternal,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
+
+// refuseInternal - net.Dialer Control, fails the connection to an internal address before it is made
+func refuseInternal(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil || domain.IsInternalAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrInternalAddress, address)
+	}
+	return nil
+}
+
+// SendWebhook - posts the JSON body to the url, any status but 2xx is reported as ErrUnexpectedStatus
+func (s *Sender) SendWebhook(ctx context.Context, url string, body []byte, depth int) (err error) {
+	ctx, span := tracing.Start(ctx, "webhook.Sender.SendWebhook")
+	defer tracing.End(span, &err)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DepthHeader, strconv.Itoa(depth))
+	if len(s.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(s.secret, depth, time.Now()))
+	}
+	if traceParent := tracing.TraceParent(ctx); traceParent != "" {
+		req.Header.Set("traceparent", traceParent)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot send webhook: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+	return nil
+}
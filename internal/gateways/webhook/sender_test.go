@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender_SendWebhook(t *testing.T) {
+	t.Run("ok, posted with depth", func(t *testing.T) {
+		received := make(chan *http.Request, 1)
+		var body []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			received <- r
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		secret := []byte("secret")
+		err := NewSender(WithSecret(secret), WithClient(srv.Client())).SendWebhook(context.Background(), srv.URL+"/hook", []byte(`{"rule_id":1}`), 2)
+		require.NoError(t, err)
+
+		r := <-received
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/hook", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "2", r.Header.Get(DepthHeader))
+		depth, ok := VerifyDepth(secret, r.Header.Get(DepthHeader), r.Header.Get(SignatureHeader), time.Now())
+		assert.True(t, ok)
+		assert.Equal(t, 2, depth)
+		assert.JSONEq(t, `{"rule_id":1}`, string(body))
+	})
+
+	t.Run("fail, unexpected status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		err := NewSender(WithClient(srv.Client())).SendWebhook(context.Background(), srv.URL, nil, 1)
+		assert.ErrorIs(t, err, ErrUnexpectedStatus)
+	})
+
+	t.Run("fail, timeout", func(t *testing.T) {
+		done := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-done
+		}))
+		defer srv.Close()
+		defer close(done)
+
+		err := NewSender(WithTimeout(50*time.Millisecond), WithClient(srv.Client())).SendWebhook(context.Background(), srv.URL, nil, 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ok, connection reused after response body", func(t *testing.T) {
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, strings.Repeat("ok", 16<<10))
+		}))
+		var connections atomic.Int32
+		srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				connections.Add(1)
+			}
+		}
+		srv.Start()
+		defer srv.Close()
+
+		sender := NewSender(WithClient(srv.Client()))
+		for i := 0; i < 3; i++ {
+			require.NoError(t, sender.SendWebhook(context.Background(), srv.URL, nil, 1))
+		}
+		assert.Equal(t, int32(1), connections.Load())
+	})
+
+	t.Run("fail, internal address", func(t *testing.T) {
+		var called atomic.Bool
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called.Store(true)
+		}))
+		defer srv.Close()
+
+		err := NewSender().SendWebhook(context.Background(), srv.URL, nil, 1)
+		assert.ErrorIs(t, err, ErrInternalAddress)
+		assert.False(t, called.Load())
+	})
+}
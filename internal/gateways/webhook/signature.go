@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader - HMAC of the depth and the signing time, proves the depth is set by the controller
+// and not by whoever posts to the API
+const SignatureHeader = "X-Automation-Signature"
+
+// SignatureTolerance - how long a signature is accepted after it has been made
+const SignatureTolerance = 5 * time.Minute
+
+func mac(secret []byte, depth int, at int64) string {
+	h := hmac.New(sha256.New, secret)
+	_, _ = fmt.Fprintf(h, "%d.%d", at, depth)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Sign - value of SignatureHeader for the depth in the form "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+func Sign(secret []byte, depth int, at time.Time) string {
+	return fmt.Sprintf("t=%d,v1=%s", at.Unix(), mac(secret, depth, at.Unix()))
+}
+
+// VerifyDepth - the depth of DepthHeader if the signature of SignatureHeader matches it and is not older than
+// SignatureTolerance, false otherwise or without a secret
+func VerifyDepth(secret []byte, depth, signature string, now time.Time) (int, bool) {
+	if len(secret) == 0 {
+		return 0, false
+	}
+	d, err := strconv.Atoi(depth)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+
+	var at int64
+	var got string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			if at, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return 0, false
+			}
+		case "v1":
+			got = value
+		}
+	}
+	if age := now.Sub(time.Unix(at, 0)); at == 0 || age > SignatureTolerance || age < -SignatureTolerance {
+		return 0, false
+	}
+	if !hmac.Equal([]byte(got), []byte(mac(secret, d, at))) {
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDepth(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+
+	depth, ok := VerifyDepth(secret, "2", Sign(secret, 2, now), now)
+	assert.True(t, ok)
+	assert.Equal(t, 2, depth)
+
+	_, ok = VerifyDepth(secret, "2", Sign(secret, 2, now.Add(-SignatureTolerance-time.Second)), now)
+	assert.False(t, ok, "expired")
+	_, ok = VerifyDepth(secret, "3", Sign(secret, 2, now), now)
+	assert.False(t, ok, "other depth")
+	_, ok = VerifyDepth(nil, "2", Sign(nil, 2, now), now)
+	assert.False(t, ok, "no secret")
+	_, ok = VerifyDepth(secret, "2", "v1=00", now)
+	assert.False(t, ok, "no time")
+	_, ok = VerifyDepth(secret, "2", "", now)
+	assert.False(t, ok, "unsigned")
+}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Status = string
+
+const (
+	StatusOK           Status = "ok"
+	StatusFail         Status = "fail"
+	StatusShuttingDown Status = "shutting_down"
+)
+
+const DefaultCheckTimeout = 2 * time.Second
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// CheckFunc - dependency check, returns nil if the dependency is healthy
+type CheckFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Status     Status `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Registry - set of the dependency checks backing the health and readiness probes
+type Registry struct {
+	checks       []namedCheck
+	mu           sync.RWMutex
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewRegistry(options ...func(*Registry)) *Registry {
+	r := &Registry{
+		timeout: DefaultCheckTimeout,
+	}
+	for _, o := range options {
+		o(r)
+	}
+	return r
+}
+
+// WithCheckTimeout - limits the time every single check is given
+func WithCheckTimeout(timeout time.Duration) func(*Registry) {
+	return func(r *Registry) {
+		r.timeout = timeout
+	}
+}
+
+func (r *Registry) Register(name string, check CheckFunc) {
+	r.mu.Lock()
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+	r.mu.Unlock()
+}
+
+// SetShuttingDown - marks the server as not ready anymore, is not reversible
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *Registry) IsShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+// Health - runs all the registered checks concurrently
+func (r *Registry) Health(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make([]namedCheck, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.runCheck(ctx, c.check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// Readiness - same as Health, but fails without running the checks once the shutdown has started
+func (r *Registry) Readiness(ctx context.Context) Report {
+	if r.IsShuttingDown() {
+		return Report{Status: StatusShuttingDown}
+	}
+	return r.Health(ctx)
+}
+
+func (r *Registry) runCheck(ctx context.Context, check CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				errCh <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		errCh <- check(ctx)
+	}()
+
+	var err error
+	select { // Do not rely on the check respecting the context
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := CheckResult{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Health(t *testing.T) {
+	t.Run("ok, no checks", func(t *testing.T) {
+		r := NewRegistry()
+		report := r.Health(context.Background())
+		assert.True(t, report.Healthy())
+		assert.Empty(t, report.Checks)
+	})
+
+	t.Run("fail, one failing check", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("good", func(context.Context) error { return nil })
+		r.Register("bad", func(context.Context) error { return errors.New("connection refused") })
+
+		report := r.Health(context.Background())
+		assert.False(t, report.Healthy())
+		assert.Equal(t, StatusOK, report.Checks["good"].Status)
+		assert.Equal(t, StatusFail, report.Checks["bad"].Status)
+		assert.Equal(t, "connection refused", report.Checks["bad"].Error)
+	})
+
+	t.Run("fail, check ignores context", func(t *testing.T) {
+		r := NewRegistry(WithCheckTimeout(10 * time.Millisecond))
+		block := make(chan struct{})
+		defer close(block)
+		r.Register("stuck", func(context.Context) error {
+			<-block
+			return nil
+		})
+
+		start := time.Now()
+		report := r.Health(context.Background())
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, StatusFail, report.Checks["stuck"].Status)
+		assert.Contains(t, report.Checks["stuck"].Error, context.DeadlineExceeded.Error())
+	})
+
+	t.Run("fail, check panics", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("panicky", func(context.Context) error { panic("boom") })
+
+		report := r.Health(context.Background())
+		assert.Equal(t, StatusFail, report.Checks["panicky"].Status)
+		assert.Contains(t, report.Checks["panicky"].Error, "boom")
+	})
+}
+
+func TestRegistry_Readiness(t *testing.T) {
+	r := NewRegistry()
+	called := 0
+	r.Register("counted", func(context.Context) error {
+		called++
+		return nil
+	})
+
+	assert.True(t, r.Readiness(context.Background()).Healthy())
+	assert.Equal(t, 1, called)
+
+	r.SetShuttingDown()
+	report := r.Readiness(context.Background())
+	assert.Equal(t, StatusShuttingDown, report.Status)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, 1, called, "checks must not run during the shutdown")
+
+	assert.True(t, r.Health(context.Background()).Healthy(), "health is not affected by the shutdown")
+}
+
+func TestHeartbeat(t *testing.T) {
+	h := NewHeartbeat("worker", 20*time.Millisecond)
+	assert.NoError(t, h.Check(context.Background()))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.ErrorContains(t, h.Check(context.Background()), "has not reported")
+
+	h.Beat()
+	assert.NoError(t, h.Check(context.Background()))
+
+	h.Stop()
+	assert.ErrorContains(t, h.Check(context.Background()), "has stopped")
+}
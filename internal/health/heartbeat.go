@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat - liveness tracker for a background worker, the worker is considered stuck
+// if it did not call Beat for longer than maxAge
+type Heartbeat struct {
+	name     string
+	maxAge   time.Duration
+	lastBeat atomic.Int64
+	stopped  atomic.Bool
+}
+
+func NewHeartbeat(name string, maxAge time.Duration) *Heartbeat {
+	h := &Heartbeat{name: name, maxAge: maxAge}
+	h.Beat()
+	return h
+}
+
+func (h *Heartbeat) Beat() {
+	h.lastBeat.Store(time.Now().UnixNano())
+}
+
+// Stop - reports the worker has exited, the check fails from now on
+func (h *Heartbeat) Stop() {
+	h.stopped.Store(true)
+}
+
+func (h *Heartbeat) Check(_ context.Context) error {
+	if h.stopped.Load() {
+		return fmt.Errorf("worker %s has stopped", h.name)
+	}
+	if age := time.Since(time.Unix(0, h.lastBeat.Load())); age > h.maxAge {
+		return fmt.Errorf("worker %s has not reported for %v", h.name, age.Truncate(time.Millisecond))
+	}
+	return nil
+}
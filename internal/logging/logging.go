@@ -0,0 +1,108 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Format = string
+
+const ( // Supported output formats
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported log format")
+
+type Options struct {
+	Level  slog.Level
+	Format Format
+}
+
+// New - creates a logger writing to w that annotates every record logged with a context
+// by the request ID and the trace and span IDs stored in it
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, opts.Format)
+	}
+	return slog.New(NewContextHandler(handler)), nil
+}
+
+// Discard - logger dropping every record, is handy for tests
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+type requestIDKey struct{}
+
+// WithRequestID - stores the correlation ID of the request being served in ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID - correlation ID of the request stored in ctx, empty if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ContextHandler - slog.Handler adding the request_id, trace_id and span_id attributes
+// taken from the record context
+type ContextHandler struct {
+	next slog.Handler
+}
+
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
+
+// OnError - logs *err unless it is nil or one of the expected errors, is meant to be deferred
+// with a named error result:
+//
+//	defer logging.OnError(ctx, r.logger, "postgres.SensorRepository.GetSensorByID", &err, usecase.ErrSensorNotFound)
+func OnError(ctx context.Context, logger *slog.Logger, operation string, err *error, expected ...error) {
+	if err == nil || *err == nil {
+		return
+	}
+	for _, e := range expected {
+		if errors.Is(*err, e) {
+			return
+		}
+	}
+	logger.ErrorContext(ctx, "operation failed", "operation", operation, "error", *err)
+}
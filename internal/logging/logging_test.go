@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		record := map[string]any{}
+		require.NoError(t, dec.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestNew(t *testing.T) {
+	t.Run("ok, json with context attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger, err := New(buf, Options{Level: slog.LevelInfo, Format: FormatJSON})
+		require.NoError(t, err)
+
+		traceID := trace.TraceID{1, 2, 3}
+		ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-1"),
+			trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{4}}))
+		logger.With("component", "test").InfoContext(ctx, "hello", "answer", 42)
+		logger.Debug("filtered out")
+
+		records := decodeRecords(t, buf)
+		require.Len(t, records, 1)
+		assert.Equal(t, "hello", records[0]["msg"])
+		assert.Equal(t, "test", records[0]["component"])
+		assert.Equal(t, "req-1", records[0]["request_id"])
+		assert.Equal(t, traceID.String(), records[0]["trace_id"])
+		assert.EqualValues(t, 42, records[0]["answer"])
+	})
+
+	t.Run("ok, text without context attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger, err := New(buf, Options{Format: FormatText})
+		require.NoError(t, err)
+
+		logger.Info("hello")
+
+		assert.Contains(t, buf.String(), "msg=hello")
+		assert.NotContains(t, buf.String(), "request_id")
+	})
+
+	t.Run("err, unsupported format", func(t *testing.T) {
+		_, err := New(&bytes.Buffer{}, Options{Format: "xml"})
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
+
+func TestOnError(t *testing.T) {
+	expected := errors.New("not found")
+
+	buf := &bytes.Buffer{}
+	logger, err := New(buf, Options{Format: FormatJSON})
+	require.NoError(t, err)
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	for _, err := range []error{nil, expected, errors.New("connection refused")} {
+		OnError(ctx, logger, "repo.Get", &err, expected)
+	}
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, "repo.Get", records[0]["operation"])
+	assert.Equal(t, "connection refused", records[0]["error"])
+	assert.Equal(t, "req-1", records[0]["request_id"])
+}
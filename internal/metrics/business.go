@@ -0,0 +1,273 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type IngestionFailureReason = string
+
+const ( // Event ingestion failure reasons
+	ReasonInvalidEvent      IngestionFailureReason = "invalid_event"
+	ReasonUnknownSensor     IngestionFailureReason = "unknown_sensor"
+	ReasonOutOfRange        IngestionFailureReason = "out_of_range"
+	ReasonUnknownChannel    IngestionFailureReason = "unknown_channel"
+	ReasonSensorLookupError IngestionFailureReason = "sensor_lookup_error"
+	ReasonEventLookupError  IngestionFailureReason = "event_lookup_error"
+	ReasonEventSaveError    IngestionFailureReason = "event_save_error"
+	ReasonSensorSaveError   IngestionFailureReason = "sensor_save_error"
+	ReasonBroadcastError    IngestionFailureReason = "broadcast_error"
+	ReasonDuplicate         IngestionFailureReason = "duplicate"
+	ReasonPendingSaveError  IngestionFailureReason = "pending_save_error"
+)
+
+type DropReason = string
+
+const ( // Websocket message drop reasons
+	DropReasonEncodeError DropReason = "encode_error"
+	DropReasonWriteError  DropReason = "write_error"
+)
+
+type ThrottleScope = string
+
+const ( // Keys the request rate is limited by
+	ThrottleScopeIP     ThrottleScope = "ip"
+	ThrottleScopeAPIKey ThrottleScope = "api_key"
+	ThrottleScopeSensor ThrottleScope = "sensor"
+)
+
+type ThrottleAction = string
+
+const ( // What is done to a request over the limit
+	ThrottleActionRejected  ThrottleAction = "rejected"
+	ThrottleActionCoalesced ThrottleAction = "coalesced"
+)
+
+type AutomationQueue = string
+
+const ( // Automation engine queues the work is dropped from when full
+	AutomationQueueEvents  AutomationQueue = "events"
+	AutomationQueueActions AutomationQueue = "actions"
+)
+
+// businessMetrics - domain level collectors of the ingestion, subscriptions and storage
+type businessMetrics struct {
+	eventsIngested    *prometheus.CounterVec
+	ingestionFailures *prometheus.CounterVec
+	eventsBuffered    prometheus.Counter
+	wsMessagesSent    prometheus.Counter
+	wsMessagesDropped *prometheus.CounterVec
+	wsBatchSize       prometheus.Histogram
+	repositoryQueries *prometheus.HistogramVec
+	commandStatuses   *prometheus.CounterVec
+	automationRuns    *prometheus.CounterVec
+	automationDropped *prometheus.CounterVec
+	jobRuns           *prometheus.CounterVec
+	jobDuration       *prometheus.HistogramVec
+	throttled         *prometheus.CounterVec
+}
+
+func newBusinessMetrics() businessMetrics {
+	return businessMetrics{
+		eventsIngested: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "events",
+			Name:      "ingested_total",
+			Help:      "Number of the successfully ingested sensor events by sensor type.",
+		}, []string{"sensor_type"}),
+		ingestionFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "events",
+			Name:      "ingestion_failures_total",
+			Help:      "Number of the sensor events failed to be ingested by reason.",
+		}, []string{"reason"}),
+		eventsBuffered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "events",
+			Name:      "buffered_total",
+			Help:      "Number of the events of the unknown sensors buffered until the sensor is claimed.",
+		}),
+		wsMessagesSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "messages_sent_total",
+			Help:      "Number of the messages sent to the websocket subscribers.",
+		}),
+		wsMessagesDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "messages_dropped_total",
+			Help:      "Number of the messages that were not delivered to the websocket subscribers by reason.",
+		}, []string{"reason"}),
+		wsBatchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "batch_size",
+			Help:      "Number of the messages flushed to a websocket subscriber at once.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}),
+		repositoryQueries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "query_duration_seconds",
+			Help:      "Latency of the repository calls by repository and method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"repository", "method"}),
+		commandStatuses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "commands",
+			Name:      "status_changes_total",
+			Help:      "Number of the actuator commands that reached the status, pending counts the enqueued ones.",
+		}, []string{"status"}),
+		automationRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "automation",
+			Name:      "executions_total",
+			Help:      "Number of the automation rule executions by outcome, skipped includes the loop protection.",
+		}, []string{"status"}),
+		automationDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "automation",
+			Name:      "dropped_total",
+			Help:      "Number of the events and rule actions dropped because the automation engine queue was full, by queue.",
+		}, []string{"queue"}),
+		jobRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "jobs",
+			Name:      "runs_total",
+			Help:      "Number of the finished scheduled job runs by job and outcome.",
+		}, []string{"job", "status"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "jobs",
+			Name:      "run_duration_seconds",
+			Help:      "Duration of the scheduled job runs by job.",
+			Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+		}, []string{"job"}),
+		throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "throttled_requests_total",
+			Help:      "Number of the requests over a rate limit by the limit scope and the action taken.",
+		}, []string{"scope", "action"}),
+	}
+}
+
+func (b *businessMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		b.eventsIngested,
+		b.ingestionFailures,
+		b.eventsBuffered,
+		b.wsMessagesSent,
+		b.wsMessagesDropped,
+		b.wsBatchSize,
+		b.repositoryQueries,
+		b.commandStatuses,
+		b.automationRuns,
+		b.automationDropped,
+		b.jobRuns,
+		b.jobDuration,
+		b.throttled,
+	}
+}
+
+func (m *Metrics) EventIngested(sensorType string) {
+	m.eventsIngested.WithLabelValues(sensorType).Inc()
+}
+
+func (m *Metrics) EventIngestionFailed(reason IngestionFailureReason) {
+	m.ingestionFailures.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) EventBuffered() {
+	m.eventsBuffered.Inc()
+}
+
+func (m *Metrics) WSMessageSent() {
+	m.wsMessagesSent.Inc()
+}
+
+func (m *Metrics) WSMessageDropped(reason DropReason) {
+	m.wsMessagesDropped.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) WSBatchFlushed(size int) {
+	m.wsBatchSize.Observe(float64(size))
+}
+
+// ObserveQuery - records the repository call latency, is meant to be deferred right at the method start:
+//
+//	defer metrics.ObserveQuery("sensor", "GetSensorByID", time.Now())
+func (m *Metrics) ObserveQuery(repository, method string, start time.Time) {
+	m.repositoryQueries.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+}
+
+func (m *Metrics) CommandStatusChanged(status string) {
+	m.commandStatuses.WithLabelValues(status).Inc()
+}
+
+func (m *Metrics) AutomationExecuted(status string) {
+	m.automationRuns.WithLabelValues(status).Inc()
+}
+
+func (m *Metrics) AutomationDropped(queue AutomationQueue) {
+	m.automationDropped.WithLabelValues(queue).Inc()
+}
+
+func (m *Metrics) JobFinished(job, status string, duration time.Duration) {
+	m.jobRuns.WithLabelValues(job, status).Inc()
+	m.jobDuration.WithLabelValues(job).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RequestThrottled(scope ThrottleScope, action ThrottleAction) {
+	m.throttled.WithLabelValues(scope, action).Inc()
+}
+
+func EventIngested(sensorType string) {
+	defaultMetrics.EventIngested(sensorType)
+}
+
+func EventIngestionFailed(reason IngestionFailureReason) {
+	defaultMetrics.EventIngestionFailed(reason)
+}
+
+func EventBuffered() {
+	defaultMetrics.EventBuffered()
+}
+
+func WSMessageSent() {
+	defaultMetrics.WSMessageSent()
+}
+
+func WSMessageDropped(reason DropReason) {
+	defaultMetrics.WSMessageDropped(reason)
+}
+
+func WSBatchFlushed(size int) {
+	defaultMetrics.WSBatchFlushed(size)
+}
+
+func ObserveQuery(repository, method string, start time.Time) {
+	defaultMetrics.ObserveQuery(repository, method, start)
+}
+
+func CommandStatusChanged(status string) {
+	defaultMetrics.CommandStatusChanged(status)
+}
+
+func AutomationExecuted(status string) {
+	defaultMetrics.AutomationExecuted(status)
+}
+
+func AutomationDropped(queue AutomationQueue) {
+	defaultMetrics.AutomationDropped(queue)
+}
+
+func JobFinished(job, status string, duration time.Duration) {
+	defaultMetrics.JobFinished(job, status, duration)
+}
+
+func RequestThrottled(scope ThrottleScope, action ThrottleAction) {
+	defaultMetrics.RequestThrottled(scope, action)
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "home_controller"
+
+// Metrics - set of the application collectors registered in its own prometheus registry.
+// All the collectors are safe for concurrent use.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequestsTotal    *prometheus.CounterVec
+	httpRequestDuration  *prometheus.HistogramVec
+	httpRequestsInFlight prometheus.Gauge
+	wsConnections        prometheus.Gauge
+
+	businessMetrics
+}
+
+var defaultMetrics = New()
+
+// Default - metrics instance used by the package level helpers
+func Default() *Metrics {
+	return defaultMetrics
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of the handled HTTP requests by route template.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the handled HTTP requests by route template.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"method", "route"}),
+		httpRequestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of the HTTP requests being handled.",
+		}),
+		wsConnections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "connections",
+			Help:      "Number of the open websocket subscription connections.",
+		}),
+		businessMetrics: newBusinessMetrics(),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequestsTotal,
+		m.httpRequestDuration,
+		m.httpRequestsInFlight,
+		m.wsConnections,
+	)
+	m.registry.MustRegister(m.businessMetrics.collectors()...)
+	return m
+}
+
+// Registerer - allows to register additional collectors next to the application ones
+func (m *Metrics) Registerer() prometheus.Registerer {
+	return m.registry
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) WSConnectionOpened() {
+	m.wsConnections.Inc()
+}
+
+func (m *Metrics) WSConnectionClosed() {
+	m.wsConnections.Dec()
+}
+
+func WSConnectionOpened() {
+	defaultMetrics.WSConnectionOpened()
+}
+
+func WSConnectionClosed() {
+	defaultMetrics.WSConnectionClosed()
+}
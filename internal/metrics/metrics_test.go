@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func seriesOf(exposition, metric string) []string {
+	var series []string
+	for _, line := range strings.Split(exposition, "\n") {
+		if strings.HasPrefix(line, metric+"{") || strings.HasPrefix(line, metric+" ") {
+			series = append(series, line)
+		}
+	}
+	return series
+}
+
+func TestRedMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+	engine := gin.New()
+	engine.Use(m.RedMiddleware())
+	engine.GET("/api/sensors/:sensor_id/history", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	const requests = 200
+	wg := sync.WaitGroup{}
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			path := fmt.Sprintf("/api/sensors/%d/history?start_date=%d&end_date=%d", i, i, i+1)
+			if i%2 == 0 {
+				path = fmt.Sprintf("/unknown/%d", i)
+			}
+			req, _ := http.NewRequest(http.MethodGet, path, nil)
+			engine.ServeHTTP(w, req)
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 10; i++ {
+		req, _ := http.NewRequest(fmt.Sprintf("BOGUS%d", i), "/api/sensors/1/history", nil)
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	exposition := scrape(t, m)
+
+	total := seriesOf(exposition, "home_controller_http_requests_total")
+	assert.ElementsMatch(t, []string{
+		`home_controller_http_requests_total{method="GET",route="/api/sensors/:sensor_id/history",status="200"} 100`,
+		`home_controller_http_requests_total{method="GET",route="unmatched",status="404"} 100`,
+		`home_controller_http_requests_total{method="other",route="unmatched",status="404"} 10`,
+	}, total)
+
+	counts := seriesOf(exposition, "home_controller_http_request_duration_seconds_count")
+	assert.Len(t, counts, 3, "one histogram per route template and method")
+	assert.Len(t, seriesOf(exposition, "home_controller_http_request_duration_seconds_bucket"), 3*14)
+
+	assert.Equal(t, []string{"home_controller_http_requests_in_flight 0"},
+		seriesOf(exposition, "home_controller_http_requests_in_flight"))
+	assert.NotContains(t, exposition, "start_date")
+}
+
+func TestWSConnections(t *testing.T) {
+	m := New()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.WSConnectionOpened()
+			if i%4 != 0 {
+				m.WSConnectionClosed()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, []string{"home_controller_ws_connections 25"}, seriesOf(scrape(t, m), "home_controller_ws_connections"))
+}
+
+type poolStatStub struct{}
+
+func (poolStatStub) AcquiredConns() int32           { return 1 }
+func (poolStatStub) IdleConns() int32               { return 2 }
+func (poolStatStub) ConstructingConns() int32       { return 0 }
+func (poolStatStub) TotalConns() int32              { return 3 }
+func (poolStatStub) MaxConns() int32                { return 10 }
+func (poolStatStub) AcquireCount() int64            { return 42 }
+func (poolStatStub) AcquireDuration() time.Duration { return time.Second }
+func (poolStatStub) CanceledAcquireCount() int64    { return 0 }
+func (poolStatStub) EmptyAcquireCount() int64       { return 1 }
+
+func TestBusinessMetrics(t *testing.T) {
+	m := New()
+	require.NoError(t, m.Registerer().Register(NewPoolCollector(func() PoolStat { return poolStatStub{} })))
+
+	m.EventIngested("cc")
+	m.EventIngested("cc")
+	m.EventIngested("adc")
+	m.EventIngestionFailed(ReasonUnknownSensor)
+	m.EventBuffered()
+	m.WSMessageSent()
+	m.WSMessageDropped(DropReasonWriteError)
+	m.WSBatchFlushed(3)
+	m.ObserveQuery("sensor", "GetSensorByID", time.Now().Add(-time.Millisecond))
+	m.CommandStatusChanged("pending")
+	m.CommandStatusChanged("acked")
+	m.AutomationExecuted("skipped")
+	m.AutomationDropped(AutomationQueueActions)
+	m.JobFinished("expire_commands", "failed", time.Second)
+	m.RequestThrottled(ThrottleScopeSensor, ThrottleActionCoalesced)
+
+	exposition := scrape(t, m)
+
+	assert.ElementsMatch(t, []string{
+		`home_controller_events_ingested_total{sensor_type="adc"} 1`,
+		`home_controller_events_ingested_total{sensor_type="cc"} 2`,
+	}, seriesOf(exposition, "home_controller_events_ingested_total"))
+	assert.Equal(t, []string{`home_controller_events_ingestion_failures_total{reason="unknown_sensor"} 1`},
+		seriesOf(exposition, "home_controller_events_ingestion_failures_total"))
+	assert.Equal(t, []string{"home_controller_events_buffered_total 1"},
+		seriesOf(exposition, "home_controller_events_buffered_total"))
+	assert.Equal(t, []string{"home_controller_ws_messages_sent_total 1"},
+		seriesOf(exposition, "home_controller_ws_messages_sent_total"))
+	assert.Equal(t, []string{`home_controller_ws_messages_dropped_total{reason="write_error"} 1`},
+		seriesOf(exposition, "home_controller_ws_messages_dropped_total"))
+	assert.Equal(t, []string{"home_controller_ws_batch_size_sum 3"},
+		seriesOf(exposition, "home_controller_ws_batch_size_sum"))
+	assert.ElementsMatch(t, []string{
+		`home_controller_commands_status_changes_total{status="acked"} 1`,
+		`home_controller_commands_status_changes_total{status="pending"} 1`,
+	}, seriesOf(exposition, "home_controller_commands_status_changes_total"))
+	assert.Equal(t, []string{`home_controller_automation_executions_total{status="skipped"} 1`},
+		seriesOf(exposition, "home_controller_automation_executions_total"))
+	assert.Equal(t, []string{`home_controller_automation_dropped_total{queue="actions"} 1`},
+		seriesOf(exposition, "home_controller_automation_dropped_total"))
+	assert.Equal(t, []string{`home_controller_jobs_runs_total{job="expire_commands",status="failed"} 1`},
+		seriesOf(exposition, "home_controller_jobs_runs_total"))
+	assert.Equal(t, []string{`home_controller_http_throttled_requests_total{action="coalesced",scope="sensor"} 1`},
+		seriesOf(exposition, "home_controller_http_throttled_requests_total"))
+	assert.Equal(t, []string{`home_controller_repository_query_duration_seconds_count{method="GetSensorByID",repository="sensor"} 1`},
+		seriesOf(exposition, "home_controller_repository_query_duration_seconds_count"))
+	assert.Equal(t, []string{"home_controller_pgxpool_total_conns 3"},
+		seriesOf(exposition, "home_controller_pgxpool_total_conns"))
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// unmatchedRoute - label for the requests that did not match any route, keeps 404 scans from creating new series
+	unmatchedRoute = "unmatched"
+	// otherMethod - label for the request methods that are not standard HTTP verbs, any token is a valid method
+	otherMethod = "other"
+)
+
+// knownMethods - HTTP verbs labelled as is
+var knownMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// methodLabel - the method of the request, otherMethod for anything but the known verbs
+func methodLabel(method string) string {
+	if _, ok := knownMethods[method]; ok {
+		return method
+	}
+	return otherMethod
+}
+
+// RedMiddleware - collects rate, errors and duration of the requests labelled by the route template
+// (e.g. /api/sensors/:sensor_id) rather than by the raw URI to keep the number of series bounded
+func (m *Metrics) RedMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t := time.Now()
+		m.httpRequestsInFlight.Inc()
+		defer m.httpRequestsInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := methodLabel(c.Request.Method)
+
+		m.httpRequestsTotal.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpRequestDuration.WithLabelValues(method, route).Observe(time.Since(t).Seconds())
+	}
+}
+
+func RedMiddleware() gin.HandlerFunc {
+	return defaultMetrics.RedMiddleware()
+}
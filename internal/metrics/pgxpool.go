@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStat - connection pool statistics snapshot, implemented by *pgxpool.Stat
+type PoolStat interface {
+	AcquiredConns() int32
+	IdleConns() int32
+	ConstructingConns() int32
+	TotalConns() int32
+	MaxConns() int32
+	AcquireCount() int64
+	AcquireDuration() time.Duration
+	CanceledAcquireCount() int64
+	EmptyAcquireCount() int64
+}
+
+// poolCollector - exports the connection pool statistics, gathered on every scrape
+type poolCollector struct {
+	stat func() PoolStat
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+}
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+}
+
+func NewPoolCollector(stat func() PoolStat) prometheus.Collector {
+	return &poolCollector{
+		stat:                 stat,
+		acquiredConns:        poolDesc("acquired_conns", "Number of the currently acquired connections."),
+		idleConns:            poolDesc("idle_conns", "Number of the currently idle connections."),
+		constructingConns:    poolDesc("constructing_conns", "Number of the connections being established."),
+		totalConns:           poolDesc("total_conns", "Total number of the connections in the pool."),
+		maxConns:             poolDesc("max_conns", "Maximum size of the pool."),
+		acquireCount:         poolDesc("acquires_total", "Number of the successful connection acquires."),
+		acquireDuration:      poolDesc("acquire_duration_seconds_total", "Total time spent on the successful acquires."),
+		canceledAcquireCount: poolDesc("canceled_acquires_total", "Number of the acquires canceled by the context."),
+		emptyAcquireCount:    poolDesc("empty_acquires_total", "Number of the acquires that waited for a free connection."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(s.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+}
+
+// RegisterPool - exports the statistics of the pool with the default metrics
+func RegisterPool(pool *pgxpool.Pool) error {
+	return defaultMetrics.Registerer().Register(NewPoolCollector(func() PoolStat {
+		return pool.Stat()
+	}))
+}
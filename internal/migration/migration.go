@@ -0,0 +1,188 @@
+// Package migration - applies the embedded schema migrations to the postgres storage
+package migration
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/jackc/pgx/v5/stdlib" // database/sql driver "pgx"
+
+	"homework/migrations"
+)
+
+// DefaultLockTimeout - how long a migration waits for the one running concurrently (e.g. by another replica)
+const DefaultLockTimeout = time.Minute
+
+var (
+	ErrSchemaBehind = errors.New("database schema is behind the migrations")
+	ErrSchemaDirty  = errors.New("database schema is dirty, the last migration failed")
+)
+
+// Migration - single embedded migration
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// Status - version of the database schema and the known migrations in the ascending version order
+type Status struct {
+	// Version - the last applied migration, 0 if none
+	Version    uint
+	Dirty      bool
+	Migrations []Migration
+}
+
+// Latest - version of the last known migration
+func (s Status) Latest() uint {
+	if len(s.Migrations) == 0 {
+		return 0
+	}
+	return s.Migrations[len(s.Migrations)-1].Version
+}
+
+// Pending - migrations that are not applied yet
+func (s Status) Pending() []Migration {
+	var pending []Migration
+	for _, m := range s.Migrations {
+		if m.Version > s.Version {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// Check - whether the service can work with the schema: it must not be dirty or behind the migrations.
+// A schema ahead of the migrations (e.g. after a rollback of the binary) is accepted
+func (s Status) Check() error {
+	if s.Dirty {
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, s.Version)
+	}
+	if s.Version < s.Latest() {
+		return fmt.Errorf("%w: version %d, latest %d", ErrSchemaBehind, s.Version, s.Latest())
+	}
+	return nil
+}
+
+// Migrations - the embedded migrations in the ascending version order
+func Migrations() ([]Migration, error) {
+	return list(migrations.FS)
+}
+
+func list(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("can't read migrations: %w", err)
+	}
+	var result []Migration
+	for _, entry := range entries {
+		m, err := source.Parse(entry.Name())
+		if err != nil || m.Direction != source.Up {
+			continue
+		}
+		result = append(result, Migration{Version: m.Version, Name: m.Identifier})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// Migrator - applies the embedded migrations to a database. Up and Down hold a postgres advisory lock
+// while migrating, so the replicas starting at once apply every migration exactly once
+type Migrator struct {
+	m           *migrate.Migrate
+	migrations  []Migration
+	lockTimeout time.Duration
+}
+
+// New - migrator of the database, databaseURL is the same connection URL the storage uses
+func New(databaseURL string, options ...func(*Migrator)) (*Migrator, error) {
+	mg := &Migrator{lockTimeout: DefaultLockTimeout}
+	for _, o := range options {
+		o(mg)
+	}
+
+	var err error
+	if mg.migrations, err = Migrations(); err != nil {
+		return nil, err
+	}
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("can't open migrations: %w", err)
+	}
+	db, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("can't open database: %w", err)
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("can't connect to the database: %w", err)
+	}
+	mg.m, err = migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		_ = driver.Close()
+		return nil, fmt.Errorf("can't create migrator: %w", err)
+	}
+	mg.m.LockTimeout = mg.lockTimeout
+	return mg, nil
+}
+
+// WithLockTimeout - how long Up and Down wait for the lock held by a concurrent migration
+func WithLockTimeout(timeout time.Duration) func(*Migrator) {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+// Up - applies all the pending migrations
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("can't migrate up: %w", err)
+	}
+	return nil
+}
+
+// Down - rolls back the steps last migrations, all of them if steps is not positive
+func (m *Migrator) Down(steps int) error {
+	var err error
+	if steps > 0 {
+		err = m.m.Steps(-steps)
+	} else {
+		err = m.m.Down()
+	}
+	var short migrate.ErrShortLimit
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) && !errors.As(err, &short) {
+		return fmt.Errorf("can't migrate down: %w", err)
+	}
+	return nil
+}
+
+// Force - sets the version without running the migrations and clears the dirty flag, the way out after
+// a failed migration was fixed by hand; -1 means no migration applied
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("can't force version %d: %w", version, err)
+	}
+	return nil
+}
+
+// Status - current version of the schema
+func (m *Migrator) Status() (Status, error) {
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, fmt.Errorf("can't get schema version: %w", err)
+	}
+	return Status{Version: version, Dirty: dirty, Migrations: m.migrations}, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
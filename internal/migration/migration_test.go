@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"homework/migrations"
+)
+
+func TestMigrations(t *testing.T) {
+	t.Run("ok, embedded", func(t *testing.T) {
+		list, err := Migrations()
+		require.NoError(t, err)
+		require.NotEmpty(t, list)
+		assert.Equal(t, Migration{Version: 1, Name: "create_users_table"}, list[0])
+
+		for i, m := range list {
+			assert.Equal(t, uint(i+1), m.Version, "versions must be consecutive")
+			_, err := fs.Stat(migrations.FS, fmt.Sprintf("%06d_%s.down.sql", m.Version, m.Name))
+			assert.NoError(t, err, "%s has no down migration", m.Name)
+		}
+	})
+
+	t.Run("ok, sorted and only up", func(t *testing.T) {
+		list, err := list(fstest.MapFS{
+			"000010_b.up.sql":   {},
+			"000010_b.down.sql": {},
+			"000002_a.up.sql":   {},
+			"README.md":         {},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []Migration{{Version: 2, Name: "a"}, {Version: 10, Name: "b"}}, list)
+	})
+}
+
+func TestStatus(t *testing.T) {
+	migrations := []Migration{{Version: 1, Name: "a"}, {Version: 2, Name: "b"}, {Version: 3, Name: "c"}}
+
+	status := Status{Version: 3, Migrations: migrations}
+	assert.Equal(t, uint(3), status.Latest())
+	assert.Empty(t, status.Pending())
+	assert.NoError(t, status.Check())
+
+	status = Status{Version: 1, Migrations: migrations}
+	assert.Equal(t, migrations[1:], status.Pending())
+	assert.ErrorIs(t, status.Check(), ErrSchemaBehind)
+
+	status = Status{Version: 3, Dirty: true, Migrations: migrations}
+	assert.ErrorIs(t, status.Check(), ErrSchemaDirty)
+
+	status = Status{Version: 4, Migrations: migrations}
+	assert.NoError(t, status.Check(), "the schema ahead is accepted")
+
+	assert.Zero(t, Status{}.Latest())
+}
@@ -0,0 +1,93 @@
+package migration_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"homework/internal/migration"
+	"homework/pkg/pg_test"
+)
+
+type MigratorTestSuite struct {
+	suite.Suite
+	testDB *pg_test.TestDatabase
+	url    string
+}
+
+func (suite *MigratorTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.url = "postgres://" + pg_test.DbUser + ":" + pg_test.DbPass + "@" + suite.testDB.DbAddress + "/" + pg_test.DbName + "?sslmode=disable"
+}
+
+func (suite *MigratorTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *MigratorTestSuite) status(m *migration.Migrator) migration.Status {
+	status, err := m.Status()
+	suite.Require().NoError(err)
+	return status
+}
+
+func (suite *MigratorTestSuite) TestMigrator() {
+	m, err := migration.New(suite.url)
+	suite.Require().NoError(err)
+	defer m.Close()
+
+	status := suite.status(m)
+	suite.Equal(status.Latest(), status.Version, "migrated by the test database")
+	suite.NoError(status.Check())
+	suite.NoError(m.Up(), "nothing to apply is not an error")
+
+	suite.Require().NoError(m.Down(2))
+	status = suite.status(m)
+	suite.Equal(status.Latest()-2, status.Version)
+	suite.Len(status.Pending(), 2)
+	suite.ErrorIs(status.Check(), migration.ErrSchemaBehind)
+
+	suite.Require().NoError(m.Down(0))
+	suite.Equal(uint(0), suite.status(m).Version)
+	suite.NoError(m.Down(1), "nothing to roll back is not an error")
+
+	suite.Run("concurrent up", func() {
+		var wg sync.WaitGroup
+		errs := make([]error, 3)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				replica, err := migration.New(suite.url)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				defer replica.Close()
+				errs[i] = replica.Up()
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			suite.NoError(err)
+		}
+		status := suite.status(m)
+		suite.Equal(status.Latest(), status.Version)
+		suite.False(status.Dirty)
+	})
+
+	suite.Run("force", func() {
+		latest := suite.status(m).Latest()
+		suite.Require().NoError(m.Down(1))
+		suite.Require().NoError(m.Force(int(latest)))
+		suite.Equal(latest, suite.status(m).Version)
+
+		suite.Require().NoError(m.Force(int(latest) - 1))
+		suite.Require().NoError(m.Up())
+		suite.Equal(latest, suite.status(m).Version)
+	})
+}
+
+func TestMigratorTestSuite(t *testing.T) {
+	suite.Run(t, new(MigratorTestSuite))
+}
@@ -0,0 +1,141 @@
+package openapigen
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// object - YAML mapping keeping the order of its keys, so the generated spec reads in the source order
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+// newObject - object of the key, value pairs
+func newObject(pairs ...any) *object {
+	o := &object{values: make(map[string]any, len(pairs)/2)}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		o.set(pairs[i].(string), pairs[i+1])
+	}
+	return o
+}
+
+func (o *object) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) lookup(key string) (any, bool) {
+	if o == nil {
+		return nil, false
+	}
+	v, ok := o.values[key]
+	return v, ok
+}
+
+func (o *object) get(key string) any {
+	v, _ := o.lookup(key)
+	return v
+}
+
+// object - the nested object of the key, nil if there is none
+func (o *object) object(key string) *object {
+	v, _ := o.get(key).(*object)
+	return v
+}
+
+func (o *object) list(key string) []any {
+	v, _ := o.get(key).([]any)
+	return v
+}
+
+func (o *object) strings(key string) []string {
+	var res []string
+	for _, v := range o.list(key) {
+		if s, ok := v.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// pick - object of the keys present in o, in the order of keys
+func (o *object) pick(keys []string) *object {
+	res := newObject()
+	for _, k := range keys {
+		if v, ok := o.lookup(k); ok {
+			res.set(k, v)
+		}
+	}
+	return res
+}
+
+func clone(node any) any {
+	switch n := node.(type) {
+	case *object:
+		res := newObject()
+		for _, k := range n.keys {
+			res.set(k, clone(n.values[k]))
+		}
+		return res
+	case []any:
+		res := make([]any, len(n))
+		for i, v := range n {
+			res[i] = clone(v)
+		}
+		return res
+	}
+	return node
+}
+
+// fromNode - the tree of objects, slices and scalars of the parsed YAML
+func fromNode(n *yaml.Node) any {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return fromNode(n.Content[0])
+	case yaml.MappingNode:
+		o := newObject()
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			o.set(n.Content[i].Value, fromNode(n.Content[i+1]))
+		}
+		return o
+	case yaml.SequenceNode:
+		res := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			res = append(res, fromNode(c))
+		}
+		return res
+	case yaml.AliasNode:
+		return fromNode(n.Alias)
+	}
+	var v any
+	_ = n.Decode(&v)
+	return v
+}
+
+// toNode - YAML node of the tree, the object keys are always strings
+func toNode(node any) *yaml.Node {
+	switch v := node.(type) {
+	case *object:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range v.keys {
+			key := &yaml.Node{}
+			_ = key.Encode(k)
+			n.Content = append(n.Content, key, toNode(v.values[k]))
+		}
+		return n
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			n.Content = append(n.Content, toNode(item))
+		}
+		return n
+	}
+	n := &yaml.Node{}
+	_ = n.Encode(node)
+	return n
+}
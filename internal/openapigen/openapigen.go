@@ -0,0 +1,401 @@
+// Package openapigen - converts the Swagger 2.0 description of the HTTP API (the source of the DTOs) into
+// the OpenAPI 3.1 one served by the controller, so that the two never diverge
+package openapigen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	binaryTypes = []string{"application/msgpack", "application/cbor"}
+	okTypes     = append([]string{"application/json"}, binaryTypes...)
+	errTypes    = append([]string{"application/problem+json"}, binaryTypes...)
+	// schemaKeys - keywords of the non-body Swagger parameters and headers that make their OpenAPI schema
+	schemaKeys = []string{
+		"type", "format", "enum", "items", "minimum", "maximum", "default", "pattern",
+		"minLength", "maxLength", "minItems", "maxItems",
+	}
+	pathParam = regexp.MustCompile(`{([^}]+)}`)
+)
+
+// Header - first line of the generated spec
+const Header = "# Code generated from swagger.yaml by go generate; DO NOT EDIT.\n"
+
+const description = "Интерфейс управления и мониторинга устройствами умного дома. Версия 1 доступна по /api, " +
+	"версия 2 - по /api/v2: в ней списки датчиков, локаций, исполнительных устройств и автоматизаций разбиты " +
+	"на страницы (пути /v2/...), остальные операции общие для обеих версий и доступны с обоими префиксами."
+
+// pagedLists - v1 lists paginated in v2 by the path and the element schema
+var pagedLists = []struct {
+	path, schema, title string
+}{
+	{"/sensors", "Sensor", "датчиков"},
+	{"/locations", "Location", "локаций"},
+	{"/actuators", "Actuator", "исполнительных устройств"},
+	{"/automations", "AutomationRule", "правил автоматизации"},
+}
+
+// Convert - OpenAPI 3.1 YAML of the Swagger 2.0 YAML spec: the bodies are described for every codec, v2 gets
+// the paginated variants of the lists and every other path is served under both prefixes
+func Convert(swagger []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(swagger, &root); err != nil {
+		return nil, fmt.Errorf("cannot parse swagger: %w", err)
+	}
+	if len(root.Content) != 1 {
+		return nil, errors.New("swagger is empty")
+	}
+	src, ok := fromNode(root.Content[0]).(*object)
+	if !ok {
+		return nil, errors.New("swagger is not an object")
+	}
+
+	doc, err := convertV1(src)
+	if err != nil {
+		return nil, err
+	}
+	addV2(doc)
+
+	var out bytes.Buffer
+	out.WriteString(Header)
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(toNode(fixRefs(doc))); err != nil {
+		return nil, fmt.Errorf("cannot encode openapi: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("cannot encode openapi: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func convertV1(src *object) (*object, error) {
+	info := src.object("info")
+	doc := newObject(
+		"openapi", "3.1.0",
+		"info", newObject("title", info.get("title"), "description", info.get("description"), "version", "2.0"),
+		"servers", []any{newObject("url", "/api")},
+		"tags", src.get("tags"),
+	)
+
+	paths := newObject()
+	for _, path := range src.object("paths").keys {
+		item := src.object("paths").object(path)
+		converted := newObject()
+		for _, method := range item.keys {
+			op, err := convertOperation(item.object(method))
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			converted.set(method, op)
+		}
+		addMissingPathParams(path, converted)
+		paths.set(path, converted)
+	}
+	doc.set("paths", paths)
+
+	schemas := newObject()
+	definitions := src.object("definitions")
+	for _, name := range definitions.keys {
+		schemas.set(name, fixSchema(definitions.get(name)))
+	}
+	doc.set("components", newObject("schemas", schemas))
+	return doc, nil
+}
+
+func convertOperation(op *object) (*object, error) {
+	res := newObject()
+	for _, k := range []string{"summary", "description", "operationId", "tags"} {
+		if v, ok := op.lookup(k); ok {
+			res.set(k, v)
+		}
+	}
+
+	var params []any
+	var body *object
+	for _, p := range op.list("parameters") {
+		param, ok := p.(*object)
+		if !ok {
+			return nil, errors.New("parameter is not an object")
+		}
+		if param.get("in") == "body" {
+			schema := param.object("schema")
+			types := okTypes
+			if schema.get("format") == "binary" {
+				types = op.strings("consumes")
+			}
+			required, _ := param.lookup("required")
+			if required == nil {
+				required = false
+			}
+			desc, _ := param.lookup("description")
+			if desc == nil {
+				desc = ""
+			}
+			body = newObject("description", desc, "required", required, "content", content(fixSchema(schema), types))
+			continue
+		}
+		q := newObject()
+		for _, k := range []string{"name", "in", "description", "required"} {
+			if v, ok := param.lookup(k); ok {
+				q.set(k, v)
+			}
+		}
+		q.set("schema", param.pick(schemaKeys))
+		params = append(params, q)
+	}
+	if params != nil {
+		res.set("parameters", params)
+	}
+	if body != nil {
+		res.set("requestBody", body)
+	}
+
+	responses := newObject()
+	for _, code := range op.object("responses").keys {
+		r := op.object("responses").object(code)
+		nr := newObject("description", r.get("description"))
+		if headers := r.object("headers"); headers != nil {
+			nh := newObject()
+			for _, name := range headers.keys {
+				h := headers.object(name)
+				header := newObject()
+				if v, ok := h.lookup("description"); ok {
+					header.set("description", v)
+				}
+				header.set("schema", h.pick(schemaKeys))
+				nh.set(name, header)
+			}
+			nr.set("headers", nh)
+		}
+		schema := r.object("schema")
+		operationID, _ := op.get("operationId").(string)
+		switch {
+		case schema != nil && schema.get("type") == "file":
+			var raw []string
+			for _, t := range op.strings("produces") {
+				if t != "application/problem+json" {
+					raw = append(raw, t)
+				}
+			}
+			nr.set("content", content(newObject("type", "string", "format", "binary"), raw))
+		case schema != nil:
+			types := okTypes
+			if ref, _ := schema.get("$ref").(string); strings.HasSuffix(ref, "/Problem") {
+				types = errTypes
+			}
+			nr.set("content", content(fixSchema(schema), types))
+		case (code == "default" || code >= "400") && !strings.HasPrefix(operationID, "head"):
+			nr.set("content", content(newObject("$ref", "#/definitions/Problem"), errTypes))
+		}
+		responses.set(code, nr)
+	}
+	res.set("responses", responses)
+
+	for _, k := range op.keys {
+		if strings.HasPrefix(k, "x-") {
+			res.set(k, op.get(k))
+		}
+	}
+	return res, nil
+}
+
+// addMissingPathParams - declares the path parameters of the path item in every operation, Swagger lets
+// an operation omit the ones it doesn't use while OpenAPI requires all of them
+func addMissingPathParams(path string, item *object) {
+	known := map[string]any{}
+	for _, method := range item.keys {
+		for _, p := range item.object(method).list("parameters") {
+			if param := p.(*object); param.get("in") == "path" {
+				known[param.get("name").(string)] = param
+			}
+		}
+	}
+	for _, method := range item.keys {
+		op := item.object(method)
+		have := map[string]bool{}
+		for _, p := range op.list("parameters") {
+			if param := p.(*object); param.get("in") == "path" {
+				have[param.get("name").(string)] = true
+			}
+		}
+		var missing []any
+		for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+			if p, ok := known[m[1]]; ok && !have[m[1]] {
+				missing = append(missing, clone(p))
+			}
+		}
+		if missing == nil {
+			continue
+		}
+		params := append(missing, op.list("parameters")...)
+		rest := newObject()
+		for _, k := range op.keys {
+			switch k {
+			case "parameters", "requestBody", "responses":
+			default:
+				rest.set(k, op.get(k))
+			}
+		}
+		rest.set("parameters", params)
+		for _, k := range []string{"requestBody", "responses"} {
+			if v, ok := op.lookup(k); ok {
+				rest.set(k, v)
+			}
+		}
+		item.set(method, rest)
+	}
+}
+
+// addV2 - the /v2 paginated lists and the servers of the shared paths
+func addV2(doc *object) {
+	components := doc.object("components")
+	components.set("parameters", newObject(
+		"PageLimit", newObject(
+			"name", "limit", "in", "query", "required", false,
+			"description", "Максимальное число элементов на странице",
+			"schema", newObject("type", "integer", "minimum", 1, "maximum", 200, "default", 50),
+		),
+		"PageCursor", newObject(
+			"name", "cursor", "in", "query", "required", false,
+			"description", "Курсор следующей страницы из next_cursor предыдущего ответа, без него - первая страница",
+			"schema", newObject("type", "string"),
+		),
+	))
+	doc.object("info").set("description", description)
+
+	schemas := components.object("schemas")
+	pageOf := map[string]string{}
+	for _, l := range pagedLists {
+		pageOf[l.path] = l.schema + "Page"
+		schemas.set(l.schema+"Page", newObject(
+			"title", l.schema+"Page",
+			"description", "Страница "+l.title+", элементы упорядочены по id",
+			"type", "object",
+			"properties", newObject(
+				"items", newObject(
+					"description", "Элементы страницы",
+					"type", "array",
+					"items", newObject("$ref", "#/components/schemas/"+l.schema),
+				),
+				"next_cursor", newObject(
+					"description", "Курсор следующей страницы, отсутствует на последней странице",
+					"type", "string",
+				),
+			),
+			"required", []any{"items"},
+		))
+	}
+
+	paths := doc.object("paths")
+	v2 := newObject()
+	for _, path := range append([]string(nil), paths.keys...) {
+		page, paged := pageOf[path]
+		if !paged {
+			item := newObject("servers", []any{
+				newObject("url", "/api", "description", "Версия 1"),
+				newObject("url", "/api/v2", "description", "Версия 2"),
+			})
+			for _, method := range paths.object(path).keys {
+				item.set(method, paths.object(path).get(method))
+			}
+			paths.set(path, item)
+			continue
+		}
+
+		item := clone(paths.object(path)).(*object)
+		for _, method := range item.keys {
+			op := item.object(method)
+			if id, ok := op.get("operationId").(string); ok {
+				op.set("operationId", id+"V2")
+			}
+			if method != "get" && method != "head" {
+				continue
+			}
+			op.set("description", op.get("description").(string)+" (страница списка)")
+			op.set("parameters", append(op.list("parameters"),
+				newObject("$ref", "#/components/parameters/PageLimit"),
+				newObject("$ref", "#/components/parameters/PageCursor"),
+			))
+			responses := op.object("responses")
+			responses.object("200").set("content", content(newObject("$ref", "#/components/schemas/"+page), okTypes))
+			if _, ok := responses.lookup("422"); !ok {
+				responses.set("422", newObject(
+					"description", "Неверные параметры страницы",
+					"content", content(newObject("$ref", "#/components/schemas/Problem"), errTypes),
+				))
+			}
+			sort.Strings(responses.keys)
+		}
+		v2.set("/v2"+path, item)
+	}
+	for _, path := range v2.keys {
+		paths.set(path, v2.get(path))
+	}
+}
+
+func content(schema any, types []string) *object {
+	res := newObject()
+	for _, t := range types {
+		res.set(t, newObject("schema", clone(schema)))
+	}
+	return res
+}
+
+// fixSchema - drops the Swagger only x-nullable from the schema, its properties and items
+func fixSchema(node any) any {
+	o, ok := node.(*object)
+	if !ok {
+		return node
+	}
+	res := newObject()
+	for _, k := range o.keys {
+		v := o.get(k)
+		switch k {
+		case "x-nullable":
+			continue
+		case "properties":
+			props := newObject()
+			for _, name := range v.(*object).keys {
+				props.set(name, fixSchema(v.(*object).get(name)))
+			}
+			res.set(k, props)
+		case "items", "additionalProperties":
+			res.set(k, fixSchema(v))
+		default:
+			res.set(k, v)
+		}
+	}
+	return res
+}
+
+// fixRefs - points the references to the Swagger definitions at the OpenAPI component schemas
+func fixRefs(node any) any {
+	switch n := node.(type) {
+	case *object:
+		res := newObject()
+		for _, k := range n.keys {
+			v := n.get(k)
+			if s, ok := v.(string); ok && k == "$ref" {
+				res.set(k, strings.ReplaceAll(s, "#/definitions/", "#/components/schemas/"))
+			} else {
+				res.set(k, fixRefs(v))
+			}
+		}
+		return res
+	case []any:
+		res := make([]any, len(n))
+		for i, v := range n {
+			res[i] = fixRefs(v)
+		}
+		return res
+	}
+	return node
+}
@@ -0,0 +1,98 @@
+package openapigen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const swagger = `
+swagger: "2.0"
+info:
+  title: API
+  description: test
+  version: "0.1"
+tags:
+  - name: sensors
+paths:
+  /sensors:
+    get:
+      description: Список датчиков
+      operationId: getSensors
+      responses:
+        200:
+          description: OK
+          schema:
+            type: array
+            items:
+              $ref: "#/definitions/Sensor"
+  /sensors/{sensor_id}:
+    get:
+      operationId: getSensor
+      parameters:
+        - name: sensor_id
+          in: path
+          required: true
+          type: integer
+          format: int64
+      responses:
+        200:
+          description: OK
+          schema:
+            $ref: "#/definitions/Sensor"
+        404:
+          description: Not found
+    delete:
+      operationId: deleteSensor
+      responses:
+        204:
+          description: Deleted
+definitions:
+  Sensor:
+    type: object
+    properties:
+      id:
+        type: integer
+        x-nullable: false
+`
+
+func TestConvert(t *testing.T) {
+	out, err := Convert([]byte(swagger))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), Header)
+
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal(out, &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	paths := doc["paths"].(map[string]any)
+	item := paths["/sensors/{sensor_id}"].(map[string]any)
+	assert.Len(t, item["servers"], 2, "a path shared by v1 and v2 is served under both prefixes")
+	get := item["get"].(map[string]any)
+	responses := get["responses"].(map[string]any)
+	assert.Equal(t, "#/components/schemas/Sensor",
+		responses["200"].(map[string]any)["content"].(map[string]any)["application/cbor"].(map[string]any)["schema"].(map[string]any)["$ref"])
+	assert.Contains(t, responses["404"].(map[string]any)["content"], "application/problem+json")
+	deleteParams := item["delete"].(map[string]any)["parameters"].([]any)
+	require.Len(t, deleteParams, 1, "the path parameter is declared in every operation")
+	assert.Equal(t, "sensor_id", deleteParams[0].(map[string]any)["name"])
+
+	list := paths["/v2/sensors"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, "getSensorsV2", list["operationId"])
+	assert.Len(t, list["parameters"], 2)
+	assert.Contains(t, list["responses"], "422")
+	assert.NotContains(t, paths["/sensors"], "servers")
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Contains(t, schemas, "SensorPage")
+	assert.NotContains(t, schemas["Sensor"].(map[string]any)["properties"].(map[string]any)["id"], "x-nullable")
+}
+
+func TestConvert_Error(t *testing.T) {
+	for _, src := range []string{"", "[1, 2]", "swagger: ["} {
+		_, err := Convert([]byte(src))
+		assert.Error(t, err, src)
+	}
+}
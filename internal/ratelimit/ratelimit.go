@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"container/list"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepPeriod - how often the buckets refilled up to the burst are dropped, so the idle keys don't pile up
+const sweepPeriod = time.Minute
+
+// DefaultMaxKeys - how many keys a limiter tracks by default
+const DefaultMaxKeys = 10000
+
+// Limit - sustained rate in tokens per second and the bucket size, zero rate disables the limit
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0
+}
+
+// burst - the bucket size, at least a token so the limited key can be served at all
+func (l Limit) burst() float64 {
+	return math.Max(float64(l.Burst), 1)
+}
+
+type bucket struct {
+	key     string
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter - token buckets by an arbitrary key (client IP, API key, sensor serial number),
+// a nil Limiter allows everything. At most maxKeys buckets are kept: once they are all taken, the least recently
+// used one is dropped for a new key, so a client sending random keys can't grow the memory without a bound
+type Limiter struct {
+	limit   Limit
+	now     func() time.Time
+	maxKeys int
+
+	mu        sync.Mutex
+	buckets   map[string]*list.Element
+	recent    *list.List // Buckets from the most to the least recently used
+	lastSweep time.Time
+}
+
+func New(limit Limit, options ...func(*Limiter)) *Limiter {
+	l := &Limiter{
+		limit:   limit,
+		now:     time.Now,
+		maxKeys: DefaultMaxKeys,
+		buckets: make(map[string]*list.Element),
+		recent:  list.New(),
+	}
+	for _, o := range options {
+		o(l)
+	}
+	l.lastSweep = l.now()
+	return l
+}
+
+// WithClock - replaces the time source, is meant for the tests
+func WithClock(now func() time.Time) func(*Limiter) {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// WithMaxKeys - how many keys are tracked at most, non-positive values keep the default
+func WithMaxKeys(maxKeys int) func(*Limiter) {
+	return func(l *Limiter) {
+		if maxKeys > 0 {
+			l.maxKeys = maxKeys
+		}
+	}
+}
+
+// Enabled - whether the limiter limits anything
+func (l *Limiter) Enabled() bool {
+	return l != nil && l.limit.Enabled()
+}
+
+// Allow - takes a token from the bucket of the key, if it is empty returns false
+// and the time until the next token is refilled
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if !l.Enabled() {
+		return true, 0
+	}
+
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	var b *bucket
+	if e, ok := l.buckets[key]; ok {
+		b = e.Value.(*bucket)
+		l.recent.MoveToFront(e)
+	} else {
+		if len(l.buckets) >= l.maxKeys {
+			l.remove(l.recent.Back())
+		}
+		b = &bucket{key: key, tokens: l.limit.burst(), updated: now}
+		l.buckets[key] = l.recent.PushFront(b)
+	}
+	b.tokens = l.refilled(b, now)
+	b.updated = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (l *Limiter) refilled(b *bucket, now time.Time) float64 {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed <= 0 {
+		return b.tokens
+	}
+	return math.Min(l.limit.burst(), b.tokens+elapsed*l.limit.Rate)
+}
+
+// sweep - drops the full buckets, a missing bucket is the same as a full one
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepPeriod {
+		return
+	}
+	l.lastSweep = now
+	for e := l.recent.Front(); e != nil; {
+		next := e.Next()
+		if l.refilled(e.Value.(*bucket), now) >= l.limit.burst() {
+			l.remove(e)
+		}
+		e = next
+	}
+}
+
+func (l *Limiter) remove(e *list.Element) {
+	delete(l.buckets, e.Value.(*bucket).key)
+	l.recent.Remove(e)
+}
+
+// Len - number of the tracked keys
+func (l *Limiter) Len() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Run("ok, nil or disabled limiter allows everything", func(t *testing.T) {
+		var nilLimiter *Limiter
+		ok, _ := nilLimiter.Allow("10.0.0.1")
+		assert.True(t, ok)
+
+		l := New(Limit{})
+		for i := 0; i < 100; i++ {
+			ok, _ := l.Allow("10.0.0.1")
+			assert.True(t, ok)
+		}
+		assert.Zero(t, l.Len())
+	})
+
+	t.Run("ok, burst then sustained rate", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)}
+		l := New(Limit{Rate: 2, Burst: 3}, WithClock(clock.Now))
+
+		for i := 0; i < 3; i++ {
+			ok, _ := l.Allow("sensor")
+			assert.True(t, ok)
+		}
+		ok, retryAfter := l.Allow("sensor")
+		assert.False(t, ok)
+		assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+		// Other keys have their own buckets
+		ok, _ = l.Allow("other")
+		assert.True(t, ok)
+
+		clock.now = clock.now.Add(500 * time.Millisecond)
+		ok, _ = l.Allow("sensor")
+		assert.True(t, ok)
+		ok, _ = l.Allow("sensor")
+		assert.False(t, ok)
+	})
+
+	t.Run("ok, refilled buckets are dropped", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)}
+		l := New(Limit{Rate: 0.1, Burst: 5}, WithClock(clock.Now))
+
+		l.Allow("a")
+		clock.now = clock.now.Add(sweepPeriod - time.Second)
+		l.Allow("b")
+		assert.Equal(t, 2, l.Len())
+
+		// "b" has spent a token a second ago, so its bucket is not full yet
+		clock.now = clock.now.Add(time.Second)
+		l.Allow("c")
+		assert.Equal(t, 2, l.Len())
+	})
+	t.Run("ok, least recently used key evicted over the cap", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)}
+		l := New(Limit{Rate: 0.1, Burst: 1}, WithClock(clock.Now), WithMaxKeys(2))
+
+		ok, _ := l.Allow("a")
+		require.True(t, ok)
+		ok, _ = l.Allow("b")
+		require.True(t, ok)
+		ok, _ = l.Allow("a")
+		require.False(t, ok)
+
+		// "b" is the least recently used one
+		for i := 0; i < 100; i++ {
+			l.Allow(fmt.Sprintf("random-%d", i))
+			assert.LessOrEqual(t, l.Len(), 2)
+		}
+		ok, _ = l.Allow("b")
+		assert.True(t, ok, "an evicted key starts with a full bucket")
+	})
+}
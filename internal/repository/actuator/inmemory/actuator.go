@@ -0,0 +1,90 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+)
+
+type ActuatorRepository struct {
+	storage map[int64]domain.Actuator
+	lastId  int64
+	mu      sync.Mutex
+}
+
+func NewActuatorRepository() *ActuatorRepository {
+	return &ActuatorRepository{
+		storage: make(map[int64]domain.Actuator),
+		lastId:  0,
+		mu:      sync.Mutex{},
+	}
+}
+
+func (r *ActuatorRepository) SaveActuator(ctx context.Context, actuator *domain.Actuator) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if actuator == nil {
+		return errors.New("got nil actuator at SaveActuator()")
+	}
+
+	r.mu.Lock()
+	r.lastId++
+	id := r.lastId
+
+	actuator.ID = id
+	r.storage[id] = *actuator
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *ActuatorRepository) GetActuators(ctx context.Context) ([]domain.Actuator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	res := make([]domain.Actuator, 0, len(r.storage))
+	for _, v := range r.storage {
+		res = append(res, v)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (r *ActuatorRepository) GetActuatorByID(ctx context.Context, id int64) (*domain.Actuator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	val, exists := r.storage[id]
+	r.mu.Unlock()
+	if !exists {
+		return nil, usecase.ErrActuatorNotFound
+	}
+
+	return &val, nil
+}
+
+func (r *ActuatorRepository) GetActuatorBySerialNumber(ctx context.Context, sn string) (*domain.Actuator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.storage {
+		if v.SerialNumber == sn {
+			return &v, nil
+		}
+	}
+
+	return nil, usecase.ErrActuatorNotFound
+}
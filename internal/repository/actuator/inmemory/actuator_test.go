@@ -0,0 +1,58 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActuatorRepository_SaveActuator(t *testing.T) {
+	t.Run("err, actuator is nil", func(t *testing.T) {
+		ar := NewActuatorRepository()
+		err := ar.SaveActuator(context.Background(), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		ar := NewActuatorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := ar.SaveActuator(ctx, &domain.Actuator{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, save and get", func(t *testing.T) {
+		ar := NewActuatorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		relay := &domain.Actuator{SerialNumber: "1234567890", Type: domain.ActuatorTypeRelay}
+		assert.NoError(t, ar.SaveActuator(ctx, relay))
+		valve := &domain.Actuator{SerialNumber: "1234567891", Type: domain.ActuatorTypeValve}
+		assert.NoError(t, ar.SaveActuator(ctx, valve))
+
+		got, err := ar.GetActuatorByID(ctx, valve.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, *valve, *got)
+
+		got, err = ar.GetActuatorBySerialNumber(ctx, relay.SerialNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, *relay, *got)
+
+		actuators, err := ar.GetActuators(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Actuator{*relay, *valve}, actuators)
+	})
+
+	t.Run("fail, actuator not found", func(t *testing.T) {
+		ar := NewActuatorRepository()
+		_, err := ar.GetActuatorByID(context.Background(), 1)
+		assert.ErrorIs(t, err, usecase.ErrActuatorNotFound)
+		_, err = ar.GetActuatorBySerialNumber(context.Background(), "1234567890")
+		assert.ErrorIs(t, err, usecase.ErrActuatorNotFound)
+	})
+}
@@ -0,0 +1,125 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+	"time"
+)
+
+type CommandRepository struct {
+	storage map[int64]domain.Command
+	lastId  int64
+	mu      sync.Mutex
+}
+
+func NewCommandRepository() *CommandRepository {
+	return &CommandRepository{
+		storage: make(map[int64]domain.Command),
+		lastId:  0,
+		mu:      sync.Mutex{},
+	}
+}
+
+func (r *CommandRepository) SaveCommand(ctx context.Context, command *domain.Command) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if command == nil {
+		return errors.New("got nil command at SaveCommand()")
+	}
+
+	r.mu.Lock()
+	r.lastId++
+	id := r.lastId
+
+	command.ID = id
+	r.storage[id] = *command
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *CommandRepository) UpdateCommandStatus(ctx context.Context, command *domain.Command, from domain.CommandStatus) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if command == nil {
+		return errors.New("got nil command at UpdateCommandStatus()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	val, exists := r.storage[command.ID]
+	if !exists {
+		return usecase.ErrCommandNotFound
+	}
+	if val.Status != from {
+		return usecase.ErrCommandConflict
+	}
+
+	val.Status = command.Status
+	val.Error = command.Error
+	val.UpdatedAt = command.UpdatedAt
+	r.storage[command.ID] = val
+
+	return nil
+}
+
+func (r *CommandRepository) GetCommandByID(ctx context.Context, id int64) (*domain.Command, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	val, exists := r.storage[id]
+	r.mu.Unlock()
+	if !exists {
+		return nil, usecase.ErrCommandNotFound
+	}
+
+	return &val, nil
+}
+
+func (r *CommandRepository) GetCommandsByActuatorID(ctx context.Context, actuatorID int64) ([]domain.Command, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Command, 0)
+	r.mu.Lock()
+	for _, v := range r.storage {
+		if v.ActuatorID == actuatorID {
+			res = append(res, v)
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (r *CommandRepository) ExpireCommands(ctx context.Context, now time.Time) ([]int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0)
+	r.mu.Lock()
+	for id, v := range r.storage {
+		if v.IsExpired(now) {
+			v.Status = domain.CommandStatusExpired
+			v.Error = ""
+			v.UpdatedAt = now
+			r.storage[id] = v
+			ids = append(ids, id)
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
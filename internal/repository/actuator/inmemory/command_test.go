@@ -0,0 +1,99 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandRepository_SaveCommand(t *testing.T) {
+	t.Run("err, command is nil", func(t *testing.T) {
+		cr := NewCommandRepository()
+		err := cr.SaveCommand(context.Background(), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("ok, save and get", func(t *testing.T) {
+		cr := NewCommandRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := &domain.Command{ActuatorID: 1, Action: domain.CommandActionOn, Status: domain.CommandStatusPending}
+		assert.NoError(t, cr.SaveCommand(ctx, first))
+		other := &domain.Command{ActuatorID: 2, Action: domain.CommandActionOpen, Status: domain.CommandStatusPending}
+		assert.NoError(t, cr.SaveCommand(ctx, other))
+		second := &domain.Command{ActuatorID: 1, Action: domain.CommandActionOff, Status: domain.CommandStatusPending}
+		assert.NoError(t, cr.SaveCommand(ctx, second))
+
+		got, err := cr.GetCommandByID(ctx, other.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, *other, *got)
+
+		commands, err := cr.GetCommandsByActuatorID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Command{*first, *second}, commands)
+	})
+
+	t.Run("fail, command not found", func(t *testing.T) {
+		cr := NewCommandRepository()
+		_, err := cr.GetCommandByID(context.Background(), 1)
+		assert.ErrorIs(t, err, usecase.ErrCommandNotFound)
+	})
+}
+
+func TestCommandRepository_UpdateCommandStatus(t *testing.T) {
+	cr := NewCommandRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	command := &domain.Command{ActuatorID: 1, Action: domain.CommandActionOn, Status: domain.CommandStatusPending}
+	assert.NoError(t, cr.SaveCommand(ctx, command))
+
+	delivered := *command
+	delivered.Status = domain.CommandStatusDelivered
+	assert.NoError(t, cr.UpdateCommandStatus(ctx, &delivered, domain.CommandStatusPending))
+
+	expired := *command
+	expired.Status = domain.CommandStatusExpired
+	err := cr.UpdateCommandStatus(ctx, &expired, domain.CommandStatusPending)
+	assert.ErrorIs(t, err, usecase.ErrCommandConflict)
+
+	got, err := cr.GetCommandByID(ctx, command.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, delivered, *got)
+
+	err = cr.UpdateCommandStatus(ctx, &domain.Command{ID: 42}, domain.CommandStatusPending)
+	assert.ErrorIs(t, err, usecase.ErrCommandNotFound)
+}
+
+func TestCommandRepository_ExpireCommands(t *testing.T) {
+	cr := NewCommandRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	due := &domain.Command{ActuatorID: 1, Status: domain.CommandStatusDelivered, ExpiresAt: now}
+	notDue := &domain.Command{ActuatorID: 1, Status: domain.CommandStatusPending, ExpiresAt: now.Add(time.Minute)}
+	acked := &domain.Command{ActuatorID: 2, Status: domain.CommandStatusAcked, ExpiresAt: now.Add(-time.Minute)}
+	for _, c := range []*domain.Command{due, notDue, acked} {
+		require.NoError(t, cr.SaveCommand(ctx, c))
+	}
+
+	ids, err := cr.ExpireCommands(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{due.ID}, ids)
+
+	got, err := cr.GetCommandByID(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CommandStatusExpired, got.Status)
+	assert.Equal(t, now, got.UpdatedAt)
+
+	ids, err = cr.ExpireCommands(ctx, now)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
+	"homework/internal/usecase"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ActuatorRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewActuatorRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *ActuatorRepository {
+	o := pgoptions.Apply(options)
+	return &ActuatorRepository{
+		pool:   pool,
+		logger: o.Logger,
+	}
+}
+
+func actuatorScanTargets(actuator *domain.Actuator) []any {
+	return []any{&actuator.ID, &actuator.SerialNumber, &actuator.Type, &actuator.Description, &actuator.RegisteredAt}
+}
+
+const saveActuatorQuery = `
+	INSERT INTO actuators (serial_number, type, description, registered_at) VALUES ($1, $2, $3, $4)
+	RETURNING id`
+
+func (r *ActuatorRepository) SaveActuator(ctx context.Context, actuator *domain.Actuator) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.ActuatorRepository.SaveActuator")
+	defer span.End()
+	defer metrics.ObserveQuery("actuator", "SaveActuator", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.ActuatorRepository.SaveActuator", &err)
+
+	row := r.pool.QueryRow(ctx, saveActuatorQuery, actuator.SerialNumber, actuator.Type, actuator.Description, actuator.RegisteredAt)
+
+	if err := row.Scan(&actuator.ID); err != nil {
+		return fmt.Errorf("unable to save actuator to pg: %w", err)
+	}
+
+	return nil
+}
+
+const getActuatorsQuery = `SELECT id, serial_number, type, description, registered_at FROM actuators ORDER BY id`
+
+func (r *ActuatorRepository) GetActuators(ctx context.Context) (_ []domain.Actuator, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.ActuatorRepository.GetActuators")
+	defer span.End()
+	defer metrics.ObserveQuery("actuator", "GetActuators", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.ActuatorRepository.GetActuators", &err)
+
+	rows, err := r.pool.Query(ctx, getActuatorsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("can't get actuators: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []domain.Actuator
+	for rows.Next() {
+		actuator := domain.Actuator{}
+		if err := rows.Scan(actuatorScanTargets(&actuator)...); err != nil {
+			return nil, fmt.Errorf("can't scan actuators: %w", err)
+		}
+
+		result = append(result, actuator)
+	}
+
+	return result, nil
+}
+
+const getActuatorByIDQuery = `SELECT id, serial_number, type, description, registered_at FROM actuators WHERE id = $1`
+
+func (r *ActuatorRepository) GetActuatorByID(ctx context.Context, id int64) (_ *domain.Actuator, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.ActuatorRepository.GetActuatorByID")
+	defer span.End()
+	defer metrics.ObserveQuery("actuator", "GetActuatorByID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.ActuatorRepository.GetActuatorByID", &err, usecase.ErrActuatorNotFound)
+
+	row := r.pool.QueryRow(ctx, getActuatorByIDQuery, id)
+
+	actuator := &domain.Actuator{}
+	if err := row.Scan(actuatorScanTargets(actuator)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrActuatorNotFound
+		}
+		return nil, fmt.Errorf("unable to find actuator by id: %w", err)
+	}
+
+	return actuator, nil
+}
+
+const getActuatorBySerialNumberQuery = `
+	SELECT id, serial_number, type, description, registered_at FROM actuators WHERE serial_number = $1`
+
+func (r *ActuatorRepository) GetActuatorBySerialNumber(ctx context.Context, sn string) (_ *domain.Actuator, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.ActuatorRepository.GetActuatorBySerialNumber")
+	defer span.End()
+	defer metrics.ObserveQuery("actuator", "GetActuatorBySerialNumber", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.ActuatorRepository.GetActuatorBySerialNumber", &err, usecase.ErrActuatorNotFound)
+
+	row := r.pool.QueryRow(ctx, getActuatorBySerialNumberQuery, sn)
+
+	actuator := &domain.Actuator{}
+	if err := row.Scan(actuatorScanTargets(actuator)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrActuatorNotFound
+		}
+		return nil, fmt.Errorf("unable to find actuator by serial number: %w", err)
+	}
+
+	return actuator, nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ActuatorTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	actuators *ActuatorRepository
+	commands  *CommandRepository
+}
+
+func (suite *ActuatorTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.actuators = NewActuatorRepository(suite.testDbInstance)
+	suite.commands = NewCommandRepository(suite.testDbInstance)
+}
+
+func (suite *ActuatorTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *ActuatorTestSuite) TestActuatorRepository_SaveActuator() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	relay := &domain.Actuator{
+		SerialNumber: "1234567890",
+		Type:         domain.ActuatorTypeRelay,
+		Description:  "Boiler",
+		RegisteredAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+	}
+	assert.NoError(suite.T(), suite.actuators.SaveActuator(ctx, relay))
+
+	got, err := suite.actuators.GetActuatorByID(ctx, relay.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *relay, *got)
+
+	got, err = suite.actuators.GetActuatorBySerialNumber(ctx, relay.SerialNumber)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *relay, *got)
+
+	_, err = suite.actuators.GetActuatorByID(ctx, relay.ID+100)
+	assert.ErrorIs(suite.T(), err, usecase.ErrActuatorNotFound)
+	_, err = suite.actuators.GetActuatorBySerialNumber(ctx, "0000000000")
+	assert.ErrorIs(suite.T(), err, usecase.ErrActuatorNotFound)
+
+	err = suite.actuators.SaveActuator(ctx, &domain.Actuator{SerialNumber: relay.SerialNumber, Type: domain.ActuatorTypeValve})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ActuatorTestSuite) TestCommandRepository_UpdateCommandStatus() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dimmer := &domain.Actuator{SerialNumber: "1234567891", Type: domain.ActuatorTypeDimmer}
+	require.NoError(suite.T(), suite.actuators.SaveActuator(ctx, dimmer))
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	command := &domain.Command{
+		ActuatorID: dimmer.ID,
+		Action:     domain.CommandActionSet,
+		Value:      40,
+		Status:     domain.CommandStatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		ExpiresAt:  now.Add(time.Minute),
+	}
+	require.NoError(suite.T(), suite.commands.SaveCommand(ctx, command))
+
+	failed := *command
+	failed.Status = domain.CommandStatusFailed
+	failed.Error = "overheated"
+	failed.UpdatedAt = now.Add(time.Second)
+	assert.NoError(suite.T(), suite.commands.UpdateCommandStatus(ctx, &failed, domain.CommandStatusPending))
+
+	expired := *command
+	expired.Status = domain.CommandStatusExpired
+	err := suite.commands.UpdateCommandStatus(ctx, &expired, domain.CommandStatusPending)
+	assert.ErrorIs(suite.T(), err, usecase.ErrCommandConflict)
+
+	got, err := suite.commands.GetCommandByID(ctx, command.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), failed, *got)
+
+	second := *command
+	require.NoError(suite.T(), suite.commands.SaveCommand(ctx, &second))
+	commands, err := suite.commands.GetCommandsByActuatorID(ctx, dimmer.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Command{failed, second}, commands)
+
+	err = suite.commands.UpdateCommandStatus(ctx, &domain.Command{ID: second.ID + 100}, domain.CommandStatusPending)
+	assert.ErrorIs(suite.T(), err, usecase.ErrCommandNotFound)
+}
+
+func (suite *ActuatorTestSuite) TestCommandRepository_ExpireCommands() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	relay := &domain.Actuator{SerialNumber: "1234567892", Type: domain.ActuatorTypeRelay}
+	require.NoError(suite.T(), suite.actuators.SaveActuator(ctx, relay))
+
+	// an hour ago, so the commands of the other tests are not due yet
+	past := time.Now().Add(-time.Hour).Truncate(time.Microsecond).In(time.UTC)
+	newCommand := func(status domain.CommandStatus, expiresAt time.Time) *domain.Command {
+		command := &domain.Command{ActuatorID: relay.ID, Action: domain.CommandActionOn, Status: status,
+			CreatedAt: past, UpdatedAt: past, ExpiresAt: expiresAt}
+		require.NoError(suite.T(), suite.commands.SaveCommand(ctx, command))
+		return command
+	}
+	due := newCommand(domain.CommandStatusDelivered, past.Add(-time.Minute))
+	newCommand(domain.CommandStatusPending, past.Add(time.Minute))
+	newCommand(domain.CommandStatusAcked, past.Add(-time.Minute))
+
+	ids, err := suite.commands.ExpireCommands(ctx, past)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []int64{due.ID}, ids)
+
+	got, err := suite.commands.GetCommandByID(ctx, due.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), domain.CommandStatusExpired, got.Status)
+	assert.Equal(suite.T(), past, got.UpdatedAt)
+
+	ids, err = suite.commands.ExpireCommands(ctx, past)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), ids)
+}
+
+func TestActuatorTestSuite(t *testing.T) {
+	suite.Run(t, new(ActuatorTestSuite))
+}
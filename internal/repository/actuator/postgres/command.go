@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
+	"homework/internal/usecase"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CommandRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewCommandRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *CommandRepository {
+	o := pgoptions.Apply(options)
+	return &CommandRepository{
+		pool:   pool,
+		logger: o.Logger,
+	}
+}
+
+func commandScanTargets(command *domain.Command) []any {
+	return []any{&command.ID, &command.ActuatorID, &command.Action, &command.Value, &command.Status, &command.Error,
+		&command.CreatedAt, &command.UpdatedAt, &command.ExpiresAt}
+}
+
+const saveCommandQuery = `
+	INSERT INTO commands (actuator_id, action, value, status, error, created_at, updated_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id`
+
+func (r *CommandRepository) SaveCommand(ctx context.Context, command *domain.Command) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.CommandRepository.SaveCommand")
+	defer span.End()
+	defer metrics.ObserveQuery("command", "SaveCommand", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.CommandRepository.SaveCommand", &err)
+
+	row := r.pool.QueryRow(ctx, saveCommandQuery, command.ActuatorID, command.Action, command.Value, command.Status,
+		command.Error, command.CreatedAt, command.UpdatedAt, command.ExpiresAt)
+
+	if err := row.Scan(&command.ID); err != nil {
+		return fmt.Errorf("unable to save command to pg: %w", err)
+	}
+
+	return nil
+}
+
+// updateCommandStatusQuery - the status check makes the concurrent transitions of a command exclusive
+const updateCommandStatusQuery = `
+	UPDATE commands SET status = $2, error = $3, updated_at = $4
+	WHERE id = $1 AND status = $5`
+
+func (r *CommandRepository) UpdateCommandStatus(ctx context.Context, command *domain.Command, from domain.CommandStatus) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.CommandRepository.UpdateCommandStatus")
+	defer span.End()
+	defer metrics.ObserveQuery("command", "UpdateCommandStatus", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.CommandRepository.UpdateCommandStatus", &err,
+		usecase.ErrCommandConflict, usecase.ErrCommandNotFound)
+
+	tag, err := r.pool.Exec(ctx, updateCommandStatusQuery, command.ID, command.Status, command.Error, command.UpdatedAt, from)
+	if err != nil {
+		return fmt.Errorf("unable to update command status in pg: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetCommandByID(ctx, command.ID); err != nil {
+			return err
+		}
+		return usecase.ErrCommandConflict
+	}
+
+	return nil
+}
+
+const getCommandByIDQuery = `
+	SELECT id, actuator_id, action, value, status, error, created_at, updated_at, expires_at
+	FROM commands WHERE id = $1`
+
+func (r *CommandRepository) GetCommandByID(ctx context.Context, id int64) (_ *domain.Command, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.CommandRepository.GetCommandByID")
+	defer span.End()
+	defer metrics.ObserveQuery("command", "GetCommandByID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.CommandRepository.GetCommandByID", &err, usecase.ErrCommandNotFound)
+
+	row := r.pool.QueryRow(ctx, getCommandByIDQuery, id)
+
+	command := &domain.Command{}
+	if err := row.Scan(commandScanTargets(command)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrCommandNotFound
+		}
+		return nil, fmt.Errorf("unable to find command by id: %w", err)
+	}
+
+	return command, nil
+}
+
+const getCommandsByActuatorIDQuery = `
+	SELECT id, actuator_id, action, value, status, error, created_at, updated_at, expires_at
+	FROM commands WHERE actuator_id = $1 ORDER BY id`
+
+func (r *CommandRepository) GetCommandsByActuatorID(ctx context.Context, actuatorID int64) (_ []domain.Command, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.CommandRepository.GetCommandsByActuatorID")
+	defer span.End()
+	defer metrics.ObserveQuery("command", "GetCommandsByActuatorID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.CommandRepository.GetCommandsByActuatorID", &err)
+
+	rows, err := r.pool.Query(ctx, getCommandsByActuatorIDQuery, actuatorID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get commands: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []domain.Command
+	for rows.Next() {
+		command := domain.Command{}
+		if err := rows.Scan(commandScanTargets(&command)...); err != nil {
+			return nil, fmt.Errorf("can't scan commands: %w", err)
+		}
+
+		result = append(result, command)
+	}
+
+	return result, nil
+}
+
+// expireCommandsQuery - served by the partial index of the not finished commands, so the periodic run doesn't scan
+// the history of the commands
+const expireCommandsQuery = `
+	UPDATE commands SET status = 'expired', error = '', updated_at = $1
+	WHERE status IN ('pending', 'delivered') AND expires_at <= $1
+	RETURNING id`
+
+func (r *CommandRepository) ExpireCommands(ctx context.Context, now time.Time) (_ []int64, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.CommandRepository.ExpireCommands")
+	defer span.End()
+	defer metrics.ObserveQuery("command", "ExpireCommands", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.CommandRepository.ExpireCommands", &err)
+
+	rows, err := r.pool.Query(ctx, expireCommandsQuery, now)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expire commands in pg: %w", err)
+	}
+
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("can't scan expired command id: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,144 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+)
+
+type AutomationRepository struct {
+	rules           map[int64]domain.AutomationRule
+	executions      map[int64][]domain.AutomationExecution // Executions by rule id in the order of saving
+	lastRuleId      int64
+	lastExecutionId int64
+	mu              sync.Mutex
+}
+
+func NewAutomationRepository() *AutomationRepository {
+	return &AutomationRepository{
+		rules:      make(map[int64]domain.AutomationRule),
+		executions: make(map[int64][]domain.AutomationExecution),
+		mu:         sync.Mutex{},
+	}
+}
+
+func (r *AutomationRepository) SaveRule(ctx context.Context, rule *domain.AutomationRule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if rule == nil {
+		return errors.New("got nil rule at SaveRule()")
+	}
+
+	r.mu.Lock()
+	r.lastRuleId++
+	rule.ID = r.lastRuleId
+	r.rules[rule.ID] = *rule
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *AutomationRepository) UpdateRule(ctx context.Context, rule *domain.AutomationRule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if rule == nil {
+		return errors.New("got nil rule at UpdateRule()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.rules[rule.ID]; !exists {
+		return usecase.ErrRuleNotFound
+	}
+	r.rules[rule.ID] = *rule
+
+	return nil
+}
+
+func (r *AutomationRepository) DeleteRule(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.rules[id]; !exists {
+		return usecase.ErrRuleNotFound
+	}
+	delete(r.rules, id)
+	delete(r.executions, id)
+
+	return nil
+}
+
+func (r *AutomationRepository) GetRules(ctx context.Context) ([]domain.AutomationRule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	res := make([]domain.AutomationRule, 0, len(r.rules))
+	for _, v := range r.rules {
+		res = append(res, v)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (r *AutomationRepository) GetRuleByID(ctx context.Context, id int64) (*domain.AutomationRule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	val, exists := r.rules[id]
+	r.mu.Unlock()
+	if !exists {
+		return nil, usecase.ErrRuleNotFound
+	}
+
+	return &val, nil
+}
+
+func (r *AutomationRepository) SaveExecution(ctx context.Context, execution *domain.AutomationExecution) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if execution == nil {
+		return errors.New("got nil execution at SaveExecution()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.rules[execution.RuleID]; !exists {
+		return usecase.ErrRuleNotFound
+	}
+	r.lastExecutionId++
+	execution.ID = r.lastExecutionId
+	r.executions[execution.RuleID] = append(r.executions[execution.RuleID], *execution)
+
+	return nil
+}
+
+func (r *AutomationRepository) GetExecutionsByRuleID(ctx context.Context, ruleID int64, limit int) ([]domain.AutomationExecution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	executions := r.executions[ruleID]
+	res := make([]domain.AutomationExecution, 0, min(limit, len(executions)))
+	for i := len(executions) - 1; i >= 0 && len(res) < limit; i-- {
+		res = append(res, executions[i])
+	}
+	r.mu.Unlock()
+
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomationRepository_Rules(t *testing.T) {
+	t.Run("err, rule is nil", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		assert.Error(t, ar.SaveRule(context.Background(), nil))
+		assert.Error(t, ar.UpdateRule(context.Background(), nil))
+	})
+
+	t.Run("ok, save, update and delete", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := &domain.AutomationRule{Name: "first", Trigger: domain.Trigger{Kind: domain.TriggerSchedule, Every: time.Hour}}
+		require.NoError(t, ar.SaveRule(ctx, first))
+		second := &domain.AutomationRule{Name: "second", Trigger: domain.Trigger{Kind: domain.TriggerOffline, SensorID: 1, After: time.Hour}}
+		require.NoError(t, ar.SaveRule(ctx, second))
+
+		second.Enabled = true
+		require.NoError(t, ar.UpdateRule(ctx, second))
+		got, err := ar.GetRuleByID(ctx, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, *second, *got)
+
+		require.NoError(t, ar.DeleteRule(ctx, first.ID))
+		rules, err := ar.GetRules(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.AutomationRule{*second}, rules)
+	})
+
+	t.Run("fail, rule not found", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx := context.Background()
+
+		_, err := ar.GetRuleByID(ctx, 1)
+		assert.ErrorIs(t, err, usecase.ErrRuleNotFound)
+		assert.ErrorIs(t, ar.UpdateRule(ctx, &domain.AutomationRule{ID: 1}), usecase.ErrRuleNotFound)
+		assert.ErrorIs(t, ar.DeleteRule(ctx, 1), usecase.ErrRuleNotFound)
+		assert.ErrorIs(t, ar.SaveExecution(ctx, &domain.AutomationExecution{RuleID: 1}), usecase.ErrRuleNotFound)
+	})
+}
+
+func TestAutomationRepository_Executions(t *testing.T) {
+	ar := NewAutomationRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rule := &domain.AutomationRule{Name: "rule"}
+	require.NoError(t, ar.SaveRule(ctx, rule))
+
+	var saved []domain.AutomationExecution
+	for _, status := range []domain.ExecutionStatus{domain.ExecutionSucceeded, domain.ExecutionSkipped, domain.ExecutionFailed} {
+		execution := &domain.AutomationExecution{RuleID: rule.ID, Status: status}
+		require.NoError(t, ar.SaveExecution(ctx, execution))
+		saved = append(saved, *execution)
+	}
+
+	executions, err := ar.GetExecutionsByRuleID(ctx, rule.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AutomationExecution{saved[2], saved[1]}, executions)
+
+	require.NoError(t, ar.DeleteRule(ctx, rule.ID))
+	executions, err = ar.GetExecutionsByRuleID(ctx, rule.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, executions)
+}
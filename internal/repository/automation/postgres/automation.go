@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
+	"homework/internal/usecase"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AutomationRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewAutomationRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *AutomationRepository {
+	o := pgoptions.Apply(options)
+	return &AutomationRepository{
+		pool:   pool,
+		logger: o.Logger,
+	}
+}
+
+func ruleScanTargets(rule *domain.AutomationRule) []any {
+	return []any{&rule.ID, &rule.Name, &rule.Enabled, &rule.Trigger, &rule.Conditions, &rule.Actions, &rule.Cooldown, &rule.CreatedAt}
+}
+
+// conditionsOrNull - stores the rules without conditions as NULL
+func conditionsOrNull(rule *domain.AutomationRule) any {
+	if len(rule.Conditions) == 0 {
+		return nil
+	}
+	return rule.Conditions
+}
+
+const saveRuleQuery = `
+	INSERT INTO automation_rules (name, enabled, trigger, conditions, actions, cooldown, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id`
+
+func (r *AutomationRepository) SaveRule(ctx context.Context, rule *domain.AutomationRule) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.AutomationRepository.SaveRule")
+	defer span.End()
+	defer metrics.ObserveQuery("automation", "SaveRule", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.AutomationRepository.SaveRule", &err)
+
+	row := r.pool.QueryRow(ctx, saveRuleQuery, rule.Name, rule.Enabled, rule.Trigger, conditionsOrNull(rule),
+		rule.Actions, rule.Cooldown, rule.CreatedAt)
+
+	if err := row.Scan(&rule.ID); err != nil {
+		return fmt.Errorf("unable to save rule to pg: %w", err)
+	}
+
+	return nil
+}
+
+const updateRuleQuery = `
+	UPDATE automation_rules
+	SET name = $2, enabled = $3, trigger = $4, conditions = $5, actions = $6, cooldown = $7, created_at = $8
+	WHERE id = $1`
+
+func (r *AutomationRepository) UpdateRule(ctx context.Context, rule *domain.AutomationRule) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.AutomationRepository.UpdateRule")
+	defer span.End()
+	defer metrics.ObserveQuery("automation", "UpdateRule", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.AutomationRepository.UpdateRule", &err, usecase.ErrRuleNotFound)
+
+	tag, err := r.pool.Exec(ctx, updateRuleQuery, rule.ID, rule.Name, rule.Enabled, rule.Trigger, conditionsOrNull(rule),
+		rule.Actions, rule.Cooldown, rule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("unable to update rule in pg: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrRuleNotFound
+	}
+
+	return nil
+}
+
+// deleteRuleQuery - the executions of the rule are removed by the foreign key cascade
+const deleteRuleQuery = `DELETE FROM automation_rules WHERE id = $1`
+
+func (r *AutomationRepository) DeleteRule(ctx context.Context, id int64) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.AutomationRepository.DeleteRule")
+	defer span.End()
+	defer metrics.ObserveQuery("automation", "DeleteRule", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.AutomationRepository.DeleteRule", &err, usecase.ErrRuleNotFound)
+
+	tag, err := r.pool.Exec(ctx, deleteRuleQuery, id)
+	if err != nil {
+		return fmt.Errorf("unable to delete rule from pg: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrRuleNotFound
+	}
+
+	return nil
+}
+
+const getRulesQuery = `
+	SELECT id, name, enabled, trigger, conditions, actions, cooldown, created_at
+	FROM automation_rules ORDER BY id`
+
+func (r *AutomationRepository) GetRules(ctx context.Context) (_ []domain.AutomationRule, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.AutomationRepository.GetRules")
+	defer span.End()
+	defer metrics.ObserveQuery("automation", "GetRules", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.AutomationRepository.GetRules", &err)
+
+	rows, err := r.pool.Query(ctx, getRulesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("can't get rules: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []domain.AutomationRule
+	for rows.Next() {
+		rule := domain.AutomationRule{}
+		if err := rows.Scan(ruleScanTargets(&rule)...); err != nil {
+			return nil, fmt.Errorf("can't scan rules: %w", err)
+		}
+
+		result = append(result, rule)
+	}
+
+	return result, nil
+}
+
+const getRuleByIDQuery = `
+	SELECT id, name, enabled, trigger, conditions, actions, cooldown, created_at
+	FROM automation_rules WHERE id = $1`
+
+func (r *AutomationRepository) GetRuleByID(ctx context.Context, id int64) (_ *domain.AutomationRule, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.AutomationRepository.GetRuleByID")
+	defer span.End()
+	defer metrics.ObserveQuery("automation", "GetRuleByID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.AutomationRepository.GetRuleByID", &err, usecase.ErrRuleNotFound)
+
+	row := r.pool.QueryRow(ctx, getRuleByIDQuery, id)
+
+	rule := &domain.AutomationRule{}
+	if err := row.Scan(ruleScanTargets(rule)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrRuleNotFound
+		}
+		return nil, fmt.Errorf("unable to find rule by id: %w", err)
+	}
+
+	return rule, nil
+}
+
+// saveExecutionQuery - inserts nothing if the rule has been deleted meanwhile
+const saveExecutionQuery = `
+	INSERT INTO automation_executions (rule_id, trigger, status, reason, depth, started_at)
+	SELECT id, $2, $3, $4, $5, $6 FROM automation_rules WHERE id = $1
+	RETURNING id`
+
+func (r *AutomationRepository) SaveExecution(ctx context.Context, execution *domain.AutomationExecution) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.AutomationRepository.SaveExecution")
+	defer span.End()
+	defer metrics.ObserveQuery("automation", "SaveExecution", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.AutomationRepository.SaveExecution", &err, usecase.ErrRuleNotFound)
+
+	row := r.pool.QueryRow(ctx, saveExecutionQuery, execution.RuleID, execution.Trigger, execution.Status,
+		execution.Reason, execution.Depth, execution.StartedAt)
+
+	if err := row.Scan(&execution.ID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return usecase.ErrRuleNotFound
+		}
+		return fmt.Errorf("unable to save execution to pg: %w", err)
+	}
+
+	return nil
+}
+
+const getExecutionsByRuleIDQuery = `
+	SELECT id, rule_id, trigger, status, reason, depth, started_at
+	FROM automation_executions WHERE rule_id = $1 ORDER BY id DESC LIMIT $2`
+
+func (r *AutomationRepository) GetExecutionsByRuleID(ctx context.Context, ruleID int64, limit int) (_ []domain.AutomationExecution, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.AutomationRepository.GetExecutionsByRuleID")
+	defer span.End()
+	defer metrics.ObserveQuery("automation", "GetExecutionsByRuleID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.AutomationRepository.GetExecutionsByRuleID", &err)
+
+	rows, err := r.pool.Query(ctx, getExecutionsByRuleIDQuery, ruleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't get executions: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []domain.AutomationExecution
+	for rows.Next() {
+		execution := domain.AutomationExecution{}
+		if err := rows.Scan(&execution.ID, &execution.RuleID, &execution.Trigger, &execution.Status, &execution.Reason,
+			&execution.Depth, &execution.StartedAt); err != nil {
+			return nil, fmt.Errorf("can't scan executions: %w", err)
+		}
+
+		result = append(result, execution)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AutomationTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	automations *AutomationRepository
+}
+
+func (suite *AutomationTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.automations = NewAutomationRepository(suite.testDbInstance)
+}
+
+func (suite *AutomationTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *AutomationTestSuite) TestAutomationRepository_Rules() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rule := &domain.AutomationRule{
+		Name:    "lights on when dark",
+		Enabled: true,
+		Trigger: domain.Trigger{Kind: domain.TriggerEvent, SensorID: 1, Channel: "lux", Operator: domain.CompareLt, Value: 10},
+		Conditions: []domain.Condition{
+			{Kind: domain.ConditionTimeWindow, From: "18:00", To: "06:00"},
+		},
+		Actions:   []domain.Action{{Kind: domain.ActionCommand, ActuatorID: 1, Command: domain.CommandActionOn}},
+		Cooldown:  time.Minute,
+		CreatedAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+	}
+	require.NoError(suite.T(), suite.automations.SaveRule(ctx, rule))
+
+	schedule := &domain.AutomationRule{
+		Name:      "hourly report",
+		Trigger:   domain.Trigger{Kind: domain.TriggerSchedule, Every: time.Hour},
+		Actions:   []domain.Action{{Kind: domain.ActionWebhook, URL: "http://example.com/hook"}},
+		CreatedAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+	}
+	require.NoError(suite.T(), suite.automations.SaveRule(ctx, schedule))
+
+	got, err := suite.automations.GetRuleByID(ctx, rule.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *rule, *got)
+
+	schedule.Enabled = true
+	require.NoError(suite.T(), suite.automations.UpdateRule(ctx, schedule))
+	rules, err := suite.automations.GetRules(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []domain.AutomationRule{*rule, *schedule}, rules)
+
+	require.NoError(suite.T(), suite.automations.DeleteRule(ctx, schedule.ID))
+	_, err = suite.automations.GetRuleByID(ctx, schedule.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrRuleNotFound)
+	assert.ErrorIs(suite.T(), suite.automations.UpdateRule(ctx, schedule), usecase.ErrRuleNotFound)
+	assert.ErrorIs(suite.T(), suite.automations.DeleteRule(ctx, schedule.ID), usecase.ErrRuleNotFound)
+}
+
+func (suite *AutomationTestSuite) TestAutomationRepository_Executions() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rule := &domain.AutomationRule{
+		Name:      "sensor is down",
+		Trigger:   domain.Trigger{Kind: domain.TriggerOffline, SensorID: 1, After: time.Hour},
+		Actions:   []domain.Action{{Kind: domain.ActionAlert, Message: "sensor 1 is offline"}},
+		CreatedAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+	}
+	require.NoError(suite.T(), suite.automations.SaveRule(ctx, rule))
+
+	var saved []domain.AutomationExecution
+	for i, status := range []domain.ExecutionStatus{domain.ExecutionSucceeded, domain.ExecutionSkipped, domain.ExecutionFailed} {
+		execution := &domain.AutomationExecution{
+			RuleID:    rule.ID,
+			Trigger:   domain.TriggerOffline,
+			Status:    status,
+			Reason:    string(status),
+			Depth:     i,
+			StartedAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+		}
+		require.NoError(suite.T(), suite.automations.SaveExecution(ctx, execution))
+		saved = append(saved, *execution)
+	}
+
+	executions, err := suite.automations.GetExecutionsByRuleID(ctx, rule.ID, 2)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []domain.AutomationExecution{saved[2], saved[1]}, executions)
+
+	require.NoError(suite.T(), suite.automations.DeleteRule(ctx, rule.ID))
+	executions, err = suite.automations.GetExecutionsByRuleID(ctx, rule.ID, 10)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), executions)
+
+	err = suite.automations.SaveExecution(ctx, &domain.AutomationExecution{RuleID: rule.ID, Status: domain.ExecutionSucceeded})
+	assert.ErrorIs(suite.T(), err, usecase.ErrRuleNotFound)
+}
+
+func TestAutomationTestSuite(t *testing.T) {
+	suite.Run(t, new(AutomationTestSuite))
+}
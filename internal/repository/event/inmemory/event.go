@@ -3,6 +3,7 @@ package inmemory
 import (
 	"context"
 	"errors"
+	"fmt"
 	"homework/internal/domain"
 	"homework/internal/usecase"
 	"sync"
@@ -11,22 +12,46 @@ import (
 	"github.com/hashicorp/go-set/v2"
 )
 
+// storedEvent - the event with the number of its saving, which orders the events having the same timestamp,
+// so the repository keeps every saved event like the postgres one does
+type storedEvent struct {
+	*domain.Event
+	seq int64
+}
+
 type EventRepository struct {
-	storage map[int64]*set.TreeSet[*domain.Event]
+	storage map[int64]*set.TreeSet[storedEvent]
+	byID    map[int64]storedEvent          // Stored events by id, which is their seq
+	keys    map[int64]map[string]time.Time // Timestamps of the events by sensor id and idempotency key
+	lastSeq int64
 	mu      sync.Mutex
 }
 
-func compareEvent(lhs, rhs *domain.Event) int {
-	cmp1 := lhs.Timestamp.Compare(rhs.Timestamp)
-	if cmp1 == 0 {
-		return set.Compare(lhs.Payload, rhs.Payload)
+func compareEvent(lhs, rhs storedEvent) int {
+	if cmp := lhs.Timestamp.Compare(rhs.Timestamp); cmp != 0 {
+		return cmp
+	}
+	return set.Compare(lhs.seq, rhs.seq)
+}
+
+// probe - the bound preceding all the events having the timestamp, as the saved ones have positive seq
+func probe(timestamp time.Time) storedEvent {
+	return storedEvent{Event: &domain.Event{Timestamp: timestamp}}
+}
+
+func events(stored []storedEvent) []*domain.Event {
+	res := make([]*domain.Event, 0, len(stored))
+	for _, s := range stored {
+		res = append(res, s.Event)
 	}
-	return cmp1
+	return res
 }
 
 func NewEventRepository() *EventRepository {
 	return &EventRepository{
-		storage: make(map[int64]*set.TreeSet[*domain.Event]),
+		storage: make(map[int64]*set.TreeSet[storedEvent]),
+		byID:    make(map[int64]storedEvent),
+		keys:    make(map[int64]map[string]time.Time),
 		mu:      sync.Mutex{},
 	}
 }
@@ -40,13 +65,28 @@ func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) er
 	}
 
 	r.mu.Lock()
-	events, exists := r.storage[event.SensorID]
+	defer r.mu.Unlock()
+	if event.IdempotencyKey != "" {
+		keys, exists := r.keys[event.SensorID]
+		if !exists {
+			keys = make(map[string]time.Time)
+			r.keys[event.SensorID] = keys
+		}
+		if _, duplicate := keys[event.IdempotencyKey]; duplicate {
+			return usecase.ErrDuplicateEvent
+		}
+		keys[event.IdempotencyKey] = event.Timestamp
+	}
+
+	stored, exists := r.storage[event.SensorID]
 	if !exists {
-		events = set.NewTreeSet[*domain.Event](compareEvent)
-		r.storage[event.SensorID] = events
+		stored = set.NewTreeSet[storedEvent](compareEvent)
+		r.storage[event.SensorID] = stored
 	}
-	events.Insert(event)
-	r.mu.Unlock()
+	r.lastSeq++
+	event.ID = r.lastSeq
+	r.byID[event.ID] = storedEvent{Event: event, seq: r.lastSeq}
+	stored.Insert(r.byID[event.ID])
 
 	return nil
 }
@@ -63,7 +103,7 @@ func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64)
 		return nil, usecase.ErrEventNotFound
 	}
 
-	return val.Max(), nil
+	return val.Max().Event, nil
 }
 
 func (r *EventRepository) GetEventsHistoryBySensorID(ctx context.Context, id int64, startTime, endTime time.Time) ([]*domain.Event, error) {
@@ -79,5 +119,70 @@ func (r *EventRepository) GetEventsHistoryBySensorID(ctx context.Context, id int
 		return nil, nil // Empty result if no events ever existed for this sensor, no error
 	}
 
-	return val.AboveEqual(&domain.Event{Timestamp: startTime}).Below(&domain.Event{Timestamp: endTime.Add(time.Nanosecond)}).Slice(), nil
+	return events(val.AboveEqual(probe(startTime)).Below(probe(endTime.Add(time.Nanosecond))).Slice()), nil
+}
+
+func (r *EventRepository) GetLastEventsBySensorID(ctx context.Context, id int64, limit int) ([]*domain.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	val, exists := r.storage[id]
+	if !exists || val.Empty() {
+		return nil, nil
+	}
+
+	stored := val.Slice()
+	if len(stored) > limit {
+		stored = stored[len(stored)-limit:]
+	}
+	return events(stored), nil
+}
+
+// UpdateEvents - replaces the stored events having the same ids, nothing is replaced if any of the events
+// is not stored
+func (r *EventRepository) UpdateEvents(ctx context.Context, events []*domain.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		if s, exists := r.byID[event.ID]; !exists || s.SensorID != event.SensorID {
+			return fmt.Errorf("%w: event %v of sensor %v", usecase.ErrEventNotFound, event.ID, event.SensorID)
+		}
+	}
+	// The replacement keeps the seq, so it takes the place of the replaced event
+	for _, event := range events {
+		stored := r.storage[event.SensorID]
+		stored.Remove(r.byID[event.ID])
+		r.byID[event.ID] = storedEvent{Event: event, seq: event.ID}
+		stored.Insert(r.byID[event.ID])
+	}
+	return nil
+}
+
+func (r *EventRepository) ReleaseIdempotencyKeys(ctx context.Context, before time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, keys := range r.keys {
+		for key, timestamp := range keys {
+			if timestamp.Before(before) {
+				delete(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			delete(r.keys, id)
+		}
+	}
+	return nil
 }
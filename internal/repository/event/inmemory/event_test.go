@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEventRepository_SaveEvent(t *testing.T) {
@@ -58,6 +59,26 @@ func TestEventRepository_SaveEvent(t *testing.T) {
 		assert.Equal(t, event.Payload, actualEvent.Payload)
 	})
 
+	t.Run("ok, multi-channel event", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		event := &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "12345",
+			Payload:            2315,
+			Values:             map[string]int64{"temperature": 2315, "humidity": 4500},
+		}
+
+		err := er.SaveEvent(ctx, event)
+		assert.NoError(t, err)
+
+		actualEvent, err := er.GetLastEventBySensorID(ctx, event.SensorID)
+		assert.NoError(t, err)
+		assert.Equal(t, event.Values, actualEvent.Values)
+	})
+
 	t.Run("ok, collision test", func(t *testing.T) {
 		er := NewEventRepository()
 		ctx, cancel := context.WithCancel(context.Background())
@@ -232,3 +253,129 @@ func TestEventRepository_GetEventsHistoryBySensorID(t *testing.T) {
 		}
 	})
 }
+
+func TestEventRepository_GetLastEventsBySensorID(t *testing.T) {
+	t.Run("ok, empty history", func(t *testing.T) {
+		er := NewEventRepository()
+		events, err := er.GetLastEventsBySensorID(context.Background(), 1, 3)
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("ok, last events in chronological order", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx := context.Background()
+		startTime := time.Now()
+
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, er.SaveEvent(ctx, &domain.Event{
+				Timestamp: startTime.Add(time.Duration(4-i) * time.Second),
+				SensorID:  1,
+				Payload:   int64(4 - i),
+			}))
+		}
+
+		events, err := er.GetLastEventsBySensorID(ctx, 1, 3)
+		assert.NoError(t, err)
+		assert.Len(t, events, 3)
+		for i, event := range events {
+			assert.Equal(t, int64(i+2), event.Payload)
+		}
+	})
+}
+
+func TestEventRepository_UpdateEvents(t *testing.T) {
+	er := NewEventRepository()
+	ctx := context.Background()
+	startTime := time.Now()
+
+	var saved []*domain.Event
+	for i := 0; i < 3; i++ {
+		event := &domain.Event{
+			Timestamp:  startTime.Add(time.Duration(i) * time.Second),
+			SensorID:   1,
+			Payload:    int64(i),
+			RawPayload: int64(i),
+		}
+		assert.NoError(t, er.SaveEvent(ctx, event))
+		saved = append(saved, event)
+	}
+	twin := &domain.Event{Timestamp: saved[2].Timestamp, SensorID: 1, Payload: 2, RawPayload: 2}
+	assert.NoError(t, er.SaveEvent(ctx, twin))
+	assert.NotEqual(t, saved[2].ID, twin.ID)
+
+	t.Run("ok, updated by id", func(t *testing.T) {
+		assert.NoError(t, er.UpdateEvents(ctx, []*domain.Event{
+			{ID: saved[2].ID, Timestamp: saved[2].Timestamp, SensorID: 1, Payload: 100, RawPayload: 2},
+		}))
+
+		hist, err := er.GetEventsHistoryBySensorID(ctx, 1, startTime, startTime.Add(2*time.Second))
+		assert.NoError(t, err)
+		require.Len(t, hist, 4)
+		assert.Equal(t, int64(100), hist[2].Payload)
+		assert.Equal(t, int64(2), hist[2].RawPayload)
+		assert.Equal(t, int64(2), hist[3].Payload, "the event with the same timestamp and raw payload is kept")
+	})
+
+	t.Run("err, unknown event", func(t *testing.T) {
+		err := er.UpdateEvents(ctx, []*domain.Event{
+			{ID: saved[0].ID, Timestamp: saved[0].Timestamp, SensorID: 1, Payload: 50},
+			{ID: 42, Timestamp: saved[1].Timestamp, SensorID: 1, Payload: 51},
+		})
+		assert.ErrorIs(t, err, usecase.ErrEventNotFound)
+
+		err = er.UpdateEvents(ctx, []*domain.Event{{ID: saved[0].ID, Timestamp: saved[0].Timestamp, SensorID: 2}})
+		assert.ErrorIs(t, err, usecase.ErrEventNotFound, "the event of another sensor")
+
+		first, err := er.GetEventsHistoryBySensorID(ctx, 1, startTime, startTime)
+		assert.NoError(t, err)
+		require.Len(t, first, 1)
+		assert.Equal(t, int64(0), first[0].Payload, "nothing is updated")
+	})
+}
+
+func TestEventRepository_Duplicates(t *testing.T) {
+	t.Run("ok, events without key are all kept", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx := context.Background()
+		now := time.Now()
+
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now, SensorID: 1, Payload: 5}))
+		}
+
+		hist, err := er.GetEventsHistoryBySensorID(ctx, 1, now, now)
+		assert.NoError(t, err)
+		assert.Len(t, hist, 2)
+	})
+
+	t.Run("fail, key is already accepted", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx := context.Background()
+		now := time.Now()
+
+		assert.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now, SensorID: 1, Payload: 5, IdempotencyKey: "a"}))
+		err := er.SaveEvent(ctx, &domain.Event{Timestamp: now.Add(time.Second), SensorID: 1, Payload: 6, IdempotencyKey: "a"})
+		assert.ErrorIs(t, err, usecase.ErrDuplicateEvent)
+		// The keys are scoped by sensor
+		assert.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now, SensorID: 2, Payload: 5, IdempotencyKey: "a"}))
+
+		last, err := er.GetLastEventBySensorID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), last.Payload)
+	})
+
+	t.Run("ok, released key is accepted again", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx := context.Background()
+		now := time.Now()
+
+		assert.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now, SensorID: 1, IdempotencyKey: "old"}))
+		assert.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now.Add(time.Hour), SensorID: 1, IdempotencyKey: "new"}))
+		assert.NoError(t, er.ReleaseIdempotencyKeys(ctx, now.Add(time.Minute)))
+
+		assert.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now.Add(2 * time.Hour), SensorID: 1, IdempotencyKey: "old"}))
+		err := er.SaveEvent(ctx, &domain.Event{Timestamp: now.Add(2 * time.Hour), SensorID: 1, IdempotencyKey: "new"})
+		assert.ErrorIs(t, err, usecase.ErrDuplicateEvent)
+	})
+}
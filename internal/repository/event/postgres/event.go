@@ -5,6 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
+	"homework/internal/usecase"
+	"log/slog"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -14,32 +20,70 @@ import (
 var ErrEventNotFound = errors.New("event not found")
 
 type EventRepository struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	logger *slog.Logger
 }
 
-func NewEventRepository(pool *pgxpool.Pool) *EventRepository {
+func NewEventRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *EventRepository {
+	o := pgoptions.Apply(options)
 	return &EventRepository{
-		pool,
+		pool:   pool,
+		logger: o.Logger,
 	}
 }
 
-const saveEventQuery = `INSERT INTO events (timestamp, sensor_serial_number, sensor_id, payload) VALUES ($1, $2, $3, $4)`
+// saveEventQuery - inserts nothing if the sensor has an event with the same not released idempotency key
+const saveEventQuery = `
+	INSERT INTO events (timestamp, sensor_serial_number, sensor_id, payload, channel_values, raw_payload, raw_channel_values,
+	                    idempotency_key)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (sensor_id, idempotency_key) WHERE idempotency_key <> '' DO NOTHING
+	RETURNING id`
+
+// channelValues - stores the values of the single-value sensor events as NULL
+func channelValues(values map[string]int64) any {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func eventScanTargets(event *domain.Event) []any {
+	return []any{&event.ID, &event.Timestamp, &event.SensorSerialNumber, &event.SensorID, &event.Payload, &event.Values,
+		&event.RawPayload, &event.RawValues, &event.IdempotencyKey}
+}
+
+func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.EventRepository.SaveEvent")
+	defer span.End()
+	defer metrics.ObserveQuery("event", "SaveEvent", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.EventRepository.SaveEvent", &err, usecase.ErrDuplicateEvent)
 
-func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) error {
-	if _, err := r.pool.Exec(ctx, saveEventQuery, event.Timestamp, event.SensorSerialNumber, event.SensorID, event.Payload); err != nil {
+	err = r.pool.QueryRow(ctx, saveEventQuery, event.Timestamp, event.SensorSerialNumber, event.SensorID, event.Payload,
+		channelValues(event.Values), event.RawPayload, channelValues(event.RawValues), event.IdempotencyKey).Scan(&event.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return usecase.ErrDuplicateEvent
+	}
+	if err != nil {
 		return fmt.Errorf("unable to save event to pg: %w", err)
 	}
 	return nil
 }
 
 const getLastEventBySensorIDQuery = `
-	SELECT timestamp, sensor_serial_number, sensor_id, payload FROM events WHERE sensor_id = $1 ORDER BY timestamp DESC`
+	SELECT id, timestamp, sensor_serial_number, sensor_id, payload, channel_values, raw_payload, raw_channel_values, idempotency_key
+	FROM events WHERE sensor_id = $1 ORDER BY timestamp DESC`
+
+func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64) (_ *domain.Event, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.EventRepository.GetLastEventBySensorID")
+	defer span.End()
+	defer metrics.ObserveQuery("event", "GetLastEventBySensorID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.EventRepository.GetLastEventBySensorID", &err, ErrEventNotFound)
 
-func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error) {
 	row := r.pool.QueryRow(ctx, getLastEventBySensorIDQuery, id)
 
 	event := &domain.Event{}
-	if err := row.Scan(&event.Timestamp, &event.SensorSerialNumber, &event.SensorID, &event.Payload); err != nil {
+	if err := row.Scan(eventScanTargets(event)...); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrEventNotFound
 		}
@@ -51,13 +95,18 @@ func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64)
 
 const getEventsHistoryBySensorIDQuery = `
 	SELECT 
-	    timestamp, sensor_serial_number, sensor_id, payload 
+	    id, timestamp, sensor_serial_number, sensor_id, payload, channel_values, raw_payload, raw_channel_values, idempotency_key
 	FROM events 
 	WHERE TRUE
 	 AND sensor_id = $1
 	 AND (timestamp BETWEEN $2 AND $3)`
 
-func (r *EventRepository) GetEventsHistoryBySensorID(ctx context.Context, id int64, startTime, endTime time.Time) ([]*domain.Event, error) {
+func (r *EventRepository) GetEventsHistoryBySensorID(ctx context.Context, id int64, startTime, endTime time.Time) (_ []*domain.Event, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.EventRepository.GetEventsHistoryBySensorID")
+	defer span.End()
+	defer metrics.ObserveQuery("event", "GetEventsHistoryBySensorID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.EventRepository.GetEventsHistoryBySensorID", &err)
+
 	rows, err := r.pool.Query(ctx, getEventsHistoryBySensorIDQuery, id, startTime, endTime)
 	if err != nil {
 		return nil, fmt.Errorf("can't get events: %w", err)
@@ -68,7 +117,7 @@ func (r *EventRepository) GetEventsHistoryBySensorID(ctx context.Context, id int
 	var result []*domain.Event
 	for rows.Next() {
 		event := &domain.Event{}
-		if err := rows.Scan(&event.Timestamp, &event.SensorSerialNumber, &event.SensorID, &event.Payload); err != nil {
+		if err := rows.Scan(eventScanTargets(event)...); err != nil {
 			return nil, fmt.Errorf("can't scan sensors: %w", err)
 		}
 
@@ -77,3 +126,96 @@ func (r *EventRepository) GetEventsHistoryBySensorID(ctx context.Context, id int
 
 	return result, nil
 }
+
+const getLastEventsBySensorIDQuery = `
+	SELECT * FROM (
+	    SELECT id, timestamp, sensor_serial_number, sensor_id, payload, channel_values, raw_payload, raw_channel_values,
+	           idempotency_key
+	    FROM events WHERE sensor_id = $1 ORDER BY timestamp DESC LIMIT $2
+	) AS last ORDER BY timestamp`
+
+func (r *EventRepository) GetLastEventsBySensorID(ctx context.Context, id int64, limit int) (_ []*domain.Event, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.EventRepository.GetLastEventsBySensorID")
+	defer span.End()
+	defer metrics.ObserveQuery("event", "GetLastEventsBySensorID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.EventRepository.GetLastEventsBySensorID", &err)
+
+	rows, err := r.pool.Query(ctx, getLastEventsBySensorIDQuery, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't get last events: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []*domain.Event
+	for rows.Next() {
+		event := &domain.Event{}
+		if err := rows.Scan(eventScanTargets(event)...); err != nil {
+			return nil, fmt.Errorf("can't scan events: %w", err)
+		}
+
+		result = append(result, event)
+	}
+
+	return result, rows.Err()
+}
+
+const updateEventQuery = `
+	UPDATE events SET payload = $1, channel_values = $2
+	WHERE id = $3`
+
+// UpdateEvents - updates the events by their ids in a single transaction, nothing is updated
+// if any of the events is not stored
+func (r *EventRepository) UpdateEvents(ctx context.Context, events []*domain.Event) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.EventRepository.UpdateEvents")
+	defer span.End()
+	defer metrics.ObserveQuery("event", "UpdateEvents", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.EventRepository.UpdateEvents", &err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // Is a no-op after commit
+
+	batch := &pgx.Batch{}
+	for _, event := range events {
+		batch.Queue(updateEventQuery, event.Payload, channelValues(event.Values), event.ID)
+	}
+	results := tx.SendBatch(ctx, batch)
+	for _, event := range events {
+		tag, err := results.Exec()
+		if err != nil {
+			_ = results.Close()
+			return fmt.Errorf("unable to update event %v in pg: %w", event.ID, err)
+		}
+		if tag.RowsAffected() != 1 {
+			_ = results.Close()
+			return fmt.Errorf("%w: event %v updated %d rows", usecase.ErrEventNotFound, event.ID, tag.RowsAffected())
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("unable to update events in pg: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit events update: %w", err)
+	}
+	return nil
+}
+
+// releaseIdempotencyKeysQuery - the released events stay, only their keys are cleared
+const releaseIdempotencyKeysQuery = `
+	UPDATE events SET idempotency_key = ''
+	WHERE idempotency_key <> '' AND timestamp < $1`
+
+func (r *EventRepository) ReleaseIdempotencyKeys(ctx context.Context, before time.Time) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.EventRepository.ReleaseIdempotencyKeys")
+	defer span.End()
+	defer metrics.ObserveQuery("event", "ReleaseIdempotencyKeys", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.EventRepository.ReleaseIdempotencyKeys", &err)
+
+	if _, err := r.pool.Exec(ctx, releaseIdempotencyKeysQuery, before); err != nil {
+		return fmt.Errorf("unable to release idempotency keys in pg: %w", err)
+	}
+	return nil
+}
@@ -3,12 +3,14 @@ package postgres
 import (
 	"context"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -73,6 +75,68 @@ func (suite *EventTestSuite) TestEventRepository_GetLastEventBySensorID() {
 	assert.Equal(suite.T(), secondEvent, *event)
 }
 
+func (suite *EventTestSuite) TestEventRepository_Duplicates() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	event := domain.Event{Timestamp: now, SensorSerialNumber: "1111111111", SensorID: 3, Payload: 1, IdempotencyKey: "a"}
+	assert.NoError(suite.T(), suite.repo.SaveEvent(ctx, &event))
+
+	duplicate := event
+	duplicate.Timestamp = now.Add(time.Second)
+	assert.ErrorIs(suite.T(), suite.repo.SaveEvent(ctx, &duplicate), usecase.ErrDuplicateEvent)
+
+	// Events without key are all kept
+	plain := domain.Event{Timestamp: now, SensorSerialNumber: "1111111111", SensorID: 3, Payload: 1}
+	assert.NoError(suite.T(), suite.repo.SaveEvent(ctx, &plain))
+	assert.NoError(suite.T(), suite.repo.SaveEvent(ctx, &plain))
+
+	assert.NoError(suite.T(), suite.repo.ReleaseIdempotencyKeys(ctx, now.Add(time.Millisecond)))
+	assert.NoError(suite.T(), suite.repo.SaveEvent(ctx, &duplicate))
+
+	history, err := suite.repo.GetEventsHistoryBySensorID(ctx, 3, now, now.Add(time.Second))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), history, 4)
+}
+
+func (suite *EventTestSuite) TestEventRepository_UpdateEvents() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	event := domain.Event{Timestamp: now, SensorSerialNumber: "2222222222", SensorID: 4, Payload: 1, RawPayload: 1}
+	twin := event
+	require.NoError(suite.T(), suite.repo.SaveEvent(ctx, &event))
+	require.NoError(suite.T(), suite.repo.SaveEvent(ctx, &twin))
+	assert.NotEqual(suite.T(), event.ID, twin.ID)
+
+	updated := event
+	updated.Payload = 100
+	updated.Values = map[string]int64{"temperature": 100}
+	require.NoError(suite.T(), suite.repo.UpdateEvents(ctx, []*domain.Event{&updated}))
+
+	history, err := suite.repo.GetEventsHistoryBySensorID(ctx, 4, now, now)
+	require.NoError(suite.T(), err)
+	payloads := map[int64]int64{}
+	for _, e := range history {
+		payloads[e.ID] = e.Payload
+	}
+	assert.Equal(suite.T(), map[int64]int64{event.ID: 100, twin.ID: 1}, payloads,
+		"the event with the same timestamp and raw payload is kept")
+
+	unknown := updated
+	unknown.ID = twin.ID + 1000
+	updated.Payload = 200
+	err = suite.repo.UpdateEvents(ctx, []*domain.Event{&updated, &unknown})
+	assert.ErrorIs(suite.T(), err, usecase.ErrEventNotFound)
+	last, err := suite.repo.GetEventsHistoryBySensorID(ctx, 4, now, now)
+	require.NoError(suite.T(), err)
+	for _, e := range last {
+		assert.NotEqual(suite.T(), int64(200), e.Payload, "the update is rolled back")
+	}
+}
+
 func TestEventTestSuite(t *testing.T) {
 	suite.Run(t, new(EventTestSuite))
 }
@@ -0,0 +1,152 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+)
+
+type JobRepository struct {
+	jobs map[string]domain.Job
+	mu   sync.Mutex
+}
+
+func NewJobRepository() *JobRepository {
+	return &JobRepository{
+		jobs: make(map[string]domain.Job),
+		mu:   sync.Mutex{},
+	}
+}
+
+func (r *JobRepository) SaveJob(ctx context.Context, job *domain.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if job == nil {
+		return errors.New("got nil job at SaveJob()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if saved, exists := r.jobs[job.Name]; exists {
+		*job = saved
+		return nil
+	}
+	r.jobs[job.Name] = *job
+
+	return nil
+}
+
+func (r *JobRepository) UpdateJob(ctx context.Context, job *domain.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if job == nil {
+		return errors.New("got nil job at UpdateJob()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved, exists := r.jobs[job.Name]
+	if !exists {
+		return usecase.ErrJobNotFound
+	}
+	saved.Schedule = job.Schedule
+	saved.Enabled = job.Enabled
+	r.jobs[job.Name] = saved
+
+	return nil
+}
+
+func (r *JobRepository) UpdateJobRun(ctx context.Context, job *domain.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if job == nil {
+		return errors.New("got nil job at UpdateJobRun()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved, exists := r.jobs[job.Name]
+	if !exists {
+		return usecase.ErrJobNotFound
+	}
+	saved.LastRunAt = job.LastRunAt
+	saved.LastStatus = job.LastStatus
+	saved.LastError = job.LastError
+	saved.LastDuration = job.LastDuration
+	r.jobs[job.Name] = saved
+
+	return nil
+}
+
+func (r *JobRepository) GetJobs(ctx context.Context) ([]domain.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	res := make([]domain.Job, 0, len(r.jobs))
+	for _, v := range r.jobs {
+		res = append(res, v)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+func (r *JobRepository) GetJobByName(ctx context.Context, name string) (*domain.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	val, exists := r.jobs[name]
+	r.mu.Unlock()
+	if !exists {
+		return nil, usecase.ErrJobNotFound
+	}
+
+	return &val, nil
+}
+
+// JobLocker - locks of the jobs within the process, enough for a single replica
+type JobLocker struct {
+	locked map[string]bool
+	mu     sync.Mutex
+}
+
+func NewJobLocker() *JobLocker {
+	return &JobLocker{
+		locked: make(map[string]bool),
+		mu:     sync.Mutex{},
+	}
+}
+
+func (l *JobLocker) TryLockJob(ctx context.Context, name string) (func(), bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locked[name] {
+		return nil, false, nil
+	}
+	l.locked[name] = true
+
+	var once sync.Once
+	unlock := func() {
+		once.Do(func() {
+			l.mu.Lock()
+			delete(l.locked, name)
+			l.mu.Unlock()
+		})
+	}
+	return unlock, true, nil
+}
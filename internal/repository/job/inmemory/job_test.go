@@ -0,0 +1,98 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRepository(t *testing.T) {
+	t.Run("err, job is nil", func(t *testing.T) {
+		jr := NewJobRepository()
+		assert.Error(t, jr.SaveJob(context.Background(), nil))
+		assert.Error(t, jr.UpdateJob(context.Background(), nil))
+		assert.Error(t, jr.UpdateJobRun(context.Background(), nil))
+	})
+
+	t.Run("ok, saved definition is kept", func(t *testing.T) {
+		jr := NewJobRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		require.NoError(t, jr.SaveJob(ctx, &domain.Job{Name: "report", Schedule: "@daily", Enabled: true}))
+		require.NoError(t, jr.UpdateJob(ctx, &domain.Job{Name: "report", Schedule: "@hourly"}))
+
+		job := &domain.Job{Name: "report", Schedule: "@daily", Enabled: true}
+		require.NoError(t, jr.SaveJob(ctx, job))
+		assert.Equal(t, domain.Job{Name: "report", Schedule: "@hourly"}, *job)
+	})
+
+	t.Run("ok, run state is updated", func(t *testing.T) {
+		jr := NewJobRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		require.NoError(t, jr.SaveJob(ctx, &domain.Job{Name: "report", Schedule: "@daily", Enabled: true}))
+		require.NoError(t, jr.SaveJob(ctx, &domain.Job{Name: "cleanup", Schedule: "@hourly", Enabled: true}))
+
+		run := domain.Job{
+			Name:         "report",
+			Schedule:     "ignored",
+			LastRunAt:    time.Now(),
+			LastStatus:   domain.JobStatusFailed,
+			LastError:    "boom",
+			LastDuration: time.Second,
+		}
+		require.NoError(t, jr.UpdateJobRun(ctx, &run))
+
+		got, err := jr.GetJobByName(ctx, "report")
+		require.NoError(t, err)
+		run.Schedule = "@daily"
+		run.Enabled = true
+		assert.Equal(t, run, *got)
+
+		jobs, err := jr.GetJobs(ctx)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, "cleanup", jobs[0].Name)
+		assert.Equal(t, "report", jobs[1].Name)
+	})
+
+	t.Run("fail, job not found", func(t *testing.T) {
+		jr := NewJobRepository()
+		ctx := context.Background()
+
+		_, err := jr.GetJobByName(ctx, "report")
+		assert.ErrorIs(t, err, usecase.ErrJobNotFound)
+		assert.ErrorIs(t, jr.UpdateJob(ctx, &domain.Job{Name: "report"}), usecase.ErrJobNotFound)
+		assert.ErrorIs(t, jr.UpdateJobRun(ctx, &domain.Job{Name: "report"}), usecase.ErrJobNotFound)
+	})
+}
+
+func TestJobLocker(t *testing.T) {
+	jl := NewJobLocker()
+	ctx := context.Background()
+
+	unlock, locked, err := jl.TryLockJob(ctx, "report")
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	_, locked, err = jl.TryLockJob(ctx, "report")
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	_, locked, err = jl.TryLockJob(ctx, "cleanup")
+	require.NoError(t, err)
+	assert.True(t, locked)
+
+	unlock()
+	unlock()
+	_, locked, err = jl.TryLockJob(ctx, "report")
+	require.NoError(t, err)
+	assert.True(t, locked)
+}
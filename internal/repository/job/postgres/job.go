@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
+	"homework/internal/usecase"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// unlockTimeout - time given to release the advisory lock, the job context may be cancelled by then
+const unlockTimeout = 5 * time.Second
+
+type JobRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewJobRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *JobRepository {
+	o := pgoptions.Apply(options)
+	return &JobRepository{
+		pool:   pool,
+		logger: o.Logger,
+	}
+}
+
+// jobRow - the job as it is scanned, last_run_at is NULL for the jobs that have never run
+type jobRow struct {
+	domain.Job
+	lastRunAt *time.Time
+}
+
+func (j *jobRow) scanTargets() []any {
+	return []any{&j.Name, &j.Schedule, &j.Enabled, &j.lastRunAt, &j.LastStatus, &j.LastError, &j.LastDuration}
+}
+
+func (j *jobRow) job() domain.Job {
+	if j.lastRunAt != nil {
+		j.LastRunAt = *j.lastRunAt
+	}
+	return j.Job
+}
+
+func lastRunAtOrNull(job *domain.Job) any {
+	if job.LastRunAt.IsZero() {
+		return nil
+	}
+	return job.LastRunAt
+}
+
+// saveJobQuery - keeps the definition saved earlier, possibly changed through the API or by another replica
+const saveJobQuery = `
+	INSERT INTO jobs (name, schedule, enabled) VALUES ($1, $2, $3)
+	ON CONFLICT (name) DO NOTHING`
+
+func (r *JobRepository) SaveJob(ctx context.Context, job *domain.Job) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.JobRepository.SaveJob")
+	defer span.End()
+	defer metrics.ObserveQuery("job", "SaveJob", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.JobRepository.SaveJob", &err)
+
+	if _, err := r.pool.Exec(ctx, saveJobQuery, job.Name, job.Schedule, job.Enabled); err != nil {
+		return fmt.Errorf("unable to save job to pg: %w", err)
+	}
+
+	row := jobRow{}
+	if err := r.pool.QueryRow(ctx, getJobByNameQuery, job.Name).Scan(row.scanTargets()...); err != nil {
+		return fmt.Errorf("unable to read saved job: %w", err)
+	}
+	*job = row.job()
+
+	return nil
+}
+
+const updateJobQuery = `UPDATE jobs SET schedule = $2, enabled = $3 WHERE name = $1`
+
+func (r *JobRepository) UpdateJob(ctx context.Context, job *domain.Job) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.JobRepository.UpdateJob")
+	defer span.End()
+	defer metrics.ObserveQuery("job", "UpdateJob", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.JobRepository.UpdateJob", &err, usecase.ErrJobNotFound)
+
+	tag, err := r.pool.Exec(ctx, updateJobQuery, job.Name, job.Schedule, job.Enabled)
+	if err != nil {
+		return fmt.Errorf("unable to update job in pg: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrJobNotFound
+	}
+
+	return nil
+}
+
+const updateJobRunQuery = `
+	UPDATE jobs SET last_run_at = $2, last_status = $3, last_error = $4, last_duration = $5
+	WHERE name = $1`
+
+func (r *JobRepository) UpdateJobRun(ctx context.Context, job *domain.Job) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.JobRepository.UpdateJobRun")
+	defer span.End()
+	defer metrics.ObserveQuery("job", "UpdateJobRun", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.JobRepository.UpdateJobRun", &err, usecase.ErrJobNotFound)
+
+	tag, err := r.pool.Exec(ctx, updateJobRunQuery, job.Name, lastRunAtOrNull(job), job.LastStatus, job.LastError,
+		job.LastDuration)
+	if err != nil {
+		return fmt.Errorf("unable to update job run in pg: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrJobNotFound
+	}
+
+	return nil
+}
+
+const getJobsQuery = `
+	SELECT name, schedule, enabled, last_run_at, last_status, last_error, last_duration
+	FROM jobs ORDER BY name`
+
+func (r *JobRepository) GetJobs(ctx context.Context) (_ []domain.Job, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.JobRepository.GetJobs")
+	defer span.End()
+	defer metrics.ObserveQuery("job", "GetJobs", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.JobRepository.GetJobs", &err)
+
+	rows, err := r.pool.Query(ctx, getJobsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("can't get jobs: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []domain.Job
+	for rows.Next() {
+		row := jobRow{}
+		if err := rows.Scan(row.scanTargets()...); err != nil {
+			return nil, fmt.Errorf("can't scan jobs: %w", err)
+		}
+
+		result = append(result, row.job())
+	}
+
+	return result, nil
+}
+
+const getJobByNameQuery = `
+	SELECT name, schedule, enabled, last_run_at, last_status, last_error, last_duration
+	FROM jobs WHERE name = $1`
+
+func (r *JobRepository) GetJobByName(ctx context.Context, name string) (_ *domain.Job, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.JobRepository.GetJobByName")
+	defer span.End()
+	defer metrics.ObserveQuery("job", "GetJobByName", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.JobRepository.GetJobByName", &err, usecase.ErrJobNotFound)
+
+	row := jobRow{}
+	if err := r.pool.QueryRow(ctx, getJobByNameQuery, name).Scan(row.scanTargets()...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("unable to find job by name: %w", err)
+	}
+
+	job := row.job()
+	return &job, nil
+}
+
+// JobLocker - locks of the jobs shared by the replicas, based on the session level advisory locks.
+// A connection is held for the whole run of the job, the lock is gone with it if the replica dies.
+type JobLocker struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewJobLocker(pool *pgxpool.Pool, options ...pgoptions.Option) *JobLocker {
+	o := pgoptions.Apply(options)
+	return &JobLocker{
+		pool:   pool,
+		logger: o.Logger,
+	}
+}
+
+const (
+	tryLockJobQuery = `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`
+	unlockJobQuery  = `SELECT pg_advisory_unlock(hashtextextended($1, 0))`
+)
+
+func (l *JobLocker) TryLockJob(ctx context.Context, name string) (_ func(), _ bool, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.JobLocker.TryLockJob")
+	defer span.End()
+	defer metrics.ObserveQuery("job", "TryLockJob", time.Now())
+	defer logging.OnError(ctx, l.logger, "postgres.JobLocker.TryLockJob", &err)
+
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to acquire connection: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, tryLockJobQuery, name).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("unable to lock job: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), unlockTimeout)
+		defer cancel()
+
+		var unlocked bool
+		if err := conn.QueryRow(ctx, unlockJobQuery, name).Scan(&unlocked); err != nil || !unlocked {
+			// The lock is released with the session, so the connection is not returned to the pool
+			l.logger.ErrorContext(ctx, "cannot unlock job, closing connection", "job", name, "error", err)
+			_ = conn.Hijack().Close(ctx)
+			return
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type JobTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	jobs   *JobRepository
+	locker *JobLocker
+}
+
+func (suite *JobTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.jobs = NewJobRepository(suite.testDbInstance)
+	suite.locker = NewJobLocker(suite.testDbInstance)
+}
+
+func (suite *JobTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *JobTestSuite) TestJobRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	report := &domain.Job{Name: "report", Schedule: "@daily", Enabled: true}
+	require.NoError(suite.T(), suite.jobs.SaveJob(ctx, report))
+	assert.Equal(suite.T(), domain.Job{Name: "report", Schedule: "@daily", Enabled: true}, *report)
+
+	report.Schedule = "@hourly"
+	report.Enabled = false
+	require.NoError(suite.T(), suite.jobs.UpdateJob(ctx, report))
+
+	again := &domain.Job{Name: "report", Schedule: "@daily", Enabled: true}
+	require.NoError(suite.T(), suite.jobs.SaveJob(ctx, again))
+	assert.Equal(suite.T(), *report, *again)
+
+	report.LastRunAt = time.Now().Truncate(time.Microsecond).In(time.UTC)
+	report.LastStatus = domain.JobStatusFailed
+	report.LastError = "boom"
+	report.LastDuration = 1500 * time.Millisecond
+	require.NoError(suite.T(), suite.jobs.UpdateJobRun(ctx, report))
+
+	cleanup := &domain.Job{Name: "cleanup", Schedule: "* * * * *", Enabled: true}
+	require.NoError(suite.T(), suite.jobs.SaveJob(ctx, cleanup))
+
+	got, err := suite.jobs.GetJobByName(ctx, "report")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *report, *got)
+
+	jobs, err := suite.jobs.GetJobs(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Job{*cleanup, *report}, jobs)
+
+	_, err = suite.jobs.GetJobByName(ctx, "missing")
+	assert.ErrorIs(suite.T(), err, usecase.ErrJobNotFound)
+	assert.ErrorIs(suite.T(), suite.jobs.UpdateJob(ctx, &domain.Job{Name: "missing"}), usecase.ErrJobNotFound)
+	assert.ErrorIs(suite.T(), suite.jobs.UpdateJobRun(ctx, &domain.Job{Name: "missing"}), usecase.ErrJobNotFound)
+}
+
+func (suite *JobTestSuite) TestJobLocker() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	unlock, locked, err := suite.locker.TryLockJob(ctx, "report")
+	require.NoError(suite.T(), err)
+	require.True(suite.T(), locked)
+
+	// Another replica has a connection of its own
+	other := NewJobLocker(suite.testDbInstance)
+	_, locked, err = other.TryLockJob(ctx, "report")
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), locked)
+
+	unlock()
+	unlockOther, locked, err := other.TryLockJob(ctx, "report")
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), locked)
+	unlockOther()
+}
+
+func TestJobTestSuite(t *testing.T) {
+	suite.Run(t, new(JobTestSuite))
+}
@@ -0,0 +1,105 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+)
+
+type LocationRepository struct {
+	storage map[int64]domain.Location
+	access  map[domain.LocationAccess]struct{}
+	lastId  int64
+	mu      sync.Mutex
+}
+
+func NewLocationRepository() *LocationRepository {
+	return &LocationRepository{
+		storage: make(map[int64]domain.Location),
+		access:  make(map[domain.LocationAccess]struct{}),
+		lastId:  0,
+		mu:      sync.Mutex{},
+	}
+}
+
+func (r *LocationRepository) SaveLocation(ctx context.Context, location *domain.Location) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if location == nil {
+		return errors.New("got nil location at SaveLocation()")
+	}
+
+	r.mu.Lock()
+	r.lastId++
+	id := r.lastId
+
+	location.ID = id
+	r.storage[id] = *location
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *LocationRepository) GetLocations(ctx context.Context) ([]domain.Location, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	res := make([]domain.Location, 0, len(r.storage))
+	for _, v := range r.storage {
+		res = append(res, v)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (r *LocationRepository) GetLocationByID(ctx context.Context, id int64) (*domain.Location, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	val, exists := r.storage[id]
+	r.mu.Unlock()
+	if !exists {
+		return nil, usecase.ErrLocationNotFound
+	}
+
+	return &val, nil
+}
+
+func (r *LocationRepository) SaveLocationAccess(ctx context.Context, access domain.LocationAccess) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.access[access] = struct{}{}
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *LocationRepository) GetLocationAccessByUserID(ctx context.Context, userID int64) ([]domain.LocationAccess, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.LocationAccess, 0)
+	r.mu.Lock()
+	for a := range r.access {
+		if a.UserID == userID {
+			result = append(result, a)
+		}
+	}
+	r.mu.Unlock()
+
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocationRepository_SaveLocation(t *testing.T) {
+	t.Run("err, location is nil", func(t *testing.T) {
+		lr := NewLocationRepository()
+		err := lr.SaveLocation(context.Background(), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		lr := NewLocationRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := lr.SaveLocation(ctx, &domain.Location{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, save and get", func(t *testing.T) {
+		lr := NewLocationRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		home := &domain.Location{Kind: domain.LocationKindHome, Name: "Home"}
+		assert.NoError(t, lr.SaveLocation(ctx, home))
+		room := &domain.Location{ParentID: home.ID, Kind: domain.LocationKindRoom, Name: "Kitchen"}
+		assert.NoError(t, lr.SaveLocation(ctx, room))
+
+		got, err := lr.GetLocationByID(ctx, room.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, *room, *got)
+
+		locations, err := lr.GetLocations(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{*home, *room}, locations)
+	})
+
+	t.Run("fail, location not found", func(t *testing.T) {
+		lr := NewLocationRepository()
+		_, err := lr.GetLocationByID(context.Background(), 1)
+		assert.ErrorIs(t, err, usecase.ErrLocationNotFound)
+	})
+}
+
+func TestLocationRepository_LocationAccess(t *testing.T) {
+	lr := NewLocationRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	access := domain.LocationAccess{UserID: 1, LocationID: 2}
+	assert.NoError(t, lr.SaveLocationAccess(ctx, access))
+	assert.NoError(t, lr.SaveLocationAccess(ctx, access))
+	assert.NoError(t, lr.SaveLocationAccess(ctx, domain.LocationAccess{UserID: 2, LocationID: 2}))
+
+	accesses, err := lr.GetLocationAccessByUserID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.LocationAccess{access}, accesses)
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
+	"homework/internal/usecase"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LocationRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewLocationRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *LocationRepository {
+	o := pgoptions.Apply(options)
+	return &LocationRepository{
+		pool:   pool,
+		logger: o.Logger,
+	}
+}
+
+// parentOrNull - stores the parent of the top level locations as NULL
+func parentOrNull(location *domain.Location) any {
+	if location.ParentID == 0 {
+		return nil
+	}
+	return location.ParentID
+}
+
+const saveLocationQuery = `INSERT INTO locations (parent_id, kind, name) VALUES ($1, $2, $3) RETURNING id`
+
+func (r *LocationRepository) SaveLocation(ctx context.Context, location *domain.Location) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.LocationRepository.SaveLocation")
+	defer span.End()
+	defer metrics.ObserveQuery("location", "SaveLocation", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.LocationRepository.SaveLocation", &err)
+
+	row := r.pool.QueryRow(ctx, saveLocationQuery, parentOrNull(location), location.Kind, location.Name)
+
+	if err := row.Scan(&location.ID); err != nil {
+		return fmt.Errorf("unable to save location to pg: %w", err)
+	}
+
+	return nil
+}
+
+const getLocationsQuery = `SELECT id, COALESCE(parent_id, 0), kind, name FROM locations ORDER BY id`
+
+func (r *LocationRepository) GetLocations(ctx context.Context) (_ []domain.Location, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.LocationRepository.GetLocations")
+	defer span.End()
+	defer metrics.ObserveQuery("location", "GetLocations", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.LocationRepository.GetLocations", &err)
+
+	rows, err := r.pool.Query(ctx, getLocationsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("can't get locations: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []domain.Location
+	for rows.Next() {
+		location := domain.Location{}
+		if err := rows.Scan(&location.ID, &location.ParentID, &location.Kind, &location.Name); err != nil {
+			return nil, fmt.Errorf("can't scan locations: %w", err)
+		}
+
+		result = append(result, location)
+	}
+
+	return result, nil
+}
+
+const getLocationByIDQuery = `SELECT id, COALESCE(parent_id, 0), kind, name FROM locations WHERE id = $1`
+
+func (r *LocationRepository) GetLocationByID(ctx context.Context, id int64) (_ *domain.Location, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.LocationRepository.GetLocationByID")
+	defer span.End()
+	defer metrics.ObserveQuery("location", "GetLocationByID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.LocationRepository.GetLocationByID", &err, usecase.ErrLocationNotFound)
+
+	row := r.pool.QueryRow(ctx, getLocationByIDQuery, id)
+
+	location := &domain.Location{}
+	if err := row.Scan(&location.ID, &location.ParentID, &location.Kind, &location.Name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrLocationNotFound
+		}
+		return nil, fmt.Errorf("unable to find location by id: %w", err)
+	}
+
+	return location, nil
+}
+
+const saveLocationAccessQuery = `
+	INSERT INTO locations_users (location_id, user_id) VALUES ($1, $2)
+	ON CONFLICT (location_id, user_id) DO NOTHING`
+
+func (r *LocationRepository) SaveLocationAccess(ctx context.Context, access domain.LocationAccess) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.LocationRepository.SaveLocationAccess")
+	defer span.End()
+	defer metrics.ObserveQuery("location", "SaveLocationAccess", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.LocationRepository.SaveLocationAccess", &err)
+
+	if _, err := r.pool.Exec(ctx, saveLocationAccessQuery, access.LocationID, access.UserID); err != nil {
+		return fmt.Errorf("unable to save location access to pg: %w", err)
+	}
+	return nil
+}
+
+const getLocationAccessByUserIDQuery = `SELECT location_id, user_id FROM locations_users WHERE user_id = $1`
+
+func (r *LocationRepository) GetLocationAccessByUserID(ctx context.Context, userID int64) (_ []domain.LocationAccess, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.LocationRepository.GetLocationAccessByUserID")
+	defer span.End()
+	defer metrics.ObserveQuery("location", "GetLocationAccessByUserID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.LocationRepository.GetLocationAccessByUserID", &err)
+
+	rows, err := r.pool.Query(ctx, getLocationAccessByUserIDQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get location access: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []domain.LocationAccess
+	for rows.Next() {
+		access := domain.LocationAccess{}
+		if err := rows.Scan(&access.LocationID, &access.UserID); err != nil {
+			return nil, fmt.Errorf("can't scan location access: %w", err)
+		}
+
+		result = append(result, access)
+	}
+
+	return result, nil
+}
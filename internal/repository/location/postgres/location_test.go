@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocationTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *LocationRepository
+}
+
+func (suite *LocationTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewLocationRepository(suite.testDbInstance)
+}
+
+func (suite *LocationTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *LocationTestSuite) TestLocationRepository_SaveLocation() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	home := &domain.Location{Kind: domain.LocationKindHome, Name: "Home"}
+	assert.NoError(suite.T(), suite.repo.SaveLocation(ctx, home))
+	room := &domain.Location{ParentID: home.ID, Kind: domain.LocationKindRoom, Name: "Kitchen"}
+	assert.NoError(suite.T(), suite.repo.SaveLocation(ctx, room))
+
+	got, err := suite.repo.GetLocationByID(ctx, home.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *home, *got)
+
+	got, err = suite.repo.GetLocationByID(ctx, room.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *room, *got)
+
+	_, err = suite.repo.GetLocationByID(ctx, room.ID+100)
+	assert.ErrorIs(suite.T(), err, usecase.ErrLocationNotFound)
+}
+
+func (suite *LocationTestSuite) TestLocationRepository_LocationAccess() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	home := &domain.Location{Kind: domain.LocationKindHome, Name: "Cottage"}
+	assert.NoError(suite.T(), suite.repo.SaveLocation(ctx, home))
+
+	access := domain.LocationAccess{UserID: 42, LocationID: home.ID}
+	assert.NoError(suite.T(), suite.repo.SaveLocationAccess(ctx, access))
+	assert.NoError(suite.T(), suite.repo.SaveLocationAccess(ctx, access))
+
+	accesses, err := suite.repo.GetLocationAccessByUserID(ctx, 42)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []domain.LocationAccess{access}, accesses)
+}
+
+func TestLocationTestSuite(t *testing.T) {
+	suite.Run(t, new(LocationTestSuite))
+}
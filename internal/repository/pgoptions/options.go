@@ -0,0 +1,25 @@
+// Package pgoptions - options shared by the constructors of the postgres repositories
+package pgoptions
+
+import "log/slog"
+
+// Option - option accepted by every postgres repository constructor
+type Option func(*Options)
+
+type Options struct {
+	Logger *slog.Logger
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+func Apply(opts []Option) Options {
+	o := Options{Logger: slog.Default()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,136 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"maps"
+	"sort"
+	"sync"
+)
+
+type PendingSensorRepository struct {
+	storage map[string]*domain.PendingSensor
+	mu      sync.Mutex
+}
+
+func NewPendingSensorRepository() *PendingSensorRepository {
+	return &PendingSensorRepository{
+		storage: make(map[string]*domain.PendingSensor),
+		mu:      sync.Mutex{},
+	}
+}
+
+func (r *PendingSensorRepository) SavePendingEvent(ctx context.Context, event *domain.Event, limits usecase.PendingLimits) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if event == nil {
+		return errors.New("got nil event at SavePendingEvent()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending, exists := r.storage[event.SensorSerialNumber]
+	if !exists {
+		if len(r.storage) >= limits.Sensors {
+			return usecase.ErrPendingSensorsLimit
+		}
+		pending = &domain.PendingSensor{
+			SerialNumber: event.SensorSerialNumber,
+			FirstSeen:    event.Timestamp,
+		}
+		r.storage[event.SensorSerialNumber] = pending
+	}
+	if event.Timestamp.After(pending.LastSeen) {
+		pending.LastSeen = event.Timestamp
+	}
+	pending.Seen++
+	if pending.Seen <= int64(limits.Events) {
+		buffered := *event
+		buffered.Values = maps.Clone(event.Values)
+		pending.Events = append(pending.Events, buffered)
+		pending.Buffered++
+	}
+
+	return nil
+}
+
+func (r *PendingSensorRepository) GetPendingSensors(ctx context.Context) ([]domain.PendingSensor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	res := make([]domain.PendingSensor, 0, len(r.storage))
+	for _, v := range r.storage {
+		pending := *v
+		pending.Events = nil
+		res = append(res, pending)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].FirstSeen.Equal(res[j].FirstSeen) {
+			return res[i].FirstSeen.Before(res[j].FirstSeen)
+		}
+		return res[i].SerialNumber < res[j].SerialNumber
+	})
+	return res, nil
+}
+
+func (r *PendingSensorRepository) GetPendingSensorBySerialNumber(ctx context.Context, sn string) (*domain.PendingSensor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	val, exists := r.storage[sn]
+	if !exists {
+		return nil, usecase.ErrPendingSensorNotFound
+	}
+
+	pending := *val
+	pending.Events = append([]domain.Event(nil), val.Events...)
+	return &pending, nil
+}
+
+func (r *PendingSensorRepository) DeletePendingSensor(ctx context.Context, sn string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.storage[sn]; !exists {
+		return usecase.ErrPendingSensorNotFound
+	}
+	delete(r.storage, sn)
+
+	return nil
+}
+
+func (r *PendingSensorRepository) DeletePendingEvent(ctx context.Context, sn string, idempotencyKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending, exists := r.storage[sn]
+	if !exists {
+		return nil
+	}
+	events := pending.Events[:0]
+	for _, event := range pending.Events {
+		if event.IdempotencyKey != idempotencyKey {
+			events = append(events, event)
+		}
+	}
+	pending.Buffered -= int64(len(pending.Events) - len(events))
+	pending.Events = events
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingSensorRepository_SavePendingEvent(t *testing.T) {
+	limits := usecase.PendingLimits{Sensors: 2, Events: 2}
+	now := time.Now()
+
+	t.Run("err, event is nil", func(t *testing.T) {
+		pr := NewPendingSensorRepository()
+		assert.Error(t, pr.SavePendingEvent(context.Background(), nil, limits))
+	})
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		pr := NewPendingSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000001"}, limits)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, first events buffered", func(t *testing.T) {
+		pr := NewPendingSensorRepository()
+		ctx := context.Background()
+
+		for i := range 3 {
+			event := &domain.Event{SensorSerialNumber: "0000000001", Timestamp: now.Add(time.Duration(i) * time.Second), Payload: int64(i)}
+			require.NoError(t, pr.SavePendingEvent(ctx, event, limits))
+		}
+
+		pending, err := pr.GetPendingSensorBySerialNumber(ctx, "0000000001")
+		require.NoError(t, err)
+		assert.Equal(t, now, pending.FirstSeen)
+		assert.Equal(t, now.Add(2*time.Second), pending.LastSeen)
+		assert.Equal(t, int64(3), pending.Seen)
+		assert.Equal(t, int64(2), pending.Buffered)
+		require.Len(t, pending.Events, 2)
+		assert.Equal(t, int64(0), pending.Events[0].Payload)
+		assert.Equal(t, int64(1), pending.Events[1].Payload)
+	})
+
+	t.Run("err, too many sensors", func(t *testing.T) {
+		pr := NewPendingSensorRepository()
+		ctx := context.Background()
+
+		require.NoError(t, pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000001", Timestamp: now}, limits))
+		require.NoError(t, pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000002", Timestamp: now}, limits))
+
+		err := pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000003", Timestamp: now}, limits)
+		assert.ErrorIs(t, err, usecase.ErrPendingSensorsLimit)
+		// the known ones are still counted
+		assert.NoError(t, pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000002", Timestamp: now}, limits))
+	})
+}
+
+func TestPendingSensorRepository_GetPendingSensors(t *testing.T) {
+	pr := NewPendingSensorRepository()
+	ctx := context.Background()
+	limits := usecase.PendingLimits{Sensors: 10, Events: 10}
+	now := time.Now()
+
+	require.NoError(t, pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000002", Timestamp: now.Add(time.Second)}, limits))
+	require.NoError(t, pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000001", Timestamp: now}, limits))
+	require.NoError(t, pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000001", Timestamp: now}, limits))
+
+	pending, err := pr.GetPendingSensors(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "0000000001", pending[0].SerialNumber)
+	assert.Equal(t, int64(2), pending[0].Buffered)
+	assert.Nil(t, pending[0].Events)
+	assert.Equal(t, "0000000002", pending[1].SerialNumber)
+}
+
+func TestPendingSensorRepository_DeletePendingSensor(t *testing.T) {
+	pr := NewPendingSensorRepository()
+	ctx := context.Background()
+
+	require.NoError(t, pr.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "0000000001", Timestamp: time.Now()},
+		usecase.DefaultPendingLimits))
+	require.NoError(t, pr.DeletePendingSensor(ctx, "0000000001"))
+
+	_, err := pr.GetPendingSensorBySerialNumber(ctx, "0000000001")
+	assert.ErrorIs(t, err, usecase.ErrPendingSensorNotFound)
+	assert.ErrorIs(t, pr.DeletePendingSensor(ctx, "0000000001"), usecase.ErrPendingSensorNotFound)
+}
+
+func TestPendingSensorRepository_DeletePendingEvent(t *testing.T) {
+	pr := NewPendingSensorRepository()
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, key := range []string{"key-1", "key-2"} {
+		event := &domain.Event{SensorSerialNumber: "0000000001", Timestamp: now, Payload: int64(i), IdempotencyKey: key}
+		require.NoError(t, pr.SavePendingEvent(ctx, event, usecase.DefaultPendingLimits))
+	}
+	require.NoError(t, pr.DeletePendingEvent(ctx, "0000000001", "key-1"))
+
+	pending, err := pr.GetPendingSensorBySerialNumber(ctx, "0000000001")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pending.Seen)
+	assert.Equal(t, int64(1), pending.Buffered)
+	require.Len(t, pending.Events, 1)
+	assert.Equal(t, "key-2", pending.Events[0].IdempotencyKey)
+
+	// already deleted ones are not an error
+	assert.NoError(t, pr.DeletePendingEvent(ctx, "0000000001", "key-1"))
+	assert.NoError(t, pr.DeletePendingEvent(ctx, "0000000002", "key-1"))
+}
@@ -3,8 +3,11 @@ package inmemory
 import (
 	"context"
 	"errors"
+	"fmt"
 	"homework/internal/domain"
 	"homework/internal/usecase"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 )
@@ -25,6 +28,17 @@ func NewSensorRepository() *SensorRepository {
 	}
 }
 
+// cloneSensor - copy of the sensor not sharing the mutable fields, so the callers never change the stored sensors
+// bypassing the version check
+func cloneSensor(sensor *domain.Sensor) *domain.Sensor {
+	c := *sensor
+	c.Channels = slices.Clone(sensor.Channels)
+	c.CurrentValues = maps.Clone(sensor.CurrentValues)
+	c.Calibration = slices.Clone(sensor.Calibration)
+	c.Labels = maps.Clone(sensor.Labels)
+	return &c
+}
+
 func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -32,19 +46,79 @@ func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor
 	if sensor == nil {
 		return errors.New("got nil sensor at SaveSensor()")
 	}
-	if sensor.RegisteredAt.IsZero() {
-		sensor.RegisteredAt = time.Now()
 
-		r.mu.Lock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, exists := r.snStorage[sensor.SerialNumber]
+	switch {
+	case !exists && sensor.Version != 0, exists && stored.Version != sensor.Version:
+		return usecase.ErrSensorVersionConflict
+	case exists:
+		sensor.ID, sensor.RegisteredAt = stored.ID, stored.RegisteredAt
+	default:
 		r.lastId++
-		id := r.lastId
-		sensor.ID = id
+		sensor.ID, sensor.RegisteredAt = r.lastId, time.Now()
+	}
+	sensor.Version++
+
+	stored = cloneSensor(sensor)
+	r.idStorage[stored.ID] = stored
+	r.snStorage[stored.SerialNumber] = stored
+
+	return nil
+}
+
+func (r *SensorRepository) UpdateSensorState(ctx context.Context, sensor *domain.Sensor) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if sensor == nil {
+		return errors.New("got nil sensor at UpdateSensorState()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, exists := r.idStorage[sensor.ID]
+	if !exists {
+		return usecase.ErrSensorNotFound
+	}
+	stored.CurrentState = sensor.CurrentState
+	stored.CurrentValues = maps.Clone(sensor.CurrentValues)
+	stored.LastActivity = sensor.LastActivity
+	stored.Version++
+	sensor.Version = stored.Version
+
+	return nil
+}
+
+// RestoreSensor - saves the sensor as is with its ID if the ID is free, with a new ID otherwise.
+// The serial number must not be registered yet
+func (r *SensorRepository) RestoreSensor(ctx context.Context, sensor *domain.Sensor) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if sensor == nil {
+		return errors.New("got nil sensor at RestoreSensor()")
+	}
 
-		r.idStorage[id] = sensor
-		r.snStorage[sensor.SerialNumber] = sensor
-		r.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.snStorage[sensor.SerialNumber]; exists {
+		return fmt.Errorf("sensor %s is already registered", sensor.SerialNumber)
+	}
+	if _, taken := r.idStorage[sensor.ID]; taken || sensor.ID <= 0 {
+		r.lastId++
+		sensor.ID = r.lastId
+	}
+	r.lastId = max(r.lastId, sensor.ID)
+	if sensor.RegisteredAt.IsZero() {
+		sensor.RegisteredAt = time.Now()
 	}
 
+	stored := cloneSensor(sensor)
+	r.idStorage[stored.ID] = stored
+	r.snStorage[stored.SerialNumber] = stored
+
 	return nil
 }
 
@@ -57,7 +131,24 @@ func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, err
 	res := make([]domain.Sensor, 0, len(r.idStorage))
 
 	for _, v := range r.idStorage {
-		res = append(res, *v)
+		res = append(res, *cloneSensor(v))
+	}
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+func (r *SensorRepository) GetSensorsBySelector(ctx context.Context, selector domain.Selector) ([]domain.Sensor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	res := make([]domain.Sensor, 0)
+	for _, v := range r.idStorage {
+		if selector.Matches(v.Labels) {
+			res = append(res, *cloneSensor(v))
+		}
 	}
 	r.mu.Unlock()
 
@@ -70,13 +161,13 @@ func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain
 	}
 
 	r.mu.Lock()
+	defer r.mu.Unlock()
 	val, exists := r.idStorage[id]
-	r.mu.Unlock()
 	if !exists {
 		return nil, usecase.ErrSensorNotFound
 	}
 
-	return val, nil
+	return cloneSensor(val), nil
 }
 
 func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error) {
@@ -85,11 +176,11 @@ func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn strin
 	}
 
 	r.mu.Lock()
+	defer r.mu.Unlock()
 	val, exists := r.snStorage[sn]
-	r.mu.Unlock()
 	if !exists {
 		return nil, usecase.ErrSensorNotFound
 	}
 
-	return val, nil
+	return cloneSensor(val), nil
 }
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSensorRepository_SaveSensor(t *testing.T) {
@@ -66,6 +67,45 @@ func TestSensorRepository_SaveSensor(t *testing.T) {
 		assert.Empty(t, actualSensor.LastActivity)
 	})
 
+	t.Run("ok, version is bumped on every save", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx := context.Background()
+
+		sensor := &domain.Sensor{SerialNumber: "12345678", Type: domain.SensorTypeContactClosure}
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+		assert.Equal(t, int64(1), sensor.Version)
+
+		sensor.CurrentState = 1
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+		actualSensor, err := sr.GetSensorByID(ctx, sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), actualSensor.Version)
+	})
+
+	t.Run("err, version conflict", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx := context.Background()
+
+		sensor := &domain.Sensor{SerialNumber: "12345678", Type: domain.SensorTypeContactClosure}
+		require.NoError(t, sr.SaveSensor(ctx, sensor))
+		stale, err := sr.GetSensorByID(ctx, sensor.ID)
+		require.NoError(t, err)
+
+		sensor.Labels = map[string]string{"kind": "door"}
+		require.NoError(t, sr.SaveSensor(ctx, sensor))
+		stale.Description = "stale"
+		assert.ErrorIs(t, sr.SaveSensor(ctx, stale), usecase.ErrSensorVersionConflict)
+		// a new sensor must not replace the registered one
+		duplicate := &domain.Sensor{SerialNumber: "12345678", Type: domain.SensorTypeContactClosure}
+		assert.ErrorIs(t, sr.SaveSensor(ctx, duplicate), usecase.ErrSensorVersionConflict)
+
+		actualSensor, err := sr.GetSensorByID(ctx, sensor.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), actualSensor.Version)
+		assert.Equal(t, map[string]string{"kind": "door"}, actualSensor.Labels)
+		assert.Empty(t, actualSensor.Description)
+	})
+
 	t.Run("ok, collision test", func(t *testing.T) {
 		sr := NewSensorRepository()
 		ctx, cancel := context.WithCancel(context.Background())
@@ -96,6 +136,70 @@ func TestSensorRepository_SaveSensor(t *testing.T) {
 	})
 }
 
+func TestSensorRepository_UpdateSensorState(t *testing.T) {
+	t.Run("err, not found", func(t *testing.T) {
+		sr := NewSensorRepository()
+		err := sr.UpdateSensorState(context.Background(), &domain.Sensor{ID: 1})
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound)
+	})
+
+	t.Run("ok, only the state is written", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx := context.Background()
+
+		sensor := &domain.Sensor{SerialNumber: "12345678", Type: domain.SensorTypeTemperature}
+		require.NoError(t, sr.SaveSensor(ctx, sensor))
+		stale, err := sr.GetSensorByID(ctx, sensor.ID)
+		require.NoError(t, err)
+		sensor.Labels = map[string]string{"kind": "climate"}
+		require.NoError(t, sr.SaveSensor(ctx, sensor))
+
+		now := time.Now()
+		stale.CurrentState, stale.LastActivity = 215, now
+		require.NoError(t, sr.UpdateSensorState(ctx, stale))
+		assert.Equal(t, int64(3), stale.Version)
+
+		actualSensor, err := sr.GetSensorByID(ctx, sensor.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(215), actualSensor.CurrentState)
+		assert.Equal(t, now, actualSensor.LastActivity)
+		assert.Equal(t, map[string]string{"kind": "climate"}, actualSensor.Labels)
+		assert.Equal(t, int64(3), actualSensor.Version)
+	})
+}
+
+func TestSensorRepository_RestoreSensor(t *testing.T) {
+	t.Run("err, sensor is nil", func(t *testing.T) {
+		assert.Error(t, NewSensorRepository().RestoreSensor(context.Background(), nil))
+	})
+
+	t.Run("ok, restored as is", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx := context.Background()
+
+		registered := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		sensor := &domain.Sensor{ID: 7, SerialNumber: "0000000007", Type: domain.SensorTypeADC, RegisteredAt: registered, Version: 3}
+		require.NoError(t, sr.RestoreSensor(ctx, sensor))
+		assert.Equal(t, int64(7), sensor.ID)
+
+		actual, err := sr.GetSensorBySerialNumber(ctx, "0000000007")
+		require.NoError(t, err)
+		assert.Equal(t, sensor, actual)
+		assert.NotSame(t, sensor, actual)
+
+		taken := &domain.Sensor{ID: 7, SerialNumber: "0000000008", Type: domain.SensorTypeContactClosure}
+		require.NoError(t, sr.RestoreSensor(ctx, taken))
+		assert.Equal(t, int64(8), taken.ID)
+		assert.False(t, taken.RegisteredAt.IsZero())
+
+		saved := &domain.Sensor{SerialNumber: "0000000009", Type: domain.SensorTypeContactClosure}
+		require.NoError(t, sr.SaveSensor(ctx, saved))
+		assert.Equal(t, int64(9), saved.ID, "the IDs assigned later don't collide")
+
+		assert.Error(t, sr.RestoreSensor(ctx, &domain.Sensor{SerialNumber: "0000000007"}), "serial number is taken")
+	})
+}
+
 func TestSensorRepository_GetSensors(t *testing.T) {
 	t.Run("fail, ctx cancelled", func(t *testing.T) {
 		sr := NewSensorRepository()
@@ -230,3 +334,28 @@ func TestSensorRepository_GetSensorBySerialNumber(t *testing.T) {
 		assert.Empty(t, actualSensor.LastActivity)
 	})
 }
+
+func TestSensorRepository_GetSensorsBySelector(t *testing.T) {
+	sr := NewSensorRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leak := &domain.Sensor{SerialNumber: "0000000001", Labels: map[string]string{"kind": "leak", "floor": "2"}}
+	basement := &domain.Sensor{SerialNumber: "0000000002", Labels: map[string]string{"kind": "leak", "floor": "0"}}
+	unlabeled := &domain.Sensor{SerialNumber: "0000000003"}
+	for _, s := range []*domain.Sensor{leak, basement, unlabeled} {
+		assert.NoError(t, sr.SaveSensor(ctx, s))
+	}
+
+	selector, err := domain.ParseSelector("kind=leak,floor!=0")
+	assert.NoError(t, err)
+	sensors, err := sr.GetSensorsBySelector(ctx, selector)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Sensor{*leak}, sensors)
+
+	selector, err = domain.ParseSelector("!kind")
+	assert.NoError(t, err)
+	sensors, err = sr.GetSensorsBySelector(ctx, selector)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Sensor{*unlabeled}, sensors)
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
+	"homework/internal/usecase"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PendingSensorRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewPendingSensorRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *PendingSensorRepository {
+	o := pgoptions.Apply(options)
+	return &PendingSensorRepository{
+		pool:   pool,
+		logger: o.Logger,
+	}
+}
+
+// pendingSensorsFullQuery - whether the sensor is new and the list has no room for it,
+// the concurrent events of different new sensors may exceed the limit slightly
+const pendingSensorsFullQuery = `
+	SELECT NOT EXISTS (SELECT 1 FROM pending_sensors WHERE serial_number = $1)
+	   AND (SELECT count(*) FROM pending_sensors) >= $2`
+
+// savePendingSensorQuery - the row lock taken by the upsert serializes the events of the sensor,
+// so every event gets its own number
+const savePendingSensorQuery = `
+	INSERT INTO pending_sensors (serial_number, first_seen, last_seen, seen) VALUES ($1, $2, $2, 1)
+	ON CONFLICT (serial_number) DO UPDATE
+	  SET last_seen = GREATEST(pending_sensors.last_seen, excluded.last_seen),
+	      seen = pending_sensors.seen + 1
+	RETURNING seen`
+
+const savePendingEventQuery = `
+	INSERT INTO pending_sensor_events (sensor_serial_number, timestamp, payload, channel_values, idempotency_key)
+	VALUES ($1, $2, $3, $4, $5)`
+
+func (r *PendingSensorRepository) SavePendingEvent(ctx context.Context, event *domain.Event, limits usecase.PendingLimits) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.PendingSensorRepository.SavePendingEvent")
+	defer span.End()
+	defer metrics.ObserveQuery("pending_sensor", "SavePendingEvent", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.PendingSensorRepository.SavePendingEvent", &err, usecase.ErrPendingSensorsLimit)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // Is a no-op after commit
+
+	var full bool
+	if err := tx.QueryRow(ctx, pendingSensorsFullQuery, event.SensorSerialNumber, limits.Sensors).Scan(&full); err != nil {
+		return fmt.Errorf("unable to count pending sensors: %w", err)
+	}
+	if full {
+		return usecase.ErrPendingSensorsLimit
+	}
+
+	var seen int64
+	if err := tx.QueryRow(ctx, savePendingSensorQuery, event.SensorSerialNumber, event.Timestamp).Scan(&seen); err != nil {
+		return fmt.Errorf("unable to save pending sensor to pg: %w", err)
+	}
+	if seen <= int64(limits.Events) {
+		if _, err := tx.Exec(ctx, savePendingEventQuery, event.SensorSerialNumber, event.Timestamp, event.Payload,
+			jsonbOrNull(event.Values), event.IdempotencyKey); err != nil {
+			return fmt.Errorf("unable to save pending event to pg: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit pending event: %w", err)
+	}
+	return nil
+}
+
+const getPendingSensorsQuery = `
+	SELECT p.serial_number, p.first_seen, p.last_seen, p.seen,
+	       (SELECT count(*) FROM pending_sensor_events e WHERE e.sensor_serial_number = p.serial_number)
+	FROM pending_sensors p
+	ORDER BY p.first_seen, p.serial_number`
+
+func pendingSensorScanTargets(pending *domain.PendingSensor) []any {
+	return []any{&pending.SerialNumber, &pending.FirstSeen, &pending.LastSeen, &pending.Seen, &pending.Buffered}
+}
+
+func (r *PendingSensorRepository) GetPendingSensors(ctx context.Context) (_ []domain.PendingSensor, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.PendingSensorRepository.GetPendingSensors")
+	defer span.End()
+	defer metrics.ObserveQuery("pending_sensor", "GetPendingSensors", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.PendingSensorRepository.GetPendingSensors", &err)
+
+	rows, err := r.pool.Query(ctx, getPendingSensorsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("can't get pending sensors: %w", err)
+	}
+
+	defer rows.Close()
+
+	result := make([]domain.PendingSensor, 0)
+	for rows.Next() {
+		pending := domain.PendingSensor{}
+		if err := rows.Scan(pendingSensorScanTargets(&pending)...); err != nil {
+			return nil, fmt.Errorf("can't scan pending sensors: %w", err)
+		}
+
+		result = append(result, pending)
+	}
+
+	return result, rows.Err()
+}
+
+const getPendingSensorBySerialNumberQuery = `
+	SELECT p.serial_number, p.first_seen, p.last_seen, p.seen,
+	       (SELECT count(*) FROM pending_sensor_events e WHERE e.sensor_serial_number = p.serial_number)
+	FROM pending_sensors p
+	WHERE p.serial_number = $1`
+
+const getPendingEventsQuery = `
+	SELECT timestamp, payload, channel_values, idempotency_key
+	FROM pending_sensor_events
+	WHERE sensor_serial_number = $1
+	ORDER BY id`
+
+func (r *PendingSensorRepository) GetPendingSensorBySerialNumber(ctx context.Context, sn string) (_ *domain.PendingSensor, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.PendingSensorRepository.GetPendingSensorBySerialNumber")
+	defer span.End()
+	defer metrics.ObserveQuery("pending_sensor", "GetPendingSensorBySerialNumber", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.PendingSensorRepository.GetPendingSensorBySerialNumber", &err,
+		usecase.ErrPendingSensorNotFound)
+
+	pending := &domain.PendingSensor{}
+	if err := r.pool.QueryRow(ctx, getPendingSensorBySerialNumberQuery, sn).Scan(pendingSensorScanTargets(pending)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrPendingSensorNotFound
+		}
+		return nil, fmt.Errorf("unable to find pending sensor by serial number: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, getPendingEventsQuery, sn)
+	if err != nil {
+		return nil, fmt.Errorf("can't get pending events: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		event := domain.Event{SensorSerialNumber: sn}
+		if err := rows.Scan(&event.Timestamp, &event.Payload, &event.Values, &event.IdempotencyKey); err != nil {
+			return nil, fmt.Errorf("can't scan pending events: %w", err)
+		}
+
+		pending.Events = append(pending.Events, event)
+	}
+
+	return pending, rows.Err()
+}
+
+const deletePendingSensorQuery = `DELETE FROM pending_sensors WHERE serial_number = $1`
+
+func (r *PendingSensorRepository) DeletePendingSensor(ctx context.Context, sn string) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.PendingSensorRepository.DeletePendingSensor")
+	defer span.End()
+	defer metrics.ObserveQuery("pending_sensor", "DeletePendingSensor", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.PendingSensorRepository.DeletePendingSensor", &err,
+		usecase.ErrPendingSensorNotFound)
+
+	tag, err := r.pool.Exec(ctx, deletePendingSensorQuery, sn)
+	if err != nil {
+		return fmt.Errorf("unable to delete pending sensor from pg: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrPendingSensorNotFound
+	}
+	return nil
+}
+
+const deletePendingEventQuery = `DELETE FROM pending_sensor_events WHERE sensor_serial_number = $1 AND idempotency_key = $2`
+
+func (r *PendingSensorRepository) DeletePendingEvent(ctx context.Context, sn string, idempotencyKey string) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.PendingSensorRepository.DeletePendingEvent")
+	defer span.End()
+	defer metrics.ObserveQuery("pending_sensor", "DeletePendingEvent", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.PendingSensorRepository.DeletePendingEvent", &err)
+
+	if _, err := r.pool.Exec(ctx, deletePendingEventQuery, sn, idempotencyKey); err != nil {
+		return fmt.Errorf("unable to delete pending event from pg: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type PendingSensorTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *PendingSensorRepository
+}
+
+func (suite *PendingSensorTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewPendingSensorRepository(suite.testDbInstance)
+}
+
+func (suite *PendingSensorTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *PendingSensorTestSuite) TestPendingSensorRepository_SavePendingEvent() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sn := "7123456789"
+	limits := usecase.PendingLimits{Sensors: 100, Events: 2}
+	now := time.Now().Truncate(time.Microsecond).UTC()
+
+	for i := range 3 {
+		event := &domain.Event{
+			SensorSerialNumber: sn,
+			Timestamp:          now.Add(time.Duration(i) * time.Second),
+			Payload:            int64(i),
+			Values:             map[string]int64{"temperature": int64(i)},
+			IdempotencyKey:     "key",
+		}
+		require.NoError(suite.T(), suite.repo.SavePendingEvent(ctx, event, limits))
+	}
+
+	pending, err := suite.repo.GetPendingSensorBySerialNumber(ctx, sn)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), now, pending.FirstSeen)
+	assert.Equal(suite.T(), now.Add(2*time.Second), pending.LastSeen)
+	assert.Equal(suite.T(), int64(3), pending.Seen)
+	assert.Equal(suite.T(), int64(2), pending.Buffered)
+	require.Len(suite.T(), pending.Events, 2)
+	assert.Equal(suite.T(), domain.Event{
+		SensorSerialNumber: sn,
+		Timestamp:          now.Add(time.Second),
+		Payload:            1,
+		Values:             map[string]int64{"temperature": 1},
+		IdempotencyKey:     "key",
+	}, pending.Events[1])
+
+	pendingSensors, err := suite.repo.GetPendingSensors(ctx)
+	require.NoError(suite.T(), err)
+	full := usecase.PendingLimits{Sensors: len(pendingSensors), Events: 2}
+	err = suite.repo.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: "7123456788", Timestamp: now}, full)
+	assert.ErrorIs(suite.T(), err, usecase.ErrPendingSensorsLimit)
+	assert.NoError(suite.T(), suite.repo.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: sn, Timestamp: now}, full))
+}
+
+func (suite *PendingSensorTestSuite) TestPendingSensorRepository_DeletePendingSensor() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sn := "7987654321"
+	err := suite.repo.SavePendingEvent(ctx, &domain.Event{SensorSerialNumber: sn, Timestamp: time.Now()},
+		usecase.DefaultPendingLimits)
+	require.NoError(suite.T(), err)
+
+	pendingSensors, err := suite.repo.GetPendingSensors(ctx)
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), serialNumbers(pendingSensors), sn)
+
+	require.NoError(suite.T(), suite.repo.DeletePendingSensor(ctx, sn))
+	_, err = suite.repo.GetPendingSensorBySerialNumber(ctx, sn)
+	assert.ErrorIs(suite.T(), err, usecase.ErrPendingSensorNotFound)
+	assert.ErrorIs(suite.T(), suite.repo.DeletePendingSensor(ctx, sn), usecase.ErrPendingSensorNotFound)
+}
+
+func (suite *PendingSensorTestSuite) TestPendingSensorRepository_DeletePendingEvent() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sn := "7555555555"
+	for i, key := range []string{"key-1", "key-2"} {
+		event := &domain.Event{SensorSerialNumber: sn, Timestamp: time.Now(), Payload: int64(i), IdempotencyKey: key}
+		require.NoError(suite.T(), suite.repo.SavePendingEvent(ctx, event, usecase.DefaultPendingLimits))
+	}
+	require.NoError(suite.T(), suite.repo.DeletePendingEvent(ctx, sn, "key-1"))
+
+	pending, err := suite.repo.GetPendingSensorBySerialNumber(ctx, sn)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), pending.Seen)
+	assert.Equal(suite.T(), int64(1), pending.Buffered)
+	require.Len(suite.T(), pending.Events, 1)
+	assert.Equal(suite.T(), "key-2", pending.Events[0].IdempotencyKey)
+
+	// already deleted ones are not an error
+	assert.NoError(suite.T(), suite.repo.DeletePendingEvent(ctx, sn, "key-1"))
+	assert.NoError(suite.T(), suite.repo.DeletePendingEvent(ctx, "7000000000", "key-1"))
+}
+
+func serialNumbers(pendingSensors []domain.PendingSensor) []string {
+	res := make([]string, 0, len(pendingSensors))
+	for _, p := range pendingSensors {
+		res = append(res, p.SerialNumber)
+	}
+	return res
+}
+
+func TestPendingSensorTestSuite(t *testing.T) {
+	suite.Run(t, new(PendingSensorTestSuite))
+}
@@ -5,7 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
 	"homework/internal/usecase"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -13,44 +19,162 @@ import (
 )
 
 type SensorRepository struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	logger *slog.Logger
 }
 
-func NewSensorRepository(pool *pgxpool.Pool) *SensorRepository {
+func NewSensorRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *SensorRepository {
+	o := pgoptions.Apply(options)
 	return &SensorRepository{
-		pool: pool,
+		pool:   pool,
+		logger: o.Logger,
 	}
 }
 
+// jsonbOrNull - stores the empty channel data of the single-value sensors and the empty calibration as NULL
+func jsonbOrNull[T []domain.Channel | map[string]int64 | domain.Calibration](v T) any {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+func sensorScanTargets(sensor *domain.Sensor) []any {
+	return []any{&sensor.ID, &sensor.SerialNumber, &sensor.Type, &sensor.CurrentState, &sensor.Description,
+		&sensor.IsActive, &sensor.RegisteredAt, &sensor.LastActivity, &sensor.Channels, &sensor.CurrentValues, &sensor.Calibration, &sensor.LocationID, &sensor.Labels, &sensor.Version}
+}
+
+// labelsOrEmpty - sensors without labels keep an empty object to match the `!key` and `key!=value` requirements
+func labelsOrEmpty(sensor *domain.Sensor) map[string]string {
+	if sensor.Labels == nil {
+		return map[string]string{}
+	}
+	return sensor.Labels
+}
+
+// locationOrNull - stores the sensors placed nowhere as NULL
+func locationOrNull(sensor *domain.Sensor) any {
+	if sensor.LocationID == 0 {
+		return nil
+	}
+	return sensor.LocationID
+}
+
+// saveSensorQuery - compare-and-swap on the version: an existing sensor is only updated if it still has the version
+// the caller has read, a new one is expected with the version 0 that no stored sensor has
 const saveSensorQuery = `
-	INSERT INTO sensors (serial_number, type, current_state, description, is_active, registered_at, last_activity) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	INSERT INTO sensors (serial_number, type, current_state, description, is_active, registered_at, last_activity,
+	                     channels, current_values, calibration, location_id, labels) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	ON CONFLICT (serial_number) DO UPDATE 
 	  SET serial_number = excluded.serial_number, 
 		  type = excluded.type,
 		  current_state = excluded.current_state,
 		  description = excluded.description,
 		  is_active = excluded.is_active,
-		  last_activity = excluded.last_activity
-	RETURNING id`
+		  last_activity = excluded.last_activity,
+		  channels = excluded.channels,
+		  current_values = excluded.current_values,
+		  calibration = excluded.calibration,
+		  location_id = excluded.location_id,
+		  labels = excluded.labels,
+		  version = sensors.version + 1
+	  WHERE sensors.version = $13
+	RETURNING id, version`
+
+func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorRepository.SaveSensor")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor", "SaveSensor", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorRepository.SaveSensor", &err, usecase.ErrSensorVersionConflict)
 
-func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor) error {
 	row := r.pool.QueryRow(ctx, saveSensorQuery, sensor.SerialNumber, sensor.Type, sensor.CurrentState,
-		sensor.Description, sensor.IsActive, time.Now(), sensor.LastActivity)
+		sensor.Description, sensor.IsActive, time.Now(), sensor.LastActivity,
+		jsonbOrNull(sensor.Channels), jsonbOrNull(sensor.CurrentValues), jsonbOrNull(sensor.Calibration),
+		locationOrNull(sensor), labelsOrEmpty(sensor), sensor.Version)
 
-	if err := row.Scan(&sensor.ID); err != nil {
+	if err := row.Scan(&sensor.ID, &sensor.Version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return usecase.ErrSensorVersionConflict
+		}
 		return fmt.Errorf("unable to save sensor to pg: %w", err)
 	}
 
 	return nil
 }
 
+const updateSensorStateQuery = `
+	UPDATE sensors
+	SET current_state = $2, current_values = $3, last_activity = $4, version = version + 1
+	WHERE id = $1
+	RETURNING version`
+
+func (r *SensorRepository) UpdateSensorState(ctx context.Context, sensor *domain.Sensor) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorRepository.UpdateSensorState")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor", "UpdateSensorState", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorRepository.UpdateSensorState", &err, usecase.ErrSensorNotFound)
+
+	row := r.pool.QueryRow(ctx, updateSensorStateQuery, sensor.ID, sensor.CurrentState,
+		jsonbOrNull(sensor.CurrentValues), sensor.LastActivity)
+	if err := row.Scan(&sensor.Version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return usecase.ErrSensorNotFound
+		}
+		return fmt.Errorf("unable to update sensor state in pg: %w", err)
+	}
+
+	return nil
+}
+
+// restoreSensorQuery - keeps the ID if it is free and moves the sequence past it, so the IDs assigned later don't collide
+const restoreSensorQuery = `
+	WITH restored AS (
+	    INSERT INTO sensors (id, serial_number, type, current_state, description, is_active, registered_at, last_activity,
+	                         channels, current_values, calibration, location_id, labels, version)
+	    SELECT CASE WHEN $1::bigint > 0 AND NOT EXISTS (SELECT 1 FROM sensors WHERE id = $1::bigint)
+	                THEN $1::bigint ELSE nextval('sensors_id_seq') END,
+	           $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+	    RETURNING id
+	)
+	SELECT restored.id, setval('sensors_id_seq', GREATEST(sensors_id_seq.last_value, restored.id))
+	FROM restored, sensors_id_seq`
+
+// RestoreSensor - saves the sensor as is with its ID if the ID is free, with a new ID otherwise.
+// The serial number must not be registered yet
+func (r *SensorRepository) RestoreSensor(ctx context.Context, sensor *domain.Sensor) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorRepository.RestoreSensor")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor", "RestoreSensor", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorRepository.RestoreSensor", &err)
+
+	if sensor.RegisteredAt.IsZero() {
+		sensor.RegisteredAt = time.Now()
+	}
+	var last int64
+	row := r.pool.QueryRow(ctx, restoreSensorQuery, sensor.ID, sensor.SerialNumber, sensor.Type, sensor.CurrentState,
+		sensor.Description, sensor.IsActive, sensor.RegisteredAt, sensor.LastActivity,
+		jsonbOrNull(sensor.Channels), jsonbOrNull(sensor.CurrentValues), jsonbOrNull(sensor.Calibration),
+		locationOrNull(sensor), labelsOrEmpty(sensor), max(sensor.Version, 1))
+	if err := row.Scan(&sensor.ID, &last); err != nil {
+		return fmt.Errorf("unable to restore sensor to pg: %w", err)
+	}
+
+	return nil
+}
+
 const getSensorsQuery = `
 	SELECT 
-	    id, serial_number, type, current_state, description, is_active, registered_at, last_activity 
+	    id, serial_number, type, current_state, description, is_active, registered_at, last_activity,
+	    channels, current_values, calibration, COALESCE(location_id, 0), NULLIF(labels, '{}'), version
 	FROM sensors`
 
-func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
+func (r *SensorRepository) GetSensors(ctx context.Context) (_ []domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorRepository.GetSensors")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor", "GetSensors", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorRepository.GetSensors", &err)
+
 	rows, err := r.pool.Query(ctx, getSensorsQuery)
 	if err != nil {
 		return nil, fmt.Errorf("can't get sensors: %w", err)
@@ -61,8 +185,61 @@ func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, err
 	var result []domain.Sensor
 	for rows.Next() {
 		sensor := domain.Sensor{}
-		if err := rows.Scan(&sensor.ID, &sensor.SerialNumber, &sensor.Type, &sensor.CurrentState, &sensor.Description,
-			&sensor.IsActive, &sensor.RegisteredAt, &sensor.LastActivity); err != nil {
+		if err := rows.Scan(sensorScanTargets(&sensor)...); err != nil {
+			return nil, fmt.Errorf("can't scan sensors: %w", err)
+		}
+
+		result = append(result, sensor)
+	}
+
+	return result, nil
+}
+
+// selectorCondition - WHERE clause of the selector, every requirement is served by the GIN index on labels
+func selectorCondition(selector domain.Selector) (string, []any) {
+	conditions := make([]string, 0, len(selector))
+	args := make([]any, 0, len(selector))
+	for _, req := range selector {
+		n := len(args) + 1
+		switch req.Operator {
+		case domain.SelectorEquals:
+			conditions = append(conditions, fmt.Sprintf("labels @> $%d", n))
+			args = append(args, map[string]string{req.Key: req.Value})
+		case domain.SelectorNotEquals:
+			conditions = append(conditions, fmt.Sprintf("NOT labels @> $%d", n))
+			args = append(args, map[string]string{req.Key: req.Value})
+		case domain.SelectorExists:
+			conditions = append(conditions, fmt.Sprintf("labels ? $%d", n))
+			args = append(args, req.Key)
+		case domain.SelectorNotExists:
+			conditions = append(conditions, fmt.Sprintf("NOT labels ? $%d", n))
+			args = append(args, req.Key)
+		}
+	}
+	if len(conditions) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *SensorRepository) GetSensorsBySelector(ctx context.Context, selector domain.Selector) (_ []domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorRepository.GetSensorsBySelector")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor", "GetSensorsBySelector", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorRepository.GetSensorsBySelector", &err)
+
+	condition, args := selectorCondition(selector)
+	rows, err := r.pool.Query(ctx, getSensorsQuery+" WHERE "+condition, args...)
+	if err != nil {
+		return nil, fmt.Errorf("can't get sensors by selector: %w", err)
+	}
+
+	defer rows.Close()
+
+	var result []domain.Sensor
+	for rows.Next() {
+		sensor := domain.Sensor{}
+		if err := rows.Scan(sensorScanTargets(&sensor)...); err != nil {
 			return nil, fmt.Errorf("can't scan sensors: %w", err)
 		}
 
@@ -74,16 +251,21 @@ func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, err
 
 const getSensorByIDQuery = `
 	SELECT 
-	    id, serial_number, type, current_state, description, is_active, registered_at, last_activity 
+	    id, serial_number, type, current_state, description, is_active, registered_at, last_activity,
+	    channels, current_values, calibration, COALESCE(location_id, 0), NULLIF(labels, '{}'), version
 	FROM sensors 
 	WHERE id = $1`
 
-func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error) {
+func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (_ *domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorRepository.GetSensorByID")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor", "GetSensorByID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorRepository.GetSensorByID", &err, usecase.ErrSensorNotFound)
+
 	row := r.pool.QueryRow(ctx, getSensorByIDQuery, id)
 
 	sensor := &domain.Sensor{}
-	if err := row.Scan(&sensor.ID, &sensor.SerialNumber, &sensor.Type, &sensor.CurrentState, &sensor.Description,
-		&sensor.IsActive, &sensor.RegisteredAt, &sensor.LastActivity); err != nil {
+	if err := row.Scan(sensorScanTargets(sensor)...); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, usecase.ErrSensorNotFound
 		}
@@ -95,16 +277,21 @@ func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain
 
 const getSensorBySerialNumberQuery = `
 	SELECT 
-	    id, serial_number, type, current_state, description, is_active, registered_at, last_activity 
+	    id, serial_number, type, current_state, description, is_active, registered_at, last_activity,
+	    channels, current_values, calibration, COALESCE(location_id, 0), NULLIF(labels, '{}'), version
 	FROM sensors 
 	WHERE serial_number = $1`
 
-func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error) {
+func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn string) (_ *domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorRepository.GetSensorBySerialNumber")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor", "GetSensorBySerialNumber", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorRepository.GetSensorBySerialNumber", &err, usecase.ErrSensorNotFound)
+
 	row := r.pool.QueryRow(ctx, getSensorBySerialNumberQuery, sn)
 
 	sensor := &domain.Sensor{}
-	if err := row.Scan(&sensor.ID, &sensor.SerialNumber, &sensor.Type, &sensor.CurrentState, &sensor.Description,
-		&sensor.IsActive, &sensor.RegisteredAt, &sensor.LastActivity); err != nil {
+	if err := row.Scan(sensorScanTargets(sensor)...); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, usecase.ErrSensorNotFound
 		}
@@ -3,12 +3,14 @@ package postgres
 import (
 	"context"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -65,6 +67,7 @@ func (suite *SensorTestSuite) TestSensorRepository_SaveSensor() {
 		IsActive:     false,
 		RegisteredAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
 		LastActivity: time.Now().Truncate(time.Microsecond).In(time.UTC),
+		Version:      sensor.Version,
 	}
 
 	// update old sensor
@@ -78,6 +81,55 @@ func (suite *SensorTestSuite) TestSensorRepository_SaveSensor() {
 
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), updatedSensor, *sensor)
+	assert.Equal(suite.T(), int64(2), sensor.Version)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_SaveSensor_VersionConflict() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sensor := &domain.Sensor{SerialNumber: "1234509876", Type: domain.SensorTypeADC, Description: "first"}
+	require.NoError(suite.T(), suite.repo.SaveSensor(ctx, sensor))
+	stale := *sensor
+
+	sensor.Labels = map[string]string{"kind": "adc"}
+	require.NoError(suite.T(), suite.repo.SaveSensor(ctx, sensor))
+	stale.Description = "stale"
+	assert.ErrorIs(suite.T(), suite.repo.SaveSensor(ctx, &stale), usecase.ErrSensorVersionConflict)
+	// a new sensor must not replace the registered one
+	duplicate := &domain.Sensor{SerialNumber: "1234509876", Type: domain.SensorTypeADC}
+	assert.ErrorIs(suite.T(), suite.repo.SaveSensor(ctx, duplicate), usecase.ErrSensorVersionConflict)
+
+	actual, err := suite.repo.GetSensorByID(ctx, sensor.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "first", actual.Description)
+	assert.Equal(suite.T(), map[string]string{"kind": "adc"}, actual.Labels)
+	assert.Equal(suite.T(), int64(2), actual.Version)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_UpdateSensorState() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sensor := &domain.Sensor{SerialNumber: "1234567809", Type: domain.SensorTypeADC}
+	require.NoError(suite.T(), suite.repo.SaveSensor(ctx, sensor))
+	stale := *sensor
+	sensor.Labels = map[string]string{"kind": "adc"}
+	require.NoError(suite.T(), suite.repo.SaveSensor(ctx, sensor))
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	stale.CurrentState, stale.LastActivity = 42, now
+	require.NoError(suite.T(), suite.repo.UpdateSensorState(ctx, &stale))
+	assert.Equal(suite.T(), int64(3), stale.Version)
+
+	actual, err := suite.repo.GetSensorByID(ctx, sensor.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(42), actual.CurrentState)
+	assert.Equal(suite.T(), now, actual.LastActivity)
+	assert.Equal(suite.T(), map[string]string{"kind": "adc"}, actual.Labels)
+	assert.Equal(suite.T(), int64(3), actual.Version)
+
+	assert.ErrorIs(suite.T(), suite.repo.UpdateSensorState(ctx, &domain.Sensor{ID: -1}), usecase.ErrSensorNotFound)
 }
 
 func (suite *SensorTestSuite) TestSensorRepository_GetSensors() {
@@ -169,6 +221,70 @@ func (suite *SensorTestSuite) TestSensorRepository_GetSensorBySerialNumber() {
 	assert.Equal(suite.T(), newSensor, *sensor)
 }
 
+func (suite *SensorTestSuite) TestSensorRepository_GetSensorsBySelector() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	leak := domain.Sensor{
+		SerialNumber: "3987654321",
+		Type:         domain.SensorTypeContactClosure,
+		Description:  "test_desc_6",
+		LastActivity: time.Now().Truncate(time.Microsecond).In(time.UTC),
+		Labels:       map[string]string{"kind": "leak", "floor": "2"},
+	}
+	basement := domain.Sensor{
+		SerialNumber: "4987654321",
+		Type:         domain.SensorTypeContactClosure,
+		Description:  "test_desc_7",
+		LastActivity: time.Now().Truncate(time.Microsecond).In(time.UTC),
+		Labels:       map[string]string{"kind": "leak", "floor": "0"},
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &leak))
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &basement))
+
+	selector, err := domain.ParseSelector("kind=leak,floor!=0")
+	assert.Nil(suite.T(), err)
+	sensors, err := suite.repo.GetSensorsBySelector(ctx, selector)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), sensors, 1)
+	assert.Equal(suite.T(), leak.Labels, sensors[0].Labels)
+
+	selector, err = domain.ParseSelector("kind,!zone")
+	assert.Nil(suite.T(), err)
+	sensors, err = suite.repo.GetSensorsBySelector(ctx, selector)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), sensors, 2)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_RestoreSensor() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sensor := domain.Sensor{
+		ID:           1000,
+		SerialNumber: "5987654321",
+		Type:         domain.SensorTypeADC,
+		CurrentState: 3,
+		Description:  "test_desc_8",
+		IsActive:     true,
+		RegisteredAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		LastActivity: time.Now().Truncate(time.Microsecond).In(time.UTC),
+		Version:      3,
+	}
+	assert.Nil(suite.T(), suite.repo.RestoreSensor(ctx, &sensor))
+	assert.Equal(suite.T(), int64(1000), sensor.ID)
+
+	actual, err := suite.repo.GetSensorByID(ctx, 1000)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), sensor, *actual, "registered_at and version are kept")
+
+	taken := domain.Sensor{ID: 1000, SerialNumber: "6987654321", Type: domain.SensorTypeContactClosure, LastActivity: sensor.LastActivity}
+	assert.Nil(suite.T(), suite.repo.RestoreSensor(ctx, &taken))
+	assert.Equal(suite.T(), int64(1001), taken.ID)
+
+	assert.NotNil(suite.T(), suite.repo.RestoreSensor(ctx, &domain.Sensor{SerialNumber: "5987654321", Type: domain.SensorTypeADC}))
+}
+
 func TestSensorTestSuite(t *testing.T) {
 	suite.Run(t, new(SensorTestSuite))
 }
@@ -2,6 +2,7 @@ package inmemory
 
 import (
 	"context"
+	"fmt"
 	"homework/internal/domain"
 	"homework/internal/usecase"
 	"sync"
@@ -111,3 +112,20 @@ func (sr *SubscriptionRepository[T]) GetBroadcastHandleById(ctx context.Context,
 		Ch: in,
 	}, nil
 }
+
+// Ping - makes sure the subscribers storage is not stuck, e.g. by a broadcast to a subscriber that does not read
+func (sr *SubscriptionRepository[T]) Ping(ctx context.Context) error {
+	locked := make(chan struct{})
+	go func() {
+		sr.mu.Lock()
+		sr.mu.Unlock() //nolint: staticcheck // empty critical section is intended
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("subscriptions storage is locked: %w", ctx.Err())
+	}
+}
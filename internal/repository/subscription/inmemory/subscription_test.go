@@ -288,3 +288,33 @@ func TestSubscriptionRepository_Unsubscribe(t *testing.T) {
 		}
 	})
 }
+
+func TestSubscriptionRepository_Ping(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		sr := NewSubscriptionRepository[domain.Event]()
+		assert.NoError(t, sr.Ping(context.Background()))
+	})
+
+	t.Run("fail, broadcast is stuck on a subscriber that does not read", func(t *testing.T) {
+		sr := NewSubscriptionRepository[domain.Event]()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		sub, err := sr.Subscribe(ctx, 1)
+		assert.NoError(t, err)
+		broadcast, err := sr.GetBroadcastHandleById(ctx, 1)
+		assert.NoError(t, err)
+
+		broadcast.Ch <- domain.Event{SensorID: 1} // Fills the subscriber buffer
+		broadcast.Ch <- domain.Event{SensorID: 1} // Blocks the broadcast holding the lock
+		time.Sleep(10 * time.Millisecond)         // Let the broadcast goroutine take the lock
+
+		pingCtx, pingCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer pingCancel()
+		assert.ErrorIs(t, sr.Ping(pingCtx), context.DeadlineExceeded)
+
+		<-sub.SubscriptionReadHandle.Ch
+		<-sub.SubscriptionReadHandle.Ch
+		close(broadcast.Ch)
+	})
+}
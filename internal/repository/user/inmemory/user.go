@@ -5,6 +5,7 @@ import (
 	"errors"
 	"homework/internal/domain"
 	"homework/internal/usecase"
+	"sort"
 	"sync"
 )
 
@@ -41,6 +42,43 @@ func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) error
 	return nil
 }
 
+// RestoreUser - saves the user with its ID if the ID is free, with a new ID otherwise
+func (r *UserRepository) RestoreUser(ctx context.Context, user *domain.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("got nil user at RestoreUser()")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, taken := r.storage[user.ID]; taken || user.ID <= 0 {
+		r.lastId++
+		user.ID = r.lastId
+	}
+	r.lastId = max(r.lastId, user.ID)
+	r.storage[user.ID] = *user
+
+	return nil
+}
+
+func (r *UserRepository) GetUsers(ctx context.Context) ([]domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	res := make([]domain.User, 0, len(r.storage))
+	for _, v := range r.storage {
+		res = append(res, v)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
 func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserRepository_SaveUser(t *testing.T) {
@@ -70,6 +71,33 @@ func TestUserRepository_SaveUser(t *testing.T) {
 	})
 }
 
+func TestUserRepository_RestoreUser(t *testing.T) {
+	t.Run("err, user is nil", func(t *testing.T) {
+		assert.Error(t, NewUserRepository().RestoreUser(context.Background(), nil))
+	})
+
+	t.Run("ok, ID kept if free, new ID otherwise", func(t *testing.T) {
+		ur := NewUserRepository()
+		ctx := context.Background()
+
+		restored := &domain.User{ID: 10, Name: "restored"}
+		require.NoError(t, ur.RestoreUser(ctx, restored))
+		assert.Equal(t, int64(10), restored.ID)
+
+		remapped := &domain.User{ID: 10, Name: "remapped"}
+		require.NoError(t, ur.RestoreUser(ctx, remapped))
+		assert.Equal(t, int64(11), remapped.ID)
+
+		saved := &domain.User{Name: "saved"}
+		require.NoError(t, ur.SaveUser(ctx, saved))
+		assert.Equal(t, int64(12), saved.ID, "the IDs assigned later don't collide")
+
+		users, err := ur.GetUsers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.User{*restored, *remapped, *saved}, users)
+	})
+}
+
 func FuzzUserRepository_SaveUser(f *testing.F) {
 	testcases := []string{"User Name", "John Doe", "null", ""}
 	for _, tc := range testcases {
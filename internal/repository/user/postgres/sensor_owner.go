@@ -4,23 +4,37 @@ import (
 	"context"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
+	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type SensorOwnerRepository struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	logger *slog.Logger
 }
 
-func NewSensorOwnerRepository(pool *pgxpool.Pool) *SensorOwnerRepository {
+func NewSensorOwnerRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *SensorOwnerRepository {
+	o := pgoptions.Apply(options)
 	return &SensorOwnerRepository{
-		pool,
+		pool:   pool,
+		logger: o.Logger,
 	}
 }
 
 const saveSensorOwnerQuery = `INSERT INTO sensors_users (sensor_id, user_id) VALUES ($1, $2)`
 
-func (r *SensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error {
+func (r *SensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorOwnerRepository.SaveSensorOwner")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor_owner", "SaveSensorOwner", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorOwnerRepository.SaveSensorOwner", &err)
+
 	if _, err := r.pool.Exec(ctx, saveSensorOwnerQuery, sensorOwner.SensorID, sensorOwner.UserID); err != nil {
 		return fmt.Errorf("unable to save sensor owner to pg: %w", err)
 	}
@@ -29,7 +43,12 @@ func (r *SensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner
 
 const getSensorsByUserIDQuery = `SELECT sensor_id, user_id FROM sensors_users WHERE user_id = $1`
 
-func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error) {
+func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID int64) (_ []domain.SensorOwner, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.SensorOwnerRepository.GetSensorsByUserID")
+	defer span.End()
+	defer metrics.ObserveQuery("sensor_owner", "GetSensorsByUserID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.SensorOwnerRepository.GetSensorsByUserID", &err)
+
 	rows, err := r.pool.Query(ctx, getSensorsByUserIDQuery, userID)
 	if err != nil {
 		return nil, fmt.Errorf("can't get sensors: %w", err)
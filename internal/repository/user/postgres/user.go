@@ -5,25 +5,39 @@ import (
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/logging"
+	"homework/internal/metrics"
+	"homework/internal/repository/pgoptions"
+	"homework/internal/tracing"
 	"homework/internal/usecase"
+	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserRepository struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	logger *slog.Logger
 }
 
-func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
+func NewUserRepository(pool *pgxpool.Pool, options ...pgoptions.Option) *UserRepository {
+	o := pgoptions.Apply(options)
 	return &UserRepository{
-		pool: pool,
+		pool:   pool,
+		logger: o.Logger,
 	}
 }
 
 const saveUserQuery = `INSERT INTO users (name) VALUES ($1) RETURNING id`
 
-func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) error {
+func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.UserRepository.SaveUser")
+	defer span.End()
+	defer metrics.ObserveQuery("user", "SaveUser", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.UserRepository.SaveUser", &err)
+
 	row := r.pool.QueryRow(ctx, saveUserQuery, user.Name)
 
 	if err := row.Scan(&user.ID); err != nil {
@@ -33,9 +47,66 @@ func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) error
 	return nil
 }
 
+// restoreUserQuery - keeps the ID if it is free and moves the sequence past it, so the IDs assigned later don't collide
+const restoreUserQuery = `
+	WITH restored AS (
+	    INSERT INTO users (id, name)
+	    SELECT CASE WHEN $1::bigint > 0 AND NOT EXISTS (SELECT 1 FROM users WHERE id = $1::bigint) 
+	                THEN $1::bigint ELSE nextval('users_id_seq') END, $2
+	    RETURNING id
+	)
+	SELECT restored.id, setval('users_id_seq', GREATEST(users_id_seq.last_value, restored.id))
+	FROM restored, users_id_seq`
+
+// RestoreUser - saves the user with its ID if the ID is free, with a new ID otherwise
+func (r *UserRepository) RestoreUser(ctx context.Context, user *domain.User) (err error) {
+	ctx, span := tracing.Start(ctx, "postgres.UserRepository.RestoreUser")
+	defer span.End()
+	defer metrics.ObserveQuery("user", "RestoreUser", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.UserRepository.RestoreUser", &err)
+
+	var last int64
+	if err := r.pool.QueryRow(ctx, restoreUserQuery, user.ID, user.Name).Scan(&user.ID, &last); err != nil {
+		return fmt.Errorf("unable to restore user to pg: %w", err)
+	}
+
+	return nil
+}
+
+const getUsersQuery = `SELECT id, name FROM users ORDER BY id`
+
+func (r *UserRepository) GetUsers(ctx context.Context) (_ []domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.UserRepository.GetUsers")
+	defer span.End()
+	defer metrics.ObserveQuery("user", "GetUsers", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.UserRepository.GetUsers", &err)
+
+	rows, err := r.pool.Query(ctx, getUsersQuery)
+	if err != nil {
+		return nil, fmt.Errorf("can't get users: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.User
+	for rows.Next() {
+		user := domain.User{}
+		if err := rows.Scan(&user.ID, &user.Name); err != nil {
+			return nil, fmt.Errorf("can't scan users: %w", err)
+		}
+		result = append(result, user)
+	}
+
+	return result, rows.Err()
+}
+
 const getUserByIDQuery = `SELECT id, name FROM users WHERE id = $1`
 
-func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
+func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "postgres.UserRepository.GetUserByID")
+	defer span.End()
+	defer metrics.ObserveQuery("user", "GetUserByID", time.Now())
+	defer logging.OnError(ctx, r.logger, "postgres.UserRepository.GetUserByID", &err, usecase.ErrUserNotFound)
+
 	row := r.pool.QueryRow(ctx, getUserByIDQuery, id)
 
 	user := &domain.User{}
@@ -61,6 +61,27 @@ func (suite *UserTestSuite) TestUserRepository_GetUserByID() {
 	assert.Equal(suite.T(), name, user.Name)
 }
 
+func (suite *UserTestSuite) TestUserRepository_RestoreUser() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	restored := &domain.User{ID: 1000, Name: "restored"}
+	assert.Nil(suite.T(), suite.repo.RestoreUser(ctx, restored))
+	assert.Equal(suite.T(), int64(1000), restored.ID)
+
+	remapped := &domain.User{ID: 1000, Name: "remapped"}
+	assert.Nil(suite.T(), suite.repo.RestoreUser(ctx, remapped))
+	assert.Equal(suite.T(), int64(1001), remapped.ID)
+
+	saved := &domain.User{Name: "saved"}
+	assert.Nil(suite.T(), suite.repo.SaveUser(ctx, saved))
+	assert.Equal(suite.T(), int64(1002), saved.ID, "the IDs assigned later don't collide")
+
+	users, err := suite.repo.GetUsers(ctx)
+	assert.Nil(suite.T(), err)
+	assert.Subset(suite.T(), users, []domain.User{*restored, *remapped, *saved})
+}
+
 func TestUserTestSuite(t *testing.T) {
 	suite.Run(t, new(UserTestSuite))
 }
@@ -0,0 +1,62 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencySummary - percentiles of the latencies recorded so far
+type LatencySummary struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func (s LatencySummary) String() string {
+	if s.Count == 0 {
+		return "no deliveries"
+	}
+	return fmt.Sprintf("n=%d p50=%v p90=%v p99=%v max=%v", s.Count,
+		s.P50.Round(time.Microsecond), s.P90.Round(time.Microsecond), s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+}
+
+// LatencyRecorder - latencies of the deliveries, safe for concurrent use
+type LatencyRecorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+}
+
+func (r *LatencyRecorder) Record(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, d)
+}
+
+// Summary - nearest-rank percentiles of the recorded latencies
+func (r *LatencyRecorder) Summary() LatencySummary {
+	r.mu.Lock()
+	sorted := make([]time.Duration, len(r.latencies))
+	copy(sorted, r.latencies)
+	r.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return LatencySummary{}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return sorted[max(rank, 1)-1]
+	}
+	return LatencySummary{
+		Count: len(sorted),
+		P50:   percentile(50),
+		P90:   percentile(90),
+		P99:   percentile(99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,30 @@
+package simulator
+
+import (
+	"context"
+	"homework/pkg/client"
+)
+
+// Publisher - transport the simulated events are pushed with
+type Publisher interface {
+	Publish(ctx context.Context, event client.EventToPush) error
+	Close() error
+}
+
+// HTTPPublisher - pushes the events with POST /api/events
+type HTTPPublisher struct {
+	client *client.Client
+}
+
+func NewHTTPPublisher(c *client.Client) *HTTPPublisher {
+	return &HTTPPublisher{client: c}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, event client.EventToPush) error {
+	_, err := p.client.PushEvent(ctx, event)
+	return err
+}
+
+func (p *HTTPPublisher) Close() error {
+	return nil
+}
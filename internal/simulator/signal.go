@@ -0,0 +1,150 @@
+package simulator
+
+import (
+	"fmt"
+	"homework/internal/domain"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const adcMax = 4095 // 12-bit ADC
+
+// Signal - readings of a virtual sensor, Next returns the raw payload dt after the previous reading
+type Signal interface {
+	Next(dt time.Duration) int64
+}
+
+// NewSignal - plausible signal of the sensor type
+func NewSignal(t domain.SensorType, rng *rand.Rand) (Signal, error) {
+	info, ok := domain.LookupSensorType(t)
+	if !ok {
+		return nil, fmt.Errorf("unknown sensor type %q", t)
+	}
+	switch t {
+	case domain.SensorTypeContactClosure:
+		// a door or a window: opened a few times an hour for a minute or so
+		return &poissonSwitch{rng: rng, onRate: 1.0 / 600, offRate: 1.0 / 60}, nil
+	case domain.SensorTypeMotion:
+		// short bursts of motion every couple of minutes
+		return &poissonSwitch{rng: rng, onRate: 1.0 / 120, offRate: 1.0 / 10}, nil
+	case domain.SensorTypeADC:
+		return &randomWalk{rng: rng, value: rng.Float64() * adcMax, step: 20, min: 0, max: adcMax}, nil
+	case domain.SensorTypeTemperature:
+		return newMeanReverting(rng, info, 21, 0.5, 0.02), nil
+	case domain.SensorTypeHumidity:
+		return newMeanReverting(rng, info, 45, 2, 0.01), nil
+	case domain.SensorTypePowerMeter:
+		return &spiky{
+			base:      newMeanReverting(rng, info, 300, 50, 0.05),
+			rng:       rng,
+			spikeRate: 1.0 / 300,
+			spike:     rawOf(info, 2000),
+		}, nil
+	}
+	return nil, fmt.Errorf("no signal model for sensor type %q", t)
+}
+
+// rawOf - raw payload of the value in the units of the sensor type
+func rawOf(info domain.SensorTypeInfo, value float64) float64 {
+	return (value - info.Offset) / info.Scale
+}
+
+// occurred - whether an event of the Poisson process with the rate (per second) occurs within dt
+func occurred(rng *rand.Rand, rate float64, dt time.Duration) bool {
+	return rng.Float64() < 1-math.Exp(-rate*dt.Seconds())
+}
+
+// poissonSwitch - on/off state switched by Poisson processes with the given rates (per second)
+type poissonSwitch struct {
+	rng     *rand.Rand
+	onRate  float64
+	offRate float64
+	on      bool
+}
+
+func (s *poissonSwitch) Next(dt time.Duration) int64 {
+	rate := s.onRate
+	if s.on {
+		rate = s.offRate
+	}
+	if occurred(s.rng, rate, dt) {
+		s.on = !s.on
+	}
+	if s.on {
+		return 1
+	}
+	return 0
+}
+
+// randomWalk - value moving by a normally distributed step (per second), reflected at the bounds
+type randomWalk struct {
+	rng      *rand.Rand
+	value    float64
+	step     float64
+	min, max float64
+}
+
+func (w *randomWalk) Next(dt time.Duration) int64 {
+	w.value += w.rng.NormFloat64() * w.step * math.Sqrt(dt.Seconds())
+	if w.value < w.min {
+		w.value = 2*w.min - w.value
+	}
+	if w.value > w.max {
+		w.value = 2*w.max - w.value
+	}
+	w.value = math.Max(w.min, math.Min(w.max, w.value))
+	return int64(math.Round(w.value))
+}
+
+// meanReverting - Ornstein-Uhlenbeck process around the mean, i.e. a random walk pulled back to the mean
+// with the strength theta (per second), kept within the range of the sensor type
+type meanReverting struct {
+	rng      *rand.Rand
+	value    float64
+	mean     float64
+	sigma    float64
+	theta    float64
+	min, max float64
+}
+
+// newMeanReverting - the process with the mean and the deviation in the units of the sensor type
+func newMeanReverting(rng *rand.Rand, info domain.SensorTypeInfo, mean, deviation, theta float64) *meanReverting {
+	m := &meanReverting{
+		rng:   rng,
+		mean:  rawOf(info, mean),
+		theta: theta,
+		min:   math.Inf(-1),
+		max:   math.Inf(1),
+	}
+	// the stationary deviation of the process is sigma/sqrt(2*theta)
+	m.sigma = (rawOf(info, mean+deviation) - m.mean) * math.Sqrt(2*theta)
+	m.value = m.mean + rng.NormFloat64()*(rawOf(info, mean+deviation)-m.mean)
+	if info.Range != nil {
+		m.min, m.max = math.Ceil(rawOf(info, info.Range.Min)), math.Floor(rawOf(info, info.Range.Max))
+	}
+	return m
+}
+
+func (m *meanReverting) Next(dt time.Duration) int64 {
+	s := dt.Seconds()
+	m.value += m.theta*(m.mean-m.value)*s + m.sigma*math.Sqrt(s)*m.rng.NormFloat64()
+	m.value = math.Max(m.min, math.Min(m.max, m.value))
+	return int64(math.Round(m.value))
+}
+
+// spiky - base signal with the spikes (e.g. a kettle on the power line) of a single reading
+type spiky struct {
+	base      *meanReverting
+	rng       *rand.Rand
+	spikeRate float64
+	spike     float64
+}
+
+func (s *spiky) Next(dt time.Duration) int64 {
+	value := s.base.Next(dt)
+	if occurred(s.rng, s.spikeRate, dt) {
+		value += int64(s.spike)
+	}
+	return int64(math.Min(float64(value), s.base.max))
+}
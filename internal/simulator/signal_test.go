@@ -0,0 +1,72 @@
+package simulator
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"homework/internal/domain"
+)
+
+func TestNewSignal(t *testing.T) {
+	for _, info := range domain.SensorTypes() {
+		t.Run(string(info.Type), func(t *testing.T) {
+			signal, err := NewSignal(info.Type, rand.New(rand.NewPCG(1, 2)))
+			require.NoError(t, err)
+
+			seen := make(map[int64]struct{})
+			for i := 0; i < 10000; i++ {
+				raw := signal.Next(time.Second)
+				assert.True(t, info.InRange(raw), "%d out of range", raw)
+				seen[raw] = struct{}{}
+			}
+			assert.Greater(t, len(seen), 1, "the signal never changes")
+
+			switch info.Type {
+			case domain.SensorTypeContactClosure, domain.SensorTypeMotion:
+				assert.Len(t, seen, 2)
+				assert.Contains(t, seen, int64(0))
+				assert.Contains(t, seen, int64(1))
+			case domain.SensorTypeADC:
+				for raw := range seen {
+					assert.True(t, raw >= 0 && raw <= adcMax, raw)
+				}
+			}
+		})
+	}
+
+	t.Run("ok, reproducible", func(t *testing.T) {
+		a, err := NewSignal(domain.SensorTypeTemperature, rand.New(rand.NewPCG(42, 0)))
+		require.NoError(t, err)
+		b, err := NewSignal(domain.SensorTypeTemperature, rand.New(rand.NewPCG(42, 0)))
+		require.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			assert.Equal(t, a.Next(time.Second), b.Next(time.Second))
+		}
+	})
+
+	t.Run("fail, unknown type", func(t *testing.T) {
+		_, err := NewSignal("thermostat", rand.New(rand.NewPCG(1, 2)))
+		assert.Error(t, err)
+	})
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	var r LatencyRecorder
+	assert.Equal(t, LatencySummary{}, r.Summary())
+	assert.Equal(t, "no deliveries", r.Summary().String())
+
+	for i := 100; i >= 1; i-- {
+		r.Record(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, LatencySummary{
+		Count: 100,
+		P50:   50 * time.Millisecond,
+		P90:   90 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}, r.Summary())
+}
@@ -0,0 +1,368 @@
+// Package simulator - virtual sensors producing realistic traffic for the controller: plausible signals,
+// dropouts, websocket subscribers and the ingestion to delivery latency
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/pkg/client"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const ( // Defaults
+	DefaultSensorsPerType  = 5
+	DefaultRate            = 1.0
+	DefaultSubscribers     = 10
+	DefaultDropoutRate     = 1.0 / 3600
+	DefaultDropoutDuration = 2 * time.Minute
+	DefaultSerialBase      = 9_000_000_000
+	DefaultReportPeriod    = 10 * time.Second
+	DefaultDrain           = 2 * time.Second
+)
+
+var ErrNoSensors = errors.New("no sensors to simulate")
+
+// Simulator - registers the virtual sensors and pushes their readings until the context is done
+type Simulator struct {
+	client    *client.Client
+	publisher Publisher
+	logger    *slog.Logger
+
+	types           []domain.SensorType
+	sensorsPerType  int
+	rate            float64
+	subscribers     int
+	dropoutRate     float64
+	dropoutDuration time.Duration
+	serialBase      int64
+	seed            uint64
+	duration        time.Duration
+	reportPeriod    time.Duration
+	drain           time.Duration
+
+	pushed    atomic.Int64
+	failed    atomic.Int64
+	dropouts  atomic.Int64
+	delivered atomic.Int64
+	latencies LatencyRecorder
+}
+
+// New - simulator registering the sensors and subscribing with the client and pushing the events
+// with the publisher
+func New(c *client.Client, publisher Publisher, options ...func(*Simulator)) *Simulator {
+	s := &Simulator{
+		client:          c,
+		publisher:       publisher,
+		logger:          slog.Default(),
+		sensorsPerType:  DefaultSensorsPerType,
+		rate:            DefaultRate,
+		subscribers:     DefaultSubscribers,
+		dropoutRate:     DefaultDropoutRate,
+		dropoutDuration: DefaultDropoutDuration,
+		serialBase:      DefaultSerialBase,
+		seed:            uint64(time.Now().UnixNano()),
+		reportPeriod:    DefaultReportPeriod,
+		drain:           DefaultDrain,
+	}
+	for _, info := range domain.SensorTypes() {
+		s.types = append(s.types, info.Type)
+	}
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+// WithSensorTypes - types of the simulated sensors, all the types by default
+func WithSensorTypes(types ...domain.SensorType) func(*Simulator) {
+	return func(s *Simulator) {
+		s.types = types
+	}
+}
+
+// WithSensorsPerType - number of the virtual sensors of each type
+func WithSensorsPerType(n int) func(*Simulator) {
+	return func(s *Simulator) {
+		s.sensorsPerType = n
+	}
+}
+
+// WithRate - readings per second of each sensor
+func WithRate(rate float64) func(*Simulator) {
+	return func(s *Simulator) {
+		s.rate = rate
+	}
+}
+
+// WithSubscribers - number of the websocket subscribers, they are spread over the sensors
+func WithSubscribers(n int) func(*Simulator) {
+	return func(s *Simulator) {
+		s.subscribers = n
+	}
+}
+
+// WithDropouts - how often (per second) a sensor goes silent and for how long on average,
+// long enough dropouts fire the offline triggers of the automations
+func WithDropouts(rate float64, meanDuration time.Duration) func(*Simulator) {
+	return func(s *Simulator) {
+		s.dropoutRate = rate
+		s.dropoutDuration = meanDuration
+	}
+}
+
+// WithSerialBase - serial number of the first sensor, the rest are numbered consecutively
+func WithSerialBase(base int64) func(*Simulator) {
+	return func(s *Simulator) {
+		s.serialBase = base
+	}
+}
+
+// WithSeed - makes the signals reproducible
+func WithSeed(seed uint64) func(*Simulator) {
+	return func(s *Simulator) {
+		s.seed = seed
+	}
+}
+
+// WithDuration - how long the readings are pushed after the sensors are registered, 0 - until the context is done
+func WithDuration(d time.Duration) func(*Simulator) {
+	return func(s *Simulator) {
+		s.duration = d
+	}
+}
+
+// WithReportPeriod - how often the progress is logged, 0 disables the progress
+func WithReportPeriod(period time.Duration) func(*Simulator) {
+	return func(s *Simulator) {
+		s.reportPeriod = period
+	}
+}
+
+// WithDrain - how long the subscribers wait for the deliveries after the last push
+func WithDrain(drain time.Duration) func(*Simulator) {
+	return func(s *Simulator) {
+		s.drain = drain
+	}
+}
+
+func WithLogger(logger *slog.Logger) func(*Simulator) {
+	return func(s *Simulator) {
+		s.logger = logger
+	}
+}
+
+// Report - totals of a run
+type Report struct {
+	Sensors     int
+	Subscribers int
+	Duration    time.Duration
+	Pushed      int64
+	Failed      int64
+	Dropouts    int64
+	Delivered   int64
+	Latency     LatencySummary
+}
+
+func (r Report) String() string {
+	rate := 0.0
+	if r.Duration > 0 {
+		rate = float64(r.Pushed) / r.Duration.Seconds()
+	}
+	return fmt.Sprintf("sensors=%d subscribers=%d duration=%v pushed=%d (%.1f/s) failed=%d dropouts=%d delivered=%d latency: %v",
+		r.Sensors, r.Subscribers, r.Duration.Round(time.Millisecond), r.Pushed, rate, r.Failed, r.Dropouts, r.Delivered, r.Latency)
+}
+
+// virtualSensor - registered sensor with its signal, owned by its push goroutine
+type virtualSensor struct {
+	id     int64
+	serial string
+	signal Signal
+	rng    *rand.Rand
+}
+
+// Run - registers the sensors, subscribes to them and pushes their readings for the duration or until ctx is done,
+// then waits for the last deliveries
+func (s *Simulator) Run(ctx context.Context) (Report, error) {
+	sensors, err := s.register(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	start := time.Now()
+	if s.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.duration)
+		defer cancel()
+	}
+
+	subCtx, stopSubscribers := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopSubscribers()
+	subscribers := s.subscribe(subCtx, sensors, start)
+
+	var wg sync.WaitGroup
+	for _, vs := range sensors {
+		wg.Add(1)
+		go func(vs *virtualSensor) {
+			defer wg.Done()
+			s.push(ctx, vs)
+		}(vs)
+	}
+	if s.reportPeriod > 0 {
+		go s.progress(ctx, start)
+	}
+	wg.Wait()
+	duration := time.Since(start)
+
+	select {
+	case <-time.After(s.drain):
+	case <-subscribers:
+	}
+	stopSubscribers()
+	<-subscribers
+
+	return Report{
+		Sensors:     len(sensors),
+		Subscribers: min(s.subscribers, len(sensors)),
+		Duration:    duration,
+		Pushed:      s.pushed.Load(),
+		Failed:      s.failed.Load(),
+		Dropouts:    s.dropouts.Load(),
+		Delivered:   s.delivered.Load(),
+		Latency:     s.latencies.Summary(),
+	}, nil
+}
+
+// register - registers sensorsPerType sensors of each type, the existing ones with the same serial
+// numbers are reused
+func (s *Simulator) register(ctx context.Context) ([]*virtualSensor, error) {
+	var sensors []*virtualSensor
+	for _, t := range s.types {
+		for i := 0; i < s.sensorsPerType; i++ {
+			n := len(sensors)
+			rng := rand.New(rand.NewPCG(s.seed, uint64(n)))
+			signal, err := NewSignal(t, rng)
+			if err != nil {
+				return nil, err
+			}
+			serial := fmt.Sprintf("%010d", s.serialBase+int64(n))
+			registered, err := s.client.RegisterSensor(ctx, client.SensorToCreate{
+				SerialNumber: serial,
+				Type:         string(t),
+				Description:  fmt.Sprintf("simulated %s #%d", t, i+1),
+				IsActive:     true,
+				Labels:       map[string]string{"simulator": "true", "kind": string(t)},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("can't register sensor %s: %w", serial, err)
+			}
+			sensors = append(sensors, &virtualSensor{id: registered.ID, serial: serial, signal: signal, rng: rng})
+		}
+	}
+	if len(sensors) == 0 {
+		return nil, ErrNoSensors
+	}
+	s.logger.Info("sensors registered", "sensors", len(sensors), "types", len(s.types))
+	return sensors, nil
+}
+
+// subscribe - starts the subscribers spread over the sensors, the returned channel is closed once all of them are done.
+// The events ingested before the start (e.g. the last event sent on subscription) are not measured
+func (s *Simulator) subscribe(ctx context.Context, sensors []*virtualSensor, start time.Time) <-chan struct{} {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < min(s.subscribers, len(sensors)); i++ {
+		vs := sensors[i*len(sensors)/min(s.subscribers, len(sensors))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.client.SubscribeSensor(ctx, vs.id, func(e client.Event) error {
+				if e.Timestamp.Before(start) {
+					return nil
+				}
+				// the timestamp is set on ingestion, so the clocks of the simulator and the server have to be in sync
+				s.latencies.Record(time.Since(e.Timestamp))
+				s.delivered.Add(1)
+				return nil
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				s.logger.Error("subscription failed", "sensor_id", vs.id, "error", err)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// push - pushes the readings of the sensor at the rate, the sensor is silent during its dropouts
+func (s *Simulator) push(ctx context.Context, vs *virtualSensor) {
+	period := time.Duration(float64(time.Second) / s.rate)
+	// the sensors are spread over the period instead of pushing at once
+	select {
+	case <-time.After(time.Duration(vs.rng.Int64N(int64(period)))):
+	case <-ctx.Done():
+		return
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	var silentUntil time.Time
+	for {
+		now := time.Now()
+		payload := vs.signal.Next(period)
+		if now.After(silentUntil) && s.dropoutRate > 0 && occurred(vs.rng, s.dropoutRate, period) {
+			silentUntil = now.Add(time.Duration(vs.rng.ExpFloat64() * float64(s.dropoutDuration)))
+			s.dropouts.Add(1)
+			s.logger.Debug("sensor dropped out", "serial_number", vs.serial, "until", silentUntil)
+		}
+		if now.After(silentUntil) {
+			err := s.publisher.Publish(ctx, client.EventToPush{SensorSerialNumber: vs.serial, Payload: &payload})
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				s.failed.Add(1)
+				s.logger.Debug("push failed", "serial_number", vs.serial, "error", err)
+			default:
+				s.pushed.Add(1)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// progress - logs the totals so far every report period
+func (s *Simulator) progress(ctx context.Context, start time.Time) {
+	ticker := time.NewTicker(s.reportPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			elapsed := time.Since(start)
+			s.logger.Info("progress",
+				"elapsed", elapsed.Round(time.Second),
+				"pushed", s.pushed.Load(),
+				"push_rate", math.Round(float64(s.pushed.Load())/elapsed.Seconds()),
+				"failed", s.failed.Load(),
+				"dropouts", s.dropouts.Load(),
+				"delivered", s.delivered.Load(),
+				"latency", s.latencies.Summary().String(),
+			)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"homework/internal/domain"
+	"homework/internal/testserver"
+	"homework/pkg/client"
+)
+
+// flakyPublisher - fails every other push
+type flakyPublisher struct {
+	Publisher
+	calls atomic.Int64
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, event client.EventToPush) error {
+	if p.calls.Add(1)%2 == 0 {
+		return errors.New("network is down")
+	}
+	return p.Publisher.Publish(ctx, event)
+}
+
+func newTestSimulator(t *testing.T, srv *testserver.TestServer, publisher func(*client.Client) Publisher,
+	options ...func(*Simulator)) *Simulator {
+	c, err := client.New(srv.URL,
+		client.WithHTTPClient(&http.Client{Transport: testserver.SpecTransport(t, nil), Timeout: 5 * time.Second}))
+	require.NoError(t, err)
+	options = append([]func(*Simulator){
+		WithSensorsPerType(2),
+		WithRate(20),
+		WithDuration(time.Second),
+		WithSubscribers(4),
+		WithDropouts(0, 0),
+		WithSeed(1),
+		WithReportPeriod(0),
+		WithDrain(500 * time.Millisecond),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}, options...)
+	return New(c, publisher(c), options...)
+}
+
+func TestSimulator(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		srv := testserver.SetupTestServer()
+		defer srv.Close()
+		report, err := newTestSimulator(t, srv, func(c *client.Client) Publisher { return NewHTTPPublisher(c) }).Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 2*len(domain.SensorTypes()), report.Sensors)
+		assert.Equal(t, 4, report.Subscribers)
+		assert.Zero(t, report.Failed)
+		assert.Zero(t, report.Dropouts)
+		assert.Greater(t, report.Pushed, int64(report.Sensors*10))
+		assert.Positive(t, report.Delivered)
+		assert.Equal(t, int(report.Delivered), report.Latency.Count)
+		assert.LessOrEqual(t, report.Latency.P50, report.Latency.Max)
+
+		sensors, err := srv.UseCases.Sensor.GetSensors(context.Background())
+		require.NoError(t, err)
+		require.Len(t, sensors, report.Sensors)
+		sensor, err := srv.UseCases.Sensor.GetSensorByID(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "9000000000", sensor.SerialNumber)
+		assert.Equal(t, map[string]string{"simulator": "true", "kind": string(sensor.Type)}, sensor.Labels)
+	})
+
+	t.Run("ok, dropouts and failures", func(t *testing.T) {
+		srv := testserver.SetupTestServer()
+		defer srv.Close()
+		publisher := func(c *client.Client) Publisher { return &flakyPublisher{Publisher: NewHTTPPublisher(c)} }
+		report, err := newTestSimulator(t, srv, publisher,
+			WithSensorTypes(domain.SensorTypeContactClosure), WithDropouts(10, 50*time.Millisecond)).Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Sensors)
+		assert.Positive(t, report.Dropouts)
+		assert.Positive(t, report.Failed)
+		assert.Positive(t, report.Pushed)
+	})
+
+	t.Run("fail, no sensors", func(t *testing.T) {
+		srv := testserver.SetupTestServer()
+		defer srv.Close()
+
+		_, err := newTestSimulator(t, srv, func(c *client.Client) Publisher { return NewHTTPPublisher(c) },
+			WithSensorTypes()).Run(context.Background())
+		assert.ErrorIs(t, err, ErrNoSensors)
+	})
+}
@@ -0,0 +1,193 @@
+// Package testserver - the controller API on the inmemory repositories served by httptest, for the tests of the
+// client, homectl and the simulator
+package testserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+
+	"homework/api"
+	"homework/internal/domain"
+	httpGateway "homework/internal/gateways/http"
+	"homework/internal/usecase"
+
+	actuatorInMemory "homework/internal/repository/actuator/inmemory"
+	automationInMemory "homework/internal/repository/automation/inmemory"
+	eventInMemory "homework/internal/repository/event/inmemory"
+	locationInMemory "homework/internal/repository/location/inmemory"
+	sensorInMemory "homework/internal/repository/sensor/inmemory"
+	subscriptionInMemory "homework/internal/repository/subscription/inmemory"
+	userInMemory "homework/internal/repository/user/inmemory"
+)
+
+// TestServer - the controller API with the inmemory repositories served by httptest
+type TestServer struct {
+	*httptest.Server
+	UseCases httpGateway.UseCases
+
+	listener *trackingListener
+}
+
+// SetupTestServer - starts the server, the options are applied after the defaults of the tests
+// (e.g. the short websocket batch period)
+func SetupTestServer(options ...func(*httpGateway.Server)) *TestServer {
+	sensors := sensorInMemory.NewSensorRepository()
+	locations := locationInMemory.NewLocationRepository()
+	events := subscriptionInMemory.NewSubscriptionRepository[domain.Event]()
+	uc := httpGateway.UseCases{
+		Event:             usecase.NewEvent(eventInMemory.NewEventRepository(), sensors, events),
+		Sensor:            usecase.NewSensor(sensors),
+		User:              usecase.NewUser(userInMemory.NewUserRepository(), userInMemory.NewSensorOwnerRepository(), sensors, locations),
+		Location:          usecase.NewLocation(locations, sensors),
+		EventSubscription: usecase.NewSubscription[domain.Event](events, sensors),
+		Actuator: usecase.NewActuator(
+			actuatorInMemory.NewActuatorRepository(),
+			actuatorInMemory.NewCommandRepository(),
+			subscriptionInMemory.NewSubscriptionRepository[domain.Command](),
+		),
+	}
+	uc.Automation = usecase.NewAutomation(automationInMemory.NewAutomationRepository(), sensors, uc.Actuator, nil)
+
+	gin.SetMode(gin.TestMode)
+	options = append([]func(*httpGateway.Server){
+		httpGateway.WithWebSocketOptions(httpGateway.WithBatchPeriod(10 * time.Millisecond)),
+	}, options...)
+	srv := httptest.NewUnstartedServer(httpGateway.NewServer(uc, options...).Handler())
+	listener := &trackingListener{Listener: srv.Listener, conns: make(map[net.Conn]struct{})}
+	srv.Listener = listener
+	srv.Start()
+	return &TestServer{Server: srv, UseCases: uc, listener: listener}
+}
+
+// DropConnections - closes the connections accepted so far as if the network failed, unlike
+// httptest.Server.CloseClientConnections it closes the hijacked ones (websockets) too
+func (s *TestServer) DropConnections() {
+	s.listener.closeAll()
+}
+
+type trackingListener struct {
+	net.Listener
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.mu.Lock()
+		l.conns[conn] = struct{}{}
+		l.mu.Unlock()
+	}
+	return conn, err
+}
+
+func (l *trackingListener) closeAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for conn := range l.conns {
+		_ = conn.Close()
+	}
+	l.conns = make(map[net.Conn]struct{})
+}
+
+var (
+	specOnce   sync.Once
+	specRouter routers.Router
+	specErr    error
+)
+
+// loadSpecRouter - the router of api/openapi.yaml, the spec is parsed once per test binary
+func loadSpecRouter() (routers.Router, error) {
+	specOnce.Do(func() {
+		doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPI)
+		if err != nil {
+			specErr = fmt.Errorf("can't load OpenAPI spec: %w", err)
+			return
+		}
+		specRouter, specErr = gorillamux.NewRouter(doc)
+	})
+	return specRouter, specErr
+}
+
+// SpecTransport - fails the test if a response received through the transport doesn't match api/openapi.yaml
+// or a request the spec rejects is served successfully, the websocket handshakes are passed as is
+func SpecTransport(t testing.TB, next http.RoundTripper) http.RoundTripper {
+	t.Helper()
+	router, err := loadSpecRouter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &specTransport{t: t, router: router, next: next}
+}
+
+type specTransport struct {
+	t      testing.TB
+	router routers.Router
+	next   http.RoundTripper
+}
+
+func (st *specTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Upgrade") != "" {
+		return st.next.RoundTrip(req)
+	}
+
+	route, pathParams, err := st.router.FindRoute(req)
+	if err != nil {
+		st.t.Errorf("%s %s is not described by the spec: %v", req.Method, req.URL, err)
+		return st.next.RoundTrip(req)
+	}
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+	}
+	// the validation reads the body and puts a copy back
+	requestErr := openapi3filter.ValidateRequest(context.Background(), input)
+
+	resp, err := st.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if requestErr != nil && resp.StatusCode < http.StatusBadRequest {
+		st.t.Errorf("%s %s is invalid by the spec, but served with %d: %v", req.Method, req.URL, resp.StatusCode, requestErr)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			ExcludeResponseBody:   req.Method == http.MethodHead,
+		},
+	})
+	if err != nil {
+		st.t.Errorf("%s %s response %d doesn't match the spec: %v", req.Method, req.URL, resp.StatusCode, err)
+	}
+	return resp, nil
+}
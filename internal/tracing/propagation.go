@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceParentHeader = "traceparent"
+
+// TraceParent - W3C traceparent of the span stored in ctx, empty if there is no sampled span
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get(traceParentHeader)
+}
+
+// LinkFromTraceParent - link to the span described by the W3C traceparent,
+// is used to relate asynchronous work (e.g. a websocket delivery) to the request that caused it
+func LinkFromTraceParent(traceParent string) (trace.Link, bool) {
+	if traceParent == "" {
+		return trace.Link{}, false
+	}
+	carrier := propagation.MapCarrier{traceParentHeader: traceParent}
+	sc := trace.SpanContextFromContext(propagation.TraceContext{}.Extract(context.Background(), carrier))
+	if !sc.IsValid() {
+		return trace.Link{}, false
+	}
+	return trace.Link{SpanContext: sc}, true
+}
@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	enabled.Store(true)
+	t.Cleanup(func() {
+		enabled.Store(false)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestStart(t *testing.T) {
+	t.Run("ok, disabled tracing keeps the context", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), struct{}{}, "value")
+
+		got, span := Start(ctx, "noop")
+		defer span.End()
+
+		assert.Equal(t, ctx, got)
+		assert.False(t, span.SpanContext().IsValid())
+	})
+
+	t.Run("ok, child span and error status", func(t *testing.T) {
+		recorder := setupRecorder(t)
+
+		ctx, parent := Start(context.Background(), "parent")
+		func() (err error) {
+			_, span := Start(ctx, "child")
+			defer End(span, &err)
+			return errors.New("failed")
+		}()
+		parent.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "child", spans[0].Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	})
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("ok, none exporter", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), Options{Exporter: ExporterNone})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+		assert.False(t, enabled.Load())
+	})
+
+	t.Run("err, unsupported exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), Options{Exporter: "jaeger"})
+		assert.ErrorIs(t, err, ErrUnsupportedExporter)
+	})
+}
+
+func TestTraceParent(t *testing.T) {
+	t.Run("ok, empty without span", func(t *testing.T) {
+		assert.Empty(t, TraceParent(context.Background()))
+		_, ok := LinkFromTraceParent("")
+		assert.False(t, ok)
+	})
+
+	t.Run("ok, round trip", func(t *testing.T) {
+		setupRecorder(t)
+
+		ctx, span := Start(context.Background(), "ingest")
+		defer span.End()
+
+		link, ok := LinkFromTraceParent(TraceParent(ctx))
+		require.True(t, ok)
+		assert.Equal(t, span.SpanContext().TraceID(), link.SpanContext.TraceID())
+		assert.Equal(t, span.SpanContext().SpanID(), link.SpanContext.SpanID())
+	})
+
+	t.Run("err, malformed", func(t *testing.T) {
+		_, ok := LinkFromTraceParent("00-broken")
+		assert.False(t, ok)
+	})
+}
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := setupRecorder(t)
+
+	var handlerSpan trace.SpanContext
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(GinMiddleware())
+	r.GET("/api/sensors/:sensor_id", func(c *gin.Context) {
+		_, span := Start(c, "Sensor.GetSensorByID")
+		handlerSpan = span.SpanContext()
+		span.End()
+		c.Status(http.StatusInternalServerError)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/sensors/1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	server := spans[1]
+	assert.Equal(t, "GET /api/sensors/:sensor_id", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, traceID, server.SpanContext().TraceID().String())
+	assert.True(t, server.Parent().IsRemote())
+	assert.Equal(t, codes.Error, server.Status().Code)
+	assert.Equal(t, server.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, handlerSpan.SpanID(), spans[0].SpanContext().SpanID())
+}
@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/metrics"
+	"homework/internal/tracing"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultCommandTTL - time a command waits for the acknowledgement unless another one is requested
+	DefaultCommandTTL = time.Minute
+	maxCommandTTL     = 24 * time.Hour
+)
+
+var actuatorSNRe = regexp.MustCompile(`^[0-9]{10}$`)
+
+type Actuator struct {
+	actuatorRepository            ActuatorRepository
+	commandRepository             CommandRepository
+	commandSubscriptionRepository SubscriptionRepository[domain.Command]
+	logger                        *slog.Logger
+}
+
+func NewActuator(ar ActuatorRepository, cr CommandRepository, csr SubscriptionRepository[domain.Command], options ...Option) *Actuator {
+	o := applyOptions(options)
+	return &Actuator{
+		actuatorRepository:            ar,
+		commandRepository:             cr,
+		commandSubscriptionRepository: csr,
+		logger:                        o.logger,
+	}
+}
+
+func (a *Actuator) RegisterActuator(ctx context.Context, actuator *domain.Actuator) (_ *domain.Actuator, err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.RegisterActuator")
+	defer tracing.End(span, &err)
+
+	if actuator == nil {
+		return actuator, errors.New("got nil actuator at RegisterActuator()")
+	}
+	if !actuatorSNRe.MatchString(actuator.SerialNumber) {
+		return actuator, ErrWrongActuatorSN
+	}
+	if !actuator.Type.IsValid() {
+		return actuator, fmt.Errorf("%w: %q", ErrWrongActuatorType, actuator.Type)
+	}
+
+	if existing, err := a.actuatorRepository.GetActuatorBySerialNumber(ctx, actuator.SerialNumber); err == nil {
+		return existing, nil
+	} else if !errors.Is(err, ErrActuatorNotFound) {
+		return nil, err
+	}
+
+	if err := a.actuatorRepository.SaveActuator(ctx, actuator); err != nil { // Modifies actuator assigning new id
+		return actuator, err
+	}
+	a.logger.InfoContext(ctx, "actuator registered", "actuator_id", actuator.ID, "serial_number", actuator.SerialNumber)
+	return actuator, nil
+}
+
+func (a *Actuator) GetActuators(ctx context.Context) (_ []domain.Actuator, err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.GetActuators")
+	defer tracing.End(span, &err)
+
+	actuators, err := a.actuatorRepository.GetActuators(ctx)
+	if err != nil {
+		return actuators, fmt.Errorf("cannot get actuators from repository: %w", err)
+	}
+	return actuators, nil
+}
+
+func (a *Actuator) GetActuatorByID(ctx context.Context, id int64) (_ *domain.Actuator, err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.GetActuatorByID")
+	defer tracing.End(span, &err)
+
+	actuator, err := a.actuatorRepository.GetActuatorByID(ctx, id)
+	if err != nil {
+		return actuator, fmt.Errorf("cannot get actuator from repository for id %v: %w", id, err)
+	}
+	return actuator, nil
+}
+
+func validateCommand(actuator *domain.Actuator, command *domain.Command) error {
+	if !actuator.Type.Accepts(command.Action) {
+		return fmt.Errorf("%w: %s doesn't accept %q", ErrInvalidCommand, actuator.Type, command.Action)
+	}
+	if command.Action == domain.CommandActionSet && (command.Value < 0 || command.Value > domain.DimmerMaxLevel) {
+		return fmt.Errorf("%w: level %d is not in [0, %d]", ErrInvalidCommand, command.Value, domain.DimmerMaxLevel)
+	}
+	if command.Action != domain.CommandActionSet && command.Value != 0 {
+		return fmt.Errorf("%w: %q takes no value", ErrInvalidCommand, command.Action)
+	}
+	return nil
+}
+
+func (a *Actuator) broadcastCommand(ctx context.Context, command *domain.Command) error {
+	handle, err := a.commandSubscriptionRepository.GetBroadcastHandleById(ctx, command.ActuatorID)
+	if err != nil {
+		if errors.Is(err, ErrSensorNotFound) { // Nobody has subscribed to the actuator yet
+			return nil
+		}
+		return fmt.Errorf("can't get command broadcast handle: %w", err)
+	}
+	handle.Ch <- *command
+	return nil
+}
+
+// EnqueueCommand - queues the command for the actuator, ttl <= 0 stands for DefaultCommandTTL.
+// The command is pushed to the actuator subscribers right away, the others pick it up by polling.
+func (a *Actuator) EnqueueCommand(ctx context.Context, actuatorID int64, command *domain.Command, ttl time.Duration) (_ *domain.Command, err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.EnqueueCommand")
+	defer tracing.End(span, &err)
+
+	if command == nil {
+		return command, errors.New("got nil command at EnqueueCommand()")
+	}
+	if ttl <= 0 {
+		ttl = DefaultCommandTTL
+	}
+	if ttl > maxCommandTTL {
+		return command, fmt.Errorf("%w: ttl %v exceeds %v", ErrInvalidCommand, ttl, maxCommandTTL)
+	}
+	actuator, err := a.actuatorRepository.GetActuatorByID(ctx, actuatorID)
+	if err != nil {
+		return command, fmt.Errorf("got invalid actuator id (%v): %w", actuatorID, err)
+	}
+	if err := validateCommand(actuator, command); err != nil {
+		return command, err
+	}
+
+	now := time.Now()
+	command.ActuatorID = actuatorID
+	command.Status = domain.CommandStatusPending
+	command.Error = ""
+	command.CreatedAt = now
+	command.UpdatedAt = now
+	command.ExpiresAt = now.Add(ttl)
+	if err := a.commandRepository.SaveCommand(ctx, command); err != nil { // Modifies command assigning new id
+		return command, fmt.Errorf("cannot save command: %w", err)
+	}
+	metrics.CommandStatusChanged(string(domain.CommandStatusPending))
+	a.logger.InfoContext(ctx, "command enqueued", "actuator_id", actuatorID, "command_id", command.ID, "action", command.Action)
+
+	if err := a.broadcastCommand(ctx, command); err != nil {
+		a.logger.WarnContext(ctx, "command broadcast failed, left for polling", "command_id", command.ID, "error", err)
+	}
+	return command, nil
+}
+
+// transition - moves the command to the status, fails with ErrCommandConflict if the lifecycle doesn't allow that
+// or the command has been moved concurrently
+func (a *Actuator) transition(ctx context.Context, command *domain.Command, status domain.CommandStatus, reason string) error {
+	if !command.Status.CanBecome(status) {
+		return fmt.Errorf("%w: command %v can't become %s being %s", ErrCommandConflict, command.ID, status, command.Status)
+	}
+
+	from := command.Status
+	updated := *command
+	updated.Status = status
+	updated.Error = reason
+	updated.UpdatedAt = time.Now()
+	if err := a.commandRepository.UpdateCommandStatus(ctx, &updated, from); err != nil {
+		return fmt.Errorf("cannot update command %v status: %w", command.ID, err)
+	}
+	*command = updated
+	metrics.CommandStatusChanged(string(status))
+	a.logger.DebugContext(ctx, "command status changed", "command_id", command.ID, "from", from, "to", status)
+	return nil
+}
+
+// expire - marks the commands past their deadline expired, the concurrently finished ones are left as they are
+func (a *Actuator) expire(ctx context.Context, commands []domain.Command) error {
+	now := time.Now()
+	for i := range commands {
+		if !commands[i].IsExpired(now) {
+			continue
+		}
+		err := a.transition(ctx, &commands[i], domain.CommandStatusExpired, "")
+		if err != nil && !errors.Is(err, ErrCommandConflict) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpireCommands - expires the commands of every actuator past their deadline, is run periodically
+// so the commands of the actuators nobody polls don't stay pending
+func (a *Actuator) ExpireCommands(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.ExpireCommands")
+	defer tracing.End(span, &err)
+
+	ids, err := a.commandRepository.ExpireCommands(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot expire commands: %w", err)
+	}
+	for range ids {
+		metrics.CommandStatusChanged(string(domain.CommandStatusExpired))
+	}
+	if len(ids) > 0 {
+		a.logger.DebugContext(ctx, "commands expired", "command_ids", ids)
+	}
+	return nil
+}
+
+// GetCommands - all commands of the actuator in the order they were enqueued
+func (a *Actuator) GetCommands(ctx context.Context, actuatorID int64) (_ []domain.Command, err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.GetCommands")
+	defer tracing.End(span, &err)
+
+	if _, err := a.actuatorRepository.GetActuatorByID(ctx, actuatorID); err != nil {
+		return nil, fmt.Errorf("got invalid actuator id (%v): %w", actuatorID, err)
+	}
+	commands, err := a.commandRepository.GetCommandsByActuatorID(ctx, actuatorID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get commands from repository: %w", err)
+	}
+	if err := a.expire(ctx, commands); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (a *Actuator) GetCommandByID(ctx context.Context, actuatorID, commandID int64) (_ *domain.Command, err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.GetCommandByID")
+	defer tracing.End(span, &err)
+
+	command, err := a.commandRepository.GetCommandByID(ctx, commandID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get command from repository for id %v: %w", commandID, err)
+	}
+	if command.ActuatorID != actuatorID {
+		return nil, fmt.Errorf("%w: command %v doesn't belong to actuator %v", ErrCommandNotFound, commandID, actuatorID)
+	}
+	commands := []domain.Command{*command}
+	if err := a.expire(ctx, commands); err != nil {
+		return nil, err
+	}
+	return &commands[0], nil
+}
+
+// GetPendingCommands - the commands not handed to the actuator yet
+func (a *Actuator) GetPendingCommands(ctx context.Context, actuatorID int64) (_ []domain.Command, err error) {
+	commands, err := a.GetCommands(ctx, actuatorID)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]domain.Command, 0)
+	for _, c := range commands {
+		if c.Status == domain.CommandStatusPending {
+			pending = append(pending, c)
+		}
+	}
+	return pending, nil
+}
+
+// PollCommands - hands the pending commands to the polling actuator marking them delivered
+func (a *Actuator) PollCommands(ctx context.Context, actuatorID int64) (_ []domain.Command, err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.PollCommands")
+	defer tracing.End(span, &err)
+
+	pending, err := a.GetPendingCommands(ctx, actuatorID)
+	if err != nil {
+		return nil, err
+	}
+	delivered := make([]domain.Command, 0, len(pending))
+	for _, c := range pending {
+		if err := a.transition(ctx, &c, domain.CommandStatusDelivered, ""); err != nil {
+			if errors.Is(err, ErrCommandConflict) {
+				continue // Delivered by the subscription meanwhile
+			}
+			return delivered, err
+		}
+		delivered = append(delivered, c)
+	}
+	return delivered, nil
+}
+
+// MarkDelivered - records the command was handed to the actuator subscriber
+func (a *Actuator) MarkDelivered(ctx context.Context, command *domain.Command) (err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.MarkDelivered")
+	defer tracing.End(span, &err)
+
+	return a.transition(ctx, command, domain.CommandStatusDelivered, "")
+}
+
+// AckCommand - records the execution result reported by the actuator, status is either acked or failed
+func (a *Actuator) AckCommand(ctx context.Context, actuatorID, commandID int64, status domain.CommandStatus, reason string) (_ *domain.Command, err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.AckCommand")
+	defer tracing.End(span, &err)
+
+	if status != domain.CommandStatusAcked && status != domain.CommandStatusFailed {
+		return nil, fmt.Errorf("%w: can't acknowledge with %q", ErrInvalidCommand, status)
+	}
+	if status == domain.CommandStatusAcked {
+		reason = ""
+	}
+	command, err := a.GetCommandByID(ctx, actuatorID, commandID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.transition(ctx, command, status, reason); err != nil {
+		return nil, err
+	}
+	a.logger.InfoContext(ctx, "command acknowledged", "actuator_id", actuatorID, "command_id", commandID, "status", status)
+	return command, nil
+}
+
+// SubscribeCommands - subscribes to the commands enqueued for the actuator from now on
+func (a *Actuator) SubscribeCommands(ctx context.Context, actuatorID int64) (_ *domain.Subscription[domain.Command], err error) {
+	ctx, span := tracing.Start(ctx, "Actuator.SubscribeCommands")
+	defer tracing.End(span, &err)
+
+	if _, err := a.actuatorRepository.GetActuatorByID(ctx, actuatorID); err != nil {
+		return nil, err
+	}
+	subscription, err := a.commandSubscriptionRepository.Subscribe(ctx, actuatorID)
+	if err != nil {
+		return nil, err
+	}
+	a.logger.DebugContext(ctx, "subscribed to commands", "actuator_id", actuatorID, "subscription_id", subscription.Id)
+	return subscription, nil
+}
+
+func (a *Actuator) UnsubscribeCommands(ctx context.Context, actuatorID int64, subscriptionId uuid.UUID) error {
+	return a.commandSubscriptionRepository.Unsubscribe(ctx, actuatorID, subscriptionId)
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_actuator_RegisterActuator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid actuator", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().SaveActuator(ctx, gomock.Any()).Times(0)
+
+		a := NewActuator(ar, nil, nil)
+
+		_, err := a.RegisterActuator(ctx, &domain.Actuator{SerialNumber: "123", Type: domain.ActuatorTypeRelay})
+		assert.ErrorIs(t, err, ErrWrongActuatorSN)
+
+		_, err = a.RegisterActuator(ctx, &domain.Actuator{SerialNumber: "1234567890", Type: "pump"})
+		assert.ErrorIs(t, err, ErrWrongActuatorType)
+	})
+
+	t.Run("ok, saved", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorBySerialNumber(ctx, "1234567890").Times(1).Return(nil, ErrActuatorNotFound)
+		ar.EXPECT().SaveActuator(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, actuator *domain.Actuator) error {
+			actuator.ID = 1
+			return nil
+		})
+
+		a := NewActuator(ar, nil, nil)
+
+		actuator, err := a.RegisterActuator(ctx, &domain.Actuator{SerialNumber: "1234567890", Type: domain.ActuatorTypeValve})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), actuator.ID)
+	})
+}
+
+func Test_actuator_EnqueueCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dimmer := &domain.Actuator{ID: 1, SerialNumber: "1234567890", Type: domain.ActuatorTypeDimmer}
+
+	t.Run("fail, invalid command", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(ctx, int64(1)).AnyTimes().Return(dimmer, nil)
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().SaveCommand(ctx, gomock.Any()).Times(0)
+
+		a := NewActuator(ar, cr, nil)
+
+		for _, command := range []domain.Command{
+			{Action: domain.CommandActionOpen},
+			{Action: domain.CommandActionSet, Value: 101},
+			{Action: domain.CommandActionOn, Value: 1},
+		} {
+			_, err := a.EnqueueCommand(ctx, 1, &command, 0)
+			assert.ErrorIs(t, err, ErrInvalidCommand)
+		}
+
+		_, err := a.EnqueueCommand(ctx, 1, &domain.Command{Action: domain.CommandActionOn}, 48*time.Hour)
+		assert.ErrorIs(t, err, ErrInvalidCommand)
+	})
+
+	t.Run("fail, actuator not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(ctx, int64(2)).Times(1).Return(nil, ErrActuatorNotFound)
+
+		a := NewActuator(ar, nil, nil)
+
+		_, err := a.EnqueueCommand(ctx, 2, &domain.Command{Action: domain.CommandActionOn}, 0)
+		assert.ErrorIs(t, err, ErrActuatorNotFound)
+	})
+
+	t.Run("ok, saved pending and broadcasted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(ctx, int64(1)).Times(1).Return(dimmer, nil)
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().SaveCommand(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, command *domain.Command) error {
+			command.ID = 7
+			return nil
+		})
+
+		broadcast := make(chan domain.Command, 1)
+		csr := NewMockSubscriptionRepository[domain.Command](ctrl)
+		csr.EXPECT().GetBroadcastHandleById(ctx, int64(1)).Times(1).Return(&domain.SubscriptionWriteHandle[domain.Command]{Ch: broadcast}, nil)
+
+		a := NewActuator(ar, cr, csr)
+
+		command, err := a.EnqueueCommand(ctx, 1, &domain.Command{Action: domain.CommandActionSet, Value: 40}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, domain.CommandStatusPending, command.Status)
+		assert.Equal(t, int64(1), command.ActuatorID)
+		assert.Equal(t, DefaultCommandTTL, command.ExpiresAt.Sub(command.CreatedAt))
+
+		broadcasted := <-broadcast
+		assert.Equal(t, int64(7), broadcasted.ID)
+	})
+}
+
+func Test_actuator_AckCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	delivered := func() *domain.Command {
+		return &domain.Command{
+			ID:         7,
+			ActuatorID: 1,
+			Action:     domain.CommandActionOn,
+			Status:     domain.CommandStatusDelivered,
+			ExpiresAt:  time.Now().Add(time.Minute),
+		}
+	}
+
+	t.Run("fail, not an acknowledgement", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := NewActuator(nil, nil, nil)
+
+		_, err := a.AckCommand(ctx, 1, 7, domain.CommandStatusDelivered, "")
+		assert.ErrorIs(t, err, ErrInvalidCommand)
+	})
+
+	t.Run("fail, command of another actuator", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().GetCommandByID(ctx, int64(7)).Times(1).Return(delivered(), nil)
+
+		a := NewActuator(nil, cr, nil)
+
+		_, err := a.AckCommand(ctx, 2, 7, domain.CommandStatusAcked, "")
+		assert.ErrorIs(t, err, ErrCommandNotFound)
+	})
+
+	t.Run("fail, command expired", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		command := delivered()
+		command.ExpiresAt = time.Now().Add(-time.Second)
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().GetCommandByID(ctx, int64(7)).Times(1).Return(command, nil)
+		cr.EXPECT().UpdateCommandStatus(ctx, gomock.Any(), domain.CommandStatusDelivered).Times(1).
+			Do(func(_ context.Context, command *domain.Command, _ domain.CommandStatus) {
+				assert.Equal(t, domain.CommandStatusExpired, command.Status)
+			})
+
+		a := NewActuator(nil, cr, nil)
+
+		_, err := a.AckCommand(ctx, 1, 7, domain.CommandStatusAcked, "")
+		assert.ErrorIs(t, err, ErrCommandConflict)
+	})
+
+	t.Run("ok, failure recorded", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().GetCommandByID(ctx, int64(7)).Times(1).Return(delivered(), nil)
+		cr.EXPECT().UpdateCommandStatus(ctx, gomock.Any(), domain.CommandStatusDelivered).Times(1).Return(nil)
+
+		a := NewActuator(nil, cr, nil)
+
+		command, err := a.AckCommand(ctx, 1, 7, domain.CommandStatusFailed, "relay stuck")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.CommandStatusFailed, command.Status)
+		assert.Equal(t, "relay stuck", command.Error)
+	})
+}
+
+func Test_actuator_PollCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	future := time.Now().Add(time.Minute)
+	ar := NewMockActuatorRepository(ctrl)
+	ar.EXPECT().GetActuatorByID(ctx, int64(1)).Times(1).Return(&domain.Actuator{ID: 1}, nil)
+	cr := NewMockCommandRepository(ctrl)
+	cr.EXPECT().GetCommandsByActuatorID(ctx, int64(1)).Times(1).Return([]domain.Command{
+		{ID: 1, ActuatorID: 1, Status: domain.CommandStatusAcked, ExpiresAt: future},
+		{ID: 2, ActuatorID: 1, Status: domain.CommandStatusPending, ExpiresAt: time.Now().Add(-time.Second)},
+		{ID: 3, ActuatorID: 1, Status: domain.CommandStatusPending, ExpiresAt: future},
+		{ID: 4, ActuatorID: 1, Status: domain.CommandStatusPending, ExpiresAt: future},
+	}, nil)
+	cr.EXPECT().UpdateCommandStatus(ctx, gomock.Any(), domain.CommandStatusPending).Times(3).
+		DoAndReturn(func(_ context.Context, command *domain.Command, _ domain.CommandStatus) error {
+			if command.ID == 4 {
+				return ErrCommandConflict // Delivered by a subscription meanwhile
+			}
+			return nil
+		})
+
+	a := NewActuator(ar, cr, nil)
+
+	commands, err := a.PollCommands(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, int64(3), commands[0].ID)
+	assert.Equal(t, domain.CommandStatusDelivered, commands[0].Status)
+}
+
+func Test_actuator_SubscribeCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ar := NewMockActuatorRepository(ctrl)
+	ar.EXPECT().GetActuatorByID(ctx, int64(1)).Times(1).Return(&domain.Actuator{ID: 1}, nil)
+	ar.EXPECT().GetActuatorByID(ctx, int64(2)).Times(1).Return(nil, ErrActuatorNotFound)
+
+	expected := domain.Subscription[domain.Command]{SensorID: 1, Id: uuid.New()}
+	csr := NewMockSubscriptionRepository[domain.Command](ctrl)
+	csr.EXPECT().Subscribe(ctx, int64(1)).Times(1).Return(&expected, nil)
+
+	a := NewActuator(ar, nil, csr)
+
+	subscription, err := a.SubscribeCommands(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, *subscription)
+
+	_, err = a.SubscribeCommands(ctx, 2)
+	assert.ErrorIs(t, err, ErrActuatorNotFound)
+}
+
+func Test_actuator_ExpireCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	ar := NewMockActuatorRepository(ctrl)
+	ar.EXPECT().GetActuators(gomock.Any()).Times(0)
+	cr := NewMockCommandRepository(ctrl)
+	cr.EXPECT().GetCommandsByActuatorID(gomock.Any(), gomock.Any()).Times(0)
+	cr.EXPECT().ExpireCommands(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, now time.Time) ([]int64, error) {
+		assert.False(t, now.Before(start))
+		return []int64{1}, nil
+	})
+	errStorage := errors.New("storage failure")
+	cr.EXPECT().ExpireCommands(ctx, gomock.Any()).Times(1).Return(nil, errStorage)
+
+	a := NewActuator(ar, cr, nil)
+
+	assert.NoError(t, a.ExpireCommands(ctx))
+	assert.ErrorIs(t, a.ExpireCommands(ctx), errStorage)
+}
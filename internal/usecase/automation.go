@@ -0,0 +1,613 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/metrics"
+	"homework/internal/tracing"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxAutomationDepth - length of the rule chain (rule → webhook → event → rule ...) the engine stops at
+	MaxAutomationDepth = 3
+	// DefaultRuleCooldown - minimal interval between the executions of a rule without its own cooldown
+	DefaultRuleCooldown = time.Second
+	// DefaultExecutionsLimit - number of the latest executions returned unless another one is requested
+	DefaultExecutionsLimit = 50
+
+	automationQueueSize = 256
+	automationWorkers   = 4
+	maxRuleNameLength   = 100
+	maxRuleActions      = 10
+	minScheduleInterval = time.Minute
+	minOfflineAfter     = time.Minute
+)
+
+type automationDepthKey struct{}
+
+// WithAutomationDepth - marks the context as handling the consequence of a chain of depth automation rules
+func WithAutomationDepth(ctx context.Context, depth int) context.Context {
+	return context.WithValue(ctx, automationDepthKey{}, depth)
+}
+
+// AutomationDepth - number of the automation rules that led to the context, 0 for the external requests
+func AutomationDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(automationDepthKey{}).(int)
+	return depth
+}
+
+// Automation - rules engine, the trigger state is owned by the Run goroutine, the rule actions are run
+// by a pool of automationWorkers so a slow webhook doesn't hold the evaluation of the other rules
+type Automation struct {
+	automationRepository AutomationRepository
+	sensorRepository     SensorRepository
+	actuator             *Actuator
+	webhookSender        WebhookSender
+	events               chan domain.Event
+	actions              chan actionJob
+	logger               *slog.Logger
+	heartbeat            func()
+
+	lastRun   map[int64]time.Time // Latest execution of the rule actions, for the cooldown
+	scheduled map[int64]time.Time // Latest firing of the schedule trigger
+	offline   map[int64]time.Time // Sensor activity the offline trigger has fired for
+
+	rulesMu sync.Mutex
+	rules   []domain.AutomationRule // Rules the events are evaluated against, nil until loaded or after a change
+}
+
+func NewAutomation(ar AutomationRepository, sr SensorRepository, actuator *Actuator, ws WebhookSender, options ...Option) *Automation {
+	o := applyOptions(options)
+	return &Automation{
+		automationRepository: ar,
+		sensorRepository:     sr,
+		actuator:             actuator,
+		webhookSender:        ws,
+		events:               make(chan domain.Event, automationQueueSize),
+		actions:              make(chan actionJob, automationQueueSize),
+		logger:               o.logger,
+		heartbeat:            o.heartbeat,
+		lastRun:              make(map[int64]time.Time),
+		scheduled:            make(map[int64]time.Time),
+		offline:              make(map[int64]time.Time),
+	}
+}
+
+func (a *Automation) validateSensorRef(ctx context.Context, sensorID int64, channel string) error {
+	sensor, err := a.sensorRepository.GetSensorByID(ctx, sensorID)
+	if errors.Is(err, ErrSensorNotFound) {
+		return fmt.Errorf("%w: sensor %v not found", ErrInvalidRule, sensorID)
+	} else if err != nil {
+		return fmt.Errorf("cannot get sensor %v: %w", sensorID, err)
+	}
+	if channel != "" {
+		if _, ok := sensor.Channel(channel); !ok {
+			return fmt.Errorf("%w: sensor %v has no channel %q", ErrInvalidRule, sensorID, channel)
+		}
+	}
+	return nil
+}
+
+func (a *Automation) validateTrigger(ctx context.Context, trigger domain.Trigger) error {
+	switch trigger.Kind {
+	case domain.TriggerEvent:
+		if !trigger.Operator.IsValid() {
+			return fmt.Errorf("%w: trigger: unknown operator %q", ErrInvalidRule, trigger.Operator)
+		}
+		return a.validateSensorRef(ctx, trigger.SensorID, trigger.Channel)
+	case domain.TriggerSchedule:
+		if trigger.Every < minScheduleInterval {
+			return fmt.Errorf("%w: trigger: schedule interval is less than %v", ErrInvalidRule, minScheduleInterval)
+		}
+	case domain.TriggerOffline:
+		if trigger.After < minOfflineAfter {
+			return fmt.Errorf("%w: trigger: offline timeout is less than %v", ErrInvalidRule, minOfflineAfter)
+		}
+		return a.validateSensorRef(ctx, trigger.SensorID, "")
+	default:
+		return fmt.Errorf("%w: trigger: unknown kind %q", ErrInvalidRule, trigger.Kind)
+	}
+	return nil
+}
+
+func (a *Automation) validateCondition(ctx context.Context, i int, condition domain.Condition) error {
+	switch condition.Kind {
+	case domain.ConditionTimeWindow:
+		for _, t := range []string{condition.From, condition.To} {
+			if _, err := domain.ParseTimeOfDay(t); err != nil {
+				return fmt.Errorf("%w: condition %d: %w", ErrInvalidRule, i, err)
+			}
+		}
+	case domain.ConditionSensorState:
+		if !condition.Operator.IsValid() {
+			return fmt.Errorf("%w: condition %d: unknown operator %q", ErrInvalidRule, i, condition.Operator)
+		}
+		return a.validateSensorRef(ctx, condition.SensorID, "")
+	default:
+		return fmt.Errorf("%w: condition %d: unknown kind %q", ErrInvalidRule, i, condition.Kind)
+	}
+	return nil
+}
+
+func (a *Automation) validateAction(ctx context.Context, i int, action domain.Action) error {
+	switch action.Kind {
+	case domain.ActionCommand:
+		actuator, err := a.actuator.GetActuatorByID(ctx, action.ActuatorID)
+		if errors.Is(err, ErrActuatorNotFound) {
+			return fmt.Errorf("%w: action %d: actuator %v not found", ErrInvalidRule, i, action.ActuatorID)
+		} else if err != nil {
+			return err
+		}
+		if err := validateCommand(actuator, &domain.Command{Action: action.Command, Value: action.Value}); err != nil {
+			return fmt.Errorf("%w: action %d: %w", ErrInvalidRule, i, err)
+		}
+	case domain.ActionWebhook:
+		u, err := url.Parse(action.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: action %d: webhook url %q is not an absolute http(s) url", ErrInvalidRule, i, action.URL)
+		}
+		if isInternalHost(u.Hostname()) {
+			return fmt.Errorf("%w: action %d: webhook url %q points to an internal address", ErrInvalidRule, i, action.URL)
+		}
+	case domain.ActionAlert:
+		if action.Message == "" {
+			return fmt.Errorf("%w: action %d: alert message is empty", ErrInvalidRule, i)
+		}
+	default:
+		return fmt.Errorf("%w: action %d: unknown kind %q", ErrInvalidRule, i, action.Kind)
+	}
+	return nil
+}
+
+// isInternalHost - whether the host is localhost or an internal address literal, the names resolving
+// to the internal addresses are refused by the webhook sender when dialing
+func isInternalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && domain.IsInternalAddress(addr)
+}
+
+func (a *Automation) validateRule(ctx context.Context, rule *domain.AutomationRule) error {
+	if rule.Name == "" || len(rule.Name) > maxRuleNameLength {
+		return fmt.Errorf("%w: name must be 1..%d characters long", ErrInvalidRule, maxRuleNameLength)
+	}
+	if rule.Cooldown < 0 {
+		return fmt.Errorf("%w: cooldown is negative", ErrInvalidRule)
+	}
+	if err := a.validateTrigger(ctx, rule.Trigger); err != nil {
+		return err
+	}
+	for i, condition := range rule.Conditions {
+		if err := a.validateCondition(ctx, i, condition); err != nil {
+			return err
+		}
+	}
+	if len(rule.Actions) == 0 || len(rule.Actions) > maxRuleActions {
+		return fmt.Errorf("%w: a rule needs 1..%d actions", ErrInvalidRule, maxRuleActions)
+	}
+	for i, action := range rule.Actions {
+		if err := a.validateAction(ctx, i, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Automation) CreateRule(ctx context.Context, rule *domain.AutomationRule) (_ *domain.AutomationRule, err error) {
+	ctx, span := tracing.Start(ctx, "Automation.CreateRule")
+	defer tracing.End(span, &err)
+
+	if rule == nil {
+		return rule, errors.New("got nil rule at CreateRule()")
+	}
+	if err := a.validateRule(ctx, rule); err != nil {
+		return rule, err
+	}
+
+	rule.CreatedAt = time.Now()
+	if err := a.automationRepository.SaveRule(ctx, rule); err != nil { // Modifies rule assigning new id
+		return rule, fmt.Errorf("cannot save rule: %w", err)
+	}
+	a.invalidateRules()
+	a.logger.InfoContext(ctx, "automation rule created", "rule_id", rule.ID, "trigger", rule.Trigger.Kind)
+	return rule, nil
+}
+
+// UpdateRule - replaces the rule keeping its creation time
+func (a *Automation) UpdateRule(ctx context.Context, rule *domain.AutomationRule) (_ *domain.AutomationRule, err error) {
+	ctx, span := tracing.Start(ctx, "Automation.UpdateRule")
+	defer tracing.End(span, &err)
+
+	if rule == nil {
+		return rule, errors.New("got nil rule at UpdateRule()")
+	}
+	existing, err := a.automationRepository.GetRuleByID(ctx, rule.ID)
+	if err != nil {
+		return rule, fmt.Errorf("cannot get rule %v: %w", rule.ID, err)
+	}
+	if err := a.validateRule(ctx, rule); err != nil {
+		return rule, err
+	}
+
+	rule.CreatedAt = existing.CreatedAt
+	if err := a.automationRepository.UpdateRule(ctx, rule); err != nil {
+		return rule, fmt.Errorf("cannot update rule %v: %w", rule.ID, err)
+	}
+	a.invalidateRules()
+	a.logger.InfoContext(ctx, "automation rule updated", "rule_id", rule.ID, "enabled", rule.Enabled)
+	return rule, nil
+}
+
+func (a *Automation) DeleteRule(ctx context.Context, id int64) (err error) {
+	ctx, span := tracing.Start(ctx, "Automation.DeleteRule")
+	defer tracing.End(span, &err)
+
+	if err := a.automationRepository.DeleteRule(ctx, id); err != nil {
+		return fmt.Errorf("cannot delete rule %v: %w", id, err)
+	}
+	a.invalidateRules()
+	a.logger.InfoContext(ctx, "automation rule deleted", "rule_id", id)
+	return nil
+}
+
+func (a *Automation) GetRules(ctx context.Context) (_ []domain.AutomationRule, err error) {
+	ctx, span := tracing.Start(ctx, "Automation.GetRules")
+	defer tracing.End(span, &err)
+
+	rules, err := a.automationRepository.GetRules(ctx)
+	if err != nil {
+		return rules, fmt.Errorf("cannot get rules from repository: %w", err)
+	}
+	return rules, nil
+}
+
+func (a *Automation) GetRuleByID(ctx context.Context, id int64) (_ *domain.AutomationRule, err error) {
+	ctx, span := tracing.Start(ctx, "Automation.GetRuleByID")
+	defer tracing.End(span, &err)
+
+	rule, err := a.automationRepository.GetRuleByID(ctx, id)
+	if err != nil {
+		return rule, fmt.Errorf("cannot get rule from repository for id %v: %w", id, err)
+	}
+	return rule, nil
+}
+
+// GetExecutions - the latest executions of the rule starting from the newest, limit <= 0 stands for DefaultExecutionsLimit
+func (a *Automation) GetExecutions(ctx context.Context, ruleID int64, limit int) (_ []domain.AutomationExecution, err error) {
+	ctx, span := tracing.Start(ctx, "Automation.GetExecutions")
+	defer tracing.End(span, &err)
+
+	if _, err := a.automationRepository.GetRuleByID(ctx, ruleID); err != nil {
+		return nil, fmt.Errorf("cannot get rule %v: %w", ruleID, err)
+	}
+	if limit <= 0 {
+		limit = DefaultExecutionsLimit
+	}
+	executions, err := a.automationRepository.GetExecutionsByRuleID(ctx, ruleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get executions of rule %v: %w", ruleID, err)
+	}
+	return executions, nil
+}
+
+// HandleEvent - queues the ingested event for the rules evaluation, the event is dropped if the engine lags behind
+func (a *Automation) HandleEvent(ctx context.Context, event domain.Event) {
+	select {
+	case a.events <- event:
+	default:
+		metrics.AutomationDropped(metrics.AutomationQueueEvents)
+		a.logger.WarnContext(ctx, "automation queue is full, event dropped", "sensor_id", event.SensorID)
+	}
+}
+
+// Run - evaluates the rules against the received events, and every tick against the schedules
+// and the sensors activity, until ctx is done and the running actions have returned
+func (a *Automation) Run(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i := 0; i < automationWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runWorker(ctx)
+		}()
+	}
+
+	for {
+		a.heartbeat()
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-a.events:
+			a.processEvent(ctx, &event)
+		case now := <-ticker.C:
+			a.processTimers(ctx, now)
+		}
+	}
+}
+
+// invalidateRules - makes the next event reload the rules from the repository
+func (a *Automation) invalidateRules() {
+	a.rulesMu.Lock()
+	defer a.rulesMu.Unlock()
+	a.rules = nil
+}
+
+// loadRules - the rules from the repository, they are cached for the events until changed through the usecase
+// or reloaded by the next tick, which picks up the changes made by the other replicas
+func (a *Automation) loadRules(ctx context.Context) ([]domain.AutomationRule, error) {
+	a.rulesMu.Lock()
+	defer a.rulesMu.Unlock()
+
+	rules, err := a.automationRepository.GetRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []domain.AutomationRule{}
+	}
+	a.rules = rules
+	return rules, nil
+}
+
+// cachedRules - the cached rules, loaded from the repository if there are none
+func (a *Automation) cachedRules(ctx context.Context) ([]domain.AutomationRule, error) {
+	a.rulesMu.Lock()
+	rules := a.rules
+	a.rulesMu.Unlock()
+	if rules != nil {
+		return rules, nil
+	}
+	return a.loadRules(ctx)
+}
+
+func (a *Automation) enabledRules(ctx context.Context) []domain.AutomationRule {
+	rules, err := a.cachedRules(ctx)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "cannot get automation rules", "error", err)
+		return nil
+	}
+	enabled := make([]domain.AutomationRule, 0, len(rules))
+	for _, r := range rules {
+		if r.Enabled {
+			enabled = append(enabled, r)
+		}
+	}
+	return enabled
+}
+
+func (a *Automation) processEvent(ctx context.Context, event *domain.Event) {
+	for _, rule := range a.enabledRules(ctx) {
+		if rule.Trigger.Matches(event) {
+			a.execute(ctx, &rule, event.AutomationDepth, event)
+		}
+	}
+}
+
+// forgetDeleted - drops the trigger state of the rules that are no longer in the repository,
+// whichever replica has deleted them
+func (a *Automation) forgetDeleted(rules []domain.AutomationRule) {
+	exists := make(map[int64]bool, len(rules))
+	for _, r := range rules {
+		exists[r.ID] = true
+	}
+	for _, state := range []map[int64]time.Time{a.lastRun, a.scheduled, a.offline} {
+		for id := range state {
+			if !exists[id] {
+				delete(state, id)
+			}
+		}
+	}
+}
+
+func (a *Automation) processTimers(ctx context.Context, now time.Time) {
+	rules, err := a.loadRules(ctx)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "cannot get automation rules", "error", err)
+		return
+	}
+	a.forgetDeleted(rules)
+
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		switch rule.Trigger.Kind {
+		case domain.TriggerSchedule:
+			last, seen := a.scheduled[rule.ID]
+			if !seen {
+				a.scheduled[rule.ID] = now // The interval is counted from the moment the rule is first seen
+				continue
+			}
+			if now.Sub(last) >= rule.Trigger.Every {
+				a.scheduled[rule.ID] = now
+				a.execute(ctx, &rule, 0, nil)
+			}
+		case domain.TriggerOffline:
+			sensor, err := a.sensorRepository.GetSensorByID(ctx, rule.Trigger.SensorID)
+			if err != nil {
+				a.logger.ErrorContext(ctx, "cannot get sensor of offline trigger", "rule_id", rule.ID, "error", err)
+				continue
+			}
+			lastSeen := sensor.LastActivity
+			if lastSeen.IsZero() {
+				lastSeen = sensor.RegisteredAt
+			}
+			if now.Sub(lastSeen) < rule.Trigger.After || a.offline[rule.ID].Equal(lastSeen) {
+				continue
+			}
+			a.offline[rule.ID] = lastSeen
+			a.execute(ctx, &rule, 0, nil)
+		}
+	}
+}
+
+// conditionsHold - whether every rule condition holds at the moment, otherwise the reason the rule is skipped
+func (a *Automation) conditionsHold(ctx context.Context, rule *domain.AutomationRule, now time.Time) (bool, string) {
+	for i, condition := range rule.Conditions {
+		switch condition.Kind {
+		case domain.ConditionTimeWindow:
+			if !condition.InWindow(now) {
+				return false, fmt.Sprintf("condition %d: out of %s-%s", i, condition.From, condition.To)
+			}
+		case domain.ConditionSensorState:
+			sensor, err := a.sensorRepository.GetSensorByID(ctx, condition.SensorID)
+			if err != nil {
+				return false, fmt.Sprintf("condition %d: %v", i, err)
+			}
+			if !condition.Operator.Compare(sensor.CurrentState, condition.Value) {
+				return false, fmt.Sprintf("condition %d: sensor %v state is %d", i, condition.SensorID, sensor.CurrentState)
+			}
+		}
+	}
+	return true, ""
+}
+
+// actionJob - rule actions queued for the workers along with the execution they complete
+type actionJob struct {
+	ctx       context.Context
+	rule      domain.AutomationRule
+	depth     int
+	event     *domain.Event
+	execution domain.AutomationExecution
+}
+
+// execute - queues the rule actions unless the rule is cooling down, the chain is too long or a condition fails,
+// the outcome is written to the execution log once the actions have run
+func (a *Automation) execute(ctx context.Context, rule *domain.AutomationRule, depth int, event *domain.Event) {
+	ctx, span := tracing.Start(ctx, "Automation.execute")
+	defer span.End()
+
+	now := time.Now()
+	cooldown := rule.Cooldown
+	if cooldown == 0 {
+		cooldown = DefaultRuleCooldown
+	}
+	if last, ok := a.lastRun[rule.ID]; ok && now.Sub(last) < cooldown {
+		metrics.AutomationExecuted(string(domain.ExecutionSkipped))
+		a.logger.DebugContext(ctx, "automation rule is cooling down", "rule_id", rule.ID)
+		return
+	}
+
+	execution := domain.AutomationExecution{
+		RuleID:    rule.ID,
+		Trigger:   rule.Trigger.Kind,
+		Status:    domain.ExecutionSucceeded,
+		Depth:     depth,
+		StartedAt: now,
+	}
+	if depth >= MaxAutomationDepth {
+		execution.Status = domain.ExecutionSkipped
+		execution.Reason = fmt.Sprintf("loop protection: triggered by a chain of %d rules", depth)
+	} else if ok, reason := a.conditionsHold(ctx, rule, now); !ok {
+		execution.Status = domain.ExecutionSkipped
+		execution.Reason = reason
+	} else {
+		a.lastRun[rule.ID] = now
+		job := actionJob{ctx: ctx, rule: *rule, depth: depth + 1, execution: execution}
+		if event != nil {
+			e := *event
+			job.event = &e
+		}
+		select {
+		case a.actions <- job:
+			return
+		default:
+			metrics.AutomationDropped(metrics.AutomationQueueActions)
+			execution.Status = domain.ExecutionFailed
+			execution.Reason = "action queue is full"
+		}
+	}
+
+	a.saveExecution(ctx, &execution)
+}
+
+func (a *Automation) saveExecution(ctx context.Context, execution *domain.AutomationExecution) {
+	metrics.AutomationExecuted(string(execution.Status))
+	if err := a.automationRepository.SaveExecution(ctx, execution); err != nil {
+		a.logger.ErrorContext(ctx, "cannot save automation execution", "rule_id", execution.RuleID, "error", err)
+	}
+	a.logger.InfoContext(ctx, "automation rule executed", "rule_id", execution.RuleID, "status", execution.Status, "reason", execution.Reason)
+}
+
+// runWorker - runs the queued rule actions until ctx is done, the jobs left in the queue are abandoned
+func (a *Automation) runWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-a.actions:
+			a.runJob(&job)
+		}
+	}
+}
+
+func (a *Automation) runJob(job *actionJob) {
+	ctx, span := tracing.Start(job.ctx, "Automation.runActions")
+	defer span.End()
+
+	if err := a.runActions(WithAutomationDepth(ctx, job.depth), &job.rule, job.depth, job.event); err != nil {
+		job.execution.Status = domain.ExecutionFailed
+		job.execution.Reason = err.Error()
+	}
+	a.saveExecution(ctx, &job.execution)
+}
+
+// webhookPayload - body of the webhook action request
+type webhookPayload struct {
+	RuleID      int64              `json:"rule_id"`
+	RuleName    string             `json:"rule_name"`
+	Trigger     domain.TriggerKind `json:"trigger"`
+	TriggeredAt time.Time          `json:"triggered_at"`
+	Event       *webhookEvent      `json:"event,omitempty"`
+}
+
+type webhookEvent struct {
+	SensorID  int64            `json:"sensor_id"`
+	Payload   int64            `json:"payload"`
+	Values    map[string]int64 `json:"values,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// runActions - runs every action of the rule, a failed action doesn't prevent the following ones
+func (a *Automation) runActions(ctx context.Context, rule *domain.AutomationRule, depth int, event *domain.Event) error {
+	var errs []error
+	for i, action := range rule.Actions {
+		var err error
+		switch action.Kind {
+		case domain.ActionCommand:
+			command := &domain.Command{Action: action.Command, Value: action.Value}
+			_, err = a.actuator.EnqueueCommand(ctx, action.ActuatorID, command, 0)
+		case domain.ActionWebhook:
+			payload := webhookPayload{RuleID: rule.ID, RuleName: rule.Name, Trigger: rule.Trigger.Kind, TriggeredAt: time.Now()}
+			if event != nil {
+				payload.Event = &webhookEvent{SensorID: event.SensorID, Payload: event.Payload, Values: event.Values, Timestamp: event.Timestamp}
+			}
+			var body []byte
+			if body, err = json.Marshal(payload); err == nil {
+				err = a.webhookSender.SendWebhook(ctx, action.URL, body, depth)
+			}
+		case domain.ActionAlert:
+			a.logger.WarnContext(ctx, "automation alert", "rule_id", rule.ID, "rule_name", rule.Name, "message", action.Message)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("action %d (%s): %w", i, action.Kind, err))
+		}
+	}
+	return errors.Join(errs...)
+}
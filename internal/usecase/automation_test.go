@@ -0,0 +1,440 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_automation_CreateRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valid := func() *domain.AutomationRule {
+		return &domain.AutomationRule{
+			Name:    "lights on when dark",
+			Enabled: true,
+			Trigger: domain.Trigger{Kind: domain.TriggerEvent, SensorID: 1, Operator: domain.CompareLt, Value: 10},
+			Actions: []domain.Action{{Kind: domain.ActionCommand, ActuatorID: 1, Command: domain.CommandActionOn}},
+		}
+	}
+
+	t.Run("fail, invalid rule", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).AnyTimes().Return(&domain.Sensor{ID: 1}, nil)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).AnyTimes().Return(nil, ErrSensorNotFound)
+		acr := NewMockActuatorRepository(ctrl)
+		acr.EXPECT().GetActuatorByID(ctx, int64(1)).AnyTimes().Return(&domain.Actuator{ID: 1, Type: domain.ActuatorTypeRelay}, nil)
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().SaveRule(ctx, gomock.Any()).Times(0)
+
+		a := NewAutomation(ar, sr, NewActuator(acr, nil, nil), nil)
+
+		for name, modify := range map[string]func(rule *domain.AutomationRule){
+			"empty name":       func(rule *domain.AutomationRule) { rule.Name = "" },
+			"unknown trigger":  func(rule *domain.AutomationRule) { rule.Trigger.Kind = "sunset" },
+			"unknown operator": func(rule *domain.AutomationRule) { rule.Trigger.Operator = "like" },
+			"unknown sensor":   func(rule *domain.AutomationRule) { rule.Trigger.SensorID = 2 },
+			"unknown channel":  func(rule *domain.AutomationRule) { rule.Trigger.Channel = "humidity" },
+			"frequent schedule": func(rule *domain.AutomationRule) {
+				rule.Trigger = domain.Trigger{Kind: domain.TriggerSchedule, Every: time.Second}
+			},
+			"bad time window": func(rule *domain.AutomationRule) {
+				rule.Conditions = []domain.Condition{{Kind: domain.ConditionTimeWindow, From: "25:00", To: "06:00"}}
+			},
+			"no actions":         func(rule *domain.AutomationRule) { rule.Actions = nil },
+			"unsupported action": func(rule *domain.AutomationRule) { rule.Actions[0].Command = domain.CommandActionOpen },
+			"relative webhook": func(rule *domain.AutomationRule) {
+				rule.Actions[0] = domain.Action{Kind: domain.ActionWebhook, URL: "/hook"}
+			},
+			"internal webhook": func(rule *domain.AutomationRule) {
+				rule.Actions[0] = domain.Action{Kind: domain.ActionWebhook, URL: "http://169.254.169.254/latest/meta-data"}
+			},
+			"localhost webhook": func(rule *domain.AutomationRule) {
+				rule.Actions[0] = domain.Action{Kind: domain.ActionWebhook, URL: "http://localhost:8080/hook"}
+			},
+			"empty alert": func(rule *domain.AutomationRule) { rule.Actions[0] = domain.Action{Kind: domain.ActionAlert} },
+		} {
+			rule := valid()
+			modify(rule)
+			_, err := a.CreateRule(ctx, rule)
+			assert.ErrorIs(t, err, ErrInvalidRule, name)
+		}
+	})
+
+	t.Run("ok, saved", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+		acr := NewMockActuatorRepository(ctrl)
+		acr.EXPECT().GetActuatorByID(ctx, int64(1)).Times(1).Return(&domain.Actuator{ID: 1, Type: domain.ActuatorTypeRelay}, nil)
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().SaveRule(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, rule *domain.AutomationRule) error {
+			rule.ID = 1
+			return nil
+		})
+
+		a := NewAutomation(ar, sr, NewActuator(acr, nil, nil), nil)
+
+		rule, err := a.CreateRule(ctx, valid())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), rule.ID)
+		assert.False(t, rule.CreatedAt.IsZero())
+	})
+}
+
+// runQueuedActions - runs the actions queued by the rules the way a worker does
+func runQueuedActions(a *Automation) {
+	for {
+		select {
+		case job := <-a.actions:
+			a.runJob(&job)
+		default:
+			return
+		}
+	}
+}
+
+func Test_automation_processEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rule := domain.AutomationRule{
+		ID:      1,
+		Name:    "lights on when dark",
+		Enabled: true,
+		Trigger: domain.Trigger{Kind: domain.TriggerEvent, SensorID: 1, Operator: domain.CompareLt, Value: 10},
+		Conditions: []domain.Condition{
+			{Kind: domain.ConditionSensorState, SensorID: 2, Operator: domain.CompareEq, Value: 1},
+		},
+		Actions: []domain.Action{
+			{Kind: domain.ActionCommand, ActuatorID: 1, Command: domain.CommandActionOn},
+			{Kind: domain.ActionAlert, Message: "it's getting dark"},
+		},
+		Cooldown: time.Hour,
+	}
+	disabled := domain.AutomationRule{ID: 2, Trigger: rule.Trigger}
+
+	t.Run("ok, actions run once per cooldown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetRules(ctx).Times(1).Return([]domain.AutomationRule{rule, disabled}, nil) // Cached for the events
+		ar.EXPECT().SaveExecution(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, execution *domain.AutomationExecution) {
+			assert.Equal(t, int64(1), execution.RuleID)
+			assert.Equal(t, domain.ExecutionSucceeded, execution.Status)
+			assert.Equal(t, 0, execution.Depth)
+		})
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).Times(1).Return(&domain.Sensor{ID: 2, CurrentState: 1}, nil)
+		acr := NewMockActuatorRepository(ctrl)
+		acr.EXPECT().GetActuatorByID(gomock.Any(), int64(1)).Times(1).Return(&domain.Actuator{ID: 1, Type: domain.ActuatorTypeRelay}, nil)
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().SaveCommand(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(ctx context.Context, command *domain.Command) error {
+			assert.Equal(t, 1, AutomationDepth(ctx))
+			assert.Equal(t, domain.CommandActionOn, command.Action)
+			return nil
+		})
+		csr := NewMockSubscriptionRepository[domain.Command](ctrl)
+		csr.EXPECT().GetBroadcastHandleById(gomock.Any(), int64(1)).Times(1).Return(nil, ErrSensorNotFound)
+
+		a := NewAutomation(ar, sr, NewActuator(acr, cr, csr), nil)
+
+		a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 20}) // Doesn't match the trigger
+		a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 5})
+		a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 4}) // Cooling down
+		runQueuedActions(a)
+	})
+
+	t.Run("ok, skipped by condition", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetRules(ctx).Times(1).Return([]domain.AutomationRule{rule}, nil)
+		ar.EXPECT().SaveExecution(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, execution *domain.AutomationExecution) {
+			assert.Equal(t, domain.ExecutionSkipped, execution.Status)
+			assert.Contains(t, execution.Reason, "condition 0")
+		})
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).Times(1).Return(&domain.Sensor{ID: 2, CurrentState: 0}, nil)
+
+		a := NewAutomation(ar, sr, NewActuator(nil, nil, nil), nil)
+
+		a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 5})
+	})
+
+	t.Run("ok, loop protection", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetRules(ctx).Times(1).Return([]domain.AutomationRule{rule}, nil)
+		ar.EXPECT().SaveExecution(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, execution *domain.AutomationExecution) {
+			assert.Equal(t, domain.ExecutionSkipped, execution.Status)
+			assert.Equal(t, MaxAutomationDepth, execution.Depth)
+			assert.Contains(t, execution.Reason, "loop protection")
+		})
+
+		a := NewAutomation(ar, nil, nil, nil)
+
+		a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 5, AutomationDepth: MaxAutomationDepth})
+	})
+
+	t.Run("fail, webhook failed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hook := domain.AutomationRule{
+			ID:      3,
+			Name:    "notify",
+			Enabled: true,
+			Trigger: domain.Trigger{Kind: domain.TriggerEvent, SensorID: 1, Channel: "temperature", Operator: domain.CompareGte, Value: 30},
+			Actions: []domain.Action{{Kind: domain.ActionWebhook, URL: "http://example.com/hook"}},
+		}
+
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetRules(ctx).Times(1).Return([]domain.AutomationRule{hook}, nil)
+		ar.EXPECT().SaveExecution(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, execution *domain.AutomationExecution) {
+			assert.Equal(t, domain.ExecutionFailed, execution.Status)
+			assert.Equal(t, 1, execution.Depth)
+			assert.Contains(t, execution.Reason, "connection refused")
+		})
+		ws := NewMockWebhookSender(ctrl)
+		ws.EXPECT().SendWebhook(gomock.Any(), "http://example.com/hook", gomock.Any(), 2).Times(1).
+			DoAndReturn(func(_ context.Context, _ string, body []byte, _ int) error {
+				var payload webhookPayload
+				require.NoError(t, json.Unmarshal(body, &payload))
+				assert.Equal(t, int64(3), payload.RuleID)
+				require.NotNil(t, payload.Event)
+				assert.Equal(t, int64(31), payload.Event.Values["temperature"])
+				return errors.New("connection refused")
+			})
+
+		a := NewAutomation(ar, nil, nil, ws)
+
+		a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 50, Values: map[string]int64{"humidity": 50, "temperature": 31}, AutomationDepth: 1})
+		runQueuedActions(a)
+	})
+
+	t.Run("fail, action queue is full", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		alert := domain.AutomationRule{
+			ID:      4,
+			Name:    "alert",
+			Enabled: true,
+			Trigger: domain.Trigger{Kind: domain.TriggerEvent, SensorID: 1, Operator: domain.CompareGte, Value: 0},
+			Actions: []domain.Action{{Kind: domain.ActionAlert, Message: "event"}},
+		}
+
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetRules(ctx).Times(1).Return([]domain.AutomationRule{alert}, nil)
+		ar.EXPECT().SaveExecution(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, execution *domain.AutomationExecution) {
+			assert.Equal(t, domain.ExecutionFailed, execution.Status)
+			assert.Equal(t, "action queue is full", execution.Reason)
+		})
+
+		a := NewAutomation(ar, nil, nil, nil)
+		for i := 0; i < automationQueueSize; i++ {
+			a.actions <- actionJob{}
+		}
+
+		a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 5})
+		assert.Contains(t, a.lastRun, int64(4))
+	})
+}
+
+func Test_automation_processTimers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	offline := domain.AutomationRule{
+		ID:      1,
+		Name:    "sensor is down",
+		Enabled: true,
+		Trigger: domain.Trigger{Kind: domain.TriggerOffline, SensorID: 1, After: 10 * time.Minute},
+		Actions: []domain.Action{{Kind: domain.ActionAlert, Message: "sensor 1 is offline"}},
+	}
+	schedule := domain.AutomationRule{
+		ID:      2,
+		Name:    "every hour",
+		Enabled: true,
+		Trigger: domain.Trigger{Kind: domain.TriggerSchedule, Every: time.Hour},
+		Actions: []domain.Action{{Kind: domain.ActionAlert, Message: "tick"}},
+	}
+
+	now := time.Now()
+	ar := NewMockAutomationRepository(ctrl)
+	ar.EXPECT().GetRules(ctx).Times(3).Return([]domain.AutomationRule{offline, schedule}, nil)
+	var executed []int64
+	ar.EXPECT().SaveExecution(ctx, gomock.Any()).Times(2).Do(func(_ context.Context, execution *domain.AutomationExecution) {
+		assert.Equal(t, domain.ExecutionSucceeded, execution.Status)
+		executed = append(executed, execution.RuleID)
+	})
+	sr := NewMockSensorRepository(ctrl)
+	sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(3).Return(&domain.Sensor{ID: 1, LastActivity: now.Add(-15 * time.Minute)}, nil)
+
+	a := NewAutomation(ar, sr, nil, nil)
+
+	a.processTimers(ctx, now)                  // Offline fires, schedule starts counting
+	a.processTimers(ctx, now.Add(time.Minute)) // Offline has fired for this activity already
+	a.processTimers(ctx, now.Add(2*time.Hour)) // Schedule fires
+	runQueuedActions(a)
+	assert.Equal(t, []int64{1, 2}, executed)
+}
+
+func Test_automation_forgetDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	schedule := domain.AutomationRule{
+		ID:      2,
+		Trigger: domain.Trigger{Kind: domain.TriggerSchedule, Every: time.Hour},
+	}
+
+	ar := NewMockAutomationRepository(ctrl)
+	gomock.InOrder(
+		ar.EXPECT().GetRules(ctx).Times(1).Return(nil, errors.New("connection refused")),
+		ar.EXPECT().GetRules(ctx).Times(1).Return([]domain.AutomationRule{schedule}, nil), // Disabled, but not deleted
+	)
+
+	a := NewAutomation(ar, nil, nil, nil)
+	now := time.Now()
+	for _, id := range []int64{1, 2} {
+		a.lastRun[id] = now
+		a.scheduled[id] = now
+		a.offline[id] = now
+	}
+
+	a.processTimers(ctx, now) // The rules are unknown, nothing is forgotten
+	assert.Len(t, a.lastRun, 2)
+
+	a.processTimers(ctx, now)
+	for _, state := range []map[int64]time.Time{a.lastRun, a.scheduled, a.offline} {
+		assert.Equal(t, map[int64]time.Time{2: now}, state)
+	}
+}
+
+func Test_automation_rulesCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rule := domain.AutomationRule{
+		ID:      1,
+		Enabled: true,
+		Trigger: domain.Trigger{Kind: domain.TriggerEvent, SensorID: 1, Operator: domain.CompareEq, Value: 1},
+		Actions: []domain.Action{{Kind: domain.ActionAlert, Message: "on"}},
+	}
+
+	ar := NewMockAutomationRepository(ctrl)
+	gomock.InOrder(
+		ar.EXPECT().GetRules(ctx).Times(1).Return(nil, nil),
+		ar.EXPECT().DeleteRule(ctx, int64(2)).Times(1).Return(nil),
+		ar.EXPECT().GetRules(ctx).Times(1).Return(nil, nil),
+		ar.EXPECT().GetRules(ctx).Times(1).Return([]domain.AutomationRule{rule}, nil), // Created by another replica
+	)
+	ar.EXPECT().SaveExecution(ctx, gomock.Any()).Times(1)
+	a := NewAutomation(ar, nil, nil, nil)
+
+	a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 1})
+	a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 1}) // No rules are cached too
+	require.NoError(t, a.DeleteRule(ctx, 2))
+	a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 1}) // Reloaded after the change
+	a.processTimers(ctx, time.Now())                            // Reloaded every tick
+	a.processEvent(ctx, &domain.Event{SensorID: 1, Payload: 1})
+	runQueuedActions(a)
+}
+
+func Test_automation_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rule := domain.AutomationRule{
+		ID:      1,
+		Enabled: true,
+		Trigger: domain.Trigger{Kind: domain.TriggerEvent, SensorID: 1, Operator: domain.CompareGte, Value: 0},
+		Actions: []domain.Action{{Kind: domain.ActionWebhook, URL: "http://example.com/hook"}},
+	}
+
+	ar := NewMockAutomationRepository(ctrl)
+	ar.EXPECT().GetRules(gomock.Any()).AnyTimes().Return([]domain.AutomationRule{rule}, nil)
+	saved := make(chan domain.AutomationExecution, 1)
+	ar.EXPECT().SaveExecution(gomock.Any(), gomock.Any()).Times(1).Do(func(_ context.Context, execution *domain.AutomationExecution) {
+		saved <- *execution
+	})
+	release := make(chan struct{})
+	ws := NewMockWebhookSender(ctrl)
+	ws.EXPECT().SendWebhook(gomock.Any(), rule.Actions[0].URL, gomock.Any(), 1).Times(1).
+		DoAndReturn(func(context.Context, string, []byte, int) error {
+			<-release // A slow webhook holds a worker, not the engine
+			return nil
+		})
+
+	a := NewAutomation(ar, nil, nil, ws)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.Run(ctx, time.Hour)
+	}()
+
+	a.HandleEvent(ctx, domain.Event{SensorID: 1, Payload: 5})
+	require.Eventually(t, func() bool { return len(a.events) == 0 && len(a.actions) == 0 }, time.Second, time.Millisecond)
+	a.HandleEvent(ctx, domain.Event{SensorID: 1, Payload: 6}) // Evaluated while the webhook is running, cooling down
+	require.Eventually(t, func() bool { return len(a.events) == 0 }, time.Second, time.Millisecond)
+
+	close(release)
+	select {
+	case execution := <-saved:
+		assert.Equal(t, domain.ExecutionSucceeded, execution.Status)
+	case <-time.After(time.Second):
+		t.Fatal("the execution is not saved")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after the context is done")
+	}
+}
+
+func Test_automation_HandleEvent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := NewAutomation(nil, nil, nil, nil)
+	for i := 0; i < automationQueueSize+1; i++ {
+		a.HandleEvent(ctx, domain.Event{SensorID: int64(i)}) // The last one is dropped instead of blocking
+	}
+	assert.Len(t, a.events, automationQueueSize)
+
+	assert.Equal(t, 0, AutomationDepth(ctx))
+	assert.Equal(t, 2, AutomationDepth(WithAutomationDepth(ctx, 2)))
+}
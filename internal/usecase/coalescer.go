@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/tracing"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var ErrEventNotCoalescible = errors.New("event cannot be coalesced")
+
+// Coalescer - collapses the bursts of the contact closure events (a bouncing contact) into the latest state
+// of the sensor, which is ingested once per window instead of rejecting the events over the rate limit
+type Coalescer struct {
+	event            *Event
+	sensorRepository SensorRepository
+	logger           *slog.Logger
+	heartbeat        func()
+
+	mu      sync.Mutex
+	pending map[string]*domain.Event // Latest coalesced event by the sensor serial number
+}
+
+func NewCoalescer(event *Event, sr SensorRepository, options ...Option) *Coalescer {
+	o := applyOptions(options)
+	return &Coalescer{
+		event:            event,
+		sensorRepository: sr,
+		logger:           o.logger,
+		heartbeat:        o.heartbeat,
+		pending:          make(map[string]*domain.Event),
+	}
+}
+
+// Coalesce - keeps the event until the next flush replacing the pending one of the same sensor,
+// fails with ErrEventNotCoalescible unless the event is sent by a known contact closure sensor
+func (c *Coalescer) Coalesce(ctx context.Context, event *domain.Event) (err error) {
+	ctx, span := tracing.Start(ctx, "Coalescer.Coalesce")
+	defer tracing.End(span, &err)
+
+	if event == nil {
+		return errors.New("got nil event at Coalesce()")
+	}
+	sens, err := c.sensorRepository.GetSensorBySerialNumber(ctx, event.SensorSerialNumber)
+	if err != nil {
+		if errors.Is(err, ErrSensorNotFound) {
+			return fmt.Errorf("%w: %w", ErrEventNotCoalescible, err)
+		}
+		return fmt.Errorf("cannot get sensor %q: %w", event.SensorSerialNumber, err)
+	}
+	if sens.Type != domain.SensorTypeContactClosure {
+		return fmt.Errorf("%w: sensor %q is not a contact closure", ErrEventNotCoalescible, sens.SerialNumber)
+	}
+
+	coalesced := *event
+	c.mu.Lock()
+	c.pending[event.SensorSerialNumber] = &coalesced
+	c.mu.Unlock()
+	return nil
+}
+
+// Pending - number of the sensors having a coalesced event not ingested yet
+func (c *Coalescer) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
+
+// Run - ingests the pending events every window until ctx is done, the rest are flushed on return
+func (c *Coalescer) Run(ctx context.Context, window time.Duration) {
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+
+	for {
+		c.heartbeat()
+		select {
+		case <-ctx.Done():
+			c.flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			c.flush(ctx)
+		}
+	}
+}
+
+func (c *Coalescer) flush(ctx context.Context) {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[string]*domain.Event)
+	c.mu.Unlock()
+
+	for _, event := range pending {
+		if err := c.event.ReceiveEvent(ctx, event); err != nil && !errors.Is(err, ErrDuplicateEvent) {
+			c.logger.ErrorContext(ctx, "cannot ingest coalesced event",
+				"serial_number", event.SensorSerialNumber, "error", err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_Coalesce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, not a contact closure or unknown sensor", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1").Times(1).Return(&domain.Sensor{
+			ID: 1, SerialNumber: "1", Type: domain.SensorTypeTemperature,
+		}, nil)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "2").Times(1).Return(nil, ErrSensorNotFound)
+
+		c := NewCoalescer(nil, sr)
+		assert.ErrorIs(t, c.Coalesce(ctx, &domain.Event{SensorSerialNumber: "1"}), ErrEventNotCoalescible)
+		assert.ErrorIs(t, c.Coalesce(ctx, &domain.Event{SensorSerialNumber: "2"}), ErrEventNotCoalescible)
+		assert.Zero(t, c.Pending())
+	})
+
+	t.Run("ok, latest event is ingested on flush", func(t *testing.T) {
+		ctx := context.Background()
+		contact := &domain.Sensor{ID: 1, SerialNumber: "1", Type: domain.SensorTypeContactClosure}
+		start := time.Now()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(gomock.Any(), "1").Times(4).Return(contact, nil)
+		sr.EXPECT().UpdateSensorState(gomock.Any(), gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(1), s.CurrentState)
+		})
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(gomock.Any(), gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+			assert.Equal(t, start.Add(2*time.Millisecond), event.Timestamp)
+		})
+		esr := NewMockSubscriptionRepository[domain.Event](ctrl)
+		esr.EXPECT().GetBroadcastHandleById(gomock.Any(), int64(1)).Times(1).Return(nil, ErrSensorNotFound)
+
+		c := NewCoalescer(NewEvent(er, sr, esr), sr)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.Coalesce(ctx, &domain.Event{
+				Timestamp:          start.Add(time.Duration(i) * time.Millisecond),
+				SensorSerialNumber: "1",
+				Payload:            int64((i + 1) % 2),
+			}))
+		}
+		assert.Equal(t, 1, c.Pending())
+
+		// The pending events are flushed on shutdown as well
+		runCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		c.Run(runCtx, time.Hour)
+		assert.Zero(t, c.Pending())
+	})
+}
+
+func Test_coalescer_Run(t *testing.T) {
+	beats := 0
+	c := NewCoalescer(nil, nil, WithHeartbeat(func() { beats++ }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	c.Run(ctx, 10*time.Millisecond)
+	assert.Greater(t, beats, 1, "beats on every flush")
+}
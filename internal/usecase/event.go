@@ -5,20 +5,54 @@ import (
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/metrics"
+	"homework/internal/tracing"
+	"log/slog"
+	"slices"
+	"sort"
 	"time"
 )
 
+// historyEnd - the upper bound of the whole sensor history
+var historyEnd = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
 type Event struct {
 	eventRepository             EventRepository
 	sensorRepository            SensorRepository
 	eventSubscriptionRepository SubscriptionRepository[domain.Event]
+	listeners                   []EventListener
+	provisioning                *Provisioning
+	dedupWindow                 time.Duration
+	logger                      *slog.Logger
 }
 
-func NewEvent(er EventRepository, sr SensorRepository, esr SubscriptionRepository[domain.Event]) *Event {
+func NewEvent(er EventRepository, sr SensorRepository, esr SubscriptionRepository[domain.Event], options ...Option) *Event {
+	o := applyOptions(options)
 	return &Event{
 		eventRepository:             er,
 		sensorRepository:            sr,
 		eventSubscriptionRepository: esr,
+		dedupWindow:                 o.dedupWindow,
+		logger:                      o.logger,
+	}
+}
+
+// AddListener - makes the listener receive every ingested event, must be called before the events are received
+func (e *Event) AddListener(l EventListener) {
+	e.listeners = append(e.listeners, l)
+}
+
+// SetProvisioning - makes the events of the unknown sensors buffered in the pending list instead of being rejected,
+// must be called before the events are received
+func (e *Event) SetProvisioning(p *Provisioning) {
+	e.provisioning = p
+}
+
+func (e *Event) notifyListeners(ctx context.Context, event *domain.Event) {
+	notified := *event
+	notified.AutomationDepth = AutomationDepth(ctx)
+	for _, l := range e.listeners {
+		l.HandleEvent(ctx, notified)
 	}
 }
 
@@ -31,37 +65,244 @@ func (e *Event) broadcastEvent(ctx context.Context, sensId int64, event *domain.
 		return fmt.Errorf("can't get event broadcast handle: %w", err)
 	}
 
-	handle.Ch <- *event
+	broadcasted := *event
+	broadcasted.TraceParent = tracing.TraceParent(ctx)
+	handle.Ch <- broadcasted
+	return nil
+}
+
+func checkRange(sensorType domain.SensorType, raw int64) error {
+	info, ok := domain.LookupSensorType(sensorType)
+	if !ok || info.InRange(raw) {
+		return nil
+	}
+	return fmt.Errorf("%w: %v %s is not in [%v, %v] for %q", ErrPayloadOutOfRange,
+		info.Value(raw), info.Unit, info.Range.Min, info.Range.Max, sensorType)
+}
+
+// resolveChannels - validates the event values against the sensor channels,
+// a plain payload sent by a multi-channel sensor is the value of its first channel
+func resolveChannels(sens *domain.Sensor, event *domain.Event) error {
+	if !sens.IsMultiChannel() {
+		if len(event.Values) > 0 {
+			return fmt.Errorf("%w: sensor %v has no channels", ErrUnknownChannel, sens.SerialNumber)
+		}
+		return nil
+	}
+
+	if len(event.Values) == 0 {
+		event.Values = map[string]int64{sens.Channels[0].Name: event.Payload}
+	}
+	for name := range event.Values {
+		if _, ok := sens.Channel(name); !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownChannel, name)
+		}
+	}
+	return nil
+}
+
+// calibrate - replaces the event values with the calibrated raw readings,
+// previous are the preceding events of the sensor in chronological order used for smoothing
+func calibrate(sens *domain.Sensor, event *domain.Event, previous []*domain.Event) {
+	if !sens.IsMultiChannel() {
+		event.Payload = sens.Calibration.Apply(event.RawPayload, previousRaw(sens.Calibration, "", previous))
+		return
+	}
+
+	event.Values = make(map[string]int64, len(event.RawValues))
+	for name, raw := range event.RawValues {
+		c, _ := sens.Channel(name)
+		event.Values[name] = c.Calibration.Apply(raw, previousRaw(c.Calibration, name, previous))
+	}
+}
+
+// previousRaw - the latest raw readings of the channel needed by the calibration,
+// the events not carrying the channel are skipped
+func previousRaw(calibration domain.Calibration, channel string, previous []*domain.Event) []int64 {
+	depth := calibration.Depth()
+	if depth == 0 {
+		return nil
+	}
+	res := make([]int64, 0, depth)
+	for i := len(previous) - 1; i >= 0 && len(res) < depth; i-- {
+		if raw, ok := previous[i].RawChannelValue(channel); ok {
+			res = append(res, raw)
+		}
+	}
+	slices.Reverse(res)
+	return res
+}
+
+// checkEventRange - checks the calibrated event values against the sensor or channel type ranges
+func checkEventRange(sens *domain.Sensor, event *domain.Event) error {
+	if !sens.IsMultiChannel() {
+		return checkRange(sens.Type, event.Payload)
+	}
+	for name, value := range event.Values {
+		c, _ := sens.Channel(name)
+		if err := checkRange(c.Type, value); err != nil {
+			return fmt.Errorf("channel %q: %w", name, err)
+		}
+	}
 	return nil
 }
 
-func (e *Event) ReceiveEvent(ctx context.Context, event *domain.Event) error {
+// mergeValues - the latest values of the sensor channels after the event,
+// the payload of a multi-channel event is the latest value of the first channel
+func mergeValues(sens *domain.Sensor, current map[string]int64, event *domain.Event) map[string]int64 {
+	merged := make(map[string]int64, len(sens.Channels))
+	for name, value := range current {
+		merged[name] = value
+	}
+	for name, value := range event.Values {
+		merged[name] = value
+	}
+	event.Payload = merged[sens.Channels[0].Name]
+	return merged
+}
+
+func (e *Event) ReceiveEvent(ctx context.Context, event *domain.Event) (err error) {
+	ctx, span := tracing.Start(ctx, "Event.ReceiveEvent")
+	defer tracing.End(span, &err)
+
+	return e.receiveEvent(ctx, event, false)
+}
+
+// ReplayEvent - ingests an event received before the sensor has been registered, the listeners don't get it
+// as it is not current, and the sensor state is kept if the sensor has reported a later event already
+func (e *Event) ReplayEvent(ctx context.Context, event *domain.Event) (err error) {
+	ctx, span := tracing.Start(ctx, "Event.ReplayEvent")
+	defer tracing.End(span, &err)
+
+	return e.receiveEvent(ctx, event, true)
+}
+
+// bufferPendingEvent - passes the event of an unknown sensor to the provisioning, false if the event is rejected
+// as the provisioning is disabled, the serial number is invalid or the pending list is full
+func (e *Event) bufferPendingEvent(ctx context.Context, event *domain.Event) (bool, error) {
+	if e.provisioning == nil {
+		return false, nil
+	}
+	err := e.provisioning.bufferEvent(ctx, event)
+	switch {
+	case err == nil:
+		metrics.EventBuffered()
+		e.logger.DebugContext(ctx, "event from pending sensor buffered", "serial_number", event.SensorSerialNumber)
+		return true, nil
+	case errors.Is(err, ErrWrongSensorSerialNumber), errors.Is(err, ErrPendingSensorsLimit):
+		e.logger.WarnContext(ctx, "event from unknown sensor not buffered", "serial_number", event.SensorSerialNumber,
+			"error", err)
+		return false, nil
+	}
+	metrics.EventIngestionFailed(metrics.ReasonPendingSaveError)
+	return false, fmt.Errorf("cannot buffer event of unknown sensor %q: %w", event.SensorSerialNumber, err)
+}
+
+func (e *Event) receiveEvent(ctx context.Context, event *domain.Event, replay bool) error {
 	if event == nil {
+		metrics.EventIngestionFailed(metrics.ReasonInvalidEvent)
 		return errors.New("got nil event at ReceiveEvent()")
 	}
 	if event.Timestamp.IsZero() {
+		metrics.EventIngestionFailed(metrics.ReasonInvalidEvent)
 		return ErrInvalidEventTimestamp
 	}
 	sens, err := e.sensorRepository.GetSensorBySerialNumber(ctx, event.SensorSerialNumber)
 	if err != nil {
+		if errors.Is(err, ErrSensorNotFound) {
+			buffered, bufferErr := e.bufferPendingEvent(ctx, event)
+			if bufferErr != nil {
+				return bufferErr
+			}
+			if buffered {
+				return ErrSensorPending
+			}
+			metrics.EventIngestionFailed(metrics.ReasonUnknownSensor)
+			e.logger.WarnContext(ctx, "event from unknown sensor", "serial_number", event.SensorSerialNumber)
+		} else {
+			metrics.EventIngestionFailed(metrics.ReasonSensorLookupError)
+		}
 		return fmt.Errorf("invalid sensor serial number in event %v: %w", event, err)
 	}
 	event.SensorID = sens.ID
 
-	if err := e.eventRepository.SaveEvent(ctx, event); err != nil {
+	if err := resolveChannels(sens, event); err != nil {
+		metrics.EventIngestionFailed(metrics.ReasonUnknownChannel)
+		return err
+	}
+
+	event.RawPayload, event.RawValues = event.Payload, event.Values
+	var previous []*domain.Event
+	if depth := sens.CalibrationDepth(); depth > 0 {
+		previous, err = e.eventRepository.GetLastEventsBySensorID(ctx, sens.ID, depth)
+		if err != nil {
+			metrics.EventIngestionFailed(metrics.ReasonEventLookupError)
+			return fmt.Errorf("cannot get previous events of sensor %v: %w", sens.ID, err)
+		}
+	}
+	calibrate(sens, event, previous)
+	if err := checkEventRange(sens, event); err != nil {
+		metrics.EventIngestionFailed(metrics.ReasonOutOfRange)
+		return err
+	}
+
+	var currentValues map[string]int64
+	if sens.IsMultiChannel() {
+		currentValues = mergeValues(sens, sens.CurrentValues, event)
+	}
+
+	if err := e.eventRepository.SaveEvent(ctx, event); errors.Is(err, ErrDuplicateEvent) {
+		// The event has been accepted before, the sensor state and the subscribers already have it
+		metrics.EventIngestionFailed(metrics.ReasonDuplicate)
+		e.logger.DebugContext(ctx, "duplicate event", "sensor_id", sens.ID, "idempotency_key", event.IdempotencyKey)
+		return ErrDuplicateEvent
+	} else if err != nil {
+		metrics.EventIngestionFailed(metrics.ReasonEventSaveError)
 		return fmt.Errorf("cannot save event %v: %w", event, err)
 	}
 
-	sens.CurrentState = event.Payload
-	sens.LastActivity = event.Timestamp
-	if err := e.sensorRepository.SaveSensor(ctx, sens); err != nil {
-		return fmt.Errorf("cannot save new sensor state %v: %w", sens, err)
+	if !replay || !event.Timestamp.Before(sens.LastActivity) {
+		sens.CurrentState = event.Payload
+		if sens.IsMultiChannel() {
+			sens.CurrentValues = currentValues
+		}
+		sens.LastActivity = event.Timestamp
+		// only the state is written, so the concurrent changes of the other fields are kept
+		if err := e.sensorRepository.UpdateSensorState(ctx, sens); err != nil {
+			metrics.EventIngestionFailed(metrics.ReasonSensorSaveError)
+			return fmt.Errorf("cannot save new sensor state %v: %w", sens, err)
+		}
+	}
+	metrics.EventIngested(string(sens.Type))
+	e.logger.DebugContext(ctx, "event ingested", "sensor_id", sens.ID, "payload", event.Payload, "replay", replay)
+	if !replay {
+		e.notifyListeners(ctx, event)
 	}
 
-	return e.broadcastEvent(ctx, sens.ID, event)
+	if err := e.broadcastEvent(ctx, sens.ID, event); err != nil {
+		metrics.EventIngestionFailed(metrics.ReasonBroadcastError)
+		e.logger.ErrorContext(ctx, "event saved but not broadcast", "sensor_id", sens.ID, "error", err)
+		return err
+	}
+	return nil
 }
 
-func (e *Event) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error) {
+// ReleaseIdempotencyKeys - makes the idempotency keys of the events accepted earlier than the dedup window reusable,
+// is run periodically, so a key is kept at least the window and at most the window plus the run period
+func (e *Event) ReleaseIdempotencyKeys(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "Event.ReleaseIdempotencyKeys")
+	defer tracing.End(span, &err)
+
+	if err := e.eventRepository.ReleaseIdempotencyKeys(ctx, time.Now().Add(-e.dedupWindow)); err != nil {
+		return fmt.Errorf("cannot release idempotency keys: %w", err)
+	}
+	return nil
+}
+
+func (e *Event) GetLastEventBySensorID(ctx context.Context, id int64) (_ *domain.Event, err error) {
+	ctx, span := tracing.Start(ctx, "Event.GetLastEventBySensorID")
+	defer tracing.End(span, &err)
+
 	event, err := e.eventRepository.GetLastEventBySensorID(ctx, id)
 	if err != nil {
 		return event, fmt.Errorf("cannot get last event for id %v: %w", id, err)
@@ -70,7 +311,10 @@ func (e *Event) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.E
 	return event, err
 }
 
-func (e *Event) GetEventsHistoryBySensorID(ctx context.Context, id int64, startTime, endTime time.Time) ([]*domain.Event, error) {
+func (e *Event) GetEventsHistoryBySensorID(ctx context.Context, id int64, startTime, endTime time.Time) (_ []*domain.Event, err error) {
+	ctx, span := tracing.Start(ctx, "Event.GetEventsHistoryBySensorID")
+	defer tracing.End(span, &err)
+
 	if startTime.After(endTime) {
 		return nil, ErrInvalidEventTimestamp
 	}
@@ -81,3 +325,44 @@ func (e *Event) GetEventsHistoryBySensorID(ctx context.Context, id int64, startT
 
 	return events, err
 }
+
+// RecalibrateHistory - recomputes the stored sensor history from the raw readings with the current sensor calibration,
+// returns the number of the recomputed events
+func (e *Event) RecalibrateHistory(ctx context.Context, id int64) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "Event.RecalibrateHistory")
+	defer tracing.End(span, &err)
+
+	sens, err := e.sensorRepository.GetSensorByID(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get sensor %v: %w", id, err)
+	}
+	events, err := e.eventRepository.GetEventsHistoryBySensorID(ctx, id, time.Time{}, historyEnd)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get events history for id %v: %w", id, err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	var currentValues map[string]int64
+	for i, event := range events {
+		calibrate(sens, event, events[:i])
+		if sens.IsMultiChannel() {
+			currentValues = mergeValues(sens, currentValues, event)
+		}
+	}
+	if err := e.eventRepository.UpdateEvents(ctx, events); err != nil {
+		return 0, fmt.Errorf("cannot save recalibrated events of sensor %v: %w", id, err)
+	}
+
+	sens.CurrentState = events[len(events)-1].Payload
+	if sens.IsMultiChannel() {
+		sens.CurrentValues = currentValues
+	}
+	if err := e.sensorRepository.UpdateSensorState(ctx, sens); err != nil {
+		return 0, fmt.Errorf("cannot save new sensor state %v: %w", sens, err)
+	}
+	e.logger.InfoContext(ctx, "sensor history recalibrated", "sensor_id", id, "events", len(events))
+	return len(events), nil
+}
@@ -41,6 +41,112 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		assert.ErrorIs(t, err, ErrSensorNotFound)
 	})
 
+	t.Run("err, payload out of range", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(&domain.Sensor{
+			ID:   1,
+			Type: domain.SensorTypeTemperature,
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, nil)
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			Payload:            13000, // 130.00 °C
+		})
+		assert.ErrorIs(t, err, ErrPayloadOutOfRange)
+	})
+
+	t.Run("err, unknown channel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(2).Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeADC,
+			Channels: []domain.Channel{{Name: "temperature", Type: domain.SensorTypeTemperature}},
+		}, nil)
+
+		e := NewEvent(nil, sr, nil)
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			Values:             map[string]int64{"pressure": 1},
+		})
+		assert.ErrorIs(t, err, ErrUnknownChannel)
+
+		err = e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			Values:             map[string]int64{"temperature": 13000},
+		})
+		assert.ErrorIs(t, err, ErrPayloadOutOfRange)
+	})
+
+	t.Run("err, values for single-value sensor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		e := NewEvent(nil, sr, nil)
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			Values:             map[string]int64{"temperature": 2315},
+		})
+		assert.ErrorIs(t, err, ErrUnknownChannel)
+	})
+
+	t.Run("ok, multi-channel event", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(&domain.Sensor{
+			ID:   1,
+			Type: domain.SensorTypeADC,
+			Channels: []domain.Channel{
+				{Name: "temperature", Type: domain.SensorTypeTemperature},
+				{Name: "humidity", Type: domain.SensorTypeHumidity},
+			},
+			CurrentValues: map[string]int64{"temperature": 2100, "humidity": 4000},
+		}, nil)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(2100), s.CurrentState)
+			assert.Equal(t, map[string]int64{"temperature": 2100, "humidity": 4500}, s.CurrentValues)
+		})
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, event *domain.Event) error {
+			assert.Equal(t, int64(2100), event.Payload) // the last known value of the first channel
+			assert.Equal(t, map[string]int64{"humidity": 4500}, event.Values)
+			return nil
+		})
+
+		esr := NewMockSubscriptionRepository[domain.Event](ctrl)
+		esr.EXPECT().GetBroadcastHandleById(ctx, int64(1)).Times(1).Return(nil, ErrSensorNotFound)
+
+		e := NewEvent(er, sr, esr)
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			Values:             map[string]int64{"humidity": 4500},
+		})
+		assert.NoError(t, err)
+	})
+
 	t.Run("err, event save error", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -64,6 +170,29 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		assert.ErrorIs(t, err, expectedError)
 	})
 
+	t.Run("fail, duplicate event", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(&domain.Sensor{
+			ID: 1,
+		}, nil)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(0)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(ErrDuplicateEvent)
+
+		e := NewEvent(er, sr, nil)
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			IdempotencyKey:     "retry-1",
+		})
+		assert.ErrorIs(t, err, ErrDuplicateEvent)
+	})
+
 	t.Run("err, sensor save error", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -74,7 +203,7 @@ func Test_event_ReceiveEvent(t *testing.T) {
 			ID: 1,
 		}, nil)
 		expectedError := errors.New("some error")
-		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Times(1).Return(expectedError)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Times(1).Return(expectedError)
 
 		er := NewMockEventRepository(ctrl)
 		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(nil)
@@ -97,7 +226,7 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(&domain.Sensor{
 			ID: 1,
 		}, nil)
-		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
 			assert.Equal(t, int64(8), s.CurrentState)
 			assert.NotEmpty(t, s.LastActivity)
 		})
@@ -135,3 +264,194 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func Test_event_ReceiveEvent_calibration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	calibratedSensor := func() *domain.Sensor {
+		return &domain.Sensor{
+			ID:   1,
+			Type: domain.SensorTypeTemperature,
+			Calibration: domain.Calibration{
+				{Kind: domain.CalibrationGain, Value: 2},
+				{Kind: domain.CalibrationOffset, Value: -100},
+				{Kind: domain.CalibrationMovingAverage, Window: 2},
+			},
+		}
+	}
+
+	t.Run("ok, calibrated payload and raw payload kept", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(calibratedSensor(), nil)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(2000), s.CurrentState)
+		})
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetLastEventsBySensorID(ctx, int64(1), 1).Times(1).Return([]*domain.Event{
+			{SensorID: 1, Payload: 1900, RawPayload: 1000},
+		}, nil)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, event *domain.Event) error {
+			assert.Equal(t, int64(2000), event.Payload) // ((1000, 1100) * 2 - 100) averaged
+			assert.Equal(t, int64(1100), event.RawPayload)
+			return nil
+		})
+
+		esr := NewMockSubscriptionRepository[domain.Event](ctrl)
+		esr.EXPECT().GetBroadcastHandleById(ctx, int64(1)).Times(1).Return(nil, ErrSensorNotFound)
+
+		e := NewEvent(er, sr, esr)
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			Payload:            1100,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("err, calibrated payload out of range", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sens := calibratedSensor()
+		sens.Calibration = domain.Calibration{{Kind: domain.CalibrationGain, Value: 100}}
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(sens, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, nil)
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			Payload:            2000,
+		})
+		assert.ErrorIs(t, err, ErrPayloadOutOfRange)
+	})
+
+	t.Run("err, previous events lookup error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(calibratedSensor(), nil)
+
+		expectedError := errors.New("some error")
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetLastEventsBySensorID(ctx, int64(1), 1).Times(1).Return(nil, expectedError)
+
+		e := NewEvent(er, sr, nil)
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "123",
+			Payload:            1100,
+		})
+		assert.ErrorIs(t, err, expectedError)
+	})
+}
+
+func Test_event_RecalibrateHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("err, sensor not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(nil, ErrSensorNotFound)
+
+		e := NewEvent(nil, sr, nil)
+		_, err := e.RecalibrateHistory(ctx, 1)
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("ok, history recomputed from raw payload", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{
+			ID:   1,
+			Type: domain.SensorTypeADC,
+			Calibration: domain.Calibration{
+				{Kind: domain.CalibrationOffset, Value: 10},
+				{Kind: domain.CalibrationMovingAverage, Window: 2},
+			},
+		}, nil)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(260), s.CurrentState)
+		})
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetEventsHistoryBySensorID(ctx, int64(1), gomock.Any(), gomock.Any()).Times(1).Return([]*domain.Event{
+			{Timestamp: now.Add(2 * time.Second), SensorID: 1, Payload: 300, RawPayload: 300},
+			{Timestamp: now, SensorID: 1, Payload: 100, RawPayload: 100},
+			{Timestamp: now.Add(time.Second), SensorID: 1, Payload: 200, RawPayload: 200},
+		}, nil)
+		er.EXPECT().UpdateEvents(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, events []*domain.Event) error {
+			payloads := make([]int64, 0, len(events))
+			for _, event := range events {
+				payloads = append(payloads, event.Payload)
+			}
+			assert.Equal(t, []int64{110, 160, 260}, payloads)
+			return nil
+		})
+
+		e := NewEvent(er, sr, nil)
+		recomputed, err := e.RecalibrateHistory(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, recomputed)
+	})
+}
+
+func Test_event_ReceiveEvent_listeners(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithAutomationDepth(ctx, 2)
+
+	sr := NewMockSensorRepository(ctrl)
+	sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(&domain.Sensor{ID: 1}, nil)
+	sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Return(nil)
+	er := NewMockEventRepository(ctrl)
+	er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(nil)
+	esr := NewMockSubscriptionRepository[domain.Event](ctrl)
+	esr.EXPECT().GetBroadcastHandleById(ctx, int64(1)).Times(1).Return(nil, ErrSensorNotFound)
+
+	listener := NewMockEventListener(ctrl)
+	listener.EXPECT().HandleEvent(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, event domain.Event) {
+		assert.Equal(t, int64(1), event.SensorID)
+		assert.Equal(t, int64(8), event.Payload)
+		assert.Equal(t, 2, event.AutomationDepth)
+	})
+
+	e := NewEvent(er, sr, esr)
+	e.AddListener(listener)
+
+	err := e.ReceiveEvent(ctx, &domain.Event{Timestamp: time.Now(), SensorSerialNumber: "123", Payload: 8})
+	assert.NoError(t, err)
+}
+
+func Test_event_ReleaseIdempotencyKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	er := NewMockEventRepository(ctrl)
+	er.EXPECT().ReleaseIdempotencyKeys(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, before time.Time) error {
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), before, time.Minute)
+		return nil
+	})
+
+	e := NewEvent(er, nil, nil, WithDedupWindow(time.Hour))
+	assert.NoError(t, e.ReleaseIdempotencyKeys(ctx))
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/tracing"
+	"log/slog"
+)
+
+type Location struct {
+	locationRepository LocationRepository
+	sensorRepository   SensorRepository
+	logger             *slog.Logger
+}
+
+func NewLocation(lr LocationRepository, sr SensorRepository, options ...Option) *Location {
+	o := applyOptions(options)
+	return &Location{
+		locationRepository: lr,
+		sensorRepository:   sr,
+		logger:             o.logger,
+	}
+}
+
+func (l *Location) CreateLocation(ctx context.Context, location *domain.Location) (_ *domain.Location, err error) {
+	ctx, span := tracing.Start(ctx, "Location.CreateLocation")
+	defer tracing.End(span, &err)
+
+	if location == nil {
+		return location, errors.New("got nil location at CreateLocation()")
+	}
+	if location.Name == "" {
+		return location, fmt.Errorf("%w: empty name", ErrInvalidLocation)
+	}
+	if !location.Kind.IsValid() {
+		return location, fmt.Errorf("%w: unknown kind %q", ErrInvalidLocation, location.Kind)
+	}
+
+	if location.ParentID == 0 {
+		if location.Kind != domain.LocationKindHome {
+			return location, fmt.Errorf("%w: %s must have a parent", ErrInvalidLocation, location.Kind)
+		}
+	} else {
+		parent, err := l.locationRepository.GetLocationByID(ctx, location.ParentID)
+		if err != nil {
+			return location, fmt.Errorf("got invalid parent location id (%v): %w", location.ParentID, err)
+		}
+		if !parent.Kind.CanContain(location.Kind) {
+			return location, fmt.Errorf("%w: %s can't be placed in %s", ErrInvalidLocation, location.Kind, parent.Kind)
+		}
+	}
+
+	if err := l.locationRepository.SaveLocation(ctx, location); err != nil { // Modifies location assigning new id
+		return location, err
+	}
+	l.logger.InfoContext(ctx, "location created", "location_id", location.ID, "kind", location.Kind)
+	return location, nil
+}
+
+func (l *Location) GetLocations(ctx context.Context) (_ []domain.Location, err error) {
+	ctx, span := tracing.Start(ctx, "Location.GetLocations")
+	defer tracing.End(span, &err)
+
+	locations, err := l.locationRepository.GetLocations(ctx)
+	if err != nil {
+		return locations, fmt.Errorf("cannot get locations from repository: %w", err)
+	}
+	return locations, nil
+}
+
+func (l *Location) GetLocationByID(ctx context.Context, id int64) (_ *domain.Location, err error) {
+	ctx, span := tracing.Start(ctx, "Location.GetLocationByID")
+	defer tracing.End(span, &err)
+
+	location, err := l.locationRepository.GetLocationByID(ctx, id)
+	if err != nil {
+		return location, fmt.Errorf("cannot get location from repository for id %v: %w", id, err)
+	}
+	return location, nil
+}
+
+// AssignSensor - places the sensor in the location, a sensor is placed in a single location at a time
+func (l *Location) AssignSensor(ctx context.Context, locationID, sensorID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "Location.AssignSensor")
+	defer tracing.End(span, &err)
+
+	_, err = l.locationRepository.GetLocationByID(ctx, locationID)
+	if err != nil {
+		return fmt.Errorf("got invalid location id (%v): %w", locationID, err)
+	}
+	_, err = updateSensor(ctx, l.sensorRepository, sensorID, 0, func(sensor *domain.Sensor) error {
+		sensor.LocationID = locationID
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot assign sensor %v to location: %w", sensorID, err)
+	}
+	l.logger.InfoContext(ctx, "sensor assigned to location", "location_id", locationID, "sensor_id", sensorID)
+	return nil
+}
+
+// GetLocationSensors - sensors placed in the location and in all its nested locations
+func (l *Location) GetLocationSensors(ctx context.Context, locationID int64) (_ []domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "Location.GetLocationSensors")
+	defer tracing.End(span, &err)
+
+	_, err = l.locationRepository.GetLocationByID(ctx, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("got invalid location id (%v): %w", locationID, err)
+	}
+	locations, err := l.locationRepository.GetLocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get locations from repository: %w", err)
+	}
+	subtree := domain.LocationSubtree(locations, locationID)
+
+	sensors, err := l.sensorRepository.GetSensors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get sensors from repository: %w", err)
+	}
+	result := make([]domain.Sensor, 0)
+	for _, s := range sensors {
+		if _, ok := subtree[s.LocationID]; ok {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
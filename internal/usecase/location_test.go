@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_location_CreateLocation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid location", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lr := NewMockLocationRepository(ctrl)
+		lr.EXPECT().SaveLocation(ctx, gomock.Any()).Times(0)
+
+		l := NewLocation(lr, nil)
+
+		for _, location := range []domain.Location{
+			{Kind: domain.LocationKindHome},
+			{Kind: "garage", Name: "Garage"},
+			{Kind: domain.LocationKindRoom, Name: "Kitchen"},
+		} {
+			_, err := l.CreateLocation(ctx, &location)
+			assert.ErrorIs(t, err, ErrInvalidLocation)
+		}
+	})
+
+	t.Run("fail, parent not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lr := NewMockLocationRepository(ctrl)
+		lr.EXPECT().GetLocationByID(ctx, int64(1)).Times(1).Return(nil, ErrLocationNotFound)
+		lr.EXPECT().SaveLocation(ctx, gomock.Any()).Times(0)
+
+		l := NewLocation(lr, nil)
+
+		_, err := l.CreateLocation(ctx, &domain.Location{ParentID: 1, Kind: domain.LocationKindRoom, Name: "Kitchen"})
+		assert.ErrorIs(t, err, ErrLocationNotFound)
+	})
+
+	t.Run("fail, floor in a room", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lr := NewMockLocationRepository(ctrl)
+		lr.EXPECT().GetLocationByID(ctx, int64(1)).Times(1).Return(&domain.Location{ID: 1, Kind: domain.LocationKindRoom}, nil)
+		lr.EXPECT().SaveLocation(ctx, gomock.Any()).Times(0)
+
+		l := NewLocation(lr, nil)
+
+		_, err := l.CreateLocation(ctx, &domain.Location{ParentID: 1, Kind: domain.LocationKindFloor, Name: "First"})
+		assert.ErrorIs(t, err, ErrInvalidLocation)
+	})
+
+	t.Run("ok, room on a floor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lr := NewMockLocationRepository(ctrl)
+		lr.EXPECT().GetLocationByID(ctx, int64(2)).Times(1).Return(&domain.Location{ID: 2, ParentID: 1, Kind: domain.LocationKindFloor}, nil)
+		lr.EXPECT().SaveLocation(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, location *domain.Location) error {
+			location.ID = 3
+			return nil
+		})
+
+		l := NewLocation(lr, nil)
+
+		location, err := l.CreateLocation(ctx, &domain.Location{ParentID: 2, Kind: domain.LocationKindRoom, Name: "Kitchen"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), location.ID)
+	})
+}
+
+func Test_location_AssignSensor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, location not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lr := NewMockLocationRepository(ctrl)
+		lr.EXPECT().GetLocationByID(ctx, int64(1)).Times(1).Return(nil, ErrLocationNotFound)
+
+		l := NewLocation(lr, nil)
+
+		err := l.AssignSensor(ctx, 1, 1)
+		assert.ErrorIs(t, err, ErrLocationNotFound)
+	})
+
+	t.Run("ok, sensor saved with location", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lr := NewMockLocationRepository(ctrl)
+		lr.EXPECT().GetLocationByID(ctx, int64(1)).Times(1).Return(&domain.Location{ID: 1, Kind: domain.LocationKindHome}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(5)).Times(1).Return(&domain.Sensor{ID: 5}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(nil).Do(func(_ context.Context, sensor *domain.Sensor) {
+			assert.Equal(t, int64(1), sensor.LocationID)
+		})
+
+		l := NewLocation(lr, sr)
+
+		err := l.AssignSensor(ctx, 1, 5)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_location_GetLocationSensors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lr := NewMockLocationRepository(ctrl)
+	lr.EXPECT().GetLocationByID(ctx, int64(2)).Times(1).Return(&domain.Location{ID: 2, ParentID: 1, Kind: domain.LocationKindFloor}, nil)
+	lr.EXPECT().GetLocations(ctx).Times(1).Return([]domain.Location{
+		{ID: 1, Kind: domain.LocationKindHome},
+		{ID: 2, ParentID: 1, Kind: domain.LocationKindFloor},
+		{ID: 3, ParentID: 2, Kind: domain.LocationKindRoom},
+		{ID: 4, ParentID: 1, Kind: domain.LocationKindRoom},
+	}, nil)
+
+	sr := NewMockSensorRepository(ctrl)
+	sr.EXPECT().GetSensors(ctx).Times(1).Return([]domain.Sensor{
+		{ID: 1, LocationID: 1},
+		{ID: 2, LocationID: 2},
+		{ID: 3, LocationID: 3},
+		{ID: 4, LocationID: 4},
+		{ID: 5},
+	}, nil)
+
+	l := NewLocation(lr, sr)
+
+	sensors, err := l.GetLocationSensors(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Sensor{{ID: 2, LocationID: 2}, {ID: 3, LocationID: 3}}, sensors)
+}
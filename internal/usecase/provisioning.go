@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/tracing"
+	"log/slog"
+)
+
+// Provisioning - keeps the events of the unknown sensors in the pending list until the sensors are claimed
+type Provisioning struct {
+	pendingSensorRepository PendingSensorRepository
+	userRepository          UserRepository
+	sensor                  *Sensor
+	user                    *User
+	event                   *Event
+	limits                  PendingLimits
+	logger                  *slog.Logger
+}
+
+func NewProvisioning(pr PendingSensorRepository, ur UserRepository, sensor *Sensor, user *User, event *Event, options ...Option) *Provisioning {
+	o := applyOptions(options)
+	return &Provisioning{
+		pendingSensorRepository: pr,
+		userRepository:          ur,
+		sensor:                  sensor,
+		user:                    user,
+		event:                   event,
+		limits:                  o.pendingLimits,
+		logger:                  o.logger,
+	}
+}
+
+// ClaimResult - the claimed sensor and the outcome of its buffered events replay
+type ClaimResult struct {
+	Sensor   *domain.Sensor
+	Replayed int // Buffered events saved to the history
+	Rejected int // Buffered events not fitting the type or the channels of the sensor
+}
+
+// bufferEvent - adds the event of the unknown sensor to the pending list. The events without an idempotency key
+// get one, so the claim can drop each of them from the list once it is replayed
+func (p *Provisioning) bufferEvent(ctx context.Context, event *domain.Event) error {
+	if !p.sensor.validateSN(event.SensorSerialNumber) {
+		return ErrWrongSensorSerialNumber
+	}
+
+	buffered := *event
+	if buffered.IdempotencyKey == "" {
+		buffered.IdempotencyKey = fmt.Sprintf("provisioning-%d", event.Timestamp.UnixNano())
+	}
+	return p.pendingSensorRepository.SavePendingEvent(ctx, &buffered, p.limits)
+}
+
+func (p *Provisioning) GetPendingSensors(ctx context.Context) (_ []domain.PendingSensor, err error) {
+	ctx, span := tracing.Start(ctx, "Provisioning.GetPendingSensors")
+	defer tracing.End(span, &err)
+
+	pending, err := p.pendingSensorRepository.GetPendingSensors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get pending sensors from repository: %w", err)
+	}
+	return pending, nil
+}
+
+func (p *Provisioning) GetPendingSensor(ctx context.Context, sn string) (_ *domain.PendingSensor, err error) {
+	ctx, span := tracing.Start(ctx, "Provisioning.GetPendingSensor")
+	defer tracing.End(span, &err)
+
+	pending, err := p.pendingSensorRepository.GetPendingSensorBySerialNumber(ctx, sn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get pending sensor %q from repository: %w", sn, err)
+	}
+	return pending, nil
+}
+
+// DismissPendingSensor - drops the pending sensor with its buffered events, the sensor is pending again
+// once it sends another event
+func (p *Provisioning) DismissPendingSensor(ctx context.Context, sn string) (err error) {
+	ctx, span := tracing.Start(ctx, "Provisioning.DismissPendingSensor")
+	defer tracing.End(span, &err)
+
+	if err := p.pendingSensorRepository.DeletePendingSensor(ctx, sn); err != nil {
+		return fmt.Errorf("cannot delete pending sensor %q: %w", sn, err)
+	}
+	p.logger.InfoContext(ctx, "pending sensor dismissed", "serial_number", sn)
+	return nil
+}
+
+// ClaimSensor - registers the pending sensor with the serial number of sensor, attaches it to the user unless userID is 0
+// and replays the buffered events into its history. Every event leaves the pending list as soon as it is replayed
+// and the sensor once all of them are, so a failed claim may be retried without saving an event twice
+func (p *Provisioning) ClaimSensor(ctx context.Context, sensor *domain.Sensor, userID int64) (_ *ClaimResult, err error) {
+	ctx, span := tracing.Start(ctx, "Provisioning.ClaimSensor")
+	defer tracing.End(span, &err)
+
+	if sensor == nil {
+		return nil, errors.New("got nil sensor at ClaimSensor()")
+	}
+	pending, err := p.pendingSensorRepository.GetPendingSensorBySerialNumber(ctx, sensor.SerialNumber)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get pending sensor %q: %w", sensor.SerialNumber, err)
+	}
+	// checked before the registration, so a claim for a wrong user leaves nothing behind
+	if userID != 0 {
+		if _, err := p.userRepository.GetUserByID(ctx, userID); err != nil {
+			return nil, fmt.Errorf("got invalid user id (%v): %w", userID, err)
+		}
+	}
+
+	claimed, err := p.sensor.RegisterSensor(ctx, sensor)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 {
+		if err := p.user.AttachSensorToUser(ctx, userID, claimed.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &ClaimResult{}
+	for _, buffered := range pending.Events {
+		event := buffered
+		err := p.event.ReplayEvent(ctx, &event)
+		switch {
+		case err == nil, errors.Is(err, ErrDuplicateEvent):
+			res.Replayed++
+		case errors.Is(err, ErrPayloadOutOfRange), errors.Is(err, ErrUnknownChannel):
+			res.Rejected++
+			p.logger.WarnContext(ctx, "buffered event rejected", "sensor_id", claimed.ID, "error", err)
+		default:
+			return nil, fmt.Errorf("cannot replay buffered event %v: %w", buffered, err)
+		}
+		// the idempotency key is released after the dedup window, so it doesn't guard a later retry
+		if err := p.pendingSensorRepository.DeletePendingEvent(ctx, sensor.SerialNumber, buffered.IdempotencyKey); err != nil {
+			return nil, fmt.Errorf("cannot delete replayed event %v: %w", buffered, err)
+		}
+	}
+	if err := p.pendingSensorRepository.DeletePendingSensor(ctx, sensor.SerialNumber); err != nil &&
+		!errors.Is(err, ErrPendingSensorNotFound) { // claimed concurrently
+		return nil, fmt.Errorf("cannot delete pending sensor %q: %w", sensor.SerialNumber, err)
+	}
+
+	// the replay has changed the sensor state
+	if res.Sensor, err = p.sensor.GetSensorByID(ctx, claimed.ID); err != nil {
+		return nil, err
+	}
+	p.logger.InfoContext(ctx, "pending sensor claimed", "sensor_id", claimed.ID, "serial_number", sensor.SerialNumber,
+		"user_id", userID, "replayed", res.Replayed, "rejected", res.Rejected)
+	return res, nil
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_provisioning_BufferEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newEvent := func(sr SensorRepository, pr PendingSensorRepository) *Event {
+		e := NewEvent(nil, sr, nil)
+		e.SetProvisioning(NewProvisioning(pr, nil, NewSensor(sr), nil, e, WithPendingLimits(PendingLimits{Sensors: 1, Events: 1})))
+		return e
+	}
+
+	t.Run("ok, buffered", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Now()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(nil, ErrSensorNotFound)
+		pr := NewMockPendingSensorRepository(ctrl)
+		pr.EXPECT().SavePendingEvent(ctx, gomock.Any(), PendingLimits{Sensors: 1, Events: 1}).Times(1).
+			DoAndReturn(func(_ context.Context, event *domain.Event, _ PendingLimits) error {
+				assert.Equal(t, int64(42), event.Payload)
+				assert.NotEmpty(t, event.IdempotencyKey)
+				return nil
+			})
+
+		event := &domain.Event{Timestamp: now, SensorSerialNumber: "0123456789", Payload: 42}
+		err := newEvent(sr, pr).ReceiveEvent(ctx, event)
+		assert.ErrorIs(t, err, ErrSensorPending)
+		assert.Empty(t, event.IdempotencyKey, "the event of the caller is not changed")
+	})
+
+	t.Run("err, wrong serial number", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "123").Times(1).Return(nil, ErrSensorNotFound)
+		pr := NewMockPendingSensorRepository(ctrl)
+		pr.EXPECT().SavePendingEvent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		err := newEvent(sr, pr).ReceiveEvent(ctx, &domain.Event{Timestamp: time.Now(), SensorSerialNumber: "123"})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("err, pending list is full", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(nil, ErrSensorNotFound)
+		pr := NewMockPendingSensorRepository(ctrl)
+		pr.EXPECT().SavePendingEvent(ctx, gomock.Any(), gomock.Any()).Times(1).Return(ErrPendingSensorsLimit)
+
+		err := newEvent(sr, pr).ReceiveEvent(ctx, &domain.Event{Timestamp: time.Now(), SensorSerialNumber: "0123456789"})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("err, repository failure", func(t *testing.T) {
+		ctx := context.Background()
+		errStorage := errors.New("storage failure")
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(nil, ErrSensorNotFound)
+		pr := NewMockPendingSensorRepository(ctrl)
+		pr.EXPECT().SavePendingEvent(ctx, gomock.Any(), gomock.Any()).Times(1).Return(errStorage)
+
+		err := newEvent(sr, pr).ReceiveEvent(ctx, &domain.Event{Timestamp: time.Now(), SensorSerialNumber: "0123456789"})
+		assert.ErrorIs(t, err, errStorage)
+		assert.NotErrorIs(t, err, ErrSensorPending)
+	})
+}
+
+// provisioningMocks - repositories of the claim backed by a single sensor registered by SaveSensor
+type provisioningMocks struct {
+	pr  *MockPendingSensorRepository
+	ur  *MockUserRepository
+	sor *MockSensorOwnerRepository
+	sr  *MockSensorRepository
+	er  *MockEventRepository
+
+	registered *domain.Sensor
+	saved      []domain.Event
+	deleted    []string
+	failSave   int64 // Payload of the event whose saving fails
+}
+
+func newProvisioningMocks(ctrl *gomock.Controller) *provisioningMocks {
+	m := &provisioningMocks{
+		pr:  NewMockPendingSensorRepository(ctrl),
+		ur:  NewMockUserRepository(ctrl),
+		sor: NewMockSensorOwnerRepository(ctrl),
+		sr:  NewMockSensorRepository(ctrl),
+		er:  NewMockEventRepository(ctrl),
+	}
+	get := func() (*domain.Sensor, error) {
+		if m.registered == nil {
+			return nil, ErrSensorNotFound
+		}
+		sensor := *m.registered
+		return &sensor, nil
+	}
+	m.sr.EXPECT().GetSensorBySerialNumber(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(context.Context, string) (*domain.Sensor, error) { return get() })
+	m.sr.EXPECT().GetSensorByID(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(context.Context, int64) (*domain.Sensor, error) { return get() })
+	m.sr.EXPECT().SaveSensor(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, sensor *domain.Sensor) error {
+			sensor.ID = 1
+			saved := *sensor
+			m.registered = &saved
+			return nil
+		})
+	m.sr.EXPECT().UpdateSensorState(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, sensor *domain.Sensor) error {
+			m.registered.CurrentState = sensor.CurrentState
+			m.registered.CurrentValues = sensor.CurrentValues
+			m.registered.LastActivity = sensor.LastActivity
+			return nil
+		})
+	m.er.EXPECT().SaveEvent(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, event *domain.Event) error {
+			if m.failSave != 0 && event.Payload == m.failSave {
+				return errors.New("connection lost")
+			}
+			m.saved = append(m.saved, *event)
+			return nil
+		})
+	m.pr.EXPECT().DeletePendingEvent(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, _ string, idempotencyKey string) error {
+			m.deleted = append(m.deleted, idempotencyKey)
+			return nil
+		})
+	return m
+}
+
+func (m *provisioningMocks) provisioning(ctrl *gomock.Controller) *Provisioning {
+	esr := NewMockSubscriptionRepository[domain.Event](ctrl)
+	esr.EXPECT().GetBroadcastHandleById(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, ErrSensorNotFound)
+	listener := NewMockEventListener(ctrl)
+	listener.EXPECT().HandleEvent(gomock.Any(), gomock.Any()).Times(0)
+
+	e := NewEvent(m.er, m.sr, esr)
+	e.AddListener(listener)
+	user := NewUser(m.ur, m.sor, m.sr, nil)
+	return NewProvisioning(m.pr, m.ur, NewSensor(m.sr), user, e)
+}
+
+func Test_provisioning_ClaimSensor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sn := "0123456789"
+	now := time.Now()
+	pending := &domain.PendingSensor{
+		SerialNumber: sn,
+		Seen:         3,
+		Buffered:     3,
+		Events: []domain.Event{
+			{Timestamp: now, SensorSerialNumber: sn, Payload: 2150, IdempotencyKey: "provisioning-1"},
+			{Timestamp: now.Add(time.Second), SensorSerialNumber: sn, Payload: 13000, IdempotencyKey: "provisioning-2"},
+			{Timestamp: now.Add(2 * time.Second), SensorSerialNumber: sn, Payload: 2200, IdempotencyKey: "provisioning-3"},
+		},
+	}
+	newSensor := func() *domain.Sensor {
+		return &domain.Sensor{SerialNumber: sn, Type: domain.SensorTypeTemperature, Description: "kitchen", IsActive: true}
+	}
+
+	t.Run("ok, claimed by user", func(t *testing.T) {
+		ctx := context.Background()
+		m := newProvisioningMocks(ctrl)
+		m.pr.EXPECT().GetPendingSensorBySerialNumber(ctx, sn).Times(1).Return(pending, nil)
+		m.ur.EXPECT().GetUserByID(ctx, int64(7)).Times(2).Return(&domain.User{ID: 7, Name: "alice"}, nil)
+		m.sor.EXPECT().SaveSensorOwner(ctx, domain.SensorOwner{UserID: 7, SensorID: 1}).Times(1).Return(nil)
+		m.pr.EXPECT().DeletePendingSensor(ctx, sn).Times(1).Return(nil)
+
+		res, err := m.provisioning(ctrl).ClaimSensor(ctx, newSensor(), 7)
+		require.NoError(t, err)
+		assert.Equal(t, 2, res.Replayed)
+		assert.Equal(t, 1, res.Rejected)
+		assert.Equal(t, int64(2200), res.Sensor.CurrentState)
+		assert.Equal(t, now.Add(2*time.Second), res.Sensor.LastActivity)
+		require.Len(t, m.saved, 2)
+		assert.Equal(t, now, m.saved[0].Timestamp)
+		assert.Equal(t, int64(1), m.saved[0].SensorID)
+		assert.Equal(t, []string{"provisioning-1", "provisioning-2", "provisioning-3"}, m.deleted)
+	})
+
+	t.Run("ok, retried after failure", func(t *testing.T) {
+		ctx := context.Background()
+		m := newProvisioningMocks(ctrl)
+		m.pr.EXPECT().GetPendingSensorBySerialNumber(ctx, sn).Times(2).
+			DoAndReturn(func(context.Context, string) (*domain.PendingSensor, error) {
+				left := *pending
+				left.Events = nil
+				for _, event := range pending.Events {
+					if !slices.Contains(m.deleted, event.IdempotencyKey) {
+						left.Events = append(left.Events, event)
+					}
+				}
+				return &left, nil
+			})
+		m.pr.EXPECT().DeletePendingSensor(ctx, sn).Times(1).Return(nil)
+
+		m.failSave = 2200
+		_, err := m.provisioning(ctrl).ClaimSensor(ctx, newSensor(), 0)
+		require.Error(t, err)
+		assert.Equal(t, []string{"provisioning-1", "provisioning-2"}, m.deleted)
+
+		// the replayed events are not saved again, however long ago their keys were released
+		m.failSave = 0
+		res, err := m.provisioning(ctrl).ClaimSensor(ctx, newSensor(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Replayed)
+		require.Len(t, m.saved, 2)
+		assert.Equal(t, []int64{2150, 2200}, []int64{m.saved[0].Payload, m.saved[1].Payload})
+	})
+
+	t.Run("ok, later state kept", func(t *testing.T) {
+		ctx := context.Background()
+		m := newProvisioningMocks(ctrl)
+		m.pr.EXPECT().GetPendingSensorBySerialNumber(ctx, sn).Times(1).Return(pending, nil)
+		m.pr.EXPECT().DeletePendingSensor(ctx, sn).Times(1).Return(ErrPendingSensorNotFound)
+		// registered and reporting already, e.g. by a concurrent claim
+		m.registered = &domain.Sensor{ID: 1, SerialNumber: sn, Type: domain.SensorTypeTemperature, CurrentState: 2500,
+			LastActivity: now.Add(time.Minute)}
+
+		res, err := m.provisioning(ctrl).ClaimSensor(ctx, newSensor(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, res.Replayed)
+		assert.Equal(t, int64(2500), res.Sensor.CurrentState)
+		assert.Len(t, m.saved, 2)
+	})
+
+	t.Run("err, user not found", func(t *testing.T) {
+		ctx := context.Background()
+		m := newProvisioningMocks(ctrl)
+		m.pr.EXPECT().GetPendingSensorBySerialNumber(ctx, sn).Times(1).Return(pending, nil)
+		m.ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(nil, ErrUserNotFound)
+		m.pr.EXPECT().DeletePendingSensor(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := m.provisioning(ctrl).ClaimSensor(ctx, newSensor(), 7)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		assert.Nil(t, m.registered)
+		assert.Empty(t, m.deleted)
+	})
+
+	t.Run("err, not pending", func(t *testing.T) {
+		ctx := context.Background()
+		m := newProvisioningMocks(ctrl)
+		m.pr.EXPECT().GetPendingSensorBySerialNumber(ctx, sn).Times(1).Return(nil, ErrPendingSensorNotFound)
+
+		_, err := m.provisioning(ctrl).ClaimSensor(ctx, newSensor(), 0)
+		assert.ErrorIs(t, err, ErrPendingSensorNotFound)
+	})
+
+	t.Run("err, wrong sensor type", func(t *testing.T) {
+		ctx := context.Background()
+		m := newProvisioningMocks(ctrl)
+		m.pr.EXPECT().GetPendingSensorBySerialNumber(ctx, sn).Times(1).Return(pending, nil)
+
+		sensor := newSensor()
+		sensor.Type = "unknown"
+		_, err := m.provisioning(ctrl).ClaimSensor(ctx, sensor, 0)
+		assert.ErrorIs(t, err, ErrWrongSensorType)
+		assert.Nil(t, m.registered)
+	})
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/metrics"
+	"homework/internal/tracing"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+var errSchedulerStopped = errors.New("scheduler is not running")
+
+// JobFunc - work of a periodic job, ctx is cancelled on the server shutdown
+type JobFunc func(ctx context.Context) error
+
+// Scheduler - runs the registered jobs by their cron schedules, a job runs on a single replica at a time
+type Scheduler struct {
+	jobRepository JobRepository
+	locker        JobLocker
+	logger        *slog.Logger
+	heartbeat     func()
+
+	mu        sync.Mutex
+	jobs      map[string]registeredJob
+	running   map[string]bool
+	runCtx    context.Context // Context of Run the jobs are started in, nil until Run is called
+	stopped   bool
+	startedAt time.Time
+	wg        sync.WaitGroup
+}
+
+type registeredJob struct {
+	schedule string // Schedule the job is created with unless there is a persisted one
+	run      JobFunc
+}
+
+func NewScheduler(jr JobRepository, locker JobLocker, options ...Option) *Scheduler {
+	o := applyOptions(options)
+	return &Scheduler{
+		jobRepository: jr,
+		locker:        locker,
+		logger:        o.logger,
+		heartbeat:     o.heartbeat,
+		jobs:          make(map[string]registeredJob),
+		running:       make(map[string]bool),
+	}
+}
+
+// Register - adds the job to the scheduler, is meant to be called before Run.
+// The schedule and the enabled flag persisted earlier take precedence over the ones given here.
+func (s *Scheduler) Register(name, schedule string, run JobFunc) error {
+	if name == "" || run == nil {
+		return fmt.Errorf("%w: name and function are required", ErrInvalidJob)
+	}
+	if _, err := domain.ParseCron(schedule); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJob, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("%w: job %q is already registered", ErrInvalidJob, name)
+	}
+	s.jobs[name] = registeredJob{schedule: schedule, run: run}
+	return nil
+}
+
+// Run - persists the definitions of the registered jobs and starts the due ones every tick until ctx is done,
+// then waits for the running jobs, which get ctx cancelled, to return
+func (s *Scheduler) Run(ctx context.Context, tick time.Duration) {
+	s.mu.Lock()
+	s.runCtx = ctx
+	s.startedAt = time.Now()
+	definitions := make([]domain.Job, 0, len(s.jobs))
+	for name, j := range s.jobs {
+		definitions = append(definitions, domain.Job{Name: name, Schedule: j.schedule, Enabled: true})
+	}
+	s.mu.Unlock()
+
+	for _, job := range definitions {
+		if err := s.jobRepository.SaveJob(ctx, &job); err != nil {
+			s.logger.ErrorContext(ctx, "cannot save job definition", "job", job.Name, "error", err)
+		}
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		s.heartbeat()
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.stopped = true
+			s.mu.Unlock()
+			s.wg.Wait()
+			return
+		case now := <-ticker.C:
+			s.runDue(ctx, now)
+		}
+	}
+}
+
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	jobs, err := s.jobRepository.GetJobs(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "cannot get jobs", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		if !job.Enabled || !s.isRegistered(job.Name) || !s.isDue(&job, now) {
+			continue
+		}
+		if err := s.start(ctx, job.Name, false); err != nil && !errors.Is(err, ErrJobRunning) {
+			s.logger.ErrorContext(ctx, "cannot start job", "job", job.Name, "error", err)
+		}
+	}
+}
+
+func (s *Scheduler) isRegistered(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.jobs[name]
+	return ok
+}
+
+// NextRunAt - the moment the job is due at, zero if the schedule is invalid or never fires.
+// The jobs that have never run are counted from the scheduler start.
+func (s *Scheduler) NextRunAt(job *domain.Job) time.Time {
+	schedule, err := domain.ParseCron(job.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	last := job.LastRunAt
+	if last.IsZero() {
+		s.mu.Lock()
+		last = s.startedAt
+		s.mu.Unlock()
+	}
+	return schedule.Next(last)
+}
+
+func (s *Scheduler) isDue(job *domain.Job, now time.Time) bool {
+	next := s.NextRunAt(job)
+	return !next.IsZero() && !now.Before(next)
+}
+
+// IsRunning - whether the job is running on this replica
+func (s *Scheduler) IsRunning(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[name]
+}
+
+// start - takes the job lock and runs the job in background, fails with ErrJobRunning if the job
+// is running on this or another replica
+func (s *Scheduler) start(ctx context.Context, name string, manual bool) error {
+	s.mu.Lock()
+	job, registered := s.jobs[name]
+	switch {
+	case !registered:
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %q is not registered", ErrJobNotFound, name)
+	case s.runCtx == nil || s.stopped:
+		s.mu.Unlock()
+		return errSchedulerStopped
+	case s.running[name]:
+		s.mu.Unlock()
+		return ErrJobRunning
+	}
+	s.running[name] = true
+	s.wg.Add(1)
+	runCtx := s.runCtx
+	s.mu.Unlock()
+
+	unlock, locked, err := s.locker.TryLockJob(ctx, name)
+	if err != nil || !locked {
+		s.finish(name)
+		if err != nil {
+			return fmt.Errorf("cannot lock job %q: %w", name, err)
+		}
+		return fmt.Errorf("%w: %q is locked by another replica", ErrJobRunning, name)
+	}
+
+	go func() {
+		defer s.finish(name)
+		defer unlock()
+		s.execute(runCtx, name, job.run, manual)
+	}()
+	return nil
+}
+
+func (s *Scheduler) finish(name string) {
+	s.mu.Lock()
+	delete(s.running, name)
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+// execute - runs the job unless another replica has run it since it became due, and records the outcome
+func (s *Scheduler) execute(ctx context.Context, name string, run JobFunc, manual bool) {
+	ctx, span := tracing.Start(ctx, "Scheduler.execute")
+	defer span.End()
+
+	job, err := s.jobRepository.GetJobByName(ctx, name)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "cannot get job", "job", name, "error", err)
+		return
+	}
+	start := time.Now()
+	if !manual && !s.isDue(job, start) {
+		return
+	}
+
+	s.logger.DebugContext(ctx, "job started", "job", name, "manual", manual)
+	err = runSafely(ctx, run)
+
+	job.LastRunAt = start
+	job.LastDuration = time.Since(start)
+	job.LastStatus = domain.JobStatusSucceeded
+	job.LastError = ""
+	if err != nil {
+		job.LastStatus = domain.JobStatusFailed
+		job.LastError = err.Error()
+	}
+	metrics.JobFinished(name, string(job.LastStatus), job.LastDuration)
+
+	// The outcome is recorded even if the job has been cancelled by the shutdown
+	if err := s.jobRepository.UpdateJobRun(context.WithoutCancel(ctx), job); err != nil {
+		s.logger.ErrorContext(ctx, "cannot save job run", "job", name, "error", err)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "job failed", "job", name, "duration", job.LastDuration, "error", err)
+		return
+	}
+	s.logger.InfoContext(ctx, "job succeeded", "job", name, "duration", job.LastDuration)
+}
+
+// runSafely - reports the panic of the job as its error, so it doesn't bring the server down
+func runSafely(ctx context.Context, run JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// GetJobs - the persisted jobs sorted by name
+func (s *Scheduler) GetJobs(ctx context.Context) (_ []domain.Job, err error) {
+	ctx, span := tracing.Start(ctx, "Scheduler.GetJobs")
+	defer tracing.End(span, &err)
+
+	jobs, err := s.jobRepository.GetJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get jobs from repository: %w", err)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs, nil
+}
+
+func (s *Scheduler) GetJobByName(ctx context.Context, name string) (_ *domain.Job, err error) {
+	ctx, span := tracing.Start(ctx, "Scheduler.GetJobByName")
+	defer tracing.End(span, &err)
+
+	job, err := s.jobRepository.GetJobByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get job %q from repository: %w", name, err)
+	}
+	return job, nil
+}
+
+// UpdateJob - changes the schedule and the enabled flag of the job, the change is picked up by every replica
+func (s *Scheduler) UpdateJob(ctx context.Context, name, schedule string, enabled bool) (_ *domain.Job, err error) {
+	ctx, span := tracing.Start(ctx, "Scheduler.UpdateJob")
+	defer tracing.End(span, &err)
+
+	if _, err := domain.ParseCron(schedule); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJob, err)
+	}
+	job, err := s.jobRepository.GetJobByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get job %q from repository: %w", name, err)
+	}
+
+	job.Schedule = schedule
+	job.Enabled = enabled
+	if err := s.jobRepository.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("cannot update job %q: %w", name, err)
+	}
+	s.logger.InfoContext(ctx, "job updated", "job", name, "schedule", schedule, "enabled", enabled)
+	return job, nil
+}
+
+// TriggerJob - starts the job right away regardless of its schedule and the enabled flag
+func (s *Scheduler) TriggerJob(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "Scheduler.TriggerJob")
+	defer tracing.End(span, &err)
+
+	if err := s.start(ctx, name, true); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "job triggered", "job", name)
+	return nil
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_scheduler_Register(t *testing.T) {
+	s := NewScheduler(nil, nil)
+	noop := func(context.Context) error { return nil }
+
+	assert.ErrorIs(t, s.Register("cleanup", "every minute", noop), ErrInvalidJob)
+	assert.ErrorIs(t, s.Register("cleanup", "* * * * *", nil), ErrInvalidJob)
+	require.NoError(t, s.Register("cleanup", "* * * * *", noop))
+	assert.ErrorIs(t, s.Register("cleanup", "@hourly", noop), ErrInvalidJob)
+}
+
+func Test_scheduler_runDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.May, 1, 10, 30, 0, 0, time.UTC)
+	hourly := domain.Job{Name: "report", Schedule: "0 * * * *", Enabled: true, LastRunAt: now.Add(-90 * time.Minute)}
+	notDue := domain.Job{Name: "cleanup", Schedule: "0 * * * *", Enabled: true, LastRunAt: now.Add(-10 * time.Minute)}
+	disabled := domain.Job{Name: "disabled", Schedule: "* * * * *", LastRunAt: now.Add(-time.Hour)}
+	unknown := domain.Job{Name: "removed", Schedule: "* * * * *", Enabled: true}
+
+	t.Run("ok, due job is run and recorded", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		jr := NewMockJobRepository(ctrl)
+		jr.EXPECT().GetJobs(ctx).Times(1).Return([]domain.Job{hourly, notDue, disabled, unknown}, nil)
+		jr.EXPECT().GetJobByName(ctx, "report").Times(1).DoAndReturn(func(context.Context, string) (*domain.Job, error) {
+			job := hourly
+			return &job, nil
+		})
+		recorded := make(chan domain.Job, 1)
+		jr.EXPECT().UpdateJobRun(gomock.Any(), gomock.Any()).Times(1).Do(func(_ context.Context, job *domain.Job) {
+			recorded <- *job
+		})
+		unlocked := false
+		locker := NewMockJobLocker(ctrl)
+		locker.EXPECT().TryLockJob(ctx, "report").Times(1).Return(func() { unlocked = true }, true, nil)
+
+		s := NewScheduler(jr, locker)
+		ran := 0
+		for _, name := range []string{"report", "cleanup", "disabled"} {
+			require.NoError(t, s.Register(name, "0 * * * *", func(context.Context) error {
+				ran++
+				return errors.New("report service is down")
+			}))
+		}
+		s.runCtx = ctx
+
+		s.runDue(ctx, now)
+		s.wg.Wait()
+
+		job := <-recorded
+		assert.Equal(t, 1, ran)
+		assert.True(t, unlocked)
+		assert.Equal(t, domain.JobStatusFailed, job.LastStatus)
+		assert.Equal(t, "report service is down", job.LastError)
+		assert.False(t, s.IsRunning("report"))
+	})
+
+	t.Run("ok, skipped if run by another replica", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		jr := NewMockJobRepository(ctrl)
+		jr.EXPECT().GetJobs(ctx).Times(1).Return([]domain.Job{hourly}, nil)
+		jr.EXPECT().GetJobByName(ctx, "report").Times(1).DoAndReturn(func(context.Context, string) (*domain.Job, error) {
+			job := hourly
+			job.LastRunAt = time.Now()
+			return &job, nil
+		})
+		jr.EXPECT().UpdateJobRun(gomock.Any(), gomock.Any()).Times(0)
+		locker := NewMockJobLocker(ctrl)
+		locker.EXPECT().TryLockJob(ctx, "report").Times(1).Return(func() {}, true, nil)
+
+		s := NewScheduler(jr, locker)
+		require.NoError(t, s.Register("report", "0 * * * *", func(context.Context) error {
+			t.Error("job must not run")
+			return nil
+		}))
+		s.runCtx = ctx
+
+		s.runDue(ctx, now)
+		s.wg.Wait()
+	})
+}
+
+func Test_scheduler_TriggerJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, not running or unknown", func(t *testing.T) {
+		s := NewScheduler(nil, nil)
+		require.NoError(t, s.Register("report", "@daily", func(context.Context) error { return nil }))
+
+		assert.ErrorIs(t, s.TriggerJob(context.Background(), "missing"), ErrJobNotFound)
+		assert.Error(t, s.TriggerJob(context.Background(), "report"))
+	})
+
+	t.Run("fail, locked by another replica", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		locker := NewMockJobLocker(ctrl)
+		locker.EXPECT().TryLockJob(ctx, "report").Times(1).Return(nil, false, nil)
+
+		s := NewScheduler(nil, locker)
+		require.NoError(t, s.Register("report", "@daily", func(context.Context) error { return nil }))
+		s.runCtx = ctx
+
+		assert.ErrorIs(t, s.TriggerJob(ctx, "report"), ErrJobRunning)
+		assert.False(t, s.IsRunning("report"))
+	})
+
+	t.Run("ok, running job is cancelled on shutdown", func(t *testing.T) {
+		runCtx, stop := context.WithCancel(context.Background())
+		defer stop()
+		ctx := context.Background()
+
+		jr := NewMockJobRepository(ctrl)
+		jr.EXPECT().SaveJob(runCtx, gomock.Any()).Times(1).Return(nil)
+		jr.EXPECT().GetJobByName(runCtx, "report").Times(1).Return(&domain.Job{Name: "report", Schedule: "@daily"}, nil)
+		jr.EXPECT().UpdateJobRun(gomock.Any(), gomock.Any()).Times(1).Do(func(_ context.Context, job *domain.Job) {
+			assert.Equal(t, domain.JobStatusFailed, job.LastStatus)
+			assert.Equal(t, context.Canceled.Error(), job.LastError)
+		})
+		locker := NewMockJobLocker(ctrl)
+		locker.EXPECT().TryLockJob(ctx, "report").Times(1).Return(func() {}, true, nil)
+
+		started := make(chan struct{})
+		s := NewScheduler(jr, locker)
+		require.NoError(t, s.Register("report", "@daily", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		done := make(chan struct{})
+		go func() {
+			s.Run(runCtx, time.Hour)
+			close(done)
+		}()
+		require.Eventually(t, func() bool { return s.TriggerJob(ctx, "report") == nil }, time.Second, time.Millisecond)
+		<-started
+		assert.ErrorIs(t, s.TriggerJob(ctx, "report"), ErrJobRunning)
+
+		stop()
+		<-done
+		assert.False(t, s.IsRunning("report"))
+	})
+}
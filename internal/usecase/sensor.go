@@ -5,16 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/tracing"
+	"log/slog"
+	"math"
 	"regexp"
 )
 
 type Sensor struct {
 	sensorRepository SensorRepository
+	logger           *slog.Logger
 }
 
-func NewSensor(sr SensorRepository) *Sensor {
+func NewSensor(sr SensorRepository, options ...Option) *Sensor {
+	o := applyOptions(options)
 	return &Sensor{
 		sensorRepository: sr,
+		logger:           o.logger,
 	}
 }
 
@@ -24,11 +30,80 @@ func (s *Sensor) validateSN(sn string) bool {
 }
 
 func (s *Sensor) validateSensType(t domain.SensorType) bool {
-	return t == domain.SensorTypeADC ||
-		t == domain.SensorTypeContactClosure
+	_, ok := domain.LookupSensorType(t)
+	return ok
 }
 
-func (s *Sensor) RegisterSensor(ctx context.Context, sensor *domain.Sensor) (*domain.Sensor, error) {
+var channelNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+func (s *Sensor) validateChannels(channels []domain.Channel) error {
+	seen := make(map[string]struct{}, len(channels))
+	for _, c := range channels {
+		if !channelNameRe.MatchString(c.Name) {
+			return fmt.Errorf("%w: bad name %q", ErrInvalidSensorChannel, c.Name)
+		}
+		if _, ok := seen[c.Name]; ok {
+			return fmt.Errorf("%w: duplicate name %q", ErrInvalidSensorChannel, c.Name)
+		}
+		seen[c.Name] = struct{}{}
+		if !s.validateSensType(c.Type) {
+			return fmt.Errorf("%w: %q has unknown type %q", ErrInvalidSensorChannel, c.Name, c.Type)
+		}
+		if err := s.validateCalibration(c.Calibration); err != nil {
+			return fmt.Errorf("channel %q: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+const maxMovingAverageWindow = 100
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func (s *Sensor) validateCalibration(calibration domain.Calibration) error {
+	for i, step := range calibration {
+		switch step.Kind {
+		case domain.CalibrationOffset, domain.CalibrationGain:
+			if !isFinite(step.Value) {
+				return fmt.Errorf("%w: step %d: %s value is not finite", ErrInvalidCalibration, i, step.Kind)
+			}
+		case domain.CalibrationLookup:
+			if len(step.Points) < 2 {
+				return fmt.Errorf("%w: step %d: lookup needs at least 2 points", ErrInvalidCalibration, i)
+			}
+			for j, p := range step.Points {
+				if !isFinite(p.Raw) || !isFinite(p.Value) {
+					return fmt.Errorf("%w: step %d: lookup point %d is not finite", ErrInvalidCalibration, i, j)
+				}
+				if j > 0 && p.Raw <= step.Points[j-1].Raw {
+					return fmt.Errorf("%w: step %d: lookup points are not ordered by raw", ErrInvalidCalibration, i)
+				}
+			}
+		case domain.CalibrationClamp:
+			if step.Min == nil && step.Max == nil {
+				return fmt.Errorf("%w: step %d: clamp needs min or max", ErrInvalidCalibration, i)
+			}
+			if step.Min != nil && step.Max != nil && *step.Min > *step.Max {
+				return fmt.Errorf("%w: step %d: clamp min is greater than max", ErrInvalidCalibration, i)
+			}
+		case domain.CalibrationMovingAverage:
+			if step.Window < 1 || step.Window > maxMovingAverageWindow {
+				return fmt.Errorf("%w: step %d: moving average window is not in [1, %d]",
+					ErrInvalidCalibration, i, maxMovingAverageWindow)
+			}
+		default:
+			return fmt.Errorf("%w: step %d: unknown kind %q", ErrInvalidCalibration, i, step.Kind)
+		}
+	}
+	return nil
+}
+
+func (s *Sensor) RegisterSensor(ctx context.Context, sensor *domain.Sensor) (_ *domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "Sensor.RegisterSensor")
+	defer tracing.End(span, &err)
+
 	if sensor == nil {
 		return sensor, errors.New("got nil sensor at RegisterSensor()")
 	}
@@ -38,6 +113,15 @@ func (s *Sensor) RegisterSensor(ctx context.Context, sensor *domain.Sensor) (*do
 	if !s.validateSensType(sensor.Type) {
 		return sensor, ErrWrongSensorType
 	}
+	if err := s.validateChannels(sensor.Channels); err != nil {
+		return sensor, err
+	}
+	if err := s.validateCalibration(sensor.Calibration); err != nil {
+		return sensor, err
+	}
+	if err := domain.ValidateLabels(sensor.Labels); err != nil {
+		return sensor, fmt.Errorf("%w: %v", ErrInvalidSensorLabels, err)
+	}
 
 	if existing, err := s.sensorRepository.GetSensorBySerialNumber(ctx, sensor.SerialNumber); err == nil {
 		existing.LastActivity = sensor.LastActivity
@@ -52,10 +136,14 @@ func (s *Sensor) RegisterSensor(ctx context.Context, sensor *domain.Sensor) (*do
 	if err := s.sensorRepository.SaveSensor(ctx, sensor); err != nil { // Modifies sensor assigning new id
 		return sensor, err
 	}
+	s.logger.InfoContext(ctx, "sensor registered", "sensor_id", sensor.ID, "serial_number", sensor.SerialNumber)
 	return sensor, nil
 }
 
-func (s *Sensor) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
+func (s *Sensor) GetSensors(ctx context.Context) (_ []domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "Sensor.GetSensors")
+	defer tracing.End(span, &err)
+
 	sens, err := s.sensorRepository.GetSensors(ctx)
 	if err != nil {
 		return sens, fmt.Errorf("cannot get sensors from repository: %w", err)
@@ -63,7 +151,40 @@ func (s *Sensor) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
 	return sens, err
 }
 
-func (s *Sensor) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error) {
+// GetSensorsBySelector - sensors whose labels match the selector, all sensors for an empty selector
+func (s *Sensor) GetSensorsBySelector(ctx context.Context, selector domain.Selector) (_ []domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "Sensor.GetSensorsBySelector")
+	defer tracing.End(span, &err)
+
+	if len(selector) == 0 {
+		return s.GetSensors(ctx)
+	}
+	sens, err := s.sensorRepository.GetSensorsBySelector(ctx, selector)
+	if err != nil {
+		return sens, fmt.Errorf("cannot get sensors from repository for selector %q: %w", selector, err)
+	}
+	return sens, nil
+}
+
+// IsRegistered - whether the serial number is valid and a sensor with it is registered
+func (s *Sensor) IsRegistered(ctx context.Context, sn string) (bool, error) {
+	if !s.validateSN(sn) {
+		return false, nil
+	}
+	_, err := s.sensorRepository.GetSensorBySerialNumber(ctx, sn)
+	switch {
+	case errors.Is(err, ErrSensorNotFound):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("cannot get sensor %q from repository: %w", sn, err)
+	}
+	return true, nil
+}
+
+func (s *Sensor) GetSensorByID(ctx context.Context, id int64) (_ *domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "Sensor.GetSensorByID")
+	defer tracing.End(span, &err)
+
 	sens, err := s.sensorRepository.GetSensorByID(ctx, id)
 	if err != nil {
 		return sens, fmt.Errorf("cannot get sensors from repository for id %v: %w", id, err)
@@ -71,3 +192,90 @@ func (s *Sensor) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, e
 
 	return sens, nil
 }
+
+// sensorUpdateAttempts - how many times an unconditional update is applied to the sensor changed concurrently
+const sensorUpdateAttempts = 3
+
+// updateSensor - applies update to the sensor and saves it comparing the version. If version is set, the update is
+// conditional and fails with ErrSensorVersionConflict unless the sensor has that version, otherwise
+// it is reapplied to the fresh sensor if another update has saved it in between
+func updateSensor(ctx context.Context, repo SensorRepository, id, version int64, update func(*domain.Sensor) error) (*domain.Sensor, error) {
+	for attempt := 1; ; attempt++ {
+		sens, err := repo.GetSensorByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get sensor %v: %w", id, err)
+		}
+		if version != 0 && sens.Version != version {
+			return nil, fmt.Errorf("%w: sensor %v has version %v", ErrSensorVersionConflict, id, sens.Version)
+		}
+		if err := update(sens); err != nil {
+			return nil, err
+		}
+
+		err = repo.SaveSensor(ctx, sens)
+		if errors.Is(err, ErrSensorVersionConflict) && version == 0 && attempt < sensorUpdateAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot save sensor %v: %w", id, err)
+		}
+		return sens, nil
+	}
+}
+
+// SetCalibration - replaces the calibration of the single-value sensor or, if channel is set, of its channel.
+// The stored history is recomputed separately by Event.RecalibrateHistory. A non-zero version makes the change
+// conditional on the sensor version.
+func (s *Sensor) SetCalibration(ctx context.Context, id, version int64, channel string, calibration domain.Calibration) (_ *domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "Sensor.SetCalibration")
+	defer tracing.End(span, &err)
+
+	if err := s.validateCalibration(calibration); err != nil {
+		return nil, err
+	}
+	sens, err := updateSensor(ctx, s.sensorRepository, id, version, func(sens *domain.Sensor) error {
+		switch {
+		case channel == "" && sens.IsMultiChannel():
+			return fmt.Errorf("%w: multi-channel sensor is calibrated by channels", ErrUnknownChannel)
+		case channel == "":
+			sens.Calibration = calibration
+		default:
+			if _, ok := sens.Channel(channel); !ok {
+				return fmt.Errorf("%w: %q", ErrUnknownChannel, channel)
+			}
+			channels := make([]domain.Channel, 0, len(sens.Channels))
+			for _, c := range sens.Channels {
+				if c.Name == channel {
+					c.Calibration = calibration
+				}
+				channels = append(channels, c)
+			}
+			sens.Channels = channels
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "sensor calibration changed", "sensor_id", id, "channel", channel)
+	return sens, nil
+}
+
+// SetLabels - replaces all the labels of the sensor, a non-zero version makes the change conditional on the sensor version
+func (s *Sensor) SetLabels(ctx context.Context, id, version int64, labels map[string]string) (_ *domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "Sensor.SetLabels")
+	defer tracing.End(span, &err)
+
+	if err := domain.ValidateLabels(labels); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSensorLabels, err)
+	}
+	sens, err := updateSensor(ctx, s.sensorRepository, id, version, func(sens *domain.Sensor) error {
+		sens.Labels = labels
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "sensor labels changed", "sensor_id", id, "labels", len(labels))
+	return sens, nil
+}
@@ -43,6 +43,29 @@ func Test_sensor_RegisterSensor(t *testing.T) {
 		assert.ErrorIs(t, err, ErrWrongSensorSerialNumber)
 	})
 
+	t.Run("fail, invalid channels", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr)
+
+		for _, channels := range [][]domain.Channel{
+			{{Name: "Temperature", Type: domain.SensorTypeTemperature}},
+			{{Name: "temperature", Type: "some"}},
+			{{Name: "t", Type: domain.SensorTypeTemperature}, {Name: "t", Type: domain.SensorTypeHumidity}},
+		} {
+			_, err := s.RegisterSensor(ctx, &domain.Sensor{
+				SerialNumber: "1234567890",
+				Type:         domain.SensorTypeADC,
+				Channels:     channels,
+			})
+			assert.ErrorIs(t, err, ErrInvalidSensorChannel)
+		}
+	})
+
 	t.Run("fail, repository return an error", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -253,3 +276,252 @@ func Test_sensor_GetSensorByID(t *testing.T) {
 		assert.NotNil(t, sensor)
 	})
 }
+
+func Test_sensor_IsRegistered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	sr := NewMockSensorRepository(ctrl)
+	sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(&domain.Sensor{ID: 1}, nil)
+	sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000002").Times(1).Return(nil, ErrSensorNotFound)
+	sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000003").Times(1).Return(nil, errors.New("connection lost"))
+	s := NewSensor(sr)
+
+	registered, err := s.IsRegistered(ctx, "0000000001")
+	assert.NoError(t, err)
+	assert.True(t, registered)
+	registered, err = s.IsRegistered(ctx, "0000000002")
+	assert.NoError(t, err)
+	assert.False(t, registered)
+	_, err = s.IsRegistered(ctx, "0000000003")
+	assert.Error(t, err)
+	// not looked up at all
+	registered, err = s.IsRegistered(ctx, "bogus")
+	assert.NoError(t, err)
+	assert.False(t, registered)
+}
+
+func Test_sensor_RegisterSensor_types(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, info := range domain.SensorTypes() {
+		t.Run("ok, "+string(info.Type), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			sr := NewMockSensorRepository(ctrl)
+			sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Times(1).Return(nil, ErrSensorNotFound)
+			sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(nil)
+
+			s := NewSensor(sr)
+
+			_, err := s.RegisterSensor(ctx, &domain.Sensor{
+				SerialNumber: "1234567890",
+				Type:         info.Type,
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_sensor_SetCalibration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	multiChannel := func() *domain.Sensor {
+		return &domain.Sensor{
+			ID:   1,
+			Type: domain.SensorTypeADC,
+			Channels: []domain.Channel{
+				{Name: "temperature", Type: domain.SensorTypeTemperature},
+				{Name: "humidity", Type: domain.SensorTypeHumidity},
+			},
+		}
+	}
+
+	t.Run("fail, invalid calibration", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := NewSensor(NewMockSensorRepository(ctrl))
+
+		for _, calibration := range []domain.Calibration{
+			{{Kind: "polynomial"}},
+			{{Kind: domain.CalibrationLookup, Points: []domain.CalibrationPoint{{Raw: 0, Value: 0}}}},
+			{{Kind: domain.CalibrationLookup, Points: []domain.CalibrationPoint{{Raw: 10, Value: 0}, {Raw: 0, Value: 10}}}},
+			{{Kind: domain.CalibrationClamp}},
+			{{Kind: domain.CalibrationMovingAverage, Window: 0}},
+		} {
+			_, err := s.SetCalibration(ctx, 1, 0, "", calibration)
+			assert.ErrorIs(t, err, ErrInvalidCalibration)
+		}
+	})
+
+	t.Run("fail, unknown channel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(2).DoAndReturn(func(context.Context, int64) (*domain.Sensor, error) {
+			return multiChannel(), nil
+		})
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr)
+		calibration := domain.Calibration{{Kind: domain.CalibrationOffset, Value: -35}}
+
+		_, err := s.SetCalibration(ctx, 1, 0, "pressure", calibration)
+		assert.ErrorIs(t, err, ErrUnknownChannel)
+
+		_, err = s.SetCalibration(ctx, 1, 0, "", calibration)
+		assert.ErrorIs(t, err, ErrUnknownChannel)
+	})
+
+	t.Run("ok, channel calibrated", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		calibration := domain.Calibration{{Kind: domain.CalibrationOffset, Value: -35}}
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(multiChannel(), nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sens *domain.Sensor) {
+			assert.Empty(t, sens.Channels[0].Calibration)
+			assert.Equal(t, calibration, sens.Channels[1].Calibration)
+		})
+
+		s := NewSensor(sr)
+		_, err := s.SetCalibration(ctx, 1, 0, "humidity", calibration)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_sensor_Labels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid labels on registration", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr)
+
+		_, err := s.RegisterSensor(ctx, &domain.Sensor{
+			SerialNumber: "1234567890",
+			Type:         domain.SensorTypeContactClosure,
+			Labels:       map[string]string{"kind": "water leak"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidSensorLabels)
+	})
+
+	t.Run("fail, invalid labels", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr)
+
+		_, err := s.SetLabels(ctx, 1, 0, map[string]string{"": "leak"})
+		assert.ErrorIs(t, err, ErrInvalidSensorLabels)
+	})
+
+	t.Run("ok, labels replaced", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		labels := map[string]string{"kind": "leak", "floor": "2"}
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Labels: map[string]string{"zone": "north"}}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sens *domain.Sensor) {
+			assert.Equal(t, labels, sens.Labels)
+		})
+
+		s := NewSensor(sr)
+		sens, err := s.SetLabels(ctx, 1, 0, labels)
+		assert.NoError(t, err)
+		assert.Equal(t, labels, sens.Labels)
+	})
+
+	t.Run("fail, version mismatch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Version: 3}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr)
+		_, err := s.SetLabels(ctx, 1, 2, map[string]string{"kind": "leak"})
+		assert.ErrorIs(t, err, ErrSensorVersionConflict)
+	})
+
+	t.Run("fail, conditional update changed concurrently", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Version: 2}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(ErrSensorVersionConflict)
+
+		s := NewSensor(sr)
+		_, err := s.SetLabels(ctx, 1, 2, map[string]string{"kind": "leak"})
+		assert.ErrorIs(t, err, ErrSensorVersionConflict)
+	})
+
+	t.Run("ok, unconditional update reapplied", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		labels := map[string]string{"kind": "leak"}
+		sr := NewMockSensorRepository(ctrl)
+		gomock.InOrder(
+			sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Version: 2}, nil),
+			sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(ErrSensorVersionConflict),
+			sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).
+				Return(&domain.Sensor{ID: 1, Version: 3, LocationID: 5}, nil),
+			sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sens *domain.Sensor) {
+				assert.Equal(t, int64(3), sens.Version)
+				assert.Equal(t, int64(5), sens.LocationID)
+				assert.Equal(t, labels, sens.Labels)
+			}),
+		)
+
+		s := NewSensor(sr)
+		_, err := s.SetLabels(ctx, 1, 0, labels)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ok, empty selector lists all sensors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensors(ctx).Times(1).Return([]domain.Sensor{{ID: 1}, {ID: 2}}, nil)
+		sr.EXPECT().GetSensorsBySelector(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr)
+		sensors, err := s.GetSensorsBySelector(ctx, nil)
+		assert.NoError(t, err)
+		assert.Len(t, sensors, 2)
+	})
+
+	t.Run("ok, selector passed to repository", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		selector := domain.Selector{{Key: "kind", Operator: domain.SelectorEquals, Value: "leak"}}
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorsBySelector(ctx, selector).Times(1).Return([]domain.Sensor{{ID: 2}}, nil)
+
+		s := NewSensor(sr)
+		sensors, err := s.GetSensorsBySelector(ctx, selector)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Sensor{{ID: 2}}, sensors)
+	})
+}
@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"homework/internal/domain"
+	"homework/internal/tracing"
+	"log/slog"
 
 	"github.com/google/uuid"
 )
@@ -10,23 +13,75 @@ import (
 type Subscription[T any] struct {
 	subscriptionRepository SubscriptionRepository[T]
 	sensorRepository       SensorRepository
+	logger                 *slog.Logger
 }
 
-func NewSubscription[T any](sur SubscriptionRepository[T], ser SensorRepository) *Subscription[T] {
+func NewSubscription[T any](sur SubscriptionRepository[T], ser SensorRepository, options ...Option) *Subscription[T] {
+	o := applyOptions(options)
 	return &Subscription[T]{
 		subscriptionRepository: sur,
 		sensorRepository:       ser,
+		logger:                 o.logger,
 	}
 }
 
-func (s *Subscription[T]) Subscribe(ctx context.Context, sensId int64) (*domain.Subscription[T], error) {
+func (s *Subscription[T]) Subscribe(ctx context.Context, sensId int64) (_ *domain.Subscription[T], err error) {
+	ctx, span := tracing.Start(ctx, "Subscription.Subscribe")
+	defer tracing.End(span, &err)
+
 	if _, err := s.sensorRepository.GetSensorByID(ctx, sensId); err != nil {
 		return nil, err
 	}
 
-	return s.subscriptionRepository.Subscribe(ctx, sensId)
+	subscription, err := s.subscriptionRepository.Subscribe(ctx, sensId)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.DebugContext(ctx, "subscribed", "sensor_id", sensId, "subscription_id", subscription.Id)
+	return subscription, nil
 }
 
-func (s *Subscription[T]) Unsubscribe(ctx context.Context, sensId int64, subscriptionId uuid.UUID) error {
-	return s.subscriptionRepository.Unsubscribe(ctx, sensId, subscriptionId)
+// SubscribeSelector - subscribes to every sensor matching the selector at the moment of the call.
+// Sensors labeled later are not picked up, the caller resubscribes for that.
+func (s *Subscription[T]) SubscribeSelector(ctx context.Context, selector domain.Selector) (_ []*domain.Subscription[T], err error) {
+	ctx, span := tracing.Start(ctx, "Subscription.SubscribeSelector")
+	defer tracing.End(span, &err)
+
+	var sensors []domain.Sensor
+	if len(selector) == 0 {
+		sensors, err = s.sensorRepository.GetSensors(ctx)
+	} else {
+		sensors, err = s.sensorRepository.GetSensorsBySelector(ctx, selector)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get sensors for selector %q: %w", selector, err)
+	}
+	if len(sensors) == 0 {
+		return nil, fmt.Errorf("%w: no sensors match selector %q", ErrSensorNotFound, selector)
+	}
+
+	subscriptions := make([]*domain.Subscription[T], 0, len(sensors))
+	for _, sens := range sensors {
+		subscription, err := s.subscriptionRepository.Subscribe(ctx, sens.ID)
+		if err != nil {
+			for _, done := range subscriptions {
+				_ = s.subscriptionRepository.Unsubscribe(ctx, done.SensorID, done.Id)
+			}
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	s.logger.DebugContext(ctx, "subscribed by selector", "selector", selector.String(), "sensors", len(subscriptions))
+	return subscriptions, nil
+}
+
+func (s *Subscription[T]) Unsubscribe(ctx context.Context, sensId int64, subscriptionId uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "Subscription.Unsubscribe")
+	defer tracing.End(span, &err)
+
+	if err := s.subscriptionRepository.Unsubscribe(ctx, sensId, subscriptionId); err != nil {
+		return err
+	}
+	s.logger.DebugContext(ctx, "unsubscribed", "sensor_id", sensId, "subscription_id", subscriptionId)
+	return nil
 }
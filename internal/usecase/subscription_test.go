@@ -73,3 +73,64 @@ func Test_subscription_Subscribe(t *testing.T) {
 		assert.Equal(t, expectedSubscription, *sub)
 	})
 }
+
+func Test_subscription_SubscribeSelector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	selector := domain.Selector{{Key: "kind", Operator: domain.SelectorEquals, Value: "leak"}}
+
+	t.Run("err, no sensors match", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorsBySelector(ctx, selector).Times(1).Return(nil, nil)
+
+		s := NewSubscription[domain.Event](nil, sr)
+
+		_, err := s.SubscribeSelector(ctx, selector)
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("err, subscribe error rolls back", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorsBySelector(ctx, selector).Times(1).Return([]domain.Sensor{{ID: 1}, {ID: 2}}, nil)
+
+		first := domain.Subscription[domain.Event]{SensorID: 1, Id: uuid.New()}
+		expectedError := errors.New("some error")
+		esr := NewMockSubscriptionRepository[domain.Event](ctrl)
+		esr.EXPECT().Subscribe(ctx, int64(1)).Times(1).Return(&first, nil)
+		esr.EXPECT().Subscribe(ctx, int64(2)).Times(1).Return(nil, expectedError)
+		esr.EXPECT().Unsubscribe(ctx, int64(1), first.Id).Times(1).Return(nil)
+
+		s := NewSubscription[domain.Event](esr, sr)
+
+		_, err := s.SubscribeSelector(ctx, selector)
+		assert.ErrorIs(t, err, expectedError)
+	})
+
+	t.Run("ok, every matching sensor subscribed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorsBySelector(ctx, selector).Times(1).Return([]domain.Sensor{{ID: 1}, {ID: 2}}, nil)
+
+		esr := NewMockSubscriptionRepository[domain.Event](ctrl)
+		esr.EXPECT().Subscribe(ctx, gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, id int64) (*domain.Subscription[domain.Event], error) {
+			return &domain.Subscription[domain.Event]{SensorID: id, Id: uuid.New()}, nil
+		})
+
+		s := NewSubscription[domain.Event](esr, sr)
+
+		subscriptions, err := s.SubscribeSelector(ctx, selector)
+		assert.NoError(t, err)
+		assert.Len(t, subscriptions, 2)
+		assert.Equal(t, int64(1), subscriptions[0].SensorID)
+		assert.Equal(t, int64(2), subscriptions[1].SensorID)
+	})
+}
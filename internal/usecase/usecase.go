@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,20 +19,106 @@ var (
 	ErrUserNotFound            = errors.New("user not found")
 	ErrEventNotFound           = errors.New("event not found")
 	ErrSubscriptionNotFound    = errors.New("subscription not found")
+	ErrPayloadOutOfRange       = errors.New("payload is out of the sensor type range")
+	ErrInvalidSensorChannel    = errors.New("invalid sensor channel")
+	ErrUnknownChannel          = errors.New("unknown sensor channel")
+	ErrInvalidCalibration      = errors.New("invalid sensor calibration")
+	ErrLocationNotFound        = errors.New("location not found")
+	ErrInvalidLocation         = errors.New("invalid location")
+	ErrInvalidSensorLabels     = errors.New("invalid sensor labels")
+	ErrActuatorNotFound        = errors.New("actuator not found")
+	ErrWrongActuatorSN         = errors.New("wrong actuator serial number")
+	ErrWrongActuatorType       = errors.New("wrong actuator type")
+	ErrCommandNotFound         = errors.New("command not found")
+	ErrInvalidCommand          = errors.New("invalid command")
+	ErrCommandConflict         = errors.New("command status conflict")
+	ErrRuleNotFound            = errors.New("automation rule not found")
+	ErrInvalidRule             = errors.New("invalid automation rule")
+	ErrJobNotFound             = errors.New("job not found")
+	ErrInvalidJob              = errors.New("invalid job")
+	ErrJobRunning              = errors.New("job is already running")
+	ErrDuplicateEvent          = errors.New("event has already been accepted")
+	ErrSensorPending           = errors.New("sensor is pending provisioning")
+	ErrPendingSensorNotFound   = errors.New("pending sensor not found")
+	ErrPendingSensorsLimit     = errors.New("too many pending sensors")
+	ErrSensorVersionConflict   = errors.New("sensor has been modified concurrently")
 )
 
+// Option - option accepted by every usecase constructor
+type Option func(*options)
+
+type options struct {
+	logger        *slog.Logger
+	dedupWindow   time.Duration
+	pendingLimits PendingLimits
+	heartbeat     func()
+}
+
+// DefaultDedupWindow - how long an idempotency key of an accepted event is kept by default
+const DefaultDedupWindow = 24 * time.Hour
+
+// PendingLimits - limits of the pending sensors list
+type PendingLimits struct {
+	Sensors int // How many unknown sensors are kept, the events of the others are rejected
+	Events  int // How many first events of a pending sensor are buffered, the rest are only counted
+}
+
+// DefaultPendingLimits - limits of the pending sensors list by default
+var DefaultPendingLimits = PendingLimits{Sensors: 1000, Events: 100}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithDedupWindow - how long the Event usecase keeps an idempotency key of an accepted event
+func WithDedupWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.dedupWindow = window
+	}
+}
+
+// WithPendingLimits - limits of the pending sensors list kept by the Provisioning usecase
+func WithPendingLimits(limits PendingLimits) Option {
+	return func(o *options) {
+		o.pendingLimits = limits
+	}
+}
+
+// WithHeartbeat - called by the background loop of the usecase on every iteration, so a stuck loop can be detected
+func WithHeartbeat(beat func()) Option {
+	return func(o *options) {
+		o.heartbeat = beat
+	}
+}
+
+func applyOptions(opts []Option) options {
+	o := options{logger: slog.Default(), dedupWindow: DefaultDedupWindow, pendingLimits: DefaultPendingLimits, heartbeat: func() {}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // requires mockgen v1.7+
 
 //go:generate mockgen -source usecase.go -package usecase -destination usecase_mock.go
 type SensorRepository interface {
-	// SaveSensor - функция сохранения датчика
+	// SaveSensor - функция сохранения датчика, сохраняет его, только если сохраненная версия равна sensor.Version
+	// (0 - датчик еще не сохранен), иначе возвращает ErrSensorVersionConflict; увеличивает sensor.Version
 	SaveSensor(ctx context.Context, sensor *domain.Sensor) error
+	// UpdateSensorState - функция сохранения текущего состояния датчика (CurrentState, CurrentValues и LastActivity)
+	// без изменения остальных полей, увеличивает sensor.Version
+	UpdateSensorState(ctx context.Context, sensor *domain.Sensor) error
 	// GetSensors - функция получения списка датчиков
 	GetSensors(ctx context.Context) ([]domain.Sensor, error)
 	// GetSensorByID - функция получения датчика по ID
 	GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error)
 	// GetSensorBySerialNumber - функция получения датчика по серийному номеру
 	GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error)
+	// GetSensorsBySelector - функция получения списка датчиков, метки которых подходят под селектор
+	GetSensorsBySelector(ctx context.Context, selector domain.Selector) ([]domain.Sensor, error)
 }
 
 type SubscriptionRepository[T any] interface {
@@ -44,12 +131,36 @@ type SubscriptionRepository[T any] interface {
 }
 
 type EventRepository interface {
-	// SaveEvent - функция сохранения события по датчику
+	// SaveEvent - функция сохранения события по датчику, назначает событию ID; возвращает ErrDuplicateEvent,
+	// если событие датчика с тем же ключом идемпотентности уже сохранено и ключ еще не освобожден
 	SaveEvent(ctx context.Context, event *domain.Event) error
 	// GetLastEventBySensorID - функция получения последнего события по ID датчика
 	GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error)
 	// GetEventsHistoryBySensorID - функция получения истории событий по ID датчика и временному промежутку
 	GetEventsHistoryBySensorID(ctx context.Context, id int64, startTime, endTime time.Time) ([]*domain.Event, error)
+	// GetLastEventsBySensorID - функция получения не более limit последних событий по ID датчика в хронологическом порядке
+	GetLastEventsBySensorID(ctx context.Context, id int64, limit int) ([]*domain.Event, error)
+	// UpdateEvents - функция обновления откалиброванных значений сохраненных событий по их ID, возвращает
+	// ErrEventNotFound и ничего не обновляет, если какого-то из событий нет
+	UpdateEvents(ctx context.Context, events []*domain.Event) error
+	// ReleaseIdempotencyKeys - функция освобождения ключей идемпотентности событий, принятых раньше before
+	ReleaseIdempotencyKeys(ctx context.Context, before time.Time) error
+}
+
+type PendingSensorRepository interface {
+	// SavePendingEvent - функция добавления события неизвестного датчика в список ожидающих подключения,
+	// событие сохраняется, только если от датчика получено не больше limits.Events событий,
+	// возвращает ErrPendingSensorsLimit, если датчика нет в списке и в нем уже limits.Sensors датчиков
+	SavePendingEvent(ctx context.Context, event *domain.Event, limits PendingLimits) error
+	// GetPendingSensors - функция получения списка ожидающих подключения датчиков без событий в порядке обнаружения
+	GetPendingSensors(ctx context.Context) ([]domain.PendingSensor, error)
+	// GetPendingSensorBySerialNumber - функция получения ожидающего подключения датчика вместе с сохраненными событиями
+	GetPendingSensorBySerialNumber(ctx context.Context, sn string) (*domain.PendingSensor, error)
+	// DeletePendingSensor - функция удаления датчика из списка ожидающих подключения вместе с его событиями
+	DeletePendingSensor(ctx context.Context, sn string) error
+	// DeletePendingEvent - функция удаления перенесенных в историю событий ожидающего подключения датчика
+	// по ключу идемпотентности, отсутствие таких событий или датчика не считается ошибкой
+	DeletePendingEvent(ctx context.Context, sn string, idempotencyKey string) error
 }
 
 type UserRepository interface {
@@ -57,6 +168,8 @@ type UserRepository interface {
 	SaveUser(ctx context.Context, user *domain.User) error
 	// GetUserByID - функция получения пользователя по id
 	GetUserByID(ctx context.Context, id int64) (*domain.User, error)
+	// GetUsers - функция получения списка всех пользователей в порядке id
+	GetUsers(ctx context.Context) ([]domain.User, error)
 }
 
 type SensorOwnerRepository interface {
@@ -65,3 +178,87 @@ type SensorOwnerRepository interface {
 	// GetSensorsByUserID -функция, возвращающая список привязок для пользователя
 	GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error)
 }
+
+type LocationRepository interface {
+	// SaveLocation - функция сохранения локации
+	SaveLocation(ctx context.Context, location *domain.Location) error
+	// GetLocations - функция получения списка всех локаций
+	GetLocations(ctx context.Context) ([]domain.Location, error)
+	// GetLocationByID - функция получения локации по ID
+	GetLocationByID(ctx context.Context, id int64) (*domain.Location, error)
+	// SaveLocationAccess - функция предоставления пользователю доступа к локации
+	SaveLocationAccess(ctx context.Context, access domain.LocationAccess) error
+	// GetLocationAccessByUserID - функция, возвращающая список доступов пользователя к локациям
+	GetLocationAccessByUserID(ctx context.Context, userID int64) ([]domain.LocationAccess, error)
+}
+
+type ActuatorRepository interface {
+	// SaveActuator - функция сохранения исполнительного устройства
+	SaveActuator(ctx context.Context, actuator *domain.Actuator) error
+	// GetActuators - функция получения списка исполнительных устройств
+	GetActuators(ctx context.Context) ([]domain.Actuator, error)
+	// GetActuatorByID - функция получения исполнительного устройства по ID
+	GetActuatorByID(ctx context.Context, id int64) (*domain.Actuator, error)
+	// GetActuatorBySerialNumber - функция получения исполнительного устройства по серийному номеру
+	GetActuatorBySerialNumber(ctx context.Context, sn string) (*domain.Actuator, error)
+}
+
+type CommandRepository interface {
+	// SaveCommand - функция сохранения новой команды
+	SaveCommand(ctx context.Context, command *domain.Command) error
+	// UpdateCommandStatus - функция смены этапа команды, возвращает ErrCommandConflict, если этап команды уже не from
+	UpdateCommandStatus(ctx context.Context, command *domain.Command, from domain.CommandStatus) error
+	// GetCommandByID - функция получения команды по ID
+	GetCommandByID(ctx context.Context, id int64) (*domain.Command, error)
+	// GetCommandsByActuatorID - функция получения команд исполнительного устройства в порядке создания
+	GetCommandsByActuatorID(ctx context.Context, actuatorID int64) ([]domain.Command, error)
+	// ExpireCommands - функция перевода в expired всех не завершенных команд со сроком не позже now,
+	// возвращает ID переведенных команд
+	ExpireCommands(ctx context.Context, now time.Time) ([]int64, error)
+}
+
+type AutomationRepository interface {
+	// SaveRule - функция сохранения нового правила автоматизации
+	SaveRule(ctx context.Context, rule *domain.AutomationRule) error
+	// UpdateRule - функция замены правила автоматизации, возвращает ErrRuleNotFound, если правила нет
+	UpdateRule(ctx context.Context, rule *domain.AutomationRule) error
+	// DeleteRule - функция удаления правила автоматизации вместе с журналом его запусков
+	DeleteRule(ctx context.Context, id int64) error
+	// GetRules - функция получения списка правил автоматизации
+	GetRules(ctx context.Context) ([]domain.AutomationRule, error)
+	// GetRuleByID - функция получения правила автоматизации по ID
+	GetRuleByID(ctx context.Context, id int64) (*domain.AutomationRule, error)
+	// SaveExecution - функция добавления записи в журнал запусков
+	SaveExecution(ctx context.Context, execution *domain.AutomationExecution) error
+	// GetExecutionsByRuleID - функция получения не более limit последних запусков правила, начиная с новых
+	GetExecutionsByRuleID(ctx context.Context, ruleID int64, limit int) ([]domain.AutomationExecution, error)
+}
+
+type WebhookSender interface {
+	// SendWebhook - функция отправки POST запроса с телом body, depth передается получателю для защиты от зацикливания
+	SendWebhook(ctx context.Context, url string, body []byte, depth int) error
+}
+
+type EventListener interface {
+	// HandleEvent - функция получения принятого события, вызывается синхронно и не должна блокироваться
+	HandleEvent(ctx context.Context, event domain.Event)
+}
+
+type JobRepository interface {
+	// SaveJob - функция сохранения определения задачи, если его еще нет, иначе job заполняется сохраненным
+	SaveJob(ctx context.Context, job *domain.Job) error
+	// UpdateJob - функция изменения расписания и включенности задачи, возвращает ErrJobNotFound, если задачи нет
+	UpdateJob(ctx context.Context, job *domain.Job) error
+	// UpdateJobRun - функция сохранения состояния последнего запуска задачи
+	UpdateJobRun(ctx context.Context, job *domain.Job) error
+	// GetJobs - функция получения списка задач
+	GetJobs(ctx context.Context) ([]domain.Job, error)
+	// GetJobByName - функция получения задачи по имени
+	GetJobByName(ctx context.Context, name string) (*domain.Job, error)
+}
+
+type JobLocker interface {
+	// TryLockJob - функция захвата блокировки задачи, общей для всех реплик, locked = false, если блокировка занята,
+	// при успехе блокировку надо освободить вызовом unlock
+	TryLockJob(ctx context.Context, name string) (unlock func(), locked bool, err error)
+}
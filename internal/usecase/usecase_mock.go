@@ -82,6 +82,21 @@ func (mr *MockSensorRepositoryMockRecorder) GetSensors(ctx interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensors", reflect.TypeOf((*MockSensorRepository)(nil).GetSensors), ctx)
 }
 
+// GetSensorsBySelector mocks base method.
+func (m *MockSensorRepository) GetSensorsBySelector(ctx context.Context, selector domain.Selector) ([]domain.Sensor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorsBySelector", ctx, selector)
+	ret0, _ := ret[0].([]domain.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorsBySelector indicates an expected call of GetSensorsBySelector.
+func (mr *MockSensorRepositoryMockRecorder) GetSensorsBySelector(ctx, selector interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorsBySelector", reflect.TypeOf((*MockSensorRepository)(nil).GetSensorsBySelector), ctx, selector)
+}
+
 // SaveSensor mocks base method.
 func (m *MockSensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor) error {
 	m.ctrl.T.Helper()
@@ -96,6 +111,20 @@ func (mr *MockSensorRepositoryMockRecorder) SaveSensor(ctx, sensor interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensor", reflect.TypeOf((*MockSensorRepository)(nil).SaveSensor), ctx, sensor)
 }
 
+// UpdateSensorState mocks base method.
+func (m *MockSensorRepository) UpdateSensorState(ctx context.Context, sensor *domain.Sensor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSensorState", ctx, sensor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSensorState indicates an expected call of UpdateSensorState.
+func (mr *MockSensorRepositoryMockRecorder) UpdateSensorState(ctx, sensor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSensorState", reflect.TypeOf((*MockSensorRepository)(nil).UpdateSensorState), ctx, sensor)
+}
+
 // MockSubscriptionRepository is a mock of SubscriptionRepository interface.
 type MockSubscriptionRepository[T any] struct {
 	ctrl     *gomock.Controller
@@ -216,6 +245,35 @@ func (mr *MockEventRepositoryMockRecorder) GetLastEventBySensorID(ctx, id interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastEventBySensorID", reflect.TypeOf((*MockEventRepository)(nil).GetLastEventBySensorID), ctx, id)
 }
 
+// GetLastEventsBySensorID mocks base method.
+func (m *MockEventRepository) GetLastEventsBySensorID(ctx context.Context, id int64, limit int) ([]*domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastEventsBySensorID", ctx, id, limit)
+	ret0, _ := ret[0].([]*domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastEventsBySensorID indicates an expected call of GetLastEventsBySensorID.
+func (mr *MockEventRepositoryMockRecorder) GetLastEventsBySensorID(ctx, id, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastEventsBySensorID", reflect.TypeOf((*MockEventRepository)(nil).GetLastEventsBySensorID), ctx, id, limit)
+}
+
+// ReleaseIdempotencyKeys mocks base method.
+func (m *MockEventRepository) ReleaseIdempotencyKeys(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKeys", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKeys indicates an expected call of ReleaseIdempotencyKeys.
+func (mr *MockEventRepositoryMockRecorder) ReleaseIdempotencyKeys(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKeys", reflect.TypeOf((*MockEventRepository)(nil).ReleaseIdempotencyKeys), ctx, before)
+}
+
 // SaveEvent mocks base method.
 func (m *MockEventRepository) SaveEvent(ctx context.Context, event *domain.Event) error {
 	m.ctrl.T.Helper()
@@ -230,6 +288,115 @@ func (mr *MockEventRepositoryMockRecorder) SaveEvent(ctx, event interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEvent", reflect.TypeOf((*MockEventRepository)(nil).SaveEvent), ctx, event)
 }
 
+// UpdateEvents mocks base method.
+func (m *MockEventRepository) UpdateEvents(ctx context.Context, events []*domain.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEvents", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEvents indicates an expected call of UpdateEvents.
+func (mr *MockEventRepositoryMockRecorder) UpdateEvents(ctx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEvents", reflect.TypeOf((*MockEventRepository)(nil).UpdateEvents), ctx, events)
+}
+
+// MockPendingSensorRepository is a mock of PendingSensorRepository interface.
+type MockPendingSensorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPendingSensorRepositoryMockRecorder
+}
+
+// MockPendingSensorRepositoryMockRecorder is the mock recorder for MockPendingSensorRepository.
+type MockPendingSensorRepositoryMockRecorder struct {
+	mock *MockPendingSensorRepository
+}
+
+// NewMockPendingSensorRepository creates a new mock instance.
+func NewMockPendingSensorRepository(ctrl *gomock.Controller) *MockPendingSensorRepository {
+	mock := &MockPendingSensorRepository{ctrl: ctrl}
+	mock.recorder = &MockPendingSensorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPendingSensorRepository) EXPECT() *MockPendingSensorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeletePendingEvent mocks base method.
+func (m *MockPendingSensorRepository) DeletePendingEvent(ctx context.Context, sn, idempotencyKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingEvent", ctx, sn, idempotencyKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingEvent indicates an expected call of DeletePendingEvent.
+func (mr *MockPendingSensorRepositoryMockRecorder) DeletePendingEvent(ctx, sn, idempotencyKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingEvent", reflect.TypeOf((*MockPendingSensorRepository)(nil).DeletePendingEvent), ctx, sn, idempotencyKey)
+}
+
+// DeletePendingSensor mocks base method.
+func (m *MockPendingSensorRepository) DeletePendingSensor(ctx context.Context, sn string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingSensor", ctx, sn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingSensor indicates an expected call of DeletePendingSensor.
+func (mr *MockPendingSensorRepositoryMockRecorder) DeletePendingSensor(ctx, sn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingSensor", reflect.TypeOf((*MockPendingSensorRepository)(nil).DeletePendingSensor), ctx, sn)
+}
+
+// GetPendingSensorBySerialNumber mocks base method.
+func (m *MockPendingSensorRepository) GetPendingSensorBySerialNumber(ctx context.Context, sn string) (*domain.PendingSensor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingSensorBySerialNumber", ctx, sn)
+	ret0, _ := ret[0].(*domain.PendingSensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingSensorBySerialNumber indicates an expected call of GetPendingSensorBySerialNumber.
+func (mr *MockPendingSensorRepositoryMockRecorder) GetPendingSensorBySerialNumber(ctx, sn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingSensorBySerialNumber", reflect.TypeOf((*MockPendingSensorRepository)(nil).GetPendingSensorBySerialNumber), ctx, sn)
+}
+
+// GetPendingSensors mocks base method.
+func (m *MockPendingSensorRepository) GetPendingSensors(ctx context.Context) ([]domain.PendingSensor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingSensors", ctx)
+	ret0, _ := ret[0].([]domain.PendingSensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingSensors indicates an expected call of GetPendingSensors.
+func (mr *MockPendingSensorRepositoryMockRecorder) GetPendingSensors(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingSensors", reflect.TypeOf((*MockPendingSensorRepository)(nil).GetPendingSensors), ctx)
+}
+
+// SavePendingEvent mocks base method.
+func (m *MockPendingSensorRepository) SavePendingEvent(ctx context.Context, event *domain.Event, limits PendingLimits) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePendingEvent", ctx, event, limits)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePendingEvent indicates an expected call of SavePendingEvent.
+func (mr *MockPendingSensorRepositoryMockRecorder) SavePendingEvent(ctx, event, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePendingEvent", reflect.TypeOf((*MockPendingSensorRepository)(nil).SavePendingEvent), ctx, event, limits)
+}
+
 // MockUserRepository is a mock of UserRepository interface.
 type MockUserRepository struct {
 	ctrl     *gomock.Controller
@@ -268,6 +435,21 @@ func (mr *MockUserRepositoryMockRecorder) GetUserByID(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), ctx, id)
 }
 
+// GetUsers mocks base method.
+func (m *MockUserRepository) GetUsers(ctx context.Context) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserRepositoryMockRecorder) GetUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserRepository)(nil).GetUsers), ctx)
+}
+
 // SaveUser mocks base method.
 func (m *MockUserRepository) SaveUser(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
@@ -333,3 +515,607 @@ func (mr *MockSensorOwnerRepositoryMockRecorder) SaveSensorOwner(ctx, sensorOwne
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorOwner", reflect.TypeOf((*MockSensorOwnerRepository)(nil).SaveSensorOwner), ctx, sensorOwner)
 }
+
+// MockLocationRepository is a mock of LocationRepository interface.
+type MockLocationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLocationRepositoryMockRecorder
+}
+
+// MockLocationRepositoryMockRecorder is the mock recorder for MockLocationRepository.
+type MockLocationRepositoryMockRecorder struct {
+	mock *MockLocationRepository
+}
+
+// NewMockLocationRepository creates a new mock instance.
+func NewMockLocationRepository(ctrl *gomock.Controller) *MockLocationRepository {
+	mock := &MockLocationRepository{ctrl: ctrl}
+	mock.recorder = &MockLocationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocationRepository) EXPECT() *MockLocationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetLocationAccessByUserID mocks base method.
+func (m *MockLocationRepository) GetLocationAccessByUserID(ctx context.Context, userID int64) ([]domain.LocationAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocationAccessByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.LocationAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLocationAccessByUserID indicates an expected call of GetLocationAccessByUserID.
+func (mr *MockLocationRepositoryMockRecorder) GetLocationAccessByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocationAccessByUserID", reflect.TypeOf((*MockLocationRepository)(nil).GetLocationAccessByUserID), ctx, userID)
+}
+
+// GetLocationByID mocks base method.
+func (m *MockLocationRepository) GetLocationByID(ctx context.Context, id int64) (*domain.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocationByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLocationByID indicates an expected call of GetLocationByID.
+func (mr *MockLocationRepositoryMockRecorder) GetLocationByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocationByID", reflect.TypeOf((*MockLocationRepository)(nil).GetLocationByID), ctx, id)
+}
+
+// GetLocations mocks base method.
+func (m *MockLocationRepository) GetLocations(ctx context.Context) ([]domain.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocations", ctx)
+	ret0, _ := ret[0].([]domain.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLocations indicates an expected call of GetLocations.
+func (mr *MockLocationRepositoryMockRecorder) GetLocations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocations", reflect.TypeOf((*MockLocationRepository)(nil).GetLocations), ctx)
+}
+
+// SaveLocation mocks base method.
+func (m *MockLocationRepository) SaveLocation(ctx context.Context, location *domain.Location) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLocation", ctx, location)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLocation indicates an expected call of SaveLocation.
+func (mr *MockLocationRepositoryMockRecorder) SaveLocation(ctx, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLocation", reflect.TypeOf((*MockLocationRepository)(nil).SaveLocation), ctx, location)
+}
+
+// SaveLocationAccess mocks base method.
+func (m *MockLocationRepository) SaveLocationAccess(ctx context.Context, access domain.LocationAccess) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLocationAccess", ctx, access)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLocationAccess indicates an expected call of SaveLocationAccess.
+func (mr *MockLocationRepositoryMockRecorder) SaveLocationAccess(ctx, access interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLocationAccess", reflect.TypeOf((*MockLocationRepository)(nil).SaveLocationAccess), ctx, access)
+}
+
+// MockActuatorRepository is a mock of ActuatorRepository interface.
+type MockActuatorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockActuatorRepositoryMockRecorder
+}
+
+// MockActuatorRepositoryMockRecorder is the mock recorder for MockActuatorRepository.
+type MockActuatorRepositoryMockRecorder struct {
+	mock *MockActuatorRepository
+}
+
+// NewMockActuatorRepository creates a new mock instance.
+func NewMockActuatorRepository(ctrl *gomock.Controller) *MockActuatorRepository {
+	mock := &MockActuatorRepository{ctrl: ctrl}
+	mock.recorder = &MockActuatorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockActuatorRepository) EXPECT() *MockActuatorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetActuatorByID mocks base method.
+func (m *MockActuatorRepository) GetActuatorByID(ctx context.Context, id int64) (*domain.Actuator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActuatorByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Actuator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActuatorByID indicates an expected call of GetActuatorByID.
+func (mr *MockActuatorRepositoryMockRecorder) GetActuatorByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActuatorByID", reflect.TypeOf((*MockActuatorRepository)(nil).GetActuatorByID), ctx, id)
+}
+
+// GetActuatorBySerialNumber mocks base method.
+func (m *MockActuatorRepository) GetActuatorBySerialNumber(ctx context.Context, sn string) (*domain.Actuator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActuatorBySerialNumber", ctx, sn)
+	ret0, _ := ret[0].(*domain.Actuator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActuatorBySerialNumber indicates an expected call of GetActuatorBySerialNumber.
+func (mr *MockActuatorRepositoryMockRecorder) GetActuatorBySerialNumber(ctx, sn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActuatorBySerialNumber", reflect.TypeOf((*MockActuatorRepository)(nil).GetActuatorBySerialNumber), ctx, sn)
+}
+
+// GetActuators mocks base method.
+func (m *MockActuatorRepository) GetActuators(ctx context.Context) ([]domain.Actuator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActuators", ctx)
+	ret0, _ := ret[0].([]domain.Actuator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActuators indicates an expected call of GetActuators.
+func (mr *MockActuatorRepositoryMockRecorder) GetActuators(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActuators", reflect.TypeOf((*MockActuatorRepository)(nil).GetActuators), ctx)
+}
+
+// SaveActuator mocks base method.
+func (m *MockActuatorRepository) SaveActuator(ctx context.Context, actuator *domain.Actuator) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveActuator", ctx, actuator)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveActuator indicates an expected call of SaveActuator.
+func (mr *MockActuatorRepositoryMockRecorder) SaveActuator(ctx, actuator interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveActuator", reflect.TypeOf((*MockActuatorRepository)(nil).SaveActuator), ctx, actuator)
+}
+
+// MockCommandRepository is a mock of CommandRepository interface.
+type MockCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommandRepositoryMockRecorder
+}
+
+// MockCommandRepositoryMockRecorder is the mock recorder for MockCommandRepository.
+type MockCommandRepositoryMockRecorder struct {
+	mock *MockCommandRepository
+}
+
+// NewMockCommandRepository creates a new mock instance.
+func NewMockCommandRepository(ctrl *gomock.Controller) *MockCommandRepository {
+	mock := &MockCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommandRepository) EXPECT() *MockCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetCommandByID mocks base method.
+func (m *MockCommandRepository) GetCommandByID(ctx context.Context, id int64) (*domain.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommandByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommandByID indicates an expected call of GetCommandByID.
+func (mr *MockCommandRepositoryMockRecorder) GetCommandByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommandByID", reflect.TypeOf((*MockCommandRepository)(nil).GetCommandByID), ctx, id)
+}
+
+// ExpireCommands mocks base method.
+func (m *MockCommandRepository) ExpireCommands(ctx context.Context, now time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireCommands", ctx, now)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireCommands indicates an expected call of ExpireCommands.
+func (mr *MockCommandRepositoryMockRecorder) ExpireCommands(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireCommands", reflect.TypeOf((*MockCommandRepository)(nil).ExpireCommands), ctx, now)
+}
+
+// GetCommandsByActuatorID mocks base method.
+func (m *MockCommandRepository) GetCommandsByActuatorID(ctx context.Context, actuatorID int64) ([]domain.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommandsByActuatorID", ctx, actuatorID)
+	ret0, _ := ret[0].([]domain.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommandsByActuatorID indicates an expected call of GetCommandsByActuatorID.
+func (mr *MockCommandRepositoryMockRecorder) GetCommandsByActuatorID(ctx, actuatorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommandsByActuatorID", reflect.TypeOf((*MockCommandRepository)(nil).GetCommandsByActuatorID), ctx, actuatorID)
+}
+
+// SaveCommand mocks base method.
+func (m *MockCommandRepository) SaveCommand(ctx context.Context, command *domain.Command) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCommand", ctx, command)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCommand indicates an expected call of SaveCommand.
+func (mr *MockCommandRepositoryMockRecorder) SaveCommand(ctx, command interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCommand", reflect.TypeOf((*MockCommandRepository)(nil).SaveCommand), ctx, command)
+}
+
+// UpdateCommandStatus mocks base method.
+func (m *MockCommandRepository) UpdateCommandStatus(ctx context.Context, command *domain.Command, from domain.CommandStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCommandStatus", ctx, command, from)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCommandStatus indicates an expected call of UpdateCommandStatus.
+func (mr *MockCommandRepositoryMockRecorder) UpdateCommandStatus(ctx, command, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCommandStatus", reflect.TypeOf((*MockCommandRepository)(nil).UpdateCommandStatus), ctx, command, from)
+}
+
+// MockAutomationRepository is a mock of AutomationRepository interface.
+type MockAutomationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAutomationRepositoryMockRecorder
+}
+
+// MockAutomationRepositoryMockRecorder is the mock recorder for MockAutomationRepository.
+type MockAutomationRepositoryMockRecorder struct {
+	mock *MockAutomationRepository
+}
+
+// NewMockAutomationRepository creates a new mock instance.
+func NewMockAutomationRepository(ctrl *gomock.Controller) *MockAutomationRepository {
+	mock := &MockAutomationRepository{ctrl: ctrl}
+	mock.recorder = &MockAutomationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAutomationRepository) EXPECT() *MockAutomationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRule mocks base method.
+func (m *MockAutomationRepository) DeleteRule(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRule indicates an expected call of DeleteRule.
+func (mr *MockAutomationRepositoryMockRecorder) DeleteRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRule", reflect.TypeOf((*MockAutomationRepository)(nil).DeleteRule), ctx, id)
+}
+
+// GetExecutionsByRuleID mocks base method.
+func (m *MockAutomationRepository) GetExecutionsByRuleID(ctx context.Context, ruleID int64, limit int) ([]domain.AutomationExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExecutionsByRuleID", ctx, ruleID, limit)
+	ret0, _ := ret[0].([]domain.AutomationExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExecutionsByRuleID indicates an expected call of GetExecutionsByRuleID.
+func (mr *MockAutomationRepositoryMockRecorder) GetExecutionsByRuleID(ctx, ruleID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExecutionsByRuleID", reflect.TypeOf((*MockAutomationRepository)(nil).GetExecutionsByRuleID), ctx, ruleID, limit)
+}
+
+// GetRuleByID mocks base method.
+func (m *MockAutomationRepository) GetRuleByID(ctx context.Context, id int64) (*domain.AutomationRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuleByID", ctx, id)
+	ret0, _ := ret[0].(*domain.AutomationRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuleByID indicates an expected call of GetRuleByID.
+func (mr *MockAutomationRepositoryMockRecorder) GetRuleByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleByID", reflect.TypeOf((*MockAutomationRepository)(nil).GetRuleByID), ctx, id)
+}
+
+// GetRules mocks base method.
+func (m *MockAutomationRepository) GetRules(ctx context.Context) ([]domain.AutomationRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRules", ctx)
+	ret0, _ := ret[0].([]domain.AutomationRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRules indicates an expected call of GetRules.
+func (mr *MockAutomationRepositoryMockRecorder) GetRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRules", reflect.TypeOf((*MockAutomationRepository)(nil).GetRules), ctx)
+}
+
+// SaveExecution mocks base method.
+func (m *MockAutomationRepository) SaveExecution(ctx context.Context, execution *domain.AutomationExecution) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveExecution", ctx, execution)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveExecution indicates an expected call of SaveExecution.
+func (mr *MockAutomationRepositoryMockRecorder) SaveExecution(ctx, execution interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveExecution", reflect.TypeOf((*MockAutomationRepository)(nil).SaveExecution), ctx, execution)
+}
+
+// SaveRule mocks base method.
+func (m *MockAutomationRepository) SaveRule(ctx context.Context, rule *domain.AutomationRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRule indicates an expected call of SaveRule.
+func (mr *MockAutomationRepositoryMockRecorder) SaveRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRule", reflect.TypeOf((*MockAutomationRepository)(nil).SaveRule), ctx, rule)
+}
+
+// UpdateRule mocks base method.
+func (m *MockAutomationRepository) UpdateRule(ctx context.Context, rule *domain.AutomationRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRule indicates an expected call of UpdateRule.
+func (mr *MockAutomationRepositoryMockRecorder) UpdateRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRule", reflect.TypeOf((*MockAutomationRepository)(nil).UpdateRule), ctx, rule)
+}
+
+// MockWebhookSender is a mock of WebhookSender interface.
+type MockWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookSenderMockRecorder
+}
+
+// MockWebhookSenderMockRecorder is the mock recorder for MockWebhookSender.
+type MockWebhookSenderMockRecorder struct {
+	mock *MockWebhookSender
+}
+
+// NewMockWebhookSender creates a new mock instance.
+func NewMockWebhookSender(ctrl *gomock.Controller) *MockWebhookSender {
+	mock := &MockWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookSender) EXPECT() *MockWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// SendWebhook mocks base method.
+func (m *MockWebhookSender) SendWebhook(ctx context.Context, url string, body []byte, depth int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWebhook", ctx, url, body, depth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendWebhook indicates an expected call of SendWebhook.
+func (mr *MockWebhookSenderMockRecorder) SendWebhook(ctx, url, body, depth interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWebhook", reflect.TypeOf((*MockWebhookSender)(nil).SendWebhook), ctx, url, body, depth)
+}
+
+// MockEventListener is a mock of EventListener interface.
+type MockEventListener struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventListenerMockRecorder
+}
+
+// MockEventListenerMockRecorder is the mock recorder for MockEventListener.
+type MockEventListenerMockRecorder struct {
+	mock *MockEventListener
+}
+
+// NewMockEventListener creates a new mock instance.
+func NewMockEventListener(ctrl *gomock.Controller) *MockEventListener {
+	mock := &MockEventListener{ctrl: ctrl}
+	mock.recorder = &MockEventListenerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventListener) EXPECT() *MockEventListenerMockRecorder {
+	return m.recorder
+}
+
+// HandleEvent mocks base method.
+func (m *MockEventListener) HandleEvent(ctx context.Context, event domain.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleEvent", ctx, event)
+}
+
+// HandleEvent indicates an expected call of HandleEvent.
+func (mr *MockEventListenerMockRecorder) HandleEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleEvent", reflect.TypeOf((*MockEventListener)(nil).HandleEvent), ctx, event)
+}
+
+// MockJobRepository is a mock of JobRepository interface.
+type MockJobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobRepositoryMockRecorder
+}
+
+// MockJobRepositoryMockRecorder is the mock recorder for MockJobRepository.
+type MockJobRepositoryMockRecorder struct {
+	mock *MockJobRepository
+}
+
+// NewMockJobRepository creates a new mock instance.
+func NewMockJobRepository(ctrl *gomock.Controller) *MockJobRepository {
+	mock := &MockJobRepository{ctrl: ctrl}
+	mock.recorder = &MockJobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobRepository) EXPECT() *MockJobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetJobByName mocks base method.
+func (m *MockJobRepository) GetJobByName(ctx context.Context, name string) (*domain.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobByName", ctx, name)
+	ret0, _ := ret[0].(*domain.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobByName indicates an expected call of GetJobByName.
+func (mr *MockJobRepositoryMockRecorder) GetJobByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobByName", reflect.TypeOf((*MockJobRepository)(nil).GetJobByName), ctx, name)
+}
+
+// GetJobs mocks base method.
+func (m *MockJobRepository) GetJobs(ctx context.Context) ([]domain.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobs", ctx)
+	ret0, _ := ret[0].([]domain.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobs indicates an expected call of GetJobs.
+func (mr *MockJobRepositoryMockRecorder) GetJobs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobs", reflect.TypeOf((*MockJobRepository)(nil).GetJobs), ctx)
+}
+
+// SaveJob mocks base method.
+func (m *MockJobRepository) SaveJob(ctx context.Context, job *domain.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveJob indicates an expected call of SaveJob.
+func (mr *MockJobRepositoryMockRecorder) SaveJob(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveJob", reflect.TypeOf((*MockJobRepository)(nil).SaveJob), ctx, job)
+}
+
+// UpdateJob mocks base method.
+func (m *MockJobRepository) UpdateJob(ctx context.Context, job *domain.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateJob indicates an expected call of UpdateJob.
+func (mr *MockJobRepositoryMockRecorder) UpdateJob(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJob", reflect.TypeOf((*MockJobRepository)(nil).UpdateJob), ctx, job)
+}
+
+// UpdateJobRun mocks base method.
+func (m *MockJobRepository) UpdateJobRun(ctx context.Context, job *domain.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJobRun", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateJobRun indicates an expected call of UpdateJobRun.
+func (mr *MockJobRepositoryMockRecorder) UpdateJobRun(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJobRun", reflect.TypeOf((*MockJobRepository)(nil).UpdateJobRun), ctx, job)
+}
+
+// MockJobLocker is a mock of JobLocker interface.
+type MockJobLocker struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobLockerMockRecorder
+}
+
+// MockJobLockerMockRecorder is the mock recorder for MockJobLocker.
+type MockJobLockerMockRecorder struct {
+	mock *MockJobLocker
+}
+
+// NewMockJobLocker creates a new mock instance.
+func NewMockJobLocker(ctrl *gomock.Controller) *MockJobLocker {
+	mock := &MockJobLocker{ctrl: ctrl}
+	mock.recorder = &MockJobLockerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobLocker) EXPECT() *MockJobLockerMockRecorder {
+	return m.recorder
+}
+
+// TryLockJob mocks base method.
+func (m *MockJobLocker) TryLockJob(ctx context.Context, name string) (func(), bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryLockJob", ctx, name)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TryLockJob indicates an expected call of TryLockJob.
+func (mr *MockJobLockerMockRecorder) TryLockJob(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryLockJob", reflect.TypeOf((*MockJobLocker)(nil).TryLockJob), ctx, name)
+}
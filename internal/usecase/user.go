@@ -5,23 +5,33 @@ import (
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/tracing"
+	"log/slog"
 )
 
 type User struct {
 	userRepository        UserRepository
 	sensorOwnerRepository SensorOwnerRepository
 	sensorRepository      SensorRepository
+	locationRepository    LocationRepository
+	logger                *slog.Logger
 }
 
-func NewUser(ur UserRepository, sor SensorOwnerRepository, sr SensorRepository) *User {
+func NewUser(ur UserRepository, sor SensorOwnerRepository, sr SensorRepository, lr LocationRepository, options ...Option) *User {
+	o := applyOptions(options)
 	return &User{
 		userRepository:        ur,
 		sensorOwnerRepository: sor,
 		sensorRepository:      sr,
+		locationRepository:    lr,
+		logger:                o.logger,
 	}
 }
 
-func (u *User) RegisterUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+func (u *User) RegisterUser(ctx context.Context, user *domain.User) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "User.RegisterUser")
+	defer tracing.End(span, &err)
+
 	if user == nil {
 		return user, errors.New("got nil user at RegisterUser()")
 	}
@@ -32,11 +42,15 @@ func (u *User) RegisterUser(ctx context.Context, user *domain.User) (*domain.Use
 	if err := u.userRepository.SaveUser(ctx, user); err != nil { // Modifies user assigning new id
 		return user, err
 	}
+	u.logger.InfoContext(ctx, "user registered", "user_id", user.ID)
 	return user, nil
 }
 
-func (u *User) AttachSensorToUser(ctx context.Context, userID, sensorID int64) error {
-	_, err := u.userRepository.GetUserByID(ctx, userID)
+func (u *User) AttachSensorToUser(ctx context.Context, userID, sensorID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "User.AttachSensorToUser")
+	defer tracing.End(span, &err)
+
+	_, err = u.userRepository.GetUserByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("got invalid user id (%v): %w", userID, err)
 	}
@@ -45,14 +59,23 @@ func (u *User) AttachSensorToUser(ctx context.Context, userID, sensorID int64) e
 		return fmt.Errorf("got invalid sensor id (%v): %w", userID, err)
 	}
 
-	return u.sensorOwnerRepository.SaveSensorOwner(ctx, domain.SensorOwner{
+	if err := u.sensorOwnerRepository.SaveSensorOwner(ctx, domain.SensorOwner{
 		UserID:   userID,
 		SensorID: sensorID,
-	})
+	}); err != nil {
+		return err
+	}
+	u.logger.InfoContext(ctx, "sensor attached to user", "user_id", userID, "sensor_id", sensorID)
+	return nil
 }
 
-func (u *User) GetUserSensors(ctx context.Context, userID int64) ([]domain.Sensor, error) {
-	_, err := u.userRepository.GetUserByID(ctx, userID)
+// GetUserSensors - sensors attached to the user directly and the sensors
+// of the locations the user has access to, including the nested ones
+func (u *User) GetUserSensors(ctx context.Context, userID int64) (_ []domain.Sensor, err error) {
+	ctx, span := tracing.Start(ctx, "User.GetUserSensors")
+	defer tracing.End(span, &err)
+
+	_, err = u.userRepository.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("got invalid user id (%v): %w", userID, err)
 	}
@@ -63,13 +86,106 @@ func (u *User) GetUserSensors(ctx context.Context, userID int64) ([]domain.Senso
 	}
 
 	result := make([]domain.Sensor, 0, len(soArr))
+	attached := make(map[int64]struct{}, len(soArr))
 	for _, so := range soArr {
 		s, err := u.sensorRepository.GetSensorByID(ctx, so.SensorID)
 		if err != nil {
 			return result, fmt.Errorf("error getting sensor from repository: %v, %w", so, err)
 		}
 		result = append(result, *s)
+		attached[s.ID] = struct{}{}
+	}
+
+	locations, err := u.accessibleLocations(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+	if len(locations) == 0 {
+		return result, nil
+	}
+	accessible := make(map[int64]struct{}, len(locations))
+	for _, l := range locations {
+		accessible[l.ID] = struct{}{}
+	}
+
+	sensors, err := u.sensorRepository.GetSensors(ctx)
+	if err != nil {
+		return result, fmt.Errorf("cannot get sensors from repository: %w", err)
+	}
+	for _, s := range sensors {
+		if _, ok := attached[s.ID]; ok {
+			continue
+		}
+		if _, ok := accessible[s.LocationID]; ok {
+			result = append(result, s)
+		}
 	}
 
 	return result, nil
 }
+
+// accessibleLocations - locations the user has access to along with all their descendants
+func (u *User) accessibleLocations(ctx context.Context, userID int64) ([]domain.Location, error) {
+	accesses, err := u.locationRepository.GetLocationAccessByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get location access for required user %v: %w", userID, err)
+	}
+	if len(accesses) == 0 {
+		return nil, nil
+	}
+
+	locations, err := u.locationRepository.GetLocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get locations from repository: %w", err)
+	}
+	roots := make([]int64, 0, len(accesses))
+	for _, a := range accesses {
+		roots = append(roots, a.LocationID)
+	}
+	subtree := domain.LocationSubtree(locations, roots...)
+
+	result := make([]domain.Location, 0, len(subtree))
+	for _, l := range locations {
+		if _, ok := subtree[l.ID]; ok {
+			result = append(result, l)
+		}
+	}
+	return result, nil
+}
+
+// GrantLocationAccess - gives the user access to the location and all its nested locations
+func (u *User) GrantLocationAccess(ctx context.Context, userID, locationID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "User.GrantLocationAccess")
+	defer tracing.End(span, &err)
+
+	_, err = u.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("got invalid user id (%v): %w", userID, err)
+	}
+	_, err = u.locationRepository.GetLocationByID(ctx, locationID)
+	if err != nil {
+		return fmt.Errorf("got invalid location id (%v): %w", locationID, err)
+	}
+
+	if err := u.locationRepository.SaveLocationAccess(ctx, domain.LocationAccess{
+		UserID:     userID,
+		LocationID: locationID,
+	}); err != nil {
+		return err
+	}
+	u.logger.InfoContext(ctx, "location access granted", "user_id", userID, "location_id", locationID)
+	return nil
+}
+
+// GetUserLocations - locations the user has access to, including the nested ones
+func (u *User) GetUserLocations(ctx context.Context, userID int64) (_ []domain.Location, err error) {
+	ctx, span := tracing.Start(ctx, "User.GetUserLocations")
+	defer tracing.End(span, &err)
+
+	_, err = u.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("got invalid user id (%v): %w", userID, err)
+	}
+
+	return u.accessibleLocations(ctx, userID)
+}
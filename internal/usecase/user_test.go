@@ -18,7 +18,7 @@ func Test_user_RegisterUser(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		u := NewUser(nil, nil, nil)
+		u := NewUser(nil, nil, nil, nil)
 
 		_, err := u.RegisterUser(ctx, &domain.User{})
 		assert.ErrorIs(t, err, ErrInvalidUserName)
@@ -32,7 +32,7 @@ func Test_user_RegisterUser(t *testing.T) {
 		expectedError := errors.New("doh")
 		ur.EXPECT().SaveUser(ctx, gomock.Any()).Times(1).Return(expectedError)
 
-		u := NewUser(ur, nil, nil)
+		u := NewUser(ur, nil, nil, nil)
 
 		_, err := u.RegisterUser(ctx, &domain.User{
 			Name: "Homer Simpson",
@@ -50,7 +50,7 @@ func Test_user_RegisterUser(t *testing.T) {
 			u.ID = 1
 		})
 
-		u := NewUser(ur, nil, nil)
+		u := NewUser(ur, nil, nil, nil)
 
 		user, err := u.RegisterUser(ctx, &domain.User{
 			Name: "Homer Simpson",
@@ -71,7 +71,7 @@ func Test_user_AttachSensorToUser(t *testing.T) {
 		ur := NewMockUserRepository(ctrl)
 		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(1).Return(nil, ErrUserNotFound)
 
-		u := NewUser(ur, nil, nil)
+		u := NewUser(ur, nil, nil, nil)
 
 		err := u.AttachSensorToUser(ctx, 1, 1)
 		assert.ErrorIs(t, err, ErrUserNotFound)
@@ -87,7 +87,7 @@ func Test_user_AttachSensorToUser(t *testing.T) {
 		sr := NewMockSensorRepository(ctrl)
 		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(nil, ErrSensorNotFound)
 
-		u := NewUser(ur, nil, sr)
+		u := NewUser(ur, nil, sr, nil)
 
 		err := u.AttachSensorToUser(ctx, 1, 1)
 		assert.ErrorIs(t, err, ErrSensorNotFound)
@@ -107,7 +107,7 @@ func Test_user_AttachSensorToUser(t *testing.T) {
 		expectedError := errors.New("some error")
 		sor.EXPECT().SaveSensorOwner(ctx, gomock.Any()).Times(1).Return(expectedError)
 
-		u := NewUser(ur, sor, sr)
+		u := NewUser(ur, sor, sr, nil)
 
 		err := u.AttachSensorToUser(ctx, 1, 1)
 		assert.ErrorIs(t, err, expectedError)
@@ -129,7 +129,7 @@ func Test_user_AttachSensorToUser(t *testing.T) {
 			assert.Equal(t, int64(1), o.SensorID)
 		})
 
-		u := NewUser(ur, sor, sr)
+		u := NewUser(ur, sor, sr, nil)
 
 		err := u.AttachSensorToUser(ctx, 1, 1)
 		assert.NoError(t, err)
@@ -147,7 +147,7 @@ func Test_user_GetUserSensors(t *testing.T) {
 		ur := NewMockUserRepository(ctrl)
 		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(1).Return(nil, ErrUserNotFound)
 
-		u := NewUser(ur, nil, nil)
+		u := NewUser(ur, nil, nil, nil)
 
 		_, err := u.GetUserSensors(ctx, 1)
 		assert.ErrorIs(t, err, ErrUserNotFound)
@@ -164,7 +164,7 @@ func Test_user_GetUserSensors(t *testing.T) {
 		expectedError := errors.New("some error")
 		sor.EXPECT().GetSensorsByUserID(ctx, gomock.Any()).Times(1).Return(nil, expectedError)
 
-		u := NewUser(ur, sor, nil)
+		u := NewUser(ur, sor, nil, nil)
 
 		_, err := u.GetUserSensors(ctx, 1)
 		assert.ErrorIs(t, err, expectedError)
@@ -189,7 +189,7 @@ func Test_user_GetUserSensors(t *testing.T) {
 		expectedError := errors.New("some error")
 		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(nil, expectedError)
 
-		u := NewUser(ur, sor, sr)
+		u := NewUser(ur, sor, sr, nil)
 
 		_, err := u.GetUserSensors(ctx, 1)
 		assert.ErrorIs(t, err, expectedError)
@@ -223,10 +223,50 @@ func Test_user_GetUserSensors(t *testing.T) {
 		sr.EXPECT().GetSensorByID(ctx, int64(2)).Times(1).Return(&domain.Sensor{ID: 2, Type: domain.SensorTypeContactClosure}, nil)
 		sr.EXPECT().GetSensorByID(ctx, int64(3)).Times(1).Return(&domain.Sensor{ID: 3, Type: domain.SensorTypeContactClosure}, nil)
 
-		u := NewUser(ur, sor, sr)
+		lr := NewMockLocationRepository(ctrl)
+		lr.EXPECT().GetLocationAccessByUserID(ctx, int64(1)).Times(1).Return(nil, nil)
+
+		u := NewUser(ur, sor, sr, lr)
 
 		sensors, err := u.GetUserSensors(ctx, 1)
 		assert.NoError(t, err)
 		assert.Len(t, sensors, 3)
 	})
 }
+
+func Test_user_GetUserSensors_locations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ur := NewMockUserRepository(ctrl)
+	ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1}, nil)
+
+	sor := NewMockSensorOwnerRepository(ctrl)
+	sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{{UserID: 1, SensorID: 3}}, nil)
+
+	lr := NewMockLocationRepository(ctrl)
+	lr.EXPECT().GetLocationAccessByUserID(ctx, int64(1)).Times(1).Return([]domain.LocationAccess{{UserID: 1, LocationID: 2}}, nil)
+	lr.EXPECT().GetLocations(ctx).Times(1).Return([]domain.Location{
+		{ID: 1, Kind: domain.LocationKindHome},
+		{ID: 2, ParentID: 1, Kind: domain.LocationKindFloor},
+		{ID: 3, ParentID: 2, Kind: domain.LocationKindRoom},
+	}, nil)
+
+	sr := NewMockSensorRepository(ctrl)
+	sr.EXPECT().GetSensorByID(ctx, int64(3)).Times(1).Return(&domain.Sensor{ID: 3, LocationID: 3}, nil)
+	sr.EXPECT().GetSensors(ctx).Times(1).Return([]domain.Sensor{
+		{ID: 1, LocationID: 1},
+		{ID: 2, LocationID: 3},
+		{ID: 3, LocationID: 3},
+		{ID: 4},
+	}, nil)
+
+	u := NewUser(ur, sor, sr, lr)
+
+	sensors, err := u.GetUserSensors(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Sensor{{ID: 3, LocationID: 3}, {ID: 2, LocationID: 3}}, sensors)
+}
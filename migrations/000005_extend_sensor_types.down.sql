@@ -0,0 +1,5 @@
+-- enum values can't be dropped, the type is recreated; fails if sensors of the new types are present
+alter table sensors alter column type type text;
+drop type sensor_type;
+create type sensor_type as enum ('cc', 'adc');
+alter table sensors alter column type type sensor_type using type::sensor_type;
@@ -0,0 +1,4 @@
+alter type sensor_type add value if not exists 'temperature';
+alter type sensor_type add value if not exists 'humidity';
+alter type sensor_type add value if not exists 'motion';
+alter type sensor_type add value if not exists 'power_meter';
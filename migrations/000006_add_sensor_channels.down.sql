@@ -0,0 +1,6 @@
+alter table events
+    drop column channel_values;
+
+alter table sensors
+    drop column current_values,
+    drop column channels;
@@ -0,0 +1,6 @@
+alter table sensors
+    add column channels       jsonb,
+    add column current_values jsonb;
+
+alter table events
+    add column channel_values jsonb;
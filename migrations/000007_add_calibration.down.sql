@@ -0,0 +1,8 @@
+drop index if exists events_sensor_id_timestamp_idx;
+
+alter table events
+    drop column raw_channel_values,
+    drop column raw_payload;
+
+alter table sensors
+    drop column calibration;
@@ -0,0 +1,15 @@
+alter table sensors
+    add column calibration jsonb;
+
+alter table events
+    add column raw_payload        bigint,
+    add column raw_channel_values jsonb;
+
+update events
+set raw_payload        = payload,
+    raw_channel_values = channel_values;
+
+alter table events
+    alter column raw_payload set not null;
+
+create index events_sensor_id_timestamp_idx on events (sensor_id, timestamp);
@@ -0,0 +1,6 @@
+alter table sensors
+    drop column location_id;
+
+drop table locations_users;
+
+drop table locations;
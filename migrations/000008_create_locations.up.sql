@@ -0,0 +1,18 @@
+create table locations
+(
+    id        bigserial not null unique,
+    parent_id bigint references locations (id),
+    kind      text      not null check (kind in ('home', 'floor', 'room')),
+    name      text      not null
+);
+
+create table locations_users
+(
+    id          bigserial not null,
+    location_id bigint    not null references locations (id),
+    user_id     bigint    not null,
+    unique (location_id, user_id)
+);
+
+alter table sensors
+    add column location_id bigint references locations (id);
@@ -0,0 +1,4 @@
+drop index if exists sensors_labels_idx;
+
+alter table sensors
+    drop column labels;
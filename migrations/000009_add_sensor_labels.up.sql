@@ -0,0 +1,4 @@
+alter table sensors
+    add column labels jsonb not null default '{}';
+
+create index sensors_labels_idx on sensors using gin (labels);
@@ -0,0 +1,3 @@
+drop table commands;
+
+drop table actuators;
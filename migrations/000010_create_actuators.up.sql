@@ -0,0 +1,23 @@
+create table actuators
+(
+    id            bigserial not null unique,
+    serial_number text      not null unique,
+    type          text      not null check (type in ('relay', 'valve', 'dimmer')),
+    description   text      not null default '',
+    registered_at timestamp
+);
+
+create table commands
+(
+    id          bigserial not null unique,
+    actuator_id bigint    not null references actuators (id),
+    action      text      not null check (action in ('on', 'off', 'open', 'close', 'set')),
+    value       bigint    not null default 0,
+    status      text      not null check (status in ('pending', 'delivered', 'acked', 'failed', 'expired')),
+    error       text      not null default '',
+    created_at  timestamp not null,
+    updated_at  timestamp not null,
+    expires_at  timestamp not null
+);
+
+create index commands_actuator_id_idx on commands (actuator_id, id);
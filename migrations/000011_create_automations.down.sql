@@ -0,0 +1,3 @@
+drop table automation_executions;
+
+drop table automation_rules;
@@ -0,0 +1,24 @@
+create table automation_rules
+(
+    id         bigserial not null unique,
+    name       text      not null,
+    enabled    boolean   not null default true,
+    trigger    jsonb     not null,
+    conditions jsonb,
+    actions    jsonb     not null,
+    cooldown   interval  not null default '0',
+    created_at timestamp not null
+);
+
+create table automation_executions
+(
+    id         bigserial not null unique,
+    rule_id    bigint    not null references automation_rules (id) on delete cascade,
+    trigger    text      not null,
+    status     text      not null check (status in ('succeeded', 'failed', 'skipped')),
+    reason     text      not null default '',
+    depth      integer   not null default 0,
+    started_at timestamp not null
+);
+
+create index automation_executions_rule_id_idx on automation_executions (rule_id, id);
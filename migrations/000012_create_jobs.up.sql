@@ -0,0 +1,10 @@
+create table jobs
+(
+    name          text      not null primary key,
+    schedule      text      not null,
+    enabled       boolean   not null default true,
+    last_run_at   timestamp,
+    last_status   text      not null default '' check (last_status in ('', 'succeeded', 'failed')),
+    last_error    text      not null default '',
+    last_duration interval  not null default '0'
+);
@@ -0,0 +1,4 @@
+drop index if exists events_sensor_id_idempotency_key_idx;
+
+alter table events
+    drop column idempotency_key;
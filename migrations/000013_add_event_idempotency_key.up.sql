@@ -0,0 +1,5 @@
+alter table events
+    add column idempotency_key text not null default '';
+
+create unique index events_sensor_id_idempotency_key_idx on events (sensor_id, idempotency_key)
+    where idempotency_key <> '';
@@ -0,0 +1,2 @@
+drop table pending_sensor_events;
+drop table pending_sensors;
@@ -0,0 +1,19 @@
+create table pending_sensors
+(
+    serial_number text      not null primary key,
+    first_seen    timestamp not null,
+    last_seen     timestamp not null,
+    seen          bigint    not null default 0
+);
+
+create table pending_sensor_events
+(
+    id                   bigserial not null primary key,
+    sensor_serial_number text      not null references pending_sensors (serial_number) on delete cascade,
+    timestamp            timestamp not null,
+    payload              bigint    not null,
+    channel_values       jsonb,
+    idempotency_key      text      not null default ''
+);
+
+create index pending_sensor_events_sensor_serial_number_idx on pending_sensor_events (sensor_serial_number, id);
@@ -0,0 +1 @@
+drop index commands_expiring_idx;
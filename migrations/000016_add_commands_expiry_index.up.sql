@@ -0,0 +1 @@
+create index commands_expiring_idx on commands (expires_at) where status in ('pending', 'delivered');
@@ -0,0 +1,2 @@
+alter table events
+    add column id bigserial primary key;
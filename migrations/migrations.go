@@ -0,0 +1,9 @@
+// Package migrations - SQL migrations of the postgres storage embedded into the binaries
+package migrations
+
+import "embed"
+
+// FS - the *.up.sql and *.down.sql migrations of the golang-migrate format
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,205 @@
+// Package client - typed client of the home controller HTTP API (/api v1), its requests and responses
+// are checked against api/openapi.yaml in the tests
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const ( // Defaults
+	DefaultTimeout           = 10 * time.Second
+	DefaultReconnectMinDelay = 500 * time.Millisecond
+	DefaultReconnectMaxDelay = 30 * time.Second
+
+	apiPrefix   = "/api"
+	jsonType    = "application/json"
+	problemType = "application/problem+json"
+)
+
+var ErrInvalidBaseURL = errors.New("invalid server base URL")
+
+// Client - client of the controller API, safe for concurrent use
+type Client struct {
+	baseURL           *url.URL
+	httpClient        *http.Client
+	apiKey            string
+	userAgent         string
+	reconnectMinDelay time.Duration
+	reconnectMaxDelay time.Duration
+}
+
+// New - client of the server at the base URL, e.g. http://localhost:8080, the /api prefix is added by the client
+func New(baseURL string, options ...func(*Client)) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBaseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q, expected http(s)://host[:port]", ErrInvalidBaseURL, baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:           u,
+		httpClient:        &http.Client{Timeout: DefaultTimeout},
+		userAgent:         "homework-client",
+		reconnectMinDelay: DefaultReconnectMinDelay,
+		reconnectMaxDelay: DefaultReconnectMaxDelay,
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c, nil
+}
+
+// WithHTTPClient - sets the client the requests are sent with, its timeout doesn't apply to the subscriptions
+func WithHTTPClient(httpClient *http.Client) func(*Client) {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey - sends the key in X-API-Key, the server rate limits the keys separately from the client IPs
+func WithAPIKey(key string) func(*Client) {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithUserAgent - sets the User-Agent of the requests
+func WithUserAgent(userAgent string) func(*Client) {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithReconnectDelay - sets the bounds of the exponential backoff the subscriptions reconnect with
+func WithReconnectDelay(minDelay, maxDelay time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.reconnectMinDelay = minDelay
+		c.reconnectMaxDelay = maxDelay
+	}
+}
+
+// Problem - error response of the API (RFC 7807), Code is the stable machine-readable code to branch on
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Code          string         `json:"code"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	RequestID     string         `json:"request_id,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam - invalid field of the request body
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+func (p *Problem) Error() string {
+	msg := fmt.Sprintf("%d %s", p.Status, p.Title)
+	if p.Code != "" {
+		msg += " (" + p.Code + ")"
+	}
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	for _, param := range p.InvalidParams {
+		msg += fmt.Sprintf("; %s: %s", param.Name, param.Reason)
+	}
+	return msg
+}
+
+// IsProblem - whether the error is an API error with the code, e.g. IsProblem(err, "sensor_not_found")
+func IsProblem(err error, code string) bool {
+	var p *Problem
+	return errors.As(err, &p) && p.Code == code
+}
+
+// problemFromResponse - the problem of the error response, the responses without one (e.g. of a proxy)
+// get a problem with the status only
+func problemFromResponse(resp *http.Response, body []byte) *Problem {
+	p := &Problem{}
+	if ct := resp.Header.Get("Content-Type"); strings.HasPrefix(ct, problemType) || strings.HasPrefix(ct, jsonType) {
+		_ = json.Unmarshal(body, p)
+	}
+	if p.Status == 0 {
+		p.Status = resp.StatusCode
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(resp.StatusCode)
+	}
+	return p
+}
+
+// endpoint - absolute URL of the API path
+func (c *Client) endpoint(path string, query url.Values) *url.URL {
+	u := *c.baseURL
+	u.Path += apiPrefix + path
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+func (c *Client) header() http.Header {
+	h := http.Header{}
+	h.Set("Accept", jsonType)
+	h.Set("User-Agent", c.userAgent)
+	if c.apiKey != "" {
+		h.Set("X-API-Key", c.apiKey)
+	}
+	return h
+}
+
+// do - sends the JSON request and decodes the JSON response into out (if not nil),
+// returns the response status, the error responses are returned as *Problem
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) (int, error) {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return 0, fmt.Errorf("can't encode %s %s request: %w", method, path, err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint(path, query).String(), body)
+	if err != nil {
+		return 0, fmt.Errorf("can't create %s %s request: %w", method, path, err)
+	}
+	req.Header = c.header()
+	if in != nil {
+		req.Header.Set("Content-Type", jsonType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("can't read %s %s response: %w", method, path, err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode, problemFromResponse(resp, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("can't decode %s %s response: %w", method, path, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"homework/internal/testserver"
+)
+
+func newTestClient(t *testing.T, srv *testserver.TestServer, options ...func(*Client)) *Client {
+	t.Helper()
+	// the keep-alive connections would be dropped along with the subscriptions
+	transport := &http.Transport{DisableKeepAlives: true}
+	options = append([]func(*Client){
+		WithHTTPClient(&http.Client{Transport: testserver.SpecTransport(t, transport), Timeout: 5 * time.Second}),
+	}, options...)
+	c, err := New(srv.URL, options...)
+	require.NoError(t, err)
+	return c
+}
+
+func payload(v int64) *int64 {
+	return &v
+}
+
+func TestNew(t *testing.T) {
+	for _, baseURL := range []string{"localhost:8080", "ftp://localhost", "http://", ":"} {
+		_, err := New(baseURL)
+		assert.ErrorIs(t, err, ErrInvalidBaseURL, baseURL)
+	}
+
+	c, err := New("http://localhost:8080/")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/api/sensors/1", c.endpoint(sensorPath(1), nil).String())
+}
+
+func TestClient(t *testing.T) {
+	srv := testserver.SetupTestServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	t.Run("ok, sensors", func(t *testing.T) {
+		sensor, err := c.RegisterSensor(ctx, SensorToCreate{
+			SerialNumber: "0000000001",
+			Type:         "cc",
+			Description:  "front door",
+			IsActive:     true,
+			Labels:       map[string]string{"kind": "door"},
+		})
+		require.NoError(t, err)
+		assert.NotZero(t, sensor.ID)
+		assert.Equal(t, "front door", sensor.Description)
+		assert.False(t, sensor.RegisteredAt.IsZero())
+
+		got, err := c.Sensor(ctx, sensor.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sensor.SerialNumber, got.SerialNumber)
+		assert.Equal(t, map[string]string{"kind": "door"}, got.Labels)
+
+		sensors, err := c.Sensors(ctx)
+		require.NoError(t, err)
+		assert.Len(t, sensors, 1)
+	})
+
+	t.Run("fail, problems", func(t *testing.T) {
+		_, err := c.Sensor(ctx, 42)
+		assert.True(t, IsProblem(err, "sensor_not_found"), err)
+		var p *Problem
+		require.True(t, errors.As(err, &p))
+		assert.Equal(t, http.StatusNotFound, p.Status)
+		assert.Equal(t, "/api/sensors/42", p.Instance)
+
+		_, err = c.RegisterSensor(ctx, SensorToCreate{SerialNumber: "1", Type: "cc"})
+		require.True(t, errors.As(err, &p))
+		assert.Equal(t, http.StatusUnprocessableEntity, p.Status)
+		assert.Equal(t, "validation_failed", p.Code)
+		assert.NotEmpty(t, p.InvalidParams)
+		assert.Contains(t, err.Error(), "serial_number")
+	})
+
+	t.Run("ok, users", func(t *testing.T) {
+		user, err := c.CreateUser(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user.Name)
+
+		sensors, err := c.Sensors(ctx)
+		require.NoError(t, err)
+		require.NoError(t, c.BindSensor(ctx, user.ID, sensors[0].ID))
+
+		owned, err := c.UserSensors(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, owned, 1)
+		assert.Equal(t, sensors[0].ID, owned[0].ID)
+
+		err = c.BindSensor(ctx, user.ID, 42)
+		assert.True(t, IsProblem(err, "sensor_not_found"), err)
+	})
+
+	t.Run("ok, events and history", func(t *testing.T) {
+		from := time.Now().Add(-time.Second)
+		created, err := c.PushEvent(ctx, EventToPush{SensorSerialNumber: "0000000001", Payload: payload(1), EventID: "e-1"})
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		created, err = c.PushEvent(ctx, EventToPush{SensorSerialNumber: "0000000001", Payload: payload(1), EventID: "e-1"})
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		_, err = c.PushEvent(ctx, EventToPush{SensorSerialNumber: "0000000009", Payload: payload(1)})
+		assert.Error(t, err)
+
+		sensors, err := c.Sensors(ctx)
+		require.NoError(t, err)
+		history, err := c.SensorHistory(ctx, sensors[0].ID, from, time.Now().Add(time.Second), "")
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, int64(1), history[0].Payload)
+	})
+}
+
+func TestSubscribeSensor(t *testing.T) {
+	srv := testserver.SetupTestServer()
+	defer srv.Close()
+	c := newTestClient(t, srv, WithReconnectDelay(200*time.Millisecond, time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sensor, err := c.RegisterSensor(ctx, SensorToCreate{SerialNumber: "0000000001", Type: "adc", Description: "level", IsActive: true})
+	require.NoError(t, err)
+	push := func(v int64) {
+		t.Helper()
+		_, err := c.PushEvent(ctx, EventToPush{SensorSerialNumber: sensor.SerialNumber, Payload: payload(v)})
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond) // the events are told apart by the millisecond timestamps
+	}
+	push(1)
+
+	t.Run("fail, unknown sensor", func(t *testing.T) {
+		err := c.SubscribeSensor(ctx, 42, func(Event) error { return nil })
+		assert.True(t, IsProblem(err, "sensor_not_found"), err)
+	})
+
+	t.Run("ok, resumed after the connection drop", func(t *testing.T) {
+		received := make(chan Event)
+		done := make(chan error)
+		go func() {
+			done <- c.SubscribeSensor(ctx, sensor.ID, func(e Event) error {
+				received <- e
+				return nil
+			})
+		}()
+		next := func() Event {
+			t.Helper()
+			select {
+			case e := <-received:
+				return e
+			case <-ctx.Done():
+				t.Fatal("no event received")
+				return Event{}
+			}
+		}
+
+		assert.Equal(t, int64(1), next().Payload) // the last event is sent on subscription
+		push(2)
+		assert.Equal(t, int64(2), next().Payload)
+
+		srv.DropConnections()
+		push(3) // while reconnecting
+		push(4)
+		e := next()
+		assert.Equal(t, int64(3), e.Payload)
+		assert.Equal(t, sensor.SerialNumber, e.SensorSerialNumber)
+		assert.Equal(t, int64(4), next().Payload)
+
+		push(5)
+		assert.Equal(t, int64(5), next().Payload)
+
+		select {
+		case e := <-received:
+			t.Fatalf("unexpected event %+v", e)
+		case <-time.After(100 * time.Millisecond):
+		}
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+}
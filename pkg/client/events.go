@@ -0,0 +1,16 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// PushEvent - registers the event, returns false if the server already has the event with the same EventID
+// (the push was retried) and didn't store it again
+func (c *Client) PushEvent(ctx context.Context, event EventToPush) (bool, error) {
+	status, err := c.do(ctx, http.MethodPost, "/events", nil, event, nil)
+	if err != nil {
+		return false, err
+	}
+	return status == http.StatusCreated, nil
+}
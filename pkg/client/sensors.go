@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Sensors - all the sensors
+func (c *Client) Sensors(ctx context.Context) ([]Sensor, error) {
+	var sensors []Sensor
+	if _, err := c.do(ctx, http.MethodGet, "/sensors", nil, nil, &sensors); err != nil {
+		return nil, err
+	}
+	return sensors, nil
+}
+
+// Sensor - the sensor by id, the "sensor_not_found" problem if there is no such sensor
+func (c *Client) Sensor(ctx context.Context, id int64) (Sensor, error) {
+	var sensor Sensor
+	if _, err := c.do(ctx, http.MethodGet, sensorPath(id), nil, nil, &sensor); err != nil {
+		return Sensor{}, err
+	}
+	return sensor, nil
+}
+
+// RegisterSensor - registers the sensor or updates the one with the same serial number
+func (c *Client) RegisterSensor(ctx context.Context, sensor SensorToCreate) (Sensor, error) {
+	var registered Sensor
+	if _, err := c.do(ctx, http.MethodPost, "/sensors", nil, sensor, &registered); err != nil {
+		return Sensor{}, err
+	}
+	return registered, nil
+}
+
+// SensorHistory - states of the sensor channel (the first one if empty) in [from, to]
+func (c *Client) SensorHistory(ctx context.Context, id int64, from, to time.Time, channel string) ([]HistoryPoint, error) {
+	query := url.Values{}
+	query.Set("start_date", from.UTC().Format(time.RFC3339Nano))
+	query.Set("end_date", to.UTC().Format(time.RFC3339Nano))
+	if channel != "" {
+		query.Set("channel", channel)
+	}
+
+	var history []HistoryPoint
+	if _, err := c.do(ctx, http.MethodGet, sensorPath(id)+"/history", query, nil, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func sensorPath(id int64) string {
+	return "/sensors/" + strconv.FormatInt(id, 10)
+}
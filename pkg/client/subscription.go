@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+// replayHorizon - end of the history range replayed after a reconnect, ahead of the client clock
+// so the skew with the server clock doesn't lose the events
+const replayHorizon = time.Hour
+
+// handlerError - error of the subscription callback, stops the subscription as is
+type handlerError struct {
+	err error
+}
+
+func (e handlerError) Error() string { return e.err.Error() }
+
+// sensorStream - state of a sensor subscription kept across the reconnects
+type sensorStream struct {
+	sensorID     int64
+	serialNumber string
+	last         time.Time
+	handle       func(Event) error
+}
+
+// emit - passes the event to the callback unless it was already handled, the events are compared with
+// millisecond precision as the history has no finer timestamps
+func (s *sensorStream) emit(e Event) error {
+	if !s.last.IsZero() && !e.Timestamp.Truncate(time.Millisecond).After(s.last.Truncate(time.Millisecond)) {
+		return nil
+	}
+	s.last = e.Timestamp
+	if e.SensorSerialNumber != "" {
+		s.serialNumber = e.SensorSerialNumber
+	}
+	if err := s.handle(e); err != nil {
+		return handlerError{err: err}
+	}
+	return nil
+}
+
+// SubscribeSensor - calls handle for the events of the sensor, starting with the last one the server has, until
+// ctx is done, handle fails or the server rejects the subscription (e.g. with "sensor_not_found").
+// The dropped connections are reestablished with exponential backoff and the events missed meanwhile are
+// replayed from the history, so each event is handled once and in order
+func (c *Client) SubscribeSensor(ctx context.Context, sensorID int64, handle func(Event) error) error {
+	s := &sensorStream{sensorID: sensorID, handle: handle}
+	delay := c.reconnectMinDelay
+	for {
+		connected, err := c.stream(ctx, s)
+		var he handlerError
+		switch {
+		case errors.As(err, &he):
+			return he.err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case isPermanent(err):
+			return err
+		}
+
+		if connected {
+			delay = c.reconnectMinDelay
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay = min(delay*2, c.reconnectMaxDelay)
+	}
+}
+
+// stream - handles the events of a single connection, replays the missed ones first if it's a reconnect;
+// reports whether the connection was established
+func (c *Client) stream(ctx context.Context, s *sensorStream) (bool, error) {
+	conn, err := c.dial(ctx, sensorPath(s.sensorID)+"/events")
+	if err != nil {
+		return false, err
+	}
+	defer conn.CloseNow()
+
+	// the connection is opened before the replay so the events pushed during it are not lost, they are
+	// buffered by the connection and deduplicated by emit
+	if !s.last.IsZero() {
+		history, err := c.SensorHistory(ctx, s.sensorID, s.last.Truncate(time.Millisecond), time.Now().Add(replayHorizon), "")
+		if err != nil {
+			return true, fmt.Errorf("can't replay sensor %d events: %w", s.sensorID, err)
+		}
+		for _, p := range history {
+			e := Event{
+				Timestamp:          p.Timestamp,
+				SensorSerialNumber: s.serialNumber,
+				SensorID:           s.sensorID,
+				Payload:            p.Payload,
+				RawPayload:         p.RawPayload,
+			}
+			if err := s.emit(e); err != nil {
+				return true, err
+			}
+		}
+	}
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			if websocket.CloseStatus(err) == websocket.StatusNormalClosure {
+				return true, nil
+			}
+			return true, fmt.Errorf("sensor %d subscription: %w", s.sensorID, err)
+		}
+		var e Event
+		if err := json.Unmarshal(data, &e); err != nil {
+			return true, fmt.Errorf("can't decode sensor %d event: %w", s.sensorID, err)
+		}
+		if err := s.emit(e); err != nil {
+			return true, err
+		}
+	}
+}
+
+// dial - opens the websocket, the rejected handshakes are returned as *Problem
+func (c *Client) dial(ctx context.Context, path string) (*websocket.Conn, error) {
+	// the client timeout would limit the whole subscription, the dial is bounded by ctx instead
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+
+	conn, resp, err := websocket.Dial(ctx, c.endpoint(path, nil).String(), &websocket.DialOptions{
+		HTTPClient: &httpClient,
+		HTTPHeader: c.header(),
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, problemFromResponse(resp, body)
+		}
+		return nil, fmt.Errorf("can't open %s subscription: %w", path, err)
+	}
+	return conn, nil
+}
+
+// isPermanent - whether retrying the subscription can't help, i.e. the server rejected the request itself
+func isPermanent(err error) bool {
+	var p *Problem
+	if !errors.As(err, &p) {
+		return false
+	}
+	return p.Status >= http.StatusBadRequest && p.Status < http.StatusInternalServerError &&
+		p.Status != http.StatusRequestTimeout && p.Status != http.StatusTooManyRequests
+}
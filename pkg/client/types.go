@@ -0,0 +1,75 @@
+package client
+
+import "time"
+
+// SensorChannel - channel of a multichannel sensor
+type SensorChannel struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Sensor - sensor registered on the server
+type Sensor struct {
+	ID            int64              `json:"id"`
+	SerialNumber  string             `json:"serial_number"`
+	Type          string             `json:"type"`
+	Description   string             `json:"description"`
+	IsActive      bool               `json:"is_active"`
+	CurrentState  int64              `json:"current_state"`
+	CurrentValue  float64            `json:"current_value"`
+	Unit          string             `json:"unit,omitempty"`
+	Channels      []SensorChannel    `json:"channels,omitempty"`
+	ChannelStates map[string]int64   `json:"channel_states,omitempty"`
+	ChannelValues map[string]float64 `json:"channel_values,omitempty"`
+	Labels        map[string]string  `json:"labels,omitempty"`
+	LocationID    int64              `json:"location_id,omitempty"`
+	LastActivity  time.Time          `json:"last_activity"`
+	RegisteredAt  time.Time          `json:"registered_at"`
+}
+
+// SensorToCreate - sensor to register, the serial number is 10 digits, the type is one of the sensor types
+// (cc, adc, temperature, ...)
+type SensorToCreate struct {
+	SerialNumber string            `json:"serial_number"`
+	Type         string            `json:"type"`
+	Description  string            `json:"description"`
+	IsActive     bool              `json:"is_active"`
+	Channels     []SensorChannel   `json:"channels,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// User - user of the controller
+type User struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// EventToPush - event of a sensor, Payload is required for the sensors without channels and Values for the
+// multichannel ones; EventID makes the push idempotent
+type EventToPush struct {
+	SensorSerialNumber string           `json:"sensor_serial_number"`
+	Payload            *int64           `json:"payload,omitempty"`
+	Values             map[string]int64 `json:"values,omitempty"`
+	EventID            string           `json:"event_id,omitempty"`
+}
+
+// HistoryPoint - state of a sensor channel at the time of an event
+type HistoryPoint struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Payload    int64     `json:"payload"`
+	RawPayload int64     `json:"raw_payload"`
+	Value      float64   `json:"value"`
+}
+
+// Event - event delivered by a subscription; the events replayed from the history after a reconnect
+// only carry the first channel in Payload and RawPayload
+type Event struct {
+	Timestamp          time.Time
+	SensorSerialNumber string
+	SensorID           int64
+	Payload            int64
+	Values             map[string]int64 `json:",omitempty"`
+	RawPayload         int64
+	RawValues          map[string]int64 `json:",omitempty"`
+	IdempotencyKey     string           `json:",omitempty"`
+}
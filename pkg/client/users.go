@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+type userToCreate struct {
+	Name string `json:"name"`
+}
+
+type sensorToUserBinding struct {
+	SensorID int64 `json:"sensor_id"`
+}
+
+// CreateUser - registers the user with the name
+func (c *Client) CreateUser(ctx context.Context, name string) (User, error) {
+	var user User
+	if _, err := c.do(ctx, http.MethodPost, "/users", nil, userToCreate{Name: name}, &user); err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// BindSensor - makes the user an owner of the sensor
+func (c *Client) BindSensor(ctx context.Context, userID, sensorID int64) error {
+	_, err := c.do(ctx, http.MethodPost, userPath(userID)+"/sensors", nil, sensorToUserBinding{SensorID: sensorID}, nil)
+	return err
+}
+
+// UserSensors - the sensors the user owns
+func (c *Client) UserSensors(ctx context.Context, userID int64) ([]Sensor, error) {
+	var sensors []Sensor
+	if _, err := c.do(ctx, http.MethodGet, userPath(userID)+"/sensors", nil, nil, &sensors); err != nil {
+		return nil, err
+	}
+	return sensors, nil
+}
+
+func userPath(id int64) string {
+	return "/users/" + strconv.FormatInt(id, 10)
+}
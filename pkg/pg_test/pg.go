@@ -3,20 +3,15 @@ package pg_test
 //nolint: revive // test stub
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log"
-	"path/filepath"
-	"runtime"
 	"time"
 
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+
+	"homework/internal/migration"
 )
 
 const (
@@ -106,21 +101,13 @@ func createContainer(ctx context.Context) (testcontainers.Container, *pgxpool.Po
 }
 
 func migrateDb(dbAddr string) error {
-	_, path, _, ok := runtime.Caller(0)
-	if !ok {
-		return fmt.Errorf("failed to get path")
-	}
-	pathToMigrationFiles := filepath.Dir(path) + "/../../migrations"
-
-	databaseURL := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", DbUser, DbPass, dbAddr, DbName)
-	m, err := migrate.New(fmt.Sprintf("file:%s", pathToMigrationFiles), databaseURL)
+	m, err := migration.New(fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", DbUser, DbPass, dbAddr, DbName))
 	if err != nil {
 		return err
 	}
 	defer m.Close()
 
-	err = m.Up()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+	if err := m.Up(); err != nil {
 		return err
 	}
 